@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
+	"net/url"
 	"time"
 
 	"github.com/esmailemami/eshop/app"
@@ -12,8 +12,13 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	"github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/services/authentication"
+	"github.com/esmailemami/eshop/app/services/bruteforce"
+	"github.com/esmailemami/eshop/app/services/logger"
 	"github.com/esmailemami/eshop/app/services/notifier/email"
+	"github.com/esmailemami/eshop/app/services/otp"
+	"github.com/esmailemami/eshop/app/services/roles"
 	"github.com/esmailemami/eshop/app/services/token"
+	userservice "github.com/esmailemami/eshop/app/services/user"
 	dbpkg "github.com/esmailemami/eshop/db"
 	dbmodels "github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
@@ -31,6 +36,7 @@ import (
 // @Success 200 {object} models.LoginOutputModel
 // @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]any
+// @Failure 429 {object} map[string]any
 // @Router /admin/login [post]
 func LoginAdmin(ctx *app.HttpContext) error {
 	var input models.LoginInputModel
@@ -51,10 +57,10 @@ func LoginAdmin(ctx *app.HttpContext) error {
 
 	output, err := authentication.LoginByUsername(ctx, input)
 	if err != nil {
-		return errors.NewBadRequestError(err.Error(), err)
+		return loginError(err)
 	}
 
-	ctx.SetCookie("Authorization", output.Token, int(output.ExpiresIn), "/", "", true, true)
+	setAuthCookies(ctx, output)
 
 	return ctx.JSON(*output, http.StatusOK)
 }
@@ -69,6 +75,7 @@ func LoginAdmin(ctx *app.HttpContext) error {
 // @Success 200 {object} models.LoginOutputModel
 // @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]any
+// @Failure 429 {object} map[string]any
 // @Router /user/login [post]
 func LoginUser(ctx *app.HttpContext) error {
 	var input models.LoginInputModel
@@ -89,10 +96,10 @@ func LoginUser(ctx *app.HttpContext) error {
 
 	output, err := authentication.LoginByUsername(ctx, input)
 	if err != nil {
-		return errors.NewBadRequestError(err.Error(), err)
+		return loginError(err)
 	}
 
-	ctx.SetCookie("Authorization", output.Token, int(output.ExpiresIn), "/", "", true, true)
+	setAuthCookies(ctx, output)
 
 	return ctx.JSON(*output, http.StatusOK)
 }
@@ -116,13 +123,11 @@ func IsAuthenticated(ctx *app.HttpContext) error {
 	}
 
 	user := userCtx.(dbmodels.User)
-	if user.RoleID != nil {
-		// user role data not loaded
-		if user.Role == nil {
-			dbConn := dbpkg.MustGormDBConn(ctx)
-			role := dbmodels.Role{}
-			dbConn.Where("id", user.RoleID).First(&role)
-			user.Role = &role
+
+	// user role data not loaded
+	if user.Role == nil {
+		if err := roles.Load(dbpkg.MustGormDBConn(ctx), &user); err != nil {
+			return errors.NewInternalServerError(consts.InternalServerError, err)
 		}
 	}
 	return ctx.JSON(user, http.StatusOK)
@@ -148,8 +153,8 @@ func Logout(ctx *app.HttpContext) error {
 
 	jit := uuid.MustParse((*jwtToken).JwtID())
 
-	// load token from DB
-	err = authentication.RevokeAuthTokenByID(dbpkg.MustGormDBConn(ctx), jit)
+	// load token from DB, the refresh tokens of the login are revoked too
+	err = authentication.RevokeSession(dbpkg.MustGormDBConn(ctx), jit)
 	if err != nil {
 		// can't revoke token, so let client do logout process
 		// and avoid revoking token from DB
@@ -161,9 +166,92 @@ func Logout(ctx *app.HttpContext) error {
 
 func logOutDone(ctx *app.HttpContext) error {
 	ctx.SetCookie("Authorization", "", 0, "/", "", true, true)
+	ctx.SetCookie("RefreshToken", "", 0, "/", "", true, true)
 	return ctx.QuickResponse(consts.LoggedOut, http.StatusOK)
 }
 
+// loginError returns the response of the failed login, the blocked attempts are answered by 429
+func loginError(err error) error {
+	if blocked, ok := err.(*bruteforce.BlockedError); ok {
+		return errors.NewTooManyRequestsError(blocked.Error(), blocked.RetryAfter, nil)
+	}
+
+	if err.Error() == consts.InternalServerError {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return errors.NewBadRequestError(err.Error(), err)
+}
+
+func setAuthCookies(ctx *app.HttpContext, output *models.LoginOutputModel) {
+	// the login waits for the second factor
+	if output.Mfa != nil {
+		return
+	}
+
+	ctx.SetCookie("Authorization", output.Token, int(output.ExpiresIn), "/", "", true, true)
+	ctx.SetCookie("RefreshToken", output.RefreshToken, int(output.RefreshExpiresIn), "/", "", true, true)
+}
+
+// RefreshAdminToken godoc
+// @Summary Refresh the tokens of the admin
+// @Description Exchanges the refresh token for a new access token and a new refresh token, the refresh token is read from the body or the RefreshToken cookie.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param refreshInput   body  models.RefreshTokenInputModel  false  "Refresh input model"
+// @Success 200 {object} models.LoginOutputModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/refresh [post]
+func RefreshAdminToken(ctx *app.HttpContext) error {
+	return refreshToken(ctx, consts.UserActAsAdmin)
+}
+
+// RefreshUserToken godoc
+// @Summary Refresh the tokens of the user
+// @Description Exchanges the refresh token for a new access token and a new refresh token, the refresh token is read from the body or the RefreshToken cookie.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param refreshInput   body  models.RefreshTokenInputModel  false  "Refresh input model"
+// @Success 200 {object} models.LoginOutputModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/refresh [post]
+func RefreshUserToken(ctx *app.HttpContext) error {
+	return refreshToken(ctx, consts.UserActAsUser)
+}
+
+func refreshToken(ctx *app.HttpContext, actAs string) error {
+	var input models.RefreshTokenInputModel
+
+	// the body is optional when the refresh token is sent by the cookie
+	_ = ctx.BlindBind(&input)
+
+	if input.RefreshToken == "" {
+		if c, err := ctx.Request.Cookie("RefreshToken"); err == nil {
+			input.RefreshToken, _ = url.QueryUnescape(c.Value)
+		}
+	}
+
+	if err := input.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	output, err := authentication.Refresh(dbpkg.MustGormDBConn(ctx), input.RefreshToken, actAs)
+	if err != nil {
+		if err.Error() == consts.InternalServerError {
+			return errors.NewInternalServerError(consts.InternalServerError, err)
+		}
+		return errors.NewUnauthorizedError(err.Error(), err)
+	}
+
+	setAuthCookies(ctx, output)
+
+	return ctx.JSON(*output, http.StatusOK)
+}
+
 // Register godoc
 // @Summary a new user.
 // @Description register a new user.
@@ -181,6 +269,8 @@ func Register(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
 
+	input.Mobile, input.Email = normalizeContacts(input.Mobile, input.Email)
+
 	if err := input.Validate(); err != nil {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
@@ -189,19 +279,117 @@ func Register(ctx *app.HttpContext) error {
 
 	user := input.ToDBModel()
 
-	// set default role
-	user.RoleID = func() *uuid.UUID {
-		id := uuid.MustParse(consts.ROLE_USER_ID)
-		return &id
-	}()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
 
-	if err := db.Create(user).Error; err != nil {
+		// set default role
+		return roles.AssignDefault(tx, *user.ID)
+	})
+	if err != nil {
 		return errors.NewValidationError(consts.InternalServerError, err)
 	}
 
+	// the codes are verified from the profile after login, the user can request them again if sending fails
+	for _, change := range []struct {
+		contact userservice.Contact
+		value   *string
+	}{
+		{userservice.Mobile, input.Mobile},
+		{userservice.Email, input.Email},
+	} {
+		if change.value == nil {
+			continue
+		}
+
+		if _, err := change.contact.RequestChange(db, user, *change.value); err != nil {
+			logger.Default().WithField("UserID", user.ID.String()).Error(err.Error())
+		}
+	}
+
 	return ctx.QuickResponse(consts.RegistrationDone, http.StatusOK)
 }
 
+// SendOtp godoc
+// @Summary Send a login code to the mobile number
+// @Description Sends a one time code by sms to the mobile number, the code is used to login or register by the mobile number.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param otpInput   body  models.OtpSendInputModel  true  "Otp send input model"
+// @Success 200 {object} models.OtpSendOutputModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/otp/send [post]
+func SendOtp(ctx *app.HttpContext) error {
+	var input models.OtpSendInputModel
+
+	if err := ctx.BlindBind(&input); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	input.Mobile, _ = otp.NormalizeMobile(input.Mobile)
+
+	if err := input.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	verificationCode, err := otp.Send(dbpkg.MustGormDBConn(ctx), input.Mobile)
+	if err != nil {
+		if err.Error() == consts.InternalServerError {
+			return errors.NewInternalServerError(consts.InternalServerError, err)
+		}
+		return errors.NewBadRequestError(err.Error(), err)
+	}
+
+	return ctx.JSON(models.OtpSendOutputModel{
+		ExpiresAt: verificationCode.ExpireAt,
+		ExpiresIn: int64(otp.CodeTTL.Seconds()),
+		ResendIn:  int64(otp.Cooldown.Seconds()),
+	}, http.StatusOK)
+}
+
+// LoginByOtp godoc
+// @Summary Log user in by the code sent to the mobile number
+// @Description Logs the user in by the one time code, the account is registered on the first login of the mobile number.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param otpLoginInput   body  models.OtpLoginInputModel  true  "Otp login input model"
+// @Success 200 {object} models.LoginOutputModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/otp/verify [post]
+func LoginByOtp(ctx *app.HttpContext) error {
+	var input models.OtpLoginInputModel
+
+	if err := ctx.BlindBind(&input); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	input.Mobile, _ = otp.NormalizeMobile(input.Mobile)
+
+	if err := input.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	input.IP = ctx.ClientIP()
+	input.UserAgent = ctx.UserAgent()
+
+	output, err := authentication.LoginByOtp(ctx, input)
+	if err != nil {
+		if err.Error() == consts.InternalServerError {
+			return errors.NewInternalServerError(consts.InternalServerError, err)
+		}
+		return errors.NewBadRequestError(err.Error(), err)
+	}
+
+	setAuthCookies(ctx, output)
+
+	return ctx.JSON(*output, http.StatusOK)
+}
+
 // Recovery Password godoc
 // @Summary recovery user password.
 // @Description recovery user password.
@@ -212,6 +400,7 @@ func Register(ctx *app.HttpContext) error {
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]any
+// @Failure 429 {object} map[string]any
 // @Router /user/recoveryPasword [post]
 func SendRecoveryPasswordRequest(ctx *app.HttpContext) error {
 	var input models.RecoveryPasswordReqModel
@@ -223,13 +412,18 @@ func SendRecoveryPasswordRequest(ctx *app.HttpContext) error {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
+	if err := authentication.RecoveryAttempt(input.PhoneNumberOrEmailAddress, ctx.ClientIP()); err != nil {
+		return loginError(err)
+	}
+
 	db := dbpkg.MustGormDBConn(ctx)
 
 	var user dbmodels.User
 
+	// only the verified contacts are trusted to receive the recovery link
 	if err := db.Model(&dbmodels.User{}).
-		Where("mobile=?", input.PhoneNumberOrEmailAddress).
-		Or("email=?", input.PhoneNumberOrEmailAddress).First(&user).Error; err != nil {
+		Where("mobile=? AND mobile_verified = true", input.PhoneNumberOrEmailAddress).
+		Or("email=? AND email_verified = true", input.PhoneNumberOrEmailAddress).First(&user).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
 			return errors.NewInternalServerError(consts.InternalServerError, err)
 		} else {
@@ -237,12 +431,13 @@ func SendRecoveryPasswordRequest(ctx *app.HttpContext) error {
 		}
 	}
 
-	if user.Email != nil && strings.TrimSpace(*user.Email) == "" {
+	userEmail, ok := user.VerifiedEmail()
+	if !ok {
 		return ctx.QuickResponse(consts.RecoveryPasswordReqDone, http.StatusOK)
 	}
 
 	// check that is there any not expired verification code
-	if dbpkg.Exists(db, &dbmodels.VerificationCode{}, "scope=? AND key=? AND expire_at>? AND verified = false", dbmodels.VerificationCodeScopeEmail, user.Email, time.Now()) {
+	if dbpkg.Exists(db, &dbmodels.VerificationCode{}, "scope=? AND key=? AND expire_at>? AND verified = false", dbmodels.VerificationCodeScopeEmail, userEmail, time.Now()) {
 		return errors.NewValidationError("We have been sent you an email. If you do not receive the email try again later", nil)
 	}
 
@@ -253,7 +448,7 @@ func SendRecoveryPasswordRequest(ctx *app.HttpContext) error {
 		ExpireAt:   time.Now().Add(5 * time.Minute),
 		MaxRetires: 3,
 		Scope:      dbmodels.VerificationCodeScopeEmail,
-		Key:        *user.Email,
+		Key:        userEmail,
 		Value:      uuid.NewString(),
 		Verified:   false,
 	}
@@ -265,7 +460,7 @@ func SendRecoveryPasswordRequest(ctx *app.HttpContext) error {
 	// send email
 	notifier := email.NewNotifier("gmail")
 	go func() {
-		err := notifier.Send([]string{*user.Email}, email.KeyForgotPassword, email.ForgotPassword{
+		err := notifier.Send([]string{userEmail}, email.KeyForgotPassword, email.ForgotPassword{
 			Username:    user.Username,
 			RecoveryUrl: "http://127.0.0.1:3000/recoveryPassword/" + verificaationCode.Value,
 		})
@@ -324,7 +519,7 @@ func RecoveryPassword(ctx *app.HttpContext) error {
 	// encrypt password
 	pass, _ := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 
-	if err := db.Model(&dbmodels.User{}).Where("email=?", verificationCode.Key).UpdateColumn("password", string(pass)).Error; err != nil {
+	if err := db.Model(&dbmodels.User{}).Where("email=? AND email_verified = true", verificationCode.Key).UpdateColumn("password", string(pass)).Error; err != nil {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
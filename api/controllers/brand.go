@@ -9,6 +9,7 @@ import (
 	"github.com/esmailemami/eshop/app/helpers"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/authorization"
 	fileService "github.com/esmailemami/eshop/app/services/file"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
@@ -37,6 +38,7 @@ func GetBrands(ctx *app.HttpContext) error {
 	baseDB = baseDB.Table("brand b").
 		Joins("INNER JOIN file f on f.id = b.file_id").
 		Where("b.deleted_at IS NULL")
+	baseDB = authorization.GetScope(ctx).Where(baseDB, "", "b.id")
 
 	response, err := parameter.SelectColumns("b.id, b.created_at, b.updated_at,b.name,b.code, b.file_id, f.unique_file_name as file_name,f.file_type").
 		SearchColumns("b.name").
@@ -101,6 +103,11 @@ func GetBrand(ctx *app.HttpContext) error {
 	if err != nil {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
+
+	if err := authorization.CanAccessResource(ctx, models.PermissionResource{BrandID: &id}); err != nil {
+		return err
+	}
+
 	baseDB := db.MustGormDBConn(ctx)
 
 	var brand appmodels.BrandOutPutModel
@@ -167,6 +174,10 @@ func EditBrand(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
 
+	if err := authorization.CanAccessResource(ctx, models.PermissionResource{BrandID: &id}); err != nil {
+		return err
+	}
+
 	var inputModel appmodels.BrandReqModel
 
 	err = ctx.BlindBind(&inputModel)
@@ -214,6 +225,10 @@ func DeleteBrand(ctx *app.HttpContext) error {
 		return err
 	}
 
+	if err := authorization.CanAccessResource(ctx, models.PermissionResource{BrandID: &id}); err != nil {
+		return err
+	}
+
 	baseDB := db.MustGormDBConn(ctx)
 	baseTx := baseDB.Begin()
 
@@ -0,0 +1,414 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/bundle"
+	"github.com/esmailemami/eshop/app/services/order"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const bundleColumns = `bd.id, bd.created_at, bd.updated_at, bd."name", bd.code, bd.description, bd.price, bd.status,
+	(SELECT COALESCE(SUM(pi2.price * bi.quantity), 0) FROM bundle_item bi INNER JOIN product_item pi2 ON pi2.id = bi.product_item_id
+		WHERE bi.bundle_id = bd.id AND bi.deleted_at IS NULL) AS list_price`
+
+// GetAdminBundles godoc
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.BundleOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/bundle [get]
+func GetAdminBundles(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.BundleOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("bundle bd").Where("bd.deleted_at IS NULL")
+
+	data, err := parameter.SelectColumns(bundleColumns+", "+bundle.StockQuery("bd")+" AS stock").
+		SearchColumns(`bd."name"`, "bd.code").
+		SortDescending("bd.updated_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetAdminBundle godoc
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} appmodels.BundleOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/bundle/{id} [get]
+func GetAdminBundle(ctx *app.HttpContext) error {
+	return getBundle(ctx, false)
+}
+
+// GetBundles godoc
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.BundleOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Router /user/bundle [get]
+func GetBundles(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.BundleOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("bundle bd").
+		Where("bd.deleted_at IS NULL AND bd.status = ?", models.ProductStatusPublish)
+
+	data, err := parameter.SelectColumns(bundleColumns+", "+bundle.StockQuery("bd")+" AS stock").
+		SearchColumns(`bd."name"`, "bd.code").
+		SortDescending("bd.created_at").
+		EachItemProcess(func(db *gorm.DB, item *appmodels.BundleOutPutModel) error {
+			items, err := getBundleItems(baseDB, *item.ID)
+			item.Items = items
+			return err
+		}).
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetBundle godoc
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} appmodels.BundleOutPutModel
+// @Failure 400 {object} map[string]any
+// @Router /user/bundle/{id} [get]
+func GetBundle(ctx *app.HttpContext) error {
+	return getBundle(ctx, true)
+}
+
+// CreateBundle godoc
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Bundle   body  appmodels.BundleReqModel  true  "Bundle model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/bundle  [post]
+func CreateBundle(ctx *app.HttpContext) error {
+	var inputModel appmodels.BundleReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.ValidateCreate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseTx := db.MustGormDBConn(ctx).Begin()
+
+	dbModel := inputModel.ToDBModel()
+	if err := baseTx.Create(dbModel).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Create(&dbModel.Items).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// EditBundle godoc
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param Bundle   body  appmodels.BundleReqModel  true  "Bundle model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/bundle/edit/{id}  [post]
+func EditBundle(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.BundleReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.Bundle
+
+	if baseDB.First(&dbModel, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := inputModel.ValidateUpdate(id); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	inputModel.MergeWithDBData(&dbModel)
+
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Save(&dbModel).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// the paid orders keep their own breakdown, so the items can be replaced
+	if err := baseTx.Unscoped().Where("bundle_id = ?", id).Delete(&models.BundleItem{}).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Create(&dbModel.Items).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickResponse(consts.Updated, http.StatusOK)
+}
+
+// DeleteBundle godoc
+// @Tags Bundles
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/bundle/delete/{id}  [post]
+func DeleteBundle(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.Bundle
+
+	if baseDB.First(&dbModel, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if baseDB.Delete(&dbModel).Error != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, nil)
+	}
+
+	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+}
+
+// CreateBundleOrderItem godoc
+// @Tags OrderItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param BundleOrderItem   body  appmodels.BundleOrderItemReqModel  true  "BundleOrderItem model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/orderItem/bundle  [post]
+func CreateBundleOrderItem(ctx *app.HttpContext) error {
+	var inputModel appmodels.BundleOrderItemReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(err.Error(), err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	// the stock of the components is deducted at the checkout, here it is only checked
+	data := struct {
+		Price float64 `gorm:"column:price"`
+		Stock int     `gorm:"column:stock"`
+	}{}
+
+	if err := baseDB.Table("bundle bd").
+		Select("bd.price, "+bundle.StockQuery("bd")+" AS stock").
+		Where("bd.id = ? AND bd.deleted_at IS NULL AND bd.status = ?", inputModel.BundleID, models.ProductStatusPublish).
+		Take(&data).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.ModelBundleNotFound, nil)
+	}
+
+	if data.Stock < inputModel.Quantity {
+		return errors.NewBadRequestError(consts.InvalidQuantity, nil)
+	}
+
+	order, err := order.GetOpenOrder(baseDB, *user.ID)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Unscoped().Where("order_id = ? AND bundle_id = ?", *order.ID, inputModel.BundleID).Delete(&models.OrderItem{}).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Create(&models.OrderItem{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		OrderID:  *order.ID,
+		BundleID: &inputModel.BundleID,
+		Quantity: inputModel.Quantity,
+		Price:    data.Price,
+	}).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickResponse(consts.Created, http.StatusOK)
+}
+
+// DeleteBundleOrderItem godoc
+// @Tags OrderItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param bundleId  path  string  true  "Bundle ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/orderItem/bundle/delete/{bundleId}  [post]
+func DeleteBundleOrderItem(ctx *app.HttpContext) error {
+	bundleID, err := uuid.Parse(ctx.GetPathParam("bundleId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(err.Error(), err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.OrderItem
+
+	if baseDB.Table("order_item oi").
+		Joins(`INNER JOIN "order" o ON o.id = oi.order_id`).
+		Where("o.status = ? AND o.created_by_id = ? AND oi.bundle_id = ?", models.OrderStatusOpen, *user.ID, bundleID).
+		Select("oi.*").
+		Take(&dbModel).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	baseTx := baseDB.Begin()
+
+	if baseTx.Unscoped().Delete(&dbModel).Error != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, nil)
+	}
+
+	// delete order if it is the last item
+	if !db.Exists(baseTx, &models.OrderItem{}, "order_id = ?", dbModel.OrderID) {
+		if baseTx.Unscoped().Where("id = ?", dbModel.OrderID).Delete(&models.Order{}).Error != nil {
+			baseTx.Rollback()
+			return errors.NewInternalServerError(consts.InternalServerError, nil)
+		}
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+}
+
+func getBundle(ctx *app.HttpContext, storefront bool) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	qry := baseDB.Table("bundle bd").
+		Select(bundleColumns+", "+bundle.StockQuery("bd")+" AS stock").
+		Where("bd.id = ? AND bd.deleted_at IS NULL", id)
+
+	if storefront {
+		qry = qry.Where("bd.status = ?", models.ProductStatusPublish)
+	}
+
+	var data appmodels.BundleOutPutModel
+
+	if err := qry.Take(&data).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if data.Items, err = getBundleItems(baseDB, id); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(data, http.StatusOK)
+}
+
+func getBundleItems(baseDB *gorm.DB, bundleID uuid.UUID) ([]appmodels.BundleItemOutPutModel, error) {
+	items := []appmodels.BundleItemOutPutModel{}
+
+	err := baseDB.Table("bundle_item bi").
+		Joins("INNER JOIN product_item pi2 ON pi2.id = bi.product_item_id").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("INNER JOIN color c ON c.id = pi2.color_id").
+		Where("bi.bundle_id = ? AND bi.deleted_at IS NULL", bundleID).
+		Select(`bi.product_item_id, p.id AS product_id, p."name" AS product_name, c."name" AS color_name,
+			pi2.price, bi.quantity`).
+		Order("bi.created_at ASC").
+		Find(&items).Error
+
+	return items, err
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/esmailemami/eshop/app/helpers"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/authorization"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
@@ -28,6 +29,7 @@ import (
 // @Router /admin/category [get]
 func GetCategories(ctx *app.HttpContext) error {
 	baseDB := db.MustGormDBConn(ctx).Model(&models.Category{})
+	baseDB = authorization.GetScope(ctx).Where(baseDB, "id", "")
 
 	parameter := parameter.New[appmodels.CategoryOutPutModel](ctx, baseDB)
 
@@ -89,6 +91,11 @@ func GetCategory(ctx *app.HttpContext) error {
 	if err != nil {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
+
+	if err := authorization.CanAccessResource(ctx, models.PermissionResource{CategoryID: &id}); err != nil {
+		return err
+	}
+
 	baseDB := db.MustGormDBConn(ctx).Model(&models.Category{})
 
 	var data appmodels.CategoryOutPutModel
@@ -150,6 +157,10 @@ func EditCategory(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
 
+	if err := authorization.CanAccessResource(ctx, models.PermissionResource{CategoryID: &id}); err != nil {
+		return err
+	}
+
 	var inputModel appmodels.CategoryReqModel
 
 	err = ctx.BlindBind(&inputModel)
@@ -194,6 +205,10 @@ func DeleteCategory(ctx *app.HttpContext) error {
 		return err
 	}
 
+	if err := authorization.CanAccessResource(ctx, models.PermissionResource{CategoryID: &id}); err != nil {
+		return err
+	}
+
 	baseDB := db.MustGormDBConn(ctx).Model(&models.Category{})
 
 	var dbModel models.Category
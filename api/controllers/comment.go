@@ -8,9 +8,16 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	service "github.com/esmailemami/eshop/app/services/file"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/app/services/moderation"
+	"github.com/esmailemami/eshop/app/services/order"
+	"github.com/esmailemami/eshop/app/services/review"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Get Admin Comments godoc
@@ -23,6 +30,7 @@ import (
 // @Param searchTerm  query  string  false  "search for item"
 // @Param productId  query  string  false  "Product ID"
 // @Param userId  query  string  false  "User ID"
+// @Param verified  query  bool  false  "only the verified purchase reviews"
 // @Param status  query  models.CommentStatus  false  "Comment Status"
 // @Success 200 {object} parameter.ListResponse[appmodels.AdminCommentOutPutModel]
 // @Failure 400 {object} map[string]any
@@ -38,6 +46,10 @@ func GetAdminUserComments(ctx *app.HttpContext) error {
 		baseDB = baseDB.Where("c.product_id=?", productID)
 	}
 
+	if verified, ok := ctx.GetParam("verified"); ok {
+		baseDB = baseDB.Where("c.is_verified_purchase=?", verified == "true")
+	}
+
 	if userID, ok := ctx.GetParam("userId"); ok {
 		baseDB = baseDB.Where("c.created_by_id=?", userID)
 	}
@@ -48,7 +60,7 @@ func GetAdminUserComments(ctx *app.HttpContext) error {
 
 	parameter := parameter.New[appmodels.AdminCommentOutPutModel](ctx, baseDB)
 
-	response, err := parameter.SelectColumns("c.id, c.created_at,c.status as comment_status, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, u.username, c.product_id, p.name as product_name, c.admin_note").
+	response, err := parameter.SelectColumns("c.id, c.created_at,c.status as comment_status, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, u.username, c.product_id, p.name as product_name, c.admin_note, c.is_verified_purchase, c.helpful_votes, c.unhelpful_votes").
 		SearchColumns("c.text", "p.name", "u.first_name", "u.last_name", "u.username", "u.email", "u.mobile").
 		SortDescending("c.created_at").Execute(baseDB)
 
@@ -91,7 +103,7 @@ func GetUserComments(ctx *app.HttpContext) error {
 	parameter := parameter.New[appmodels.UserCommentOutPutModel](ctx, baseDB)
 
 	response, err := parameter.
-		SelectColumns("c.id, c.created_at,c.status as comment_status, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, c.product_id, p.name as product_name, c.admin_note").
+		SelectColumns("c.id, c.created_at,c.status as comment_status, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, c.product_id, p.name as product_name, c.admin_note, c.is_verified_purchase, c.helpful_votes, c.unhelpful_votes").
 		SearchColumns("c.text", "p.name", "u.first_name", "u.last_name", "u.username", "u.email", "u.mobile").
 		SortDescending("c.created_at").Execute(baseDB)
 
@@ -111,14 +123,16 @@ func GetUserComments(ctx *app.HttpContext) error {
 // @Param page  query  string  false  "page size"
 // @Param limit  query  string  false  "length of records to show"
 // @Param searchTerm  query  string  false  "search for item"
-// @Success 200 {object} parameter.ListResponse[appmodels.ProductCommentOutPutModel]
+// @Param verified  query  bool  false  "only the verified purchase reviews"
+// @Param sort  query  string  false  "newest (default) or helpful"
+// @Success 200 {object} appmodels.ProductCommentsOutPutModel
 // @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]any
 // @Router /user/comment/product/{productId} [get]
 func GetProductComments(ctx *app.HttpContext) error {
 	baseDB := db.MustGormDBConn(ctx).Table(`"comment" c`).
 		Joins(`INNER JOIN "user" u ON u.id = c.created_by_id`).
-		Where("c.deleted_at IS NULL ")
+		Where("c.deleted_at IS NULL AND c.status = ?", models.CommntStatusAccept)
 
 	productID, err := uuid.Parse(ctx.GetPathParam("productId"))
 
@@ -128,16 +142,77 @@ func GetProductComments(ctx *app.HttpContext) error {
 
 	baseDB = baseDB.Where("c.product_id=?", productID)
 
-	parameter := parameter.New[appmodels.ProductCommentOutPutModel](ctx, baseDB)
+	if verified, ok := ctx.GetParam("verified"); ok {
+		baseDB = baseDB.Where("c.is_verified_purchase=?", verified == "true")
+	}
 
-	response, err := parameter.SelectColumns("c.id, c.created_at, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, u.username").
-		SortDescending("c.created_at").Execute(baseDB)
+	parameter := parameter.New[appmodels.ProductCommentOutPutModel](ctx, baseDB).
+		SelectColumns("c.id, c.created_at, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, u.username, c.is_verified_purchase, c.helpful_votes, c.unhelpful_votes")
+
+	if sort, _ := ctx.GetParam("sort"); sort == "helpful" {
+		parameter.Sort("(c.helpful_votes - c.unhelpful_votes) DESC", "c.helpful_votes DESC", "c.created_at DESC")
+	} else {
+		parameter.SortDescending("c.created_at")
+	}
+
+	response, err := parameter.Execute(baseDB)
 
 	if err != nil {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
-	return ctx.JSON(*response, http.StatusOK)
+	if err := loadReviewPhotos(db.MustGormDBConn(ctx), response.Data); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	rating, err := review.Summary(db.MustGormDBConn(ctx), productID)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(appmodels.ProductCommentsOutPutModel{
+		ListResponse: *response,
+		Rating:       *rating,
+	}, http.StatusOK)
+}
+
+// loadReviewPhotos fills the photos of the reviews of a page by one query
+func loadReviewPhotos(baseDB *gorm.DB, comments []appmodels.ProductCommentOutPutModel) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(comments))
+	for i, comment := range comments {
+		ids[i] = *comment.ID
+	}
+
+	var photos []appmodels.ReviewPhotoOutPutModel
+
+	if err := baseDB.Table("file f").
+		Joins("INNER JOIN comment_file_map cf ON cf.file_id = f.id").
+		Where("cf.comment_id IN ? AND f.deleted_at IS NULL", ids).
+		Order("cf.priority").
+		Select("f.id, cf.comment_id, f.original_name, f.unique_file_name, f.file_type, cf.priority").
+		Find(&photos).Error; err != nil {
+		return err
+	}
+
+	byComment := make(map[uuid.UUID][]appmodels.ReviewPhotoOutPutModel, len(comments))
+	for _, photo := range photos {
+		photo.FileUrl = photo.FileType.GetFileUrl(photo.UniqueFileName)
+		byComment[photo.CommentID] = append(byComment[photo.CommentID], photo)
+	}
+
+	for i := range comments {
+		comments[i].Photos = byComment[*comments[i].ID]
+		if comments[i].Photos == nil {
+			comments[i].Photos = []appmodels.ReviewPhotoOutPutModel{}
+		}
+	}
+
+	return nil
 }
 
 // GetComment godoc
@@ -163,7 +238,7 @@ func GetComment(ctx *app.HttpContext) error {
 	if err := baseDB.Table(`"comment" c`).
 		Joins(`INNER JOIN "user" u ON u.id = c.created_by_id`).
 		Joins("INNER JOIN product p ON p.id = c.product_id").
-		Select("c.id, c.created_at,c.status as comment_status, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, c.product_id, p.name as product_name").
+		Select("c.id, c.created_at,c.status as comment_status, c.updated_at, c.text,c.rate,c.strength_points,c.weak_ponits, c.product_id, p.name as product_name, c.is_verified_purchase, c.helpful_votes, c.unhelpful_votes").
 		First(&data, "c.id", id).Error; err != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
@@ -188,25 +263,36 @@ func CreateComment(ctx *app.HttpContext) error {
 	if err != nil {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
-	baseDB := db.MustGormDBConn(ctx)
-	tx := baseDB.Begin()
-
 	err = inputModel.ValidateCreate()
 	if err != nil {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
-	if err := tx.Create(inputModel.ToDBModel()).Error; err != nil {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	dbModel := inputModel.ToDBModel()
+	dbModel.IsVerifiedPurchase = order.HasReceived(baseDB, *user.ID, inputModel.ProductID)
+
+	if !dbModel.IsVerifiedPurchase && review.CurrentPolicy() == review.PolicyBuyersOnly {
+		return errors.NewForbiddenError(consts.OnlyBuyersCanReview, nil)
+	}
+
+	moderateComment(baseDB, dbModel, *user.ID)
+
+	tx := baseDB.Begin()
+
+	if err := tx.Create(dbModel).Error; err != nil {
 		tx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
 	// calculate product rate
-	if err := tx.Model(&models.Product{}).Where("id=?", inputModel.ProductID).
-		UpdateColumn("rate", tx.Model(&models.Comment{}).
-			Where("product_id=?", inputModel.ProductID).
-			Select("AVG(rate)"),
-		).Error; err != nil {
+	if err := review.UpdateProductRate(tx, inputModel.ProductID, review.VerifiedWeight()); err != nil {
 		tx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
@@ -253,20 +339,27 @@ func EditComment(ctx *app.HttpContext) error {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
-	tx := baseDB.Begin()
+	// the author may have received the product after the review
+	if dbModel.CreatedByID != nil && !dbModel.IsVerifiedPurchase {
+		dbModel.IsVerifiedPurchase = order.HasReceived(baseDB, *dbModel.CreatedByID, dbModel.ProductID)
+	}
 
 	inputModel.MergeWithDBData(&dbModel)
+
+	// the edited text is moderated again
+	if dbModel.CreatedByID != nil {
+		moderateComment(baseDB, &dbModel, *dbModel.CreatedByID)
+	}
+
+	tx := baseDB.Begin()
+
 	if tx.Save(&dbModel).Error != nil {
 		tx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
 	// calculate product rate
-	if err := tx.Model(&models.Product{}).Where("id=?", inputModel.ProductID).
-		UpdateColumn("rate", tx.Model(&models.Comment{}).
-			Where("product_id=?", inputModel.ProductID).
-			Select("AVG(rate)"),
-		).Error; err != nil {
+	if err := review.UpdateProductRate(tx, inputModel.ProductID, review.VerifiedWeight()); err != nil {
 		tx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
@@ -308,11 +401,7 @@ func DeleteComment(ctx *app.HttpContext) error {
 	}
 
 	// calculate product rate
-	if err := tx.Model(&models.Product{}).Where("id=?", dbModel.ProductID).
-		UpdateColumn("rate", tx.Model(&models.Comment{}).
-			Where("product_id=?", dbModel.ProductID).
-			Select("AVG(rate)"),
-		).Error; err != nil {
+	if err := review.UpdateProductRate(tx, dbModel.ProductID, review.VerifiedWeight()); err != nil {
 		tx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
@@ -322,6 +411,30 @@ func DeleteComment(ctx *app.HttpContext) error {
 	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
 }
 
+// moderateComment sets the status and the admin note of the comment by the moderation pipeline, the comment is
+// not changed when the moderation is manual
+func moderateComment(baseDB *gorm.DB, comment *models.Comment, userID uuid.UUID) {
+	if moderation.CurrentMode() != moderation.ModeAutomatic {
+		return
+	}
+
+	status, note, err := moderation.Moderate(baseDB, moderation.Review{
+		ID:             *comment.ID,
+		UserID:         userID,
+		ProductID:      comment.ProductID,
+		Text:           comment.Text,
+		StrengthPoints: comment.StrengthPoints,
+		WeakPoints:     comment.WeakPonits,
+	})
+
+	if err != nil {
+		logger.Default().WithField("CommentID", comment.ID.String()).Error(err.Error())
+	}
+
+	comment.Status = status
+	comment.AdminNote = note
+}
+
 // Change Comment Status godoc
 // @Tags Comments
 // @Accept json
@@ -354,11 +467,307 @@ func ChangeCommentStatus(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
+	previousStatus := dbModel.Status
 	inputModel.MergeWithDBData(&dbModel)
 
-	if baseDB.Save(&dbModel).Error != nil {
+	tx := db.MustGormDBConn(ctx).Begin()
+
+	if err := tx.Save(&dbModel).Error; err != nil {
+		tx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// only the approved reviews are rated
+	if previousStatus != dbModel.Status {
+		if err := review.UpdateProductRate(tx, dbModel.ProductID, review.VerifiedWeight()); err != nil {
+			tx.Rollback()
+			return errors.NewInternalServerError(consts.InternalServerError, err)
+		}
+	}
+
+	tx.Commit()
+
+	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+}
+
+// Upload Comment Photo godoc
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param file formData file true "Image file to be uploaded"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/comment/photo/{id}  [post]
+func UploadCommentPhoto(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := ctx.Request.ParseMultipartForm(10 << 20); err != nil { // 10 MB maximum file size
+		return errors.NewBadRequestError(consts.InvalidFileSize, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var comment models.Comment
+
+	if baseDB.First(&comment, id).Error != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
+	if comment.CreatedByID == nil || *comment.CreatedByID != *user.ID {
+		return errors.NewForbiddenError(consts.NotReviewAuthor, nil)
+	}
+
+	fileType := models.FileTypeReview
+	_, _, _, mapTable, foreignColumn, _, _, _ := fileType.GetInfo()
+
+	var photosCount int64
+
+	if err := baseDB.Table(mapTable).Where(foreignColumn+" = ?", id).Count(&photosCount).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	var (
+		paths = []string{}
+		files = []*models.File{}
+	)
+
+	// the uploaded files are removed when the photos are not saved
+	cleanup := func() {
+		for _, path := range paths {
+			service.DeleteFileByPath(path)
+		}
+	}
+
+	for _, fileHeaders := range ctx.Request.MultipartForm.File {
+		for _, fileHeader := range fileHeaders {
+			if !service.IsImageFile(fileHeader) {
+				cleanup()
+				return errors.NewBadRequestError(consts.InvalidFileMimeType, nil)
+			}
+
+			if int(photosCount)+len(files) >= review.MaxPhotos {
+				cleanup()
+				return errors.NewBadRequestError(consts.TooManyReviewPhotos, nil)
+			}
+
+			path, fileName, err := service.UploadFile(fileHeader, fileType.GetDirectory(), true, true)
+			if err != nil {
+				cleanup()
+				return errors.NewInternalServerError(consts.InternalServerError, err)
+			}
+			paths = append(paths, path)
+
+			files = append(files, &models.File{
+				Model: models.Model{
+					ID: models.NewID(),
+				},
+				MimeType:       service.GetMimeType(fileHeader),
+				Extension:      service.GetFileExetension(fileName),
+				OriginalName:   fileHeader.Filename,
+				UniqueFileName: fileName,
+				FileType:       fileType,
+				ItemID:         &id,
+			})
+		}
+	}
+
+	if len(files) == 0 {
+		return ctx.QuickResponse(consts.FileNotSentToServer, http.StatusBadRequest)
+	}
+
+	tx := baseDB.Begin()
+
+	if err := tx.CreateInBatches(files, len(files)).Error; err != nil {
+		tx.Rollback()
+		cleanup()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := service.InsertItemFile(baseDB, tx, id, fileType, files...); err != nil {
+		tx.Rollback()
+		cleanup()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	tx.Commit()
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+// Delete Comment Photo godoc
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param fileId  path  string  true  "file ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/comment/photo/delete/{fileId}  [post]
+func DeleteCommentPhoto(ctx *app.HttpContext) error {
+	fileID, err := uuid.Parse(ctx.GetPathParam("fileId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var file models.File
+
+	if baseDB.First(&file, "id = ? AND file_type = ?", fileID, models.FileTypeReview).Error != nil {
+		return errors.NewRecordNotFoundError(consts.FileNotFound, nil)
+	}
+
+	var comment models.Comment
+
+	if baseDB.Joins(`INNER JOIN comment_file_map cf ON cf.comment_id = "comment".id`).
+		Where("cf.file_id = ?", fileID).
+		First(&comment).Error != nil {
+		return errors.NewRecordNotFoundError(consts.FileNotFound, nil)
+	}
+
+	if comment.CreatedByID == nil || *comment.CreatedByID != *user.ID {
+		return errors.NewForbiddenError(consts.NotReviewAuthor, nil)
+	}
+
+	tx := baseDB.Begin()
+
+	if err := service.DeleteFile(baseDB, tx, comment.ID, &file); err != nil {
+		tx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	tx.Commit()
+
 	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
 }
+
+// Vote Comment godoc
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param Model   body  appmodels.CommentVoteReqModel  true  "Vote model"
+// @Success 200 {object} appmodels.CommentVoteOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/comment/vote/{id}  [post]
+func VoteComment(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.CommentVoteReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var comment models.Comment
+
+	if baseDB.First(&comment, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if comment.CreatedByID != nil && *comment.CreatedByID == *user.ID {
+		return errors.NewBadRequestError(consts.CannotVoteOwnReview, nil)
+	}
+
+	helpful := *inputModel.Helpful
+	output := appmodels.CommentVoteOutPutModel{}
+
+	// voting the same value again removes the vote and voting the other value changes it
+	err = baseDB.Transaction(func(tx *gorm.DB) error {
+		var previous []models.CommentVote
+
+		if err := tx.Unscoped().Clauses(clause.Returning{}).
+			Where("comment_id = ? AND created_by_id = ?", id, *user.ID).
+			Delete(&previous).Error; err != nil {
+			return err
+		}
+
+		var helpfulDelta, unhelpfulDelta int
+
+		for _, vote := range previous {
+			if vote.Helpful {
+				helpfulDelta--
+			} else {
+				unhelpfulDelta--
+			}
+		}
+
+		if len(previous) == 0 || previous[0].Helpful != helpful {
+			vote := models.CommentVote{
+				Model: models.Model{
+					ID: models.NewID(),
+				},
+				CommentID: id,
+				Helpful:   helpful,
+			}
+
+			// the vote of a concurrent request of the user is kept
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&vote)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected > 0 {
+				output.Helpful = &helpful
+
+				if helpful {
+					helpfulDelta++
+				} else {
+					unhelpfulDelta++
+				}
+			}
+		}
+
+		if helpfulDelta != 0 || unhelpfulDelta != 0 {
+			if err := tx.Model(&models.Comment{}).Where("id = ?", id).UpdateColumns(map[string]any{
+				"helpful_votes":   gorm.Expr("helpful_votes + ?", helpfulDelta),
+				"unhelpful_votes": gorm.Expr("unhelpful_votes + ?", unhelpfulDelta),
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Comment{}).Where("id = ?", id).
+			Select("helpful_votes, unhelpful_votes").
+			Row().Scan(&output.HelpfulVotes, &output.UnhelpfulVotes)
+	})
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(output, http.StatusOK)
+}
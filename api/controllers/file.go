@@ -17,6 +17,7 @@ import (
 	dbpkg "github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // UploadFile godoc
@@ -59,7 +60,6 @@ func UploadImage(ctx *app.HttpContext) error {
 	}
 
 	baseDB := dbpkg.MustGormDBConn(ctx)
-	baseTx := baseDB.Begin()
 
 	multiple, err := service.ValidateItem(baseDB, itemID, fileType)
 
@@ -67,6 +67,12 @@ func UploadImage(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(err.Error(), err)
 	}
 
+	if err := canAccessFileItem(ctx, baseDB, &itemID, fileType); err != nil {
+		return err
+	}
+
+	baseTx := baseDB.Begin()
+
 	paths := []string{}
 	files := []*models.File{}
 
@@ -165,6 +171,17 @@ func DeleteFile(ctx *app.HttpContext) error {
 		return err
 	}
 
+	itemID, err := service.GetItemID(baseDB, &file)
+	if err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := canAccessFileItem(ctx, baseDB, itemID, file.FileType); err != nil {
+		baseTx.Rollback()
+		return err
+	}
+
 	if err := service.DeleteFileWithNoItemID(baseDB, baseTx, &file); err != nil {
 		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
@@ -333,6 +350,10 @@ func GetItemFiles(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(err.Error(), err)
 	}
 
+	if err := canAccessFileItem(ctx, baseDB, &itemID, fileType); err != nil {
+		return err
+	}
+
 	whereClause := fileType.GenerateWhereClause(baseDB, itemID)
 
 	var files []appmodels.FileOutPutModel
@@ -398,6 +419,10 @@ func FileChangePriority(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.InvalidFileType, nil)
 	}
 
+	if err := canAccessFileItem(ctx, baseDB, &itemID, dbFile.FileType); err != nil {
+		return err
+	}
+
 	baseTx := baseDB.Begin()
 	if err := service.ChangeFilePriority(baseDB, baseTx, itemID, fileID, dbFile.FileType, priority); err != nil {
 		baseTx.Rollback()
@@ -407,3 +432,22 @@ func FileChangePriority(ctx *app.HttpContext) error {
 
 	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
 }
+
+// canAccessFileItem checks the item of the file against the permission scope of the request, the scoped roles can not
+// access the files which do not belong to a product
+func canAccessFileItem(ctx *app.HttpContext, baseDB *gorm.DB, itemID *uuid.UUID, fileType models.FileType) error {
+	if authorization.GetScope(ctx) == nil {
+		return nil
+	}
+
+	if itemID != nil {
+		switch fileType {
+		case models.FileTypeProduct:
+			return canAccessProduct(ctx, baseDB, *itemID)
+		case models.FileTypeProductItemBarcode:
+			return canAccessProductItem(ctx, baseDB, *itemID)
+		}
+	}
+
+	return authorization.CanAccessResource(ctx, models.PermissionResource{})
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	"github.com/esmailemami/eshop/app/services/token"
+)
+
+// GetJWKS returns the public keys which verify the access tokens, it is served out of the api under
+// /.well-known/jwks.json so the other services verify the tokens without the private keys. The new keys are
+// published before they sign, the response is cached less than the publish delay.
+func GetJWKS(ctx *app.HttpContext) error {
+	keys, err := token.PublicKeys()
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	ctx.ResponseWriter.Header().Set("Content-Type", "application/json")
+	ctx.ResponseWriter.Header().Set("Cache-Control", "public, max-age=300")
+
+	return ctx.JSON(keys, http.StatusOK)
+}
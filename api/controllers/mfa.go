@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/authentication"
+	"github.com/esmailemami/eshop/app/services/mfa"
+	"github.com/esmailemami/eshop/db"
+	"github.com/google/uuid"
+)
+
+// LoginByMfa godoc
+// @Summary Complete the login by the second factor
+// @Description Exchanges the "mfa pending" token of the login with the tokens by the TOTP code or a recovery code.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param mfaLoginInput   body  appmodels.MfaLoginInputModel  true  "Mfa login input model"
+// @Success 200 {object} appmodels.LoginOutputModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 429 {object} map[string]any
+// @Router /admin/login/mfa [post]
+// @Router /user/login/mfa [post]
+func LoginByMfa(ctx *app.HttpContext) error {
+	return mfaLogin(ctx, authentication.LoginByMfa)
+}
+
+// StartLoginMfaEnrollment godoc
+// @Summary Enroll the 2FA during the login
+// @Description Creates the TOTP secret of the user whose role requires the 2FA, the pending token of the login is used.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param mfaTokenInput   body  appmodels.MfaTokenInputModel  true  "Mfa token input model"
+// @Success 200 {object} appmodels.MfaEnrollmentOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/login/mfa/enroll [post]
+// @Router /user/login/mfa/enroll [post]
+func StartLoginMfaEnrollment(ctx *app.HttpContext) error {
+	var input appmodels.MfaTokenInputModel
+
+	if err := ctx.BlindBind(&input); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := input.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	enrollment, err := authentication.StartMfaEnrollment(ctx, input.Token)
+	if err != nil {
+		return mfaError(err)
+	}
+
+	return ctx.JSON(enrollmentOutPut(enrollment), http.StatusOK)
+}
+
+// ConfirmLoginMfaEnrollment godoc
+// @Summary Confirm the 2FA enrollment and complete the login
+// @Description Enables the 2FA by the first code of the enrolled secret and returns the tokens with the recovery codes, the recovery codes are shown once.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param mfaLoginInput   body  appmodels.MfaLoginInputModel  true  "Mfa login input model"
+// @Success 200 {object} appmodels.LoginOutputModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Failure 429 {object} map[string]any
+// @Router /admin/login/mfa/enroll/confirm [post]
+// @Router /user/login/mfa/enroll/confirm [post]
+func ConfirmLoginMfaEnrollment(ctx *app.HttpContext) error {
+	return mfaLogin(ctx, authentication.ConfirmMfaEnrollment)
+}
+
+// GetProfileMfa godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} appmodels.MfaStatusOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/profile/mfa [get]
+func GetProfileMfa(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	status, err := mfa.GetStatus(db.MustGormDBConn(ctx), *user)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(appmodels.MfaStatusOutPutModel{
+		Enabled:           status.Enabled,
+		Required:          status.Required,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	}, http.StatusOK)
+}
+
+// EnrollProfileMfa godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} appmodels.MfaEnrollmentOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/profile/mfa/enroll [post]
+func EnrollProfileMfa(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	enrollment, err := mfa.Enroll(db.MustGormDBConn(ctx), *user)
+	if err != nil {
+		return mfaError(err)
+	}
+
+	return ctx.JSON(enrollmentOutPut(enrollment), http.StatusOK)
+}
+
+// ConfirmProfileMfa godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code  body  appmodels.MfaCodeReqModel  true  "Code"
+// @Success 200 {object} appmodels.MfaRecoveryCodesOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/profile/mfa/enroll/confirm [post]
+func ConfirmProfileMfa(ctx *app.HttpContext) error {
+	return profileMfaCode(ctx, func(user uuid.UUID, code string) (*appmodels.MfaRecoveryCodesOutPutModel, error) {
+		codes, err := mfa.Confirm(db.MustGormDBConn(ctx), user, code)
+		if err != nil {
+			return nil, err
+		}
+		return &appmodels.MfaRecoveryCodesOutPutModel{RecoveryCodes: codes}, nil
+	})
+}
+
+// RegenerateProfileMfaRecoveryCodes godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code  body  appmodels.MfaCodeReqModel  true  "Code"
+// @Success 200 {object} appmodels.MfaRecoveryCodesOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/profile/mfa/recoveryCodes [post]
+func RegenerateProfileMfaRecoveryCodes(ctx *app.HttpContext) error {
+	return profileMfaCode(ctx, func(user uuid.UUID, code string) (*appmodels.MfaRecoveryCodesOutPutModel, error) {
+		codes, err := mfa.RegenerateRecoveryCodes(db.MustGormDBConn(ctx), user, code)
+		if err != nil {
+			return nil, err
+		}
+		return &appmodels.MfaRecoveryCodesOutPutModel{RecoveryCodes: codes}, nil
+	})
+}
+
+// DisableProfileMfa godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param code  body  appmodels.MfaCodeReqModel  true  "Code"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/profile/mfa/disable [post]
+func DisableProfileMfa(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	var inputModel appmodels.MfaCodeReqModel
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	if err := mfa.Disable(db.MustGormDBConn(ctx), *user, inputModel.Code); err != nil {
+		return mfaError(err)
+	}
+
+	return ctx.QuickResponse(consts.MfaDisabled, http.StatusOK)
+}
+
+// ResetUserMfa godoc
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "User ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/user/mfa/reset/{id} [post]
+func ResetUserMfa(ctx *app.HttpContext) error {
+	userID, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := mfa.Reset(db.MustGormDBConn(ctx), userID); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+func mfaLogin(ctx *app.HttpContext, login func(ctx context.Context, input appmodels.MfaLoginInputModel) (*appmodels.LoginOutputModel, error)) error {
+	var input appmodels.MfaLoginInputModel
+
+	if err := ctx.BlindBind(&input); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := input.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	input.IP = ctx.ClientIP()
+	input.UserAgent = ctx.UserAgent()
+
+	output, err := login(ctx, input)
+	if err != nil {
+		return loginError(err)
+	}
+
+	setAuthCookies(ctx, output)
+
+	return ctx.JSON(*output, http.StatusOK)
+}
+
+func profileMfaCode(ctx *app.HttpContext, action func(user uuid.UUID, code string) (*appmodels.MfaRecoveryCodesOutPutModel, error)) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	var inputModel appmodels.MfaCodeReqModel
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	output, err := action(*user.ID, inputModel.Code)
+	if err != nil {
+		return mfaError(err)
+	}
+
+	return ctx.JSON(*output, http.StatusOK)
+}
+
+func enrollmentOutPut(enrollment *mfa.Enrollment) appmodels.MfaEnrollmentOutPutModel {
+	return appmodels.MfaEnrollmentOutPutModel{
+		Secret: enrollment.Secret,
+		Uri:    enrollment.URI,
+		QRCode: enrollment.QRCode,
+	}
+}
+
+func mfaError(err error) error {
+	if err.Error() == consts.InternalServerError {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+	return errors.NewBadRequestError(err.Error(), err)
+}
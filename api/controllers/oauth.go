@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/authentication"
+	"github.com/esmailemami/eshop/app/services/oauth"
+	"github.com/esmailemami/eshop/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetOAuthProviders godoc
+// @Summary The login providers
+// @Description Returns the names of the configured OAuth providers.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} []string
+// @Router /user/oauth/providers [get]
+func GetOAuthProviders(ctx *app.HttpContext) error {
+	return ctx.JSON(oauth.Names(), http.StatusOK)
+}
+
+// StartOAuth godoc
+// @Summary Start the login by the provider
+// @Description Returns the consent url of the provider, the client redirects the user to it and keeps the state to compare it on the callback.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param provider  path  string  true  "Provider"
+// @Success 200 {object} appmodels.OAuthStartOutputModel
+// @Failure 400 {object} map[string]any
+// @Router /user/oauth/{provider} [get]
+func StartOAuth(ctx *app.HttpContext) error {
+	output, err := authentication.StartOAuth(ctx, ctx.GetPathParam("provider"))
+	if err != nil {
+		return loginError(err)
+	}
+
+	return ctx.JSON(*output, http.StatusOK)
+}
+
+// OAuthCallback godoc
+// @Summary Complete the login by the provider
+// @Description Exchanges the authorization code of the provider, the account is registered on the first login.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param provider  path  string  true  "Provider"
+// @Param oauthCallbackInput   body  appmodels.OAuthCallbackInputModel  true  "OAuth callback input model"
+// @Success 200 {object} appmodels.LoginOutputModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/oauth/{provider}/callback [post]
+func OAuthCallback(ctx *app.HttpContext) error {
+	var input appmodels.OAuthCallbackInputModel
+
+	if err := ctx.BlindBind(&input); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := input.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	input.IP = ctx.ClientIP()
+	input.UserAgent = ctx.UserAgent()
+
+	output, err := authentication.LoginByOAuth(ctx, ctx.GetPathParam("provider"), input)
+	if err != nil {
+		return loginError(err)
+	}
+
+	setAuthCookies(ctx, output)
+
+	return ctx.JSON(*output, http.StatusOK)
+}
+
+// GetUserIdentities godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} []appmodels.UserIdentityOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/identities [get]
+func GetUserIdentities(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	identities, err := authentication.UserIdentities(db.MustGormDBConn(ctx), *user.ID)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(identities, http.StatusOK)
+}
+
+// UnlinkUserIdentity godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Identity ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/identities/unlink/{id} [post]
+func UnlinkUserIdentity(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	if err := authentication.UnlinkIdentity(db.MustGormDBConn(ctx), *user, id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+		}
+		return loginError(err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
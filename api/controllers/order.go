@@ -9,6 +9,9 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/bundle"
+	"github.com/esmailemami/eshop/app/services/inventory"
+	"github.com/esmailemami/eshop/app/services/settings"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
@@ -56,16 +59,50 @@ func GetOrder(ctx *app.HttpContext) error {
 		).
 		Joins("INNER JOIN file f ON f.id = pf.file_id").
 		Where("o.status = 0 AND o.created_by_id = ?", *user.ID).
-		Select(`oi.id, oi.product_item_id,p."name" AS product_name,pi2.price,oi.quantity,f.file_type, f.unique_file_name AS file_name,d.type as discount_type, d.value as discount_value, d.quantity as discount_quantity`).
+		Select(`oi.id, oi.product_item_id, p.id AS product_id, p."name" AS product_name,pi2.price,oi.quantity,f.file_type, f.unique_file_name AS file_name,d.type as discount_type, d.value as discount_value, d.quantity as discount_quantity`).
 		Find(&orderItems).Error; err != nil {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
-	for _, orderItem := range orderItems {
-		orderItem.FileUrl = orderItem.FileType.GetFileUrl(orderItem.FileName)
+	// the bundles image is the image of their first component
+	var bundleItems []appmodels.OrderItemOutPutModel
+
+	if err := baseDB.Table("order_item oi").
+		Joins(`INNER JOIN "order" o ON o.id = oi.order_id`).
+		Joins("INNER JOIN bundle bd ON bd.id = oi.bundle_id").
+		Joins("LEFT JOIN LATERAL (?) as pf ON TRUE", baseDB.Table("bundle_item bi").
+			Joins("INNER JOIN product_item pi2 ON pi2.id = bi.product_item_id").
+			Joins("INNER JOIN product_file_map pfm ON pfm.product_id = pi2.product_id").
+			Select("pfm.file_id").
+			Where("bi.bundle_id = bd.id AND bi.deleted_at IS NULL").
+			Order("bi.created_at ASC, pfm.priority ASC").
+			Limit(1),
+		).
+		Joins("LEFT JOIN file f ON f.id = pf.file_id").
+		Where("o.status = 0 AND o.created_by_id = ?", *user.ID).
+		Select(`oi.id, oi.bundle_id, bd."name" AS product_name, bd.price, oi.quantity,
+			COALESCE(f.file_type, 0) AS file_type, COALESCE(f.unique_file_name, '') AS file_name`).
+		Find(&bundleItems).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	productIDs := []uuid.UUID{}
+
+	for _, orderItem := range append(orderItems, bundleItems...) {
+		if orderItem.FileName != "" {
+			orderItem.FileUrl = orderItem.FileType.GetFileUrl(orderItem.FileName)
+		}
 		orderItem.TotalPrice = orderItem.Price * float64(orderItem.Quantity)
 		data.Items = append(data.Items, orderItem)
 		data.Price += orderItem.Price
+
+		if orderItem.ProductID != nil {
+			productIDs = append(productIDs, *orderItem.ProductID)
+		}
+	}
+
+	if data.Relations, err = getRelatedProducts(baseDB, productIDs, productIDs); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
 	return ctx.JSON(data, http.StatusOK)
@@ -141,7 +178,7 @@ func CheckoutOrder(ctx *app.HttpContext) error {
 	}
 
 	// update the prices of order items
-	baseTx.Table("order_item oi").Where("oi.order_id=?", *order.ID).UpdateColumns(map[string]interface{}{
+	baseTx.Table("order_item oi").Where("oi.order_id=? AND oi.product_item_id IS NOT NULL", *order.ID).UpdateColumns(map[string]interface{}{
 		"total_price": baseDB.Model(&models.ProductItem{}).
 			Select("price").
 			Where("id = oi.product_item_id").
@@ -160,6 +197,39 @@ func CheckoutOrder(ctx *app.HttpContext) error {
 			Limit(1),
 	})
 
+	// deduct the stock of the bundles components and keep their breakdown
+	if err := bundle.Checkout(baseTx, *order.ID); err != nil {
+		baseTx.Rollback()
+
+		if err == bundle.ErrInsufficientStock {
+			return errors.NewBadRequestError(consts.InvalidQuantity, err)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// the reserved items of the cart are sold
+	if err := inventory.SellReservations(baseTx, *order.ID); err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// ship the items from the locations
+	strategy := inventory.StrategyNearest
+	if v := settings.GetSystemSettings().StockAllocationStrategy; v != nil {
+		strategy = inventory.Strategy(*v)
+	}
+
+	if err := inventory.Checkout(baseTx, *order.ID, address.PostalCode, strategy); err != nil {
+		baseTx.Rollback()
+
+		if err == inventory.ErrInsufficientStock {
+			return errors.NewBadRequestError(consts.InvalidQuantity, err)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
 	orderItemsPrice := struct {
 		Price      float64 `gorm:"price"`
 		TotalPrice float64 `gorm:"total_price"`
@@ -268,3 +338,64 @@ func GetAdminOrders(ctx *app.HttpContext) error {
 
 	return ctx.JSON(data, http.StatusOK)
 }
+
+// GetAdminOrderItems godoc
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Order ID"
+// @Success 200 {object} []appmodels.AdminOrderItemOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/order/items/{id} [get]
+func GetAdminOrderItems(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if !db.Exists(baseDB, &models.Order{}, "id = ?", id) {
+		return errors.NewRecordNotFoundError(consts.ModelOrderNotFound, nil)
+	}
+
+	items := []appmodels.AdminOrderItemOutPutModel{}
+
+	if err := baseDB.Table("order_item oi").
+		Joins("LEFT JOIN product_item pi2 ON pi2.id = oi.product_item_id").
+		Joins("LEFT JOIN product p ON p.id = pi2.product_id").
+		Joins("LEFT JOIN bundle bd ON bd.id = oi.bundle_id").
+		Where("oi.order_id = ? AND oi.deleted_at IS NULL", id).
+		Select(`oi.id, oi.product_item_id, oi.bundle_id, COALESCE(p."name", bd."name") AS name, oi.quantity, oi.price, oi.total_price`).
+		Order("oi.created_at ASC").
+		Find(&items).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	var components []appmodels.OrderItemComponentOutPutModel
+
+	if err := baseDB.Table("order_item_component oic").
+		Joins("INNER JOIN order_item oi ON oi.id = oic.order_item_id").
+		Joins("INNER JOIN product_item pi2 ON pi2.id = oic.product_item_id").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Where("oi.order_id = ? AND oic.deleted_at IS NULL", id).
+		Select(`oic.order_item_id, oic.product_item_id, p."name" AS product_name, oic.quantity, oic.list_price, oic.price`).
+		Order("oic.created_at ASC").
+		Find(&components).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	for i := range items {
+		items[i].Components = []appmodels.OrderItemComponentOutPutModel{}
+
+		for _, component := range components {
+			if component.OrderItemID == items[i].ID {
+				items[i].Components = append(items[i].Components, component)
+			}
+		}
+	}
+
+	return ctx.JSON(items, http.StatusOK)
+}
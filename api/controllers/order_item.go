@@ -7,6 +7,7 @@ import (
 	"github.com/esmailemami/eshop/app/consts"
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/inventory"
 	"github.com/esmailemami/eshop/app/services/order"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
@@ -66,9 +67,7 @@ func CreateOrderItem(ctx *app.HttpContext) error {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
-	productItem.Quantity = productItem.Quantity + lastQuantity - inputModel.Quantity
-
-	if productItem.Quantity < 0 {
+	if productItem.Quantity+lastQuantity-inputModel.Quantity < 0 {
 		return errors.NewBadRequestError(consts.InvalidQuantity, nil)
 	}
 
@@ -86,9 +85,21 @@ func CreateOrderItem(ctx *app.HttpContext) error {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
-	// update productItem quantity
-	baseTx.Model(&models.ProductItem{}).Where("id = ?", inputModel.ProductItemID).
-		UpdateColumn("quantity", productItem.Quantity)
+	// update productItem quantity, the items of the cart are reserved and they are sold at the checkout
+	reason := models.StockMovementReasonReserve
+	if lastQuantity > inputModel.Quantity {
+		reason = models.StockMovementReasonRelease
+	}
+
+	if err := inventory.ChangeItemQuantity(baseTx, inputModel.ProductItemID, lastQuantity-inputModel.Quantity, reason, order.ID, nil); err != nil {
+		baseTx.Rollback()
+
+		if err == inventory.ErrInsufficientStock {
+			return errors.NewBadRequestError(consts.InvalidQuantity, nil)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
 
 	baseTx.Commit()
 
@@ -145,18 +156,13 @@ func DeleteOrderItem(ctx *app.HttpContext) error {
 		}
 	}
 
-	var productItemQuantity int
-	if baseDB.Model(&models.ProductItem{}).Select("quantity").First(&productItemQuantity, dbModel.ProductItemID).Error != nil {
+	// update productItem quantity
+	if err := inventory.ChangeItemQuantity(baseTx, *dbModel.ProductItemID, dbModel.Quantity,
+		models.StockMovementReasonRelease, &dbModel.OrderID, nil); err != nil {
 		baseTx.Rollback()
-		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
-	productItemQuantity = productItemQuantity + dbModel.Quantity
-
-	// update productItem quantity
-	baseTx.Model(&models.ProductItem{}).Where("id = ?", dbModel.ProductItemID).
-		UpdateColumn("quantity", productItemQuantity)
-
 	baseTx.Commit()
 
 	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
@@ -9,6 +9,9 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/authorization"
+	"github.com/esmailemami/eshop/app/services/product_revision"
+	"github.com/esmailemami/eshop/app/services/product_schedule"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
@@ -73,7 +76,8 @@ func GetUserProducts(ctx *app.HttpContext) error {
 		Where("fpi.product_item_id = pi2.id AND fpi.created_by_id = ?", userID)
 
 	productItemQry = productItemQry.Select("pi2.id, pi2.price, pi2.created_at, pi2.bought_quantity, d.type, d.value, d.quantity as discount_quantity, pi2.quantity,(SELECT EXISTS(?)) as is_user_favorite", isUserFavoriteProductQry).
-		Where("pi2.quantity > 0 AND pi2.product_id = p.id AND pi2.deleted_at IS NULL")
+		Where("pi2.quantity > 0 AND pi2.product_id = p.id AND pi2.deleted_at IS NULL").
+		Where(product_schedule.VisibleCond("pi2"))
 
 	order, _ := ctx.GetParam("order")
 
@@ -103,7 +107,8 @@ func GetUserProducts(ctx *app.HttpContext) error {
 			Where("pf.product_id = p.id").Order("pf.priority ASC").Limit(1),
 		).
 		Joins("INNER JOIN file f ON f.id = pf.file_id").
-		Where("p.deleted_at IS NULL")
+		Where("p.deleted_at IS NULL").
+		Where(product_schedule.VisibleProductCond("p"))
 
 	if categoryID, ok := ctx.GetParam("categoryId"); ok {
 		baseDB = baseDB.Where("c.id = ?", categoryID)
@@ -171,6 +176,7 @@ func GetAdminProducts(ctx *app.HttpContext) error {
 		Joins("INNER JOIN file f on f.id = b.file_id").
 		Joins(`INNER JOIN category c ON c.id = p.category_id`).
 		Where("p.deleted_at IS NULL")
+	baseDB = authorization.GetScope(ctx).Where(baseDB, "p.category_id", "p.brand_id")
 
 	response, err := parameter.SelectColumns(`p.id,p.rate, p."name", p.code, p.brand_id, b."name" AS brand_name, p.category_id, 
 							c."name" AS category_name, f.file_type AS brand_file_type, 
@@ -217,16 +223,22 @@ func GetProduct(ctx *app.HttpContext) error {
 		Select(`p.id, p."name",p.rate, p.code, p.brand_id, b."name" AS brand_name, 
 			p.category_id, c."name" AS category_name, f.file_type AS brand_file_type, f.unique_file_name AS brand_file_name`).
 		Where("p.id = ? AND p.deleted_at IS NULL", id).
+		Where(product_schedule.VisibleProductCond("p")).
 		First(&data).Error; err != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
 	data.BrandFileUrl = data.BrandFileType.GetFileUrl(data.BrandFileName)
 
+	if data.Relations, err = getRelatedProducts(baseDB, []uuid.UUID{id}, []uuid.UUID{id}); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
 	return ctx.JSON(data, http.StatusOK)
 }
 
 // Create Product godoc
+// @Description The content is drafted as the first revision, the product is not visible until the revision is approved
 // @Tags Products
 // @Accept json
 // @Produce json
@@ -251,14 +263,32 @@ func CreateProduct(ctx *app.HttpContext) error {
 	}
 
 	dbModel := inputModel.ToDBModel()
-	if err := baseDB.Create(dbModel).Error; err != nil {
+
+	if err := authorization.CanAccessResource(ctx, dbModel.PermissionResource()); err != nil {
+		return err
+	}
+
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Create(dbModel).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// the product is not visible until its content is approved
+	if _, err := product_revision.NewProductDraft(baseTx, *dbModel.ID); err != nil {
+		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
-	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+	baseTx.Commit()
+
+	return ctx.QuickDBResponse(consts.ProductDrafted, *dbModel.ID, http.StatusOK)
 }
 
 // Edit Product godoc
+// @Description The name, descriptions and top features are drafted as a revision and the draft id is returned.
+// @Description The status and the schedule are applied to a new product after its first revision is approved.
 // @Tags Products
 // @Accept json
 // @Produce json
@@ -291,16 +321,44 @@ func EditProduct(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
-	err = inputModel.ValidateUpdate(id)
+	if err := authorization.CanAccessResource(ctx, dbModel.PermissionResource()); err != nil {
+		return err
+	}
+
+	err = inputModel.ValidateUpdate(baseDB, id)
 	if err != nil {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
 	inputModel.MergeWithDBData(&dbModel)
-	if baseDB.Save(&dbModel).Error != nil {
+
+	// the product cannot be moved out of the scope
+	if err := authorization.CanAccessResource(ctx, dbModel.PermissionResource()); err != nil {
+		return err
+	}
+
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Model(&dbModel).
+		Select("code", "brand_id", "category_id", "status", "publish_at", "unpublish_at", "updated_at").
+		Updates(&dbModel).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// the content is published by reviewing the draft
+	draft, err := product_revision.DraftChanges(baseTx, id, inputModel.MergeWithContent)
+	if err != nil {
+		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	baseTx.Commit()
+
+	if draft != nil {
+		return ctx.QuickDBResponse(consts.RevisionDrafted, *draft.ID, http.StatusOK)
+	}
+
 	return ctx.QuickResponse(consts.Updated, http.StatusOK)
 }
 
@@ -328,6 +386,10 @@ func DeleteProduct(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
+	if err := authorization.CanAccessResource(ctx, dbModel.PermissionResource()); err != nil {
+		return err
+	}
+
 	if baseDB.Delete(&dbModel).Error != nil {
 		return errors.NewInternalServerError(consts.InternalServerError, nil)
 	}
@@ -356,11 +418,13 @@ func GetSuggestionProducts(ctx *app.HttpContext) error {
 	qry := baseDB.Table("product as p").
 		Joins("CROSS JOIN LATERAL (?) as pi2", baseDB.Table("product_item pi2").
 			Select("id, price, color_id").
-			Where("pi2.quantity > 0 AND pi2.product_id = p.id").
+			Where("pi2.quantity > 0 AND pi2.product_id = p.id AND pi2.deleted_at IS NULL").
+			Where(product_schedule.VisibleCond("pi2")).
 			Order("CASE WHEN p.default_product_item_id IS NULL THEN pi2.bought_quantity WHEN pi2.id = p.default_product_item_id THEN 0 ELSE 1 END").
 			Limit(1),
 		).
 		Where("p.deleted_at IS NULL AND p.top_features IS NOT NULL AND jsonb_array_length(p.top_features) > 0").
+		Where(product_schedule.VisibleProductCond("p")).
 		Where("EXISTS (SELECT true FROM product_file_map WHERE product_id = p.id)")
 
 	if categoryID, ok := ctx.GetParam("categoryId"); ok {
@@ -397,6 +461,7 @@ func GetSuggestionProducts(ctx *app.HttpContext) error {
 			if err := baseDB.Table("product_item pi2").
 				Joins("INNER JOIN color c ON c.id = pi2.color_id").
 				Where("pi2.deleted_at IS NULL AND pi2.product_id=?", data.ProductID).
+				Where(product_schedule.VisibleCond("pi2")).
 				Select("pi2.id AS product_item_id, c.name, c.color_hex").Find(&colors).Error; err != nil {
 				return errors.NewInternalServerError(consts.InternalServerError, err)
 			}
@@ -434,12 +499,15 @@ func GetAdminProduct(ctx *app.HttpContext) error {
 
 	var data appmodels.ProductAdminOutPutModel
 
+	baseDB = authorization.GetScope(ctx).Where(baseDB, "p.category_id", "p.brand_id")
+
 	if err := baseDB.Table("product as p").
 		Joins(`INNER JOIN brand b ON b.id = p.brand_id`).
 		Joins("INNER JOIN file f on f.id = b.file_id").
 		Joins(`INNER JOIN category c ON c.id = p.category_id`).
 		Select(`p.id, p."name", p.code, p.brand_id, b."name" AS brand_name, 
-			p.category_id, c."name" AS category_name, p.description, p.short_description, p.top_features`).
+			p.category_id, c."name" AS category_name, p.description, p.short_description, p.top_features,
+			p.status, p.publish_at, p.unpublish_at, p.reviewed_at`).
 		Where("p.id = ? AND p.deleted_at IS NULL", id).
 		First(&data, id).Error; err != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
@@ -480,3 +548,32 @@ func GetProductsSelectList(ctx *app.HttpContext) error {
 
 	return ctx.JSON(*response, http.StatusOK)
 }
+
+// canAccessProduct checks the product against the permission scope of the request
+func canAccessProduct(ctx *app.HttpContext, baseDB *gorm.DB, productID uuid.UUID) error {
+	var product models.Product
+
+	if err := baseDB.Select("id", "category_id", "brand_id").First(&product, "id = ?", productID).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.ModelProductNotFound, nil)
+	}
+
+	return authorization.CanAccessResource(ctx, product.PermissionResource())
+}
+
+// canAccessProductItem checks the product of the item against the permission scope of the request
+func canAccessProductItem(ctx *app.HttpContext, baseDB *gorm.DB, productItemID uuid.UUID) error {
+	var item models.ProductItem
+
+	if err := baseDB.Select("id", "product_id").First(&item, "id = ?", productItemID).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	return canAccessProduct(ctx, baseDB, item.ProductID)
+}
+
+// scopedProductIDs is the subquery of the ids of the products in the permission scope of the request
+func scopedProductIDs(ctx *app.HttpContext) *gorm.DB {
+	products := db.MustGormDBConn(ctx).Table("product").Select("id")
+
+	return authorization.GetScope(ctx).Where(products, "category_id", "brand_id")
+}
@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/product_compare"
+	"github.com/esmailemami/eshop/app/services/product_schedule"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CompareProducts godoc
+// @Tags ProductComparisons
+// @Accept json
+// @Produce json
+// @Param productIds  query  string  true  "comma separated product IDs"
+// @Success 200 {object} appmodels.ProductComparisonOutPutModel
+// @Failure 400 {object} map[string]any
+// @Router /user/product/compare [get]
+func CompareProducts(ctx *app.HttpContext) error {
+	param, _ := ctx.GetParam("productIds")
+
+	productIDs := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+
+	for _, value := range strings.Split(param, ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		id, err := uuid.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			productIDs = append(productIDs, id)
+		}
+	}
+
+	if len(productIDs) < product_compare.MinProducts || len(productIDs) > product_compare.MaxProducts {
+		return errors.NewBadRequestError(consts.InvalidComparedProductsCount, nil)
+	}
+
+	data, err := compareProducts(db.MustGormDBConn(ctx), productIDs)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetProductComparisons godoc
+// @Tags ProductComparisons
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.ProductComparisonListOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/productComparison [get]
+func GetProductComparisons(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.ProductComparisonListOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Model(&models.ProductComparison{}).Where("created_by_id = ?", *user.ID)
+
+	data, err := parameter.SearchColumns("name").
+		SortDescending("updated_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetProductComparison godoc
+// @Tags ProductComparisons
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} appmodels.ProductComparisonOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/productComparison/{id} [get]
+func GetProductComparison(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductComparison
+
+	if baseDB.First(&dbModel, "id = ? AND created_by_id = ?", id, *user.ID).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(dbModel.ProductIDs))
+	for _, value := range dbModel.ProductIDs {
+		if id, err := uuid.Parse(value); err == nil {
+			productIDs = append(productIDs, id)
+		}
+	}
+
+	data, err := compareProducts(baseDB, productIDs)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// CreateProductComparison godoc
+// @Tags ProductComparisons
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param ProductComparison   body  appmodels.ProductComparisonReqModel  true  "ProductComparison model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/productComparison  [post]
+func CreateProductComparison(ctx *app.HttpContext) error {
+	var inputModel appmodels.ProductComparisonReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	dbModel := inputModel.ToDBModel()
+	if err := db.MustGormDBConn(ctx).Create(dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// EditProductComparison godoc
+// @Tags ProductComparisons
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param ProductComparison   body  appmodels.ProductComparisonReqModel  true  "ProductComparison model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/productComparison/edit/{id}  [post]
+func EditProductComparison(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ProductComparisonReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductComparison
+
+	if baseDB.First(&dbModel, "id = ? AND created_by_id = ?", id, *user.ID).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	inputModel.MergeWithDBData(&dbModel)
+	if err := baseDB.Save(&dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.Updated, http.StatusOK)
+}
+
+// DeleteProductComparison godoc
+// @Tags ProductComparisons
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/productComparison/delete/{id}  [post]
+func DeleteProductComparison(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductComparison
+
+	if baseDB.First(&dbModel, "id = ? AND created_by_id = ?", id, *user.ID).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if baseDB.Delete(&dbModel).Error != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, nil)
+	}
+
+	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+}
+
+// compareProducts returns the comparison matrix of the visible products in the order of productIDs
+func compareProducts(baseDB *gorm.DB, productIDs []uuid.UUID) (*appmodels.ProductComparisonOutPutModel, error) {
+	var rows []appmodels.ComparedProductOutPutModel
+
+	if err := baseDB.Table("product as p").
+		Joins("INNER JOIN brand b ON b.id = p.brand_id").
+		Joins("LEFT JOIN LATERAL (?) as pi2 ON TRUE", baseDB.Table("product_item pi2").
+			Select("id, price").
+			Where("pi2.quantity > 0 AND pi2.product_id = p.id AND pi2.deleted_at IS NULL").
+			Where(product_schedule.VisibleCond("pi2")).
+			Order("CASE WHEN p.default_product_item_id IS NULL THEN pi2.bought_quantity WHEN pi2.id = p.default_product_item_id THEN 0 ELSE 1 END").
+			Limit(1),
+		).
+		Joins("LEFT JOIN LATERAL (?) as pf ON TRUE", baseDB.Table("product_file_map pfm").
+			Select("file_id").
+			Where("pfm.product_id = p.id").
+			Order("pfm.priority ASC").
+			Limit(1),
+		).
+		Joins("LEFT JOIN file f ON f.id = pf.file_id").
+		Where("p.deleted_at IS NULL AND p.id IN ?", productIDs).
+		Where(product_schedule.VisibleProductCond("p")).
+		Select(`p.id AS product_id, p."name", p.rate, b."name" AS brand_name, pi2.id AS product_item_id, pi2.price,
+			f.file_type, f.unique_file_name AS file_name`).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]appmodels.ComparedProductOutPutModel, len(rows))
+	for _, row := range rows {
+		if row.FileType != nil && row.FileName != nil {
+			row.FileUrl = row.FileType.GetFileUrl(*row.FileName)
+		}
+		byID[row.ProductID] = row
+	}
+
+	// keep the requested order, the products which are not visible anymore are skipped
+	data := appmodels.ProductComparisonOutPutModel{
+		Products:   []appmodels.ComparedProductOutPutModel{},
+		Categories: []product_compare.Category{},
+	}
+
+	visibleIDs := []uuid.UUID{}
+
+	for _, id := range productIDs {
+		if row, ok := byID[id]; ok {
+			data.Products = append(data.Products, row)
+			visibleIDs = append(visibleIDs, id)
+		}
+	}
+
+	if len(visibleIDs) == 0 {
+		return &data, nil
+	}
+
+	var values []product_compare.Value
+
+	if err := baseDB.Table("product_feature_value fv").
+		Joins("INNER JOIN product_feature_key fk ON fk.id = fv.product_feature_key_id").
+		Joins("INNER JOIN product_feature_category fc ON fc.id = fk.product_feature_category_id").
+		Where("fv.deleted_at IS NULL AND fk.deleted_at IS NULL AND fc.deleted_at IS NULL").
+		Where("fv.product_id IN ?", visibleIDs).
+		Select(`fc.id AS category_id, fc."name" AS category_name, fk.id AS key_id, fk."name" AS key_name,
+			fv.product_id, fv.value`).
+		Order("fc.name, fc.id, fk.name, fk.id").
+		Find(&values).Error; err != nil {
+		return nil, err
+	}
+
+	data.Categories = product_compare.Matrix(visibleIDs, values)
+
+	return &data, nil
+}
@@ -8,9 +8,12 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/authorization"
+	"github.com/esmailemami/eshop/app/services/product_revision"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // GetProductFeatureValues godoc
@@ -41,6 +44,10 @@ func GetProductFeatureValues(ctx *app.HttpContext) error {
 		baseDB = baseDB.Where("product_id = ?", productID)
 	}
 
+	if authorization.GetScope(ctx) != nil {
+		baseDB = baseDB.Where("product_id IN (?)", scopedProductIDs(ctx))
+	}
+
 	data, err := parameter.SearchColumns("value").
 		SortDescending("created_at").
 		Execute(baseDB)
@@ -68,25 +75,38 @@ func GetProductFeatureValue(ctx *app.HttpContext) error {
 	if err != nil {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
-	baseDB := db.MustGormDBConn(ctx).Model(&models.ProductFeatureValue{})
+	baseDB := db.MustGormDBConn(ctx)
 
-	var data appmodels.ProductFeatureValueOutPutModel
+	var dbModel models.ProductFeatureValue
 
-	if err := baseDB.First(&data, "id", id).Error; err != nil {
+	if err := baseDB.First(&dbModel, "id", id).Error; err != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
+	if err := canAccessProduct(ctx, baseDB, dbModel.ProductID); err != nil {
+		return err
+	}
+
+	data := appmodels.ProductFeatureValueOutPutModel{
+		ID:                  dbModel.ID,
+		CreatedAt:           dbModel.CreatedAt,
+		UpdatedAt:           dbModel.UpdatedAt,
+		ProductFeatureKeyID: dbModel.ProductFeatureKeyID,
+		Value:               dbModel.Value,
+	}
+
 	return ctx.JSON(data, http.StatusOK)
 }
 
 // Create ProductFeatureValue godoc
+// @Description The values replace the current values of the product in a draft revision which is returned
 // @Tags ProductFeatureValues
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param productId path  string  true  "Product ID"
 // @Param ProductFeatureValue   body  []appmodels.ProductFeatureValueReqModel  true  "ProductFeatureValue model"
-// @Success 200 {object} helpers.SuccessResponse
+// @Success 200 {object} helpers.SuccessDBResponse
 // @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]any
 // @Router /admin/productFeatureValue/{productId}  [post]
@@ -104,43 +124,43 @@ func CreateProductFeatureValue(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
 	baseDB := db.MustGormDBConn(ctx)
-	baseTx := baseDB.Begin()
 
-	// remove all last items if exists
-	if err := baseTx.Unscoped().Where("product_id = ?", productId).Delete(&models.ProductFeatureValue{}).Error; err != nil {
-		baseTx.Rollback()
-		return errors.NewInternalServerError(consts.InternalServerError, err)
+	if err := canAccessProduct(ctx, baseDB, productId); err != nil {
+		return err
 	}
 
-	dbItems := []models.ProductFeatureValue{}
+	features := make([]appmodels.ProductRevisionFeatureReqModel, 0, len(inputModels))
 	for _, inputModel := range inputModels {
 		inputModel.ProductID = productId
 		err = inputModel.ValidateCreate()
 		if err != nil {
-			baseTx.Rollback()
 			return errors.NewValidationError(consts.ValidationError, err)
 		}
 
-		dbItems = append(dbItems, *inputModel.ToDBModel())
+		features = append(features, appmodels.ProductRevisionFeatureReqModel{
+			ProductFeatureKeyID: inputModel.ProductFeatureKeyID,
+			Value:               inputModel.Value,
+		})
 	}
 
-	if err := baseTx.CreateInBatches(dbItems, len(dbItems)).Error; err != nil {
-		baseTx.Rollback()
+	keys, err := productFeatureKeyNames(baseDB, features)
+	if err != nil {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
-	baseTx.Commit()
-
-	return ctx.QuickResponse(consts.Created, http.StatusOK)
+	return draftProductFeatures(ctx, baseDB, productId, func(content *product_revision.Content) {
+		content.Features = appmodels.ProductRevisionReqModel{Features: features}.ToContent(keys).Features
+	})
 }
 
 // Delete ProductFeatureValue godoc
+// @Description The value is removed in a draft revision which is returned
 // @Tags ProductFeatureValues
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id  path  string  true  "Record ID"
-// @Success 200 {object} helpers.SuccessResponse
+// @Success 200 {object} helpers.SuccessDBResponse
 // @Failure 400 {object} map[string]any
 // @Failure 401 {object} map[string]any
 // @Router /admin/productFeatureValue/delete/{id}  [post]
@@ -150,7 +170,7 @@ func DeleteProductFeatureValue(ctx *app.HttpContext) error {
 		return err
 	}
 
-	baseDB := db.MustGormDBConn(ctx).Model(&models.ProductFeatureValue{})
+	baseDB := db.MustGormDBConn(ctx)
 
 	var dbModel models.ProductFeatureValue
 
@@ -158,9 +178,36 @@ func DeleteProductFeatureValue(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
-	if baseDB.Delete(&dbModel).Error != nil {
-		return errors.NewInternalServerError(consts.InternalServerError, nil)
+	if err := canAccessProduct(ctx, baseDB, dbModel.ProductID); err != nil {
+		return err
+	}
+
+	return draftProductFeatures(ctx, baseDB, dbModel.ProductID, func(content *product_revision.Content) {
+		features := content.Features[:0]
+		for _, feature := range content.Features {
+			if feature.ProductFeatureKeyID != dbModel.ProductFeatureKeyID {
+				features = append(features, feature)
+			}
+		}
+		content.Features = features
+	})
+}
+
+// draftProductFeatures drafts the feature values of the product, they are published by reviewing the draft
+func draftProductFeatures(ctx *app.HttpContext, baseDB *gorm.DB, productID uuid.UUID, edit func(content *product_revision.Content)) error {
+	baseTx := baseDB.Begin()
+
+	draft, err := product_revision.DraftChanges(baseTx, productID, edit)
+	if err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	if draft == nil {
+		return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
 	}
 
-	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+	return ctx.QuickDBResponse(consts.RevisionDrafted, *draft.ID, http.StatusOK)
 }
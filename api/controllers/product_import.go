@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/authorization"
+	fileService "github.com/esmailemami/eshop/app/services/file"
+	productImport "github.com/esmailemami/eshop/app/services/product_import"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+const productImportDir = "import"
+
+// ImportProducts godoc
+// @Tags Products
+// @Accept mpfd
+// @Produce json
+// @Security Bearer
+// @Param file  formData  file  true  "csv or xlsx file"
+// @Param dryRun  query  bool  false  "validate the file without saving"
+// @Success 200 {object} appmodels.ProductImportOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/product/import [post]
+func ImportProducts(ctx *app.HttpContext) error {
+	if err := ctx.Request.ParseMultipartForm(20 << 20); err != nil { // 20 MB maximum file size
+		return errors.NewBadRequestError(consts.InvalidFileSize, err)
+	}
+
+	file, fileHeader, err := ctx.Request.FormFile("file")
+	if err != nil {
+		return errors.NewBadRequestError(consts.FileNotSentToServer, err)
+	}
+	file.Close()
+
+	var format string
+
+	switch {
+	case fileService.IsExcelFileMimeType(fileService.GetMimeType(fileHeader)),
+		strings.EqualFold(fileService.GetFileExetension(fileHeader.Filename), productImport.FormatXLSX):
+		format = productImport.FormatXLSX
+	case strings.EqualFold(fileService.GetFileExetension(fileHeader.Filename), productImport.FormatCSV):
+		format = productImport.FormatCSV
+	default:
+		return errors.NewBadRequestError(consts.InvalidFileType, nil)
+	}
+
+	dryRun := false
+	if v, ok := ctx.GetParam("dryRun"); ok {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	path, _, err := fileService.UploadFile(fileHeader, productImportDir, true, true)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	dbModel := models.ProductImport{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		OriginalName: fileHeader.Filename,
+		FilePath:     path,
+		Format:       format,
+		DryRun:       dryRun,
+		Status:       models.ProductImportStatusPending,
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := baseDB.Create(&dbModel).Error; err != nil {
+		fileService.DeleteFileByPath(path)
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// the request context is canceled after the response, the job keeps the user for the audit columns and the
+	// scope of the request
+	jobCtx := context.WithValue(context.Background(), consts.UserContext, *user)
+	if scope := authorization.GetScope(ctx); scope != nil {
+		jobCtx = context.WithValue(jobCtx, consts.PermissionScope, scope)
+	}
+
+	productImport.Enqueue(jobCtx, *dbModel.ID)
+
+	return ctx.JSON(appmodels.ProductImportOutPutModel{
+		ID:           dbModel.ID,
+		OriginalName: dbModel.OriginalName,
+		Format:       dbModel.Format,
+		DryRun:       dbModel.DryRun,
+		Status:       dbModel.Status,
+		CreatedAt:    dbModel.CreatedAt,
+	}, http.StatusOK)
+}
+
+// GetProductImports godoc
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.ProductImportOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/product/import [get]
+func GetProductImports(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+
+	parameter := parameter.New[appmodels.ProductImportOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("product_import").Where("deleted_at IS NULL")
+
+	response, err := parameter.SelectColumns(`id, original_name, format, dry_run, status, total_rows, succeeded_rows,
+		failed_rows, message, created_at, started_at, finished_at`).
+		SearchColumns("original_name").
+		SortDescending("created_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// GetProductImport godoc
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} appmodels.ProductImportOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/product/import/{id} [get]
+func GetProductImport(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var data appmodels.ProductImportOutPutModel
+
+	if err := baseDB.Table("product_import").
+		Where("id = ? AND deleted_at IS NULL", id).
+		First(&data).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	data.Errors = []appmodels.ProductImportErrorModel{}
+
+	if err := baseDB.Table("product_import_error").
+		Where("product_import_id = ? AND deleted_at IS NULL", id).
+		Order(`"row", "column"`).
+		Find(&data.Errors).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(data, http.StatusOK)
+}
+
+// ExportProducts godoc
+// @Tags Products
+// @Accept json
+// @Produce octet-stream
+// @Security Bearer
+// @Param format  query  string  false  "csv or xlsx, default is xlsx"
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/product/export [get]
+func ExportProducts(ctx *app.HttpContext) error {
+	format := productImport.FormatXLSX
+	if v, ok := ctx.GetParam("format"); ok {
+		format = strings.ToLower(v)
+	}
+
+	var contentType string
+
+	switch format {
+	case productImport.FormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case productImport.FormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	default:
+		return errors.NewBadRequestError(consts.InvalidFileType, nil)
+	}
+
+	records, err := productImport.Export(db.MustGormDBConn(ctx), authorization.GetScope(ctx))
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := productImport.WriteSheet(format, &buf, records); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	fileName := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102-150405"), format)
+
+	ctx.ResponseWriter.Header().Set("Content-Type", contentType)
+	ctx.ResponseWriter.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	ctx.ResponseWriter.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	ctx.ResponseWriter.Write(buf.Bytes())
+
+	return nil
+}
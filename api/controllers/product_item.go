@@ -1,13 +1,20 @@
 package controllers
 
 import (
+	errs "errors"
 	"net/http"
+	"time"
 
 	"github.com/esmailemami/eshop/app"
 	"github.com/esmailemami/eshop/app/consts"
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/authorization"
+	"github.com/esmailemami/eshop/app/services/barcode"
+	"github.com/esmailemami/eshop/app/services/inventory"
+	"github.com/esmailemami/eshop/app/services/price_history"
+	"github.com/esmailemami/eshop/app/services/product_schedule"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
@@ -45,8 +52,11 @@ func GetProductItem(ctx *app.HttpContext) error {
 			Limit(1),
 		).
 		Select(`pi2.id, pi2.price,pi2.status, pi2 .color_id, pi2.product_id, pi2.quantity,
+		pi2.sku, pi2.barcode, pi2.weight, pi2.length, pi2.width, pi2.height,
 		p."name" AS product_title,p.rate, p.code AS product_code, p.short_description AS product_short_description, 
 		p.description AS product_description, c."name" AS color_name,d.type as discount_type, d.value as discount_value, d.quantity as discount_quantity`).
+		Where(product_schedule.VisibleCond("pi2")).
+		Where(product_schedule.VisibleProductCond("p")).
 		First(&data, "pi2.id", id).Error; err != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
@@ -119,12 +129,20 @@ func GetProductItem(ctx *app.HttpContext) error {
 	if err := baseDB.Table("product_item pi2").
 		Joins("INNER JOIN color c ON c.id = pi2.color_id").
 		Where("pi2.deleted_at IS NULL AND pi2.product_id=?", data.ProductID).
+		Where(product_schedule.VisibleCond("pi2")).
 		Select("pi2.id AS product_item_id, c.name, c.color_hex").Find(&colors).Error; err != nil {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
 	data.Colors = colors
 
+	lowestPrice, err := price_history.Lowest(baseDB, *data.ID, time.Now().AddDate(0, 0, -price_history.LowestPriceDays), data.Price)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	data.LowestPrice = lowestPrice
+
 	return ctx.JSON(data, http.StatusOK)
 }
 
@@ -145,13 +163,17 @@ func GetProductItems(ctx *app.HttpContext) error {
 	}
 	baseDB := db.MustGormDBConn(ctx)
 
+	if err := canAccessProduct(ctx, baseDB, productID); err != nil {
+		return err
+	}
+
 	var data []appmodels.ProductItemOutPutModel
 
 	if err := baseDB.Table("product_item pi2").
 		Joins("INNER JOIN product p ON P.id = pi2 .product_id").
 		Joins("INNER JOIN color c ON C.id = pi2.color_id").
-		Select(`pi2.id, pi2.price,pi2.status, pi2 .color_id, pi2.product_id, pi2.quantity,
-		p."name" AS product_title, p.code AS product_code, c."name" AS color_name`).
+		Select(`pi2.id, pi2.price,pi2.status, pi2 .color_id, pi2.product_id, pi2.quantity, pi2.sku, pi2.barcode,
+		pi2.publish_at, pi2.unpublish_at, pi2.reorder_threshold, p."name" AS product_title, p.code AS product_code, c."name" AS color_name`).
 		Find(&data, "p.id = ? AND pi2.deleted_at IS NULL", productID).Error; err != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
@@ -177,15 +199,23 @@ func CreateProductItem(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
 	baseDB := db.MustGormDBConn(ctx)
-	baseTx := baseDB.Begin()
 
-	err = inputModel.ValidateCreate()
+	err = inputModel.ValidateCreate(baseDB)
 	if err != nil {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
+	if err := canAccessProduct(ctx, baseDB, inputModel.ProductID); err != nil {
+		return err
+	}
+
+	baseTx := baseDB.Begin()
+
 	dbModel := inputModel.ToDBModel()
 
+	// the quantity is set along with the ledger
+	dbModel.Quantity = 0
+
 	if db.Exists(
 		baseDB,
 		&models.ProductItem{},
@@ -204,6 +234,17 @@ func CreateProductItem(ctx *app.HttpContext) error {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	if err := price_history.Record(baseTx, *dbModel.ID, nil, dbModel.Price, models.PriceChangeSourceManual, nil); err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := inventory.ChangeItemQuantity(baseTx, *dbModel.ID, inputModel.Quantity,
+		models.StockMovementReasonAdjustment, nil, nil); err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
 	if inputModel.IsMainItem {
 		if err := baseTx.Model(&models.Product{}).
 			Where("id=?", inputModel.ProductID).
@@ -244,7 +285,6 @@ func EditProductItem(ctx *app.HttpContext) error {
 	}
 
 	baseDB := db.MustGormDBConn(ctx)
-	baseTx := baseDB.Begin()
 
 	var dbModel models.ProductItem
 
@@ -252,11 +292,24 @@ func EditProductItem(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
-	err = inputModel.ValidateUpdate()
+	if err := canAccessProduct(ctx, baseDB, dbModel.ProductID); err != nil {
+		return err
+	}
+
+	err = inputModel.ValidateUpdate(baseDB, id)
 	if err != nil {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
+	// the item cannot be moved out of the scope
+	if err := canAccessProduct(ctx, baseDB, inputModel.ProductID); err != nil {
+		return err
+	}
+
+	previousPrice := dbModel.Price
+	previousQuantity := dbModel.Quantity
+	codeChanged := inputModel.CodeChanged(&dbModel)
+
 	inputModel.MergeWithDBData(&dbModel)
 
 	if db.Exists(
@@ -273,11 +326,41 @@ func EditProductItem(ctx *app.HttpContext) error {
 		)
 	}
 
-	if baseTx.Save(&dbModel).Error != nil {
+	baseTx := baseDB.Begin()
+
+	// the quantity is changed along with the ledger
+	if baseTx.Omit("quantity").Save(&dbModel).Error != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := price_history.Record(baseTx, *dbModel.ID, &previousPrice, dbModel.Price, models.PriceChangeSourceManual, nil); err != nil {
 		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	if codeChanged {
+		if err := barcode.RefreshItemImage(baseDB, baseTx, &dbModel); err != nil {
+			baseTx.Rollback()
+			return errors.NewInternalServerError(consts.InternalServerError, err)
+		}
+	}
+
+	if err := inventory.AdjustItemQuantity(baseTx, *dbModel.ID, inputModel.Quantity-previousQuantity,
+		models.StockMovementReasonAdjustment, nil, nil); err != nil {
+		baseTx.Rollback()
+
+		if err == inventory.ErrInsufficientStock {
+			return errors.NewBadRequestError(consts.InvalidQuantity, nil)
+		}
+
+		if err == inventory.ErrManagedStock {
+			return errors.NewBadRequestError(consts.QuantityManagedByWarehouses, nil)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
 	if inputModel.IsMainItem {
 		if err := baseTx.Model(&models.Product{}).
 			Where("id=?", inputModel.ProductID).
@@ -309,7 +392,6 @@ func DeleteProductItem(ctx *app.HttpContext) error {
 	}
 
 	baseDB := db.MustGormDBConn(ctx)
-	baseTx := baseDB.Begin()
 
 	var dbModel models.ProductItem
 
@@ -317,6 +399,12 @@ func DeleteProductItem(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
+	if err := authorization.CanAccessResource(ctx, dbModel.Product.PermissionResource()); err != nil {
+		return err
+	}
+
+	baseTx := baseDB.Begin()
+
 	if baseTx.Where("id=?", &dbModel.ID).Delete(&dbModel).Error != nil {
 		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, nil)
@@ -362,6 +450,10 @@ func GetProductItemsSelectList(ctx *app.HttpContext) error {
 
 	baseDB := db.MustGormDBConn(ctx)
 
+	if err := canAccessProduct(ctx, baseDB, productID); err != nil {
+		return err
+	}
+
 	parameter := parameter.New[appmodels.ProductItemAdminSelectListOutPutModel](ctx, baseDB)
 
 	baseDB = baseDB.Table("product_item as pi2").
@@ -378,3 +470,101 @@ func GetProductItemsSelectList(ctx *app.HttpContext) error {
 
 	return ctx.JSON(*response, http.StatusOK)
 }
+
+// LookupProductItem godoc
+// @Tags ProductItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param sku  query  string  false  "SKU"
+// @Param barcode  query  string  false  "Barcode"
+// @Success 200 {object} appmodels.ProductItemLookupOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productItem/lookup [get]
+func LookupProductItem(ctx *app.HttpContext) error {
+	sku, hasSKU := ctx.GetParam("sku")
+	barcode, hasBarcode := ctx.GetParam("barcode")
+
+	if !hasSKU && !hasBarcode {
+		return errors.NewBadRequestError(consts.BadRequest, nil)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	qry := baseDB.Table("product_item pi2").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("INNER JOIN color c ON c.id = pi2.color_id").
+		Where("pi2.deleted_at IS NULL")
+
+	if hasSKU {
+		qry = qry.Where("pi2.sku = ?", sku)
+	}
+
+	if hasBarcode {
+		qry = qry.Where("pi2.barcode = ?", barcode)
+	}
+
+	qry = authorization.GetScope(ctx).Where(qry, "p.category_id", "p.brand_id")
+
+	var data appmodels.ProductItemLookupOutPutModel
+
+	if err := qry.Select(`pi2.id, pi2.price, pi2.status, pi2.product_id, pi2.quantity, pi2.sku, pi2.barcode,
+		pi2.weight, pi2.length, pi2.width, pi2.height,
+		p."name" AS product_title, p.code AS product_code, c."name" AS color_name`).
+		First(&data).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	return ctx.JSON(data, http.StatusOK)
+}
+
+// GenerateProductItemBarcode godoc
+// @Tags ProductItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} appmodels.ProductItemBarcodeOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productItem/barcode/{id}  [post]
+func GenerateProductItemBarcode(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductItem
+
+	if baseDB.First(&dbModel, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := canAccessProduct(ctx, baseDB, dbModel.ProductID); err != nil {
+		return err
+	}
+
+	baseTx := baseDB.Begin()
+
+	file, code, err := barcode.SaveItemImage(baseDB, baseTx, &dbModel)
+	if err != nil {
+		baseTx.Rollback()
+
+		if errs.Is(err, barcode.ErrNoCode) || errs.Is(err, barcode.ErrInvalidCode) {
+			return errors.NewBadRequestError(consts.InvalidBarcode, err)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.JSON(appmodels.ProductItemBarcodeOutPutModel{
+		FileID:  *file.ID,
+		Code:    code,
+		FileUrl: file.FileType.GetFileUrl(file.UniqueFileName),
+	}, http.StatusOK)
+}
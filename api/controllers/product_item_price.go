@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/price_history"
+	"github.com/esmailemami/eshop/app/services/product_schedule"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+// GetProductItemPriceHistory godoc
+// @Tags ProductItems
+// @Accept json
+// @Produce json
+// @Param id  path  string  true  "Product item ID"
+// @Param days  query  int  false  "days of the history, default is 90"
+// @Success 200 {object} appmodels.ProductItemPriceHistoryOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]any
+// @Router /user/productItem/priceHistory/{id} [get]
+func GetProductItemPriceHistory(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	days := 90
+
+	if v, ok := ctx.GetParam("days"); ok {
+		if days, err = strconv.Atoi(v); err != nil || days < 1 || days > 365 {
+			return errors.NewBadRequestError(consts.InvalidPriceHistoryDays, nil)
+		}
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var item models.ProductItem
+
+	if err := baseDB.Table("product_item pi2").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Where(product_schedule.VisibleCond("pi2")).
+		Where(product_schedule.VisibleProductCond("p")).
+		Select("pi2.id, pi2.price").
+		First(&item, "pi2.id = ?", id).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -days)
+
+	points := []appmodels.PricePointOutPutModel{}
+
+	// the price which was in effect at the start of the period is the first point of the chart
+	var startPrices []float64
+
+	if err := baseDB.Model(&models.ProductItemPrice{}).
+		Where("product_item_id = ? AND created_at < ?", id, since).
+		Order("created_at DESC").
+		Limit(1).
+		Pluck("price", &startPrices).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if len(startPrices) > 0 {
+		points = append(points, appmodels.PricePointOutPutModel{Price: startPrices[0], ChangedAt: since})
+	}
+
+	var changes []appmodels.PricePointOutPutModel
+
+	if err := baseDB.Model(&models.ProductItemPrice{}).
+		Where("product_item_id = ? AND created_at >= ?", id, since).
+		Order("created_at").
+		Select("price, created_at AS changed_at").
+		Scan(&changes).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	points = append(points, changes...)
+
+	lowestPrice, err := price_history.Lowest(baseDB, id, now.AddDate(0, 0, -price_history.LowestPriceDays), item.Price)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(appmodels.ProductItemPriceHistoryOutPutModel{
+		ProductItemID: id,
+		Price:         item.Price,
+		LowestPrice:   lowestPrice,
+		LowestDays:    price_history.LowestPriceDays,
+		Points:        points,
+	}, http.StatusOK)
+}
+
+// GetAdminProductItemPriceHistory godoc
+// @Tags ProductItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Product item ID"
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param source  query  int  false  "source" Enums(0,1,2)
+// @Success 200 {object} parameter.ListResponse[appmodels.ProductItemPriceOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productItem/priceHistory/{id} [get]
+func GetAdminProductItemPriceHistory(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := canAccessProductItem(ctx, baseDB, id); err != nil {
+		return err
+	}
+
+	parameter := parameter.New[appmodels.ProductItemPriceOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("product_item_price_history ph").
+		Joins("LEFT JOIN public.user u ON u.id = ph.created_by_id").
+		Where("ph.deleted_at IS NULL AND ph.product_item_id = ?", id)
+
+	if source, ok := ctx.GetParam("source"); ok {
+		baseDB = baseDB.Where("ph.source = ?", source)
+	}
+
+	response, err := parameter.SelectColumns(`ph.id, ph.created_at, u.username AS created_by, ph.price,
+		ph.previous_price, ph.source, ph.schedule_id`).
+		SortDescending("ph.created_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// GetProductItemPriceSchedules godoc
+// @Tags ProductItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productItemId  path  string  true  "Product item ID"
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param status  query  int  false  "status" Enums(0,1,2)
+// @Success 200 {object} parameter.ListResponse[appmodels.ProductItemPriceScheduleOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productItem/priceSchedule/{productItemId} [get]
+func GetProductItemPriceSchedules(ctx *app.HttpContext) error {
+	productItemID, err := uuid.Parse(ctx.GetPathParam("productItemId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := canAccessProductItem(ctx, baseDB, productItemID); err != nil {
+		return err
+	}
+
+	parameter := parameter.New[appmodels.ProductItemPriceScheduleOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("product_item_price_schedule ps").
+		Joins("LEFT JOIN public.user u ON u.id = ps.created_by_id").
+		Where("ps.deleted_at IS NULL AND ps.product_item_id = ?", productItemID)
+
+	if status, ok := ctx.GetParam("status"); ok {
+		baseDB = baseDB.Where("ps.status = ?", status)
+	}
+
+	response, err := parameter.SelectColumns(`ps.id, ps.created_at, u.username AS created_by, ps.price,
+		ps.apply_at, ps.status, ps.applied_at`).
+		SortDescending("ps.apply_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// CreateProductItemPriceSchedule godoc
+// @Tags ProductItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productItemId  path  string  true  "Product item ID"
+// @Param PriceSchedule   body  appmodels.ProductItemPriceScheduleReqModel  true  "Price schedule model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productItem/priceSchedule/{productItemId}  [post]
+func CreateProductItemPriceSchedule(ctx *app.HttpContext) error {
+	productItemID, err := uuid.Parse(ctx.GetPathParam("productItemId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ProductItemPriceScheduleReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	inputModel.ProductItemID = productItemID
+
+	if err := inputModel.ValidateCreate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := canAccessProductItem(ctx, baseDB, productItemID); err != nil {
+		return err
+	}
+
+	dbModel := inputModel.ToDBModel()
+
+	if err := baseDB.Create(dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// CancelProductItemPriceSchedule godoc
+// @Tags ProductItems
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productItem/priceSchedule/cancel/{id}  [post]
+func CancelProductItemPriceSchedule(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var schedule models.ProductItemPriceSchedule
+
+	if err := baseDB.Select("id", "product_item_id").First(&schedule, "id = ?", id).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := canAccessProductItem(ctx, baseDB, schedule.ProductItemID); err != nil {
+		return err
+	}
+
+	// the status is checked in the update, so a schedule which is applied meanwhile is not canceled
+	result := baseDB.Model(&models.ProductItemPriceSchedule{}).
+		Where("id = ? AND status = ?", id, models.ProductItemPriceSchedulePending).
+		UpdateColumn("status", models.ProductItemPriceScheduleCanceled)
+
+	if result.Error != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.NewBadRequestError(consts.PriceScheduleIsNotPending, nil)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
@@ -0,0 +1,649 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/app/services/notifier/email"
+	"github.com/esmailemami/eshop/app/services/order"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetProductQuestions godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Param productId  path  string  true  "Product ID"
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.ProductQuestionOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Router /user/question/product/{productId} [get]
+func GetProductQuestions(ctx *app.HttpContext) error {
+	productID, err := uuid.Parse(ctx.GetPathParam("productId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	parameter := parameter.New[appmodels.ProductQuestionOutPutModel](ctx, baseDB)
+
+	qry := baseDB.Table("product_question q").
+		Joins(`INNER JOIN "user" u ON u.id = q.created_by_id`).
+		Where("q.deleted_at IS NULL AND q.product_id = ? AND q.status = ?", productID, models.CommntStatusAccept)
+
+	response, err := parameter.SelectColumns("q.id, q.created_at, q.text, u.username, q.upvotes").
+		SearchColumns("q.text").
+		Sort("q.upvotes DESC", "q.created_at DESC").
+		Execute(qry)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if len(response.Data) == 0 {
+		return ctx.JSON(*response, http.StatusOK)
+	}
+
+	questionIDs := make([]uuid.UUID, len(response.Data))
+	for i, question := range response.Data {
+		questionIDs[i] = *question.ID
+	}
+
+	var answers []appmodels.ProductAnswerOutPutModel
+
+	// the answers of the staff and the verified buyers are shown at first
+	if err := baseDB.Table("product_answer a").
+		Joins(`INNER JOIN "user" u ON u.id = a.created_by_id`).
+		Where("a.deleted_at IS NULL AND a.question_id IN ? AND a.status = ?", questionIDs, models.CommntStatusAccept).
+		Order("a.is_staff DESC, a.is_verified_buyer DESC, a.upvotes DESC, a.created_at").
+		Select("a.id, a.question_id, a.created_at, a.text, u.username, a.upvotes, a.is_staff, a.is_verified_buyer").
+		Scan(&answers).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	for i := range response.Data {
+		response.Data[i].Answers = []appmodels.ProductAnswerOutPutModel{}
+
+		for _, answer := range answers {
+			if answer.QuestionID == *response.Data[i].ID {
+				response.Data[i].Answers = append(response.Data[i].Answers, answer)
+			}
+		}
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// GetUserProductQuestions godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param status  query  models.CommentStatus  false  "Question Status"
+// @Success 200 {object} parameter.ListResponse[appmodels.UserProductQuestionOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/question [get]
+func GetUserProductQuestions(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	parameter := parameter.New[appmodels.UserProductQuestionOutPutModel](ctx, baseDB)
+
+	qry := baseDB.Table("product_question q").
+		Joins("INNER JOIN product p ON p.id = q.product_id").
+		Where("q.deleted_at IS NULL AND q.created_by_id = ?", *user.ID)
+
+	if status, ok := ctx.GetParam("status"); ok {
+		qry = qry.Where("q.status = ?", status)
+	}
+
+	response, err := parameter.SelectColumns(`q.id, q.created_at, q.text, q.product_id, p."name" AS product_name, q.status,
+		q.admin_note, q.upvotes, (SELECT COUNT(*) FROM product_answer a
+			WHERE a.question_id = q.id AND a.status = 1 AND a.deleted_at IS NULL) AS answers_count`).
+		SearchColumns("q.text", `p."name"`).
+		SortDescending("q.created_at").
+		Execute(qry)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// CreateProductQuestion godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Question   body  appmodels.ProductQuestionReqModel  true  "Question model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/question  [post]
+func CreateProductQuestion(ctx *app.HttpContext) error {
+	var inputModel appmodels.ProductQuestionReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	dbModel := inputModel.ToDBModel()
+
+	if err := baseDB.Create(dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// DeleteProductQuestion godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/question/delete/{id}  [post]
+func DeleteProductQuestion(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductQuestion
+
+	if baseDB.First(&dbModel, "id = ? AND created_by_id = ?", id, *user.ID).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := baseDB.Delete(&dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+}
+
+// CreateProductAnswer godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Answer   body  appmodels.ProductAnswerReqModel  true  "Answer model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/question/answer  [post]
+func CreateProductAnswer(ctx *app.HttpContext) error {
+	var inputModel appmodels.ProductAnswerReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var question models.ProductQuestion
+
+	if err := baseDB.First(&question, "id = ?", inputModel.QuestionID).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.ModelProductQuestionNotFound, nil)
+	}
+
+	if question.Status != models.CommntStatusAccept {
+		return errors.NewBadRequestError(consts.QuestionIsNotAccepted, nil)
+	}
+
+	dbModel := inputModel.ToDBModel(order.HasBought(baseDB, *user.ID, question.ProductID))
+
+	if err := baseDB.Create(dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// UpvoteProductQuestion godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Question ID"
+// @Success 200 {object} appmodels.ProductQAVoteOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/question/upvote/{id}  [post]
+func UpvoteProductQuestion(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var question models.ProductQuestion
+
+	if err := baseDB.First(&question, "id = ?", id).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.ModelProductQuestionNotFound, nil)
+	}
+
+	if question.Status != models.CommntStatusAccept {
+		return errors.NewBadRequestError(consts.QuestionIsNotAccepted, nil)
+	}
+
+	return toggleProductQAVote(ctx, &models.ProductQuestion{}, "question_id", id)
+}
+
+// UpvoteProductAnswer godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Answer ID"
+// @Success 200 {object} appmodels.ProductQAVoteOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/question/answer/upvote/{id}  [post]
+func UpvoteProductAnswer(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var answer models.ProductAnswer
+
+	if err := baseDB.First(&answer, "id = ?", id).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.ModelProductAnswerNotFound, nil)
+	}
+
+	if answer.Status != models.CommntStatusAccept {
+		return errors.NewBadRequestError(consts.AnswerIsNotAccepted, nil)
+	}
+
+	return toggleProductQAVote(ctx, &models.ProductAnswer{}, "answer_id", id)
+}
+
+// toggleProductQAVote removes the vote of the user when it exists, otherwise it adds the vote,
+// the upvotes of the question or the answer are changed along with the vote.
+func toggleProductQAVote(ctx *app.HttpContext, target any, column string, id uuid.UUID) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var output appmodels.ProductQAVoteOutPutModel
+
+	err = baseDB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Where(column+" = ? AND created_by_id = ?", id, *user.ID).
+			Delete(&models.ProductQAVote{})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		delta := -1
+
+		if result.RowsAffected == 0 {
+			vote := models.ProductQAVote{
+				Model: models.Model{
+					ID: models.NewID(),
+				},
+			}
+
+			if column == "question_id" {
+				vote.QuestionID = &id
+			} else {
+				vote.AnswerID = &id
+			}
+
+			// the vote of a concurrent request of the user is kept
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&vote)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			output.Upvoted = true
+
+			if result.RowsAffected == 0 {
+				delta = 0
+			} else {
+				delta = 1
+			}
+		}
+
+		if delta != 0 {
+			if err := tx.Model(target).
+				Where("id = ?", id).
+				UpdateColumn("upvotes", gorm.Expr("upvotes + ?", delta)).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(target).Where("id = ?", id).Pluck("upvotes", &output.Upvotes).Error
+	})
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(output, http.StatusOK)
+}
+
+// GetAdminProductQuestions godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param productId  query  string  false  "Product ID"
+// @Param status  query  models.CommentStatus  false  "Question Status"
+// @Success 200 {object} parameter.ListResponse[appmodels.AdminProductQuestionOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/question [get]
+func GetAdminProductQuestions(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+
+	parameter := parameter.New[appmodels.AdminProductQuestionOutPutModel](ctx, baseDB)
+
+	qry := baseDB.Table("product_question q").
+		Joins(`INNER JOIN "user" u ON u.id = q.created_by_id`).
+		Joins("INNER JOIN product p ON p.id = q.product_id").
+		Where("q.deleted_at IS NULL")
+
+	if productID, ok := ctx.GetParam("productId"); ok {
+		qry = qry.Where("q.product_id = ?", productID)
+	}
+
+	if status, ok := ctx.GetParam("status"); ok {
+		qry = qry.Where("q.status = ?", status)
+	}
+
+	response, err := parameter.SelectColumns(`q.id, q.created_at, q.text, u.username, q.product_id, p."name" AS product_name,
+		q.status, q.admin_note, q.upvotes, (SELECT COUNT(*) FROM product_answer a
+			WHERE a.question_id = q.id AND a.deleted_at IS NULL) AS answers_count`).
+		SearchColumns("q.text", `p."name"`, "u.username", "u.email", "u.mobile").
+		SortDescending("q.created_at").
+		Execute(qry)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// GetAdminProductAnswers godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param questionId  query  string  false  "Question ID"
+// @Param status  query  models.CommentStatus  false  "Answer Status"
+// @Success 200 {object} parameter.ListResponse[appmodels.AdminProductAnswerOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/question/answer [get]
+func GetAdminProductAnswers(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+
+	parameter := parameter.New[appmodels.AdminProductAnswerOutPutModel](ctx, baseDB)
+
+	qry := baseDB.Table("product_answer a").
+		Joins(`INNER JOIN "user" u ON u.id = a.created_by_id`).
+		Joins("INNER JOIN product_question q ON q.id = a.question_id").
+		Joins("INNER JOIN product p ON p.id = q.product_id").
+		Where("a.deleted_at IS NULL AND q.deleted_at IS NULL")
+
+	if questionID, ok := ctx.GetParam("questionId"); ok {
+		qry = qry.Where("a.question_id = ?", questionID)
+	}
+
+	if status, ok := ctx.GetParam("status"); ok {
+		qry = qry.Where("a.status = ?", status)
+	}
+
+	response, err := parameter.SelectColumns(`a.id, a.created_at, a.question_id, q.text AS question, p."name" AS product_name,
+		a.text, u.username, a.status, a.admin_note, a.upvotes, a.is_staff, a.is_verified_buyer`).
+		SearchColumns("a.text", "q.text", `p."name"`, "u.username").
+		SortDescending("a.created_at").
+		Execute(qry)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// ChangeProductQuestionStatus godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param Model   body  appmodels.ChangeProductQAStatusReqModel  true  "Status model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/question/changeStatus/{id}  [post]
+func ChangeProductQuestionStatus(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ChangeProductQAStatusReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductQuestion
+
+	if baseDB.First(&dbModel, "id = ?", id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := baseDB.Model(&dbModel).Updates(map[string]interface{}{
+		"status":     inputModel.Status,
+		"admin_note": inputModel.Note,
+	}).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.Updated, http.StatusOK)
+}
+
+// ChangeProductAnswerStatus godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param Model   body  appmodels.ChangeProductQAStatusReqModel  true  "Status model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/question/answer/changeStatus/{id}  [post]
+func ChangeProductAnswerStatus(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ChangeProductQAStatusReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductAnswer
+
+	if baseDB.First(&dbModel, "id = ?", id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	wasAccepted := dbModel.Status == models.CommntStatusAccept
+
+	if err := baseDB.Model(&dbModel).Updates(map[string]interface{}{
+		"status":     inputModel.Status,
+		"admin_note": inputModel.Note,
+	}).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if !wasAccepted && inputModel.Status == models.CommntStatusAccept {
+		notifyQuestionAnswered(baseDB, &dbModel)
+	}
+
+	return ctx.QuickResponse(consts.Updated, http.StatusOK)
+}
+
+// CreateAdminProductAnswer godoc
+// @Tags ProductQuestions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Answer   body  appmodels.ProductAnswerReqModel  true  "Answer model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/question/answer  [post]
+func CreateAdminProductAnswer(ctx *app.HttpContext) error {
+	var inputModel appmodels.ProductAnswerReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	dbModel := inputModel.ToStaffDBModel()
+
+	// answering a pending question accepts it too
+	err := baseDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ProductQuestion{}).
+			Where("id = ? AND status = ?", inputModel.QuestionID, models.CommentStatusPending).
+			UpdateColumn("status", models.CommntStatusAccept).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(dbModel).Error
+	})
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	notifyQuestionAnswered(baseDB, dbModel)
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// notifyQuestionAnswered emails the user who asked the question about the accepted answer
+func notifyQuestionAnswered(baseDB *gorm.DB, answer *models.ProductAnswer) {
+	var data struct {
+		Username    string  `gorm:"column:username"`
+		Email       *string `gorm:"column:email"`
+		ProductName string  `gorm:"column:product_name"`
+		Question    string  `gorm:"column:question"`
+	}
+
+	if err := baseDB.Table("product_question q").
+		Joins(`INNER JOIN "user" u ON u.id = q.created_by_id`).
+		Joins("INNER JOIN product p ON p.id = q.product_id").
+		Where("q.id = ?", answer.QuestionID).
+		// the notifications are sent to the verified email only
+		Select(`u.username, CASE WHEN u.email_verified THEN u.email END AS email, p."name" AS product_name, q.text AS question`).
+		Take(&data).Error; err != nil {
+		logger.Default().WithField("QuestionID", answer.QuestionID.String()).Error(err.Error())
+		return
+	}
+
+	if data.Email == nil || *data.Email == "" {
+		return
+	}
+
+	notifier := email.NewNotifier("gmail")
+	go func() {
+		err := notifier.Send([]string{*data.Email}, email.KeyQuestionAnswered, email.QuestionAnswered{
+			Username:    data.Username,
+			ProductName: data.ProductName,
+			Question:    data.Question,
+			Answer:      answer.Text,
+		})
+		if err != nil {
+			logger.Default().WithField("AnswerID", answer.ID.String()).Error(err.Error())
+		}
+	}()
+}
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/product_schedule"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// the maximum number of products of each related list in the storefront
+const relatedProductsLimit = 10
+
+// GetProductRelations godoc
+// @Tags ProductRelations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productId  path  string  true  "Product ID"
+// @Param type  query  int  false  "relation type" Enums(0,1,2,3)
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.ProductRelationOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRelation/{productId} [get]
+func GetProductRelations(ctx *app.HttpContext) error {
+	productID, err := uuid.Parse(ctx.GetPathParam("productId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := canAccessProduct(ctx, baseDB, productID); err != nil {
+		return err
+	}
+
+	parameter := parameter.New[appmodels.ProductRelationOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("product_relation r").
+		Joins("INNER JOIN product p ON p.id = r.related_product_id").
+		Where("r.deleted_at IS NULL AND p.deleted_at IS NULL AND r.product_id = ?", productID)
+
+	if relationType, ok := ctx.GetParam("type"); ok {
+		baseDB = baseDB.Where("r.type = ?", relationType)
+	}
+
+	data, err := parameter.SelectColumns(`r.id, r.created_at, r.related_product_id, p."name" AS related_product_name,
+		p.code AS related_product_code, r.type, r.priority, r.score`).
+		SearchColumns("p.name", "p.code").
+		SortDescending("r.created_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// CreateProductRelation godoc
+// @Tags ProductRelations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productId  path  string  true  "Product ID"
+// @Param ProductRelation   body  appmodels.ProductRelationReqModel  true  "ProductRelation model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRelation/{productId}  [post]
+func CreateProductRelation(ctx *app.HttpContext) error {
+	productID, err := uuid.Parse(ctx.GetPathParam("productId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ProductRelationReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := canAccessProduct(ctx, baseDB, productID); err != nil {
+		return err
+	}
+
+	inputModel.ProductID = productID
+
+	if err := inputModel.ValidateCreate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	dbModel := inputModel.ToDBModel()
+	if err := baseDB.Create(dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// DeleteProductRelation godoc
+// @Tags ProductRelations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRelation/delete/{id}  [post]
+func DeleteProductRelation(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.ProductRelation
+
+	if baseDB.First(&dbModel, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := canAccessProduct(ctx, baseDB, dbModel.ProductID); err != nil {
+		return err
+	}
+
+	// the computed relations are replaced by the next run of the job
+	if dbModel.Type == models.ProductRelationTypeBoughtTogether {
+		return errors.NewBadRequestError(consts.UnableToChangeSystemData, nil)
+	}
+
+	if baseDB.Delete(&dbModel).Error != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, nil)
+	}
+
+	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+}
+
+// getRelatedProducts returns the visible related products of the given products grouped by the relation type,
+// the products of excludeIDs are not returned
+func getRelatedProducts(baseDB *gorm.DB, productIDs []uuid.UUID, excludeIDs []uuid.UUID) (*appmodels.RelatedProductsOutPutModel, error) {
+	data := appmodels.RelatedProductsOutPutModel{
+		Related:        []appmodels.RelatedProductOutPutModel{},
+		CrossSell:      []appmodels.RelatedProductOutPutModel{},
+		UpSell:         []appmodels.RelatedProductOutPutModel{},
+		BoughtTogether: []appmodels.RelatedProductOutPutModel{},
+	}
+
+	if len(productIDs) == 0 {
+		return &data, nil
+	}
+
+	qry := baseDB.Table("product_relation r").
+		Joins("INNER JOIN product p ON p.id = r.related_product_id").
+		Joins("CROSS JOIN LATERAL (?) as pi2", baseDB.Table("product_item pi2").
+			Select("id, price").
+			Where("pi2.quantity > 0 AND pi2.product_id = p.id AND pi2.deleted_at IS NULL").
+			Where(product_schedule.VisibleCond("pi2")).
+			Order("CASE WHEN p.default_product_item_id IS NULL THEN pi2.bought_quantity WHEN pi2.id = p.default_product_item_id THEN 0 ELSE 1 END").
+			Limit(1),
+		).
+		Joins("CROSS JOIN LATERAL (?) as pf", baseDB.Table("product_file_map pfm").
+			Select("file_id").
+			Where("pfm.product_id = p.id").
+			Order("pfm.priority ASC").
+			Limit(1),
+		).
+		Joins("INNER JOIN file f ON f.id = pf.file_id").
+		Where("r.deleted_at IS NULL AND p.deleted_at IS NULL AND r.product_id IN ?", productIDs).
+		Where(product_schedule.VisibleProductCond("p"))
+
+	if len(excludeIDs) > 0 {
+		qry = qry.Where("r.related_product_id NOT IN ?", excludeIDs)
+	}
+
+	var rows []appmodels.RelatedProductOutPutModel
+
+	if err := qry.Select(`r.type, p.id AS product_id, p."name", p.rate, pi2.id AS product_item_id, pi2.price,
+			f.file_type, f.unique_file_name AS file_name`).
+		Order("r.type, r.priority, r.score DESC, p.rate DESC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	// a product may be related to several products of the list, it is returned once in each list
+	seen := map[models.ProductRelationType]map[uuid.UUID]bool{}
+
+	for _, row := range rows {
+		if seen[row.Type] == nil {
+			seen[row.Type] = map[uuid.UUID]bool{}
+		}
+
+		if seen[row.Type][row.ProductID] {
+			continue
+		}
+		seen[row.Type][row.ProductID] = true
+
+		row.FileUrl = row.FileType.GetFileUrl(row.FileName)
+
+		var list *[]appmodels.RelatedProductOutPutModel
+
+		switch row.Type {
+		case models.ProductRelationTypeRelated:
+			list = &data.Related
+		case models.ProductRelationTypeCrossSell:
+			list = &data.CrossSell
+		case models.ProductRelationTypeUpSell:
+			list = &data.UpSell
+		case models.ProductRelationTypeBoughtTogether:
+			list = &data.BoughtTogether
+		default:
+			continue
+		}
+
+		if len(*list) < relatedProductsLimit {
+			*list = append(*list, row)
+		}
+	}
+
+	return &data, nil
+}
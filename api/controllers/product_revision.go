@@ -0,0 +1,403 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/product_revision"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetProductRevisions godoc
+// @Tags ProductRevisions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productId  path  string  true  "Product ID"
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param status  query  int  false  "status" Enums(0,1,2)
+// @Success 200 {object} parameter.ListResponse[appmodels.ProductRevisionOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRevision/product/{productId} [get]
+func GetProductRevisions(ctx *app.HttpContext) error {
+	productID, err := uuid.Parse(ctx.GetPathParam("productId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := canAccessProduct(ctx, baseDB, productID); err != nil {
+		return err
+	}
+
+	parameter := parameter.New[appmodels.ProductRevisionOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("product_revision pr").
+		Joins("LEFT JOIN public.user cu ON cu.id = pr.created_by_id").
+		Joins("LEFT JOIN public.user ru ON ru.id = pr.reviewed_by_id").
+		Where("pr.deleted_at IS NULL AND pr.product_id = ?", productID)
+
+	if status, ok := ctx.GetParam("status"); ok {
+		baseDB = baseDB.Where("pr.status = ?", status)
+	}
+
+	response, err := parameter.SelectColumns(`pr.id, pr.product_id, pr.number, pr.status, pr.name, pr.note, pr.restored_from_id,
+		pr.created_at, cu.username AS created_by, ru.username AS reviewed_by, pr.reviewed_at, pr.review_note`).
+		SearchColumns("pr.name", "pr.note").
+		SortDescending("pr.number").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// GetProductRevision godoc
+// @Tags ProductRevisions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param compareWith  query  string  false  "Revision ID to compare with, default is the live product"
+// @Success 200 {object} appmodels.ProductRevisionInfoOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRevision/{id} [get]
+func GetProductRevision(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var revision models.ProductRevision
+
+	if err := baseDB.First(&revision, "id = ?", id).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := canAccessProduct(ctx, baseDB, revision.ProductID); err != nil {
+		return err
+	}
+
+	data := appmodels.ProductRevisionInfoOutPutModel{
+		Revision: revision,
+	}
+
+	var base *product_revision.Content
+
+	if compareWith, ok := ctx.GetParam("compareWith"); ok {
+		compareWithID, err := uuid.Parse(compareWith)
+		if err != nil {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+
+		var other models.ProductRevision
+
+		if err := baseDB.First(&other, "id = ? AND product_id = ?", compareWithID, revision.ProductID).Error; err != nil {
+			return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+		}
+
+		content := product_revision.ContentOf(&other)
+		base = &content
+		data.ComparedWith = other.ID
+	} else {
+		if base, err = product_revision.LiveContent(baseDB, revision.ProductID); err != nil {
+			return errors.NewInternalServerError(consts.InternalServerError, err)
+		}
+	}
+
+	data.Changes = product_revision.Diff(*base, product_revision.ContentOf(&revision))
+
+	return ctx.JSON(data, http.StatusOK)
+}
+
+// CreateProductRevision godoc
+// @Tags ProductRevisions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productId  path  string  true  "Product ID"
+// @Param ProductRevision   body  appmodels.ProductRevisionReqModel  true  "ProductRevision model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRevision/{productId}  [post]
+func CreateProductRevision(ctx *app.HttpContext) error {
+	productID, err := uuid.Parse(ctx.GetPathParam("productId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ProductRevisionReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if err := canAccessProduct(ctx, baseDB, productID); err != nil {
+		return err
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	keys, err := productFeatureKeyNames(baseDB, inputModel.Features)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx := baseDB.Begin()
+
+	revision, err := product_revision.NewDraft(baseTx, productID, inputModel.ToContent(keys), inputModel.Note, nil)
+	if err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickDBResponse(consts.Created, *revision.ID, http.StatusOK)
+}
+
+// EditProductRevision godoc
+// @Tags ProductRevisions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param ProductRevision   body  appmodels.ProductRevisionReqModel  true  "ProductRevision model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRevision/edit/{id}  [post]
+func EditProductRevision(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ProductRevisionReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var revision models.ProductRevision
+
+	if err := baseDB.First(&revision, "id = ?", id).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := canAccessProduct(ctx, baseDB, revision.ProductID); err != nil {
+		return err
+	}
+
+	if revision.Status != models.ProductRevisionStatusDraft {
+		return errors.NewBadRequestError(consts.RevisionIsNotDraft, nil)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	keys, err := productFeatureKeyNames(baseDB, inputModel.Features)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	content := inputModel.ToContent(keys)
+
+	revision.Name = content.Name
+	revision.ShortDescription = content.ShortDescription
+	revision.Description = content.Description
+	revision.TopFeatures = content.TopFeatures
+	revision.Features = content.Features
+	revision.Note = inputModel.Note
+
+	// the status condition prevents editing a revision which is reviewed meanwhile
+	result := baseDB.Model(&revision).
+		Where("status = ?", models.ProductRevisionStatusDraft).
+		Select("name", "short_description", "description", "top_features", "features", "note").
+		Updates(&revision)
+
+	if result.Error != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.NewBadRequestError(consts.RevisionIsNotDraft, nil)
+	}
+
+	return ctx.QuickResponse(consts.Updated, http.StatusOK)
+}
+
+// ApproveProductRevision godoc
+// @Tags ProductRevisions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param ProductRevisionReview   body  appmodels.ProductRevisionReviewReqModel  false  "Review model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRevision/approve/{id}  [post]
+func ApproveProductRevision(ctx *app.HttpContext) error {
+	return reviewProductRevision(ctx, product_revision.Publish, consts.RevisionApproved)
+}
+
+// RejectProductRevision godoc
+// @Tags ProductRevisions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param ProductRevisionReview   body  appmodels.ProductRevisionReviewReqModel  false  "Review model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRevision/reject/{id}  [post]
+func RejectProductRevision(ctx *app.HttpContext) error {
+	return reviewProductRevision(ctx, product_revision.Reject, consts.RevisionRejected)
+}
+
+// RestoreProductRevision godoc
+// @Tags ProductRevisions
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/productRevision/restore/{id}  [post]
+func RestoreProductRevision(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var revision models.ProductRevision
+
+	if err := baseDB.First(&revision, "id = ?", id).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := canAccessProduct(ctx, baseDB, revision.ProductID); err != nil {
+		return err
+	}
+
+	baseTx := baseDB.Begin()
+
+	// the restored content is a new draft and it goes through the review again
+	draft, err := product_revision.NewDraft(baseTx, revision.ProductID, product_revision.ContentOf(&revision), nil, revision.ID)
+	if err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickDBResponse(consts.Created, *draft.ID, http.StatusOK)
+}
+
+func reviewProductRevision(
+	ctx *app.HttpContext,
+	review func(tx *gorm.DB, revision *models.ProductRevision, reviewerID uuid.UUID, reviewNote *string) error,
+	message string,
+) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.ProductRevisionReviewReqModel
+
+	// the review note is optional
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.BlindBind(&inputModel); err != nil {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+	baseTx := baseDB.Begin()
+
+	var revision models.ProductRevision
+
+	if err := baseTx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&revision, "id = ?", id).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if revision.Status != models.ProductRevisionStatusDraft {
+		baseTx.Rollback()
+		return errors.NewBadRequestError(consts.RevisionIsNotDraft, nil)
+	}
+
+	if err := review(baseTx, &revision, *user.ID, inputModel.Note); err != nil {
+		baseTx.Rollback()
+
+		if err == product_revision.ErrOutdated {
+			return errors.NewBadRequestError(consts.RevisionIsOutdated, nil)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickResponse(message, http.StatusOK)
+}
+
+func productFeatureKeyNames(baseDB *gorm.DB, features []appmodels.ProductRevisionFeatureReqModel) (map[uuid.UUID]string, error) {
+	keys := map[uuid.UUID]string{}
+
+	if len(features) == 0 {
+		return keys, nil
+	}
+
+	ids := make([]uuid.UUID, len(features))
+	for i, feature := range features {
+		ids[i] = feature.ProductFeatureKeyID
+	}
+
+	var rows []models.ProductFeatureKey
+
+	if err := baseDB.Select("id", "name").Find(&rows, "id IN ?", ids).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		keys[*row.ID] = row.Name
+	}
+
+	return keys, nil
+}
@@ -2,12 +2,16 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/esmailemami/eshop/app"
 	"github.com/esmailemami/eshop/app/consts"
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/otp"
+	"github.com/esmailemami/eshop/app/services/roles"
+	userservice "github.com/esmailemami/eshop/app/services/user"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
@@ -35,15 +39,45 @@ func GetProfile(ctx *app.HttpContext) error {
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Mobile:    user.Mobile,
-	}
+		Email:     user.Email,
+
+		EmailVerified:  user.EmailVerified,
+		MobileVerified: user.MobileVerified,
+		PendingEmail:   user.PendingEmail,
+		PendingMobile:  user.PendingMobile,
 
-	if user.Role != nil {
-		data.RoleName = user.Role.Name
+		RoleNames: user.Role.Names(),
 	}
 
 	return ctx.JSON(data, http.StatusOK)
 }
 
+// GetProfilePermissions godoc
+// @Summary The effective permissions of the logged in user
+// @Description Returns the roles of the user, the roles they inherit and the merged permissions with their scopes.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} models.EffectiveRole
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/profile/permissions [get]
+func GetProfilePermissions(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	if user.Role == nil {
+		if err := roles.Load(db.MustGormDBConn(ctx), user); err != nil {
+			return errors.NewInternalServerError(consts.InternalServerError, err)
+		}
+	}
+
+	return ctx.JSON(user.Role, http.StatusOK)
+}
+
 // GetUserOrders godoc
 // @Tags Profile
 // @Accept json
@@ -351,6 +385,8 @@ func EditProfile(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
 
+	inputModel.Mobile, inputModel.Email = normalizeContacts(inputModel.Mobile, inputModel.Email)
+
 	baseDB := db.MustGormDBConn(ctx)
 
 	err = inputModel.ValidateUpdate(*user.ID)
@@ -358,10 +394,153 @@ func EditProfile(ctx *app.HttpContext) error {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
+	// the changed contacts are kept as pending until they are verified
+	sent := false
+	for _, change := range []struct {
+		contact userservice.Contact
+		value   *string
+	}{
+		{userservice.Mobile, inputModel.Mobile},
+		{userservice.Email, inputModel.Email},
+	} {
+		if change.value == nil {
+			continue
+		}
+
+		code, err := change.contact.RequestChange(baseDB, user, *change.value)
+		if err != nil {
+			return contactError(err)
+		}
+		sent = sent || code != nil
+	}
+
 	inputModel.MergeWithDBData(user)
-	if baseDB.Save(user).Error != nil {
+	if err := baseDB.Omit("Role").Save(user).Error; err != nil {
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	if sent {
+		return ctx.QuickResponse(consts.ContactVerificationSent, http.StatusOK)
+	}
+
 	return ctx.QuickResponse(consts.Updated, http.StatusOK)
 }
+
+// Send Email Verification Code godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} appmodels.ContactCodeOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/email/sendCode  [post]
+func SendProfileEmailCode(ctx *app.HttpContext) error {
+	return sendContactCode(ctx, userservice.Email)
+}
+
+// Verify Email godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Code   body  appmodels.VerifyContactReqModel  true  "Verification code model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/email/verify  [post]
+func VerifyProfileEmail(ctx *app.HttpContext) error {
+	return verifyContact(ctx, userservice.Email)
+}
+
+// Send Mobile Verification Code godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} appmodels.ContactCodeOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/mobile/sendCode  [post]
+func SendProfileMobileCode(ctx *app.HttpContext) error {
+	return sendContactCode(ctx, userservice.Mobile)
+}
+
+// Verify Mobile godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Code   body  appmodels.VerifyContactReqModel  true  "Verification code model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/mobile/verify  [post]
+func VerifyProfileMobile(ctx *app.HttpContext) error {
+	return verifyContact(ctx, userservice.Mobile)
+}
+
+func sendContactCode(ctx *app.HttpContext, contact userservice.Contact) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	code, err := contact.SendCode(db.MustGormDBConn(ctx), user)
+	if err != nil {
+		return contactError(err)
+	}
+
+	return ctx.JSON(appmodels.ContactCodeOutPutModel{ExpiresAt: code.ExpireAt}, http.StatusOK)
+}
+
+func verifyContact(ctx *app.HttpContext, contact userservice.Contact) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	var inputModel appmodels.VerifyContactReqModel
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	if err := contact.Verify(db.MustGormDBConn(ctx), user, inputModel.Code); err != nil {
+		return contactError(err)
+	}
+
+	return ctx.QuickResponse(consts.ContactVerified, http.StatusOK)
+}
+
+func contactError(err error) error {
+	if err.Error() == consts.InternalServerError {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+	return errors.NewBadRequestError(err.Error(), err)
+}
+
+// normalizeContacts transforms the mobile and email to their stored form, the blank ones are treated as removed
+func normalizeContacts(mobile, email *string) (*string, *string) {
+	if mobile != nil {
+		if value := strings.TrimSpace(*mobile); value != "" {
+			value, _ = otp.NormalizeMobile(value)
+			mobile = &value
+		} else {
+			mobile = nil
+		}
+	}
+
+	if email != nil {
+		if value := strings.ToLower(strings.TrimSpace(*email)); value != "" {
+			email = &value
+		} else {
+			email = nil
+		}
+	}
+
+	return mobile, email
+}
@@ -10,7 +10,11 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	"github.com/esmailemami/eshop/app/helpers"
 	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/restock"
+	"github.com/esmailemami/eshop/app/services/settings"
 	dbpkg "github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
 )
 
 // GetRevenueByCategory godoc
@@ -29,12 +33,22 @@ func ReportRevenueByCategory(ctx *app.HttpContext) error {
 		totalPrice float64 = 0
 	)
 
-	subquery := db.Table("order_item as oi").
-		Select("product.category_id as category_id, sum(oi.price) as price").
-		Joins("INNER JOIN \"order\" o ON o.id = oi.order_id").
-		Joins("INNER JOIN product_item pi2 ON pi2.id = oi.product_item_id").
-		Joins("INNER JOIN product ON product.id = pi2.product_id").
-		Where("o.deleted_at IS NULL AND oi.deleted_at IS NULL AND o.status > 0").
+	// the revenue of the bundles is divided between the categories of their components
+	subquery := db.Table("(?) AS r", db.Raw("(?) UNION ALL (?)",
+		db.Table("order_item as oi").
+			Select("pi2.product_id, oi.price").
+			Joins("INNER JOIN \"order\" o ON o.id = oi.order_id").
+			Joins("INNER JOIN product_item pi2 ON pi2.id = oi.product_item_id").
+			Where("o.deleted_at IS NULL AND oi.deleted_at IS NULL AND o.status > 0"),
+		db.Table("order_item_component as oic").
+			Select("pi2.product_id, oic.price * oic.quantity / oi.quantity AS price").
+			Joins("INNER JOIN order_item oi ON oi.id = oic.order_item_id").
+			Joins("INNER JOIN \"order\" o ON o.id = oi.order_id").
+			Joins("INNER JOIN product_item pi2 ON pi2.id = oic.product_item_id").
+			Where("o.deleted_at IS NULL AND oi.deleted_at IS NULL AND oic.deleted_at IS NULL AND o.status > 0"),
+	)).
+		Select("product.category_id as category_id, sum(r.price) as price").
+		Joins("INNER JOIN product ON product.id = r.product_id").
 		Group("product.category_id")
 
 	err := db.Table("category").
@@ -120,3 +134,135 @@ func ReportSellsChart(ctx *app.HttpContext) error {
 
 	return ctx.JSON(data, http.StatusOK)
 }
+
+// ReportLowStock godoc
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param threshold  query  int  false  "the items which their quantity is not more than the threshold, default is 5"
+// @Param days  query  int  false  "days of the sold quantity, default is 30"
+// @Success 200 {object} parameter.ListResponse[appmodels.LowStockOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/report/lowStock [get]
+func ReportLowStock(ctx *app.HttpContext) error {
+	threshold, days := 5, 30
+
+	if v, ok := ctx.GetParam("threshold"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+		threshold = n
+	}
+
+	if v, ok := ctx.GetParam("days"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+		days = n
+	}
+
+	baseDB := dbpkg.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.LowStockOutPutModel](ctx, baseDB)
+
+	// the sold quantity is the net of the sales and the returns of the ledger
+	baseDB = baseDB.Table("product_item pi2").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("INNER JOIN color c ON c.id = pi2.color_id").
+		Joins(`LEFT JOIN LATERAL (SELECT COALESCE(SUM(ws.quantity), 0) AS quantity FROM warehouse_stock ws
+			WHERE ws.product_item_id = pi2.id AND ws.deleted_at IS NULL) ws ON TRUE`).
+		Joins(`LEFT JOIN LATERAL (SELECT COALESCE(-SUM(sm.quantity), 0) AS quantity FROM stock_movement sm
+			WHERE sm.product_item_id = pi2.id AND sm.warehouse_id IS NULL AND sm.reason IN (?, ?) AND sm.created_at >= ?) s ON TRUE`,
+			models.StockMovementReasonSale, models.StockMovementReasonReturn, time.Now().AddDate(0, 0, -days)).
+		Where("pi2.deleted_at IS NULL AND p.deleted_at IS NULL AND pi2.quantity <= ?", threshold)
+
+	data, err := parameter.SelectColumns(`pi2.id AS product_item_id, p.id AS product_id, p."name" AS product_name,
+		c."name" AS color_name, pi2.sku, pi2.quantity, ws.quantity AS warehouse_stock, s.quantity AS sold_quantity`).
+		SearchColumns(`p."name"`, "pi2.sku", "pi2.barcode").
+		SortAscending("pi2.quantity").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// ReportAtRiskItems godoc
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param days  query  int  false  "days of the sales velocity, default is 30"
+// @Param coverDays  query  int  false  "the items which their stock does not cover the days are at risk too, default is 7"
+// @Success 200 {object} parameter.ListResponse[appmodels.AtRiskItemOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/report/atRisk [get]
+func ReportAtRiskItems(ctx *app.HttpContext) error {
+	days, coverDays := 30, 7
+
+	if v, ok := ctx.GetParam("days"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+		days = n
+	}
+
+	if v, ok := ctx.GetParam("coverDays"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+		coverDays = n
+	}
+
+	baseDB := dbpkg.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.AtRiskItemOutPutModel](ctx, baseDB)
+	since := time.Now().AddDate(0, 0, -days)
+
+	// the items of the bundles are sold through the components of the order items
+	threshold := restock.DefaultThreshold
+	if v := settings.GetSystemSettings().ReorderThreshold; v != nil {
+		threshold = *v
+	}
+
+	baseDB = restock.Scope(baseDB, threshold).
+		Joins(`CROSS JOIN LATERAL (SELECT COALESCE(SUM(x.quantity), 0) AS quantity, COALESCE(SUM(x.quantity), 0) / ?::numeric AS velocity
+			FROM (
+				SELECT oi.quantity FROM order_item oi
+				INNER JOIN "order" o ON o.id = oi.order_id
+				WHERE oi.product_item_id = pi2.id AND oi.deleted_at IS NULL AND o.deleted_at IS NULL AND o.status <> ? AND o.paid_at >= ?
+				UNION ALL
+				SELECT oic.quantity FROM order_item_component oic
+				INNER JOIN order_item oi ON oi.id = oic.order_item_id
+				INNER JOIN "order" o ON o.id = oi.order_id
+				WHERE oic.product_item_id = pi2.id AND oic.deleted_at IS NULL AND oi.deleted_at IS NULL AND o.deleted_at IS NULL
+					AND o.status <> ? AND o.paid_at >= ?
+			) x) s`, days, models.OrderStatusOpen, since, models.OrderStatusOpen, since).
+		Where("(pi2.quantity < t.threshold OR (s.velocity > 0 AND pi2.quantity / s.velocity <= ?))", coverDays)
+
+	data, err := parameter.SelectColumns(`pi2.id AS product_item_id, p.id AS product_id, p."name" AS product_name,
+		c."name" AS color_name, pi2.sku, pi2.quantity, t.threshold, s.quantity AS sold_quantity, pi2.low_stock_alerted_at,
+		ROUND(s.velocity, 2) AS velocity, CASE WHEN s.velocity > 0 THEN ROUND(pi2.quantity / s.velocity, 1) END AS days_of_cover`).
+		SearchColumns(`p."name"`, "pi2.sku", "pi2.barcode").
+		SortAscending("days_of_cover", "pi2.quantity").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	errs "errors"
 	"net/http"
 
 	"github.com/esmailemami/eshop/app"
@@ -8,9 +9,12 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/roles"
 	"github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // GetRoles godoc
@@ -65,6 +69,10 @@ func GetRole(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
+	if data.ParentIDs, err = roles.Parents(db.MustGormDBConn(ctx), id); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
 	return ctx.JSON(data, http.StatusOK)
 }
 
@@ -92,10 +100,21 @@ func CreateRole(ctx *app.HttpContext) error {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
-	if err := baseDB.Create(inputModel.ToDBModel()).Error; err != nil {
+	dbModel := inputModel.ToDBModel()
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Create(dbModel).Error; err != nil {
+		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	if err := setRoleParents(baseTx, *dbModel.ID, inputModel.ParentIDs); err != nil {
+		baseTx.Rollback()
+		return err
+	}
+
+	baseTx.Commit()
+
 	return ctx.QuickResponse(consts.Created, http.StatusOK)
 }
 
@@ -138,10 +157,23 @@ func EditRole(ctx *app.HttpContext) error {
 	}
 
 	inputModel.MergeWithDBData(&dbModel)
-	if baseDB.Save(&dbModel).Error != nil {
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Save(&dbModel).Error; err != nil {
+		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	if err := setRoleParents(baseTx, id, inputModel.ParentIDs); err != nil {
+		baseTx.Rollback()
+		return err
+	}
+
+	baseTx.Commit()
+
+	// the users of the roles which inherit the role are changed too
+	roles.InvalidateAll()
+
 	return ctx.QuickResponse(consts.Updated, http.StatusOK)
 }
 
@@ -169,14 +201,42 @@ func DeleteRole(ctx *app.HttpContext) error {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
 
-	if baseDB.Delete(&dbModel).Error != nil {
+	baseTx := db.MustGormDBConn(ctx).Begin()
+
+	if err := roles.RemoveRole(baseTx, id); err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if baseTx.Delete(&dbModel).Error != nil {
+		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, nil)
 	}
 
+	baseTx.Commit()
+
+	roles.InvalidateAll()
+
 	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
 }
 
-// GetRole godoc
+// setRoleParents replaces the parents of the role, the cycle which is made by a concurrent edit after the validation
+// is reported as a validation error
+func setRoleParents(tx *gorm.DB, id uuid.UUID, parentIDs []uuid.UUID) error {
+	err := roles.SetParents(tx, id, parentIDs)
+	if errs.Is(err, roles.ErrInheritanceCycle) {
+		return errors.NewValidationError(consts.ValidationError, validation.Errors{"parentIds": err})
+	}
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return nil
+}
+
+// GetPermissions godoc
+// @Summary The permissions which can be granted to the roles
+// @Description Returns the registered permissions by their groups with their descriptions and the attributes which they can be scoped by.
 // @Tags Roles
 // @Accept json
 // @Produce json
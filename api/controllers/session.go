@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/authentication"
+	"github.com/esmailemami/eshop/db"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetUserSessions godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} []appmodels.SessionOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/sessions [get]
+func GetUserSessions(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	sessions, err := authentication.Sessions(baseDB, *user.ID)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if familyID := currentFamily(ctx, baseDB); familyID != nil {
+		for i := range sessions {
+			sessions[i].Current = sessions[i].ID == *familyID
+		}
+	}
+
+	return ctx.JSON(sessions, http.StatusOK)
+}
+
+// RevokeUserSession godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Session ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/sessions/revoke/{id} [post]
+func RevokeUserSession(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	if err := authentication.RevokeUserSession(db.MustGormDBConn(ctx), *user.ID, id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+		}
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+// RevokeOtherUserSessions godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/sessions/revokeOthers [post]
+func RevokeOtherUserSessions(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	authTokenID := currentAuthTokenID(ctx)
+	if authTokenID == nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, nil)
+	}
+
+	if err := authentication.RevokeUserSessions(baseDB, *user.ID, currentFamily(ctx, baseDB), authTokenID); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+// GetUserLoginHistory godoc
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Success 200 {object} parameter.ListResponse[appmodels.LoginHistoryOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /user/profile/loginHistory [get]
+func GetUserLoginHistory(ctx *app.HttpContext) error {
+	user, err := ctx.GetUser()
+	if err != nil {
+		return errors.NewUnauthorizedError(consts.UnauthorizedError, err)
+	}
+
+	return loginHistory(ctx, *user.ID)
+}
+
+// GetAdminUserSessions godoc
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "User ID"
+// @Success 200 {object} []appmodels.SessionOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/user/sessions/{id} [get]
+func GetAdminUserSessions(ctx *app.HttpContext) error {
+	userID, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	sessions, err := authentication.Sessions(db.MustGormDBConn(ctx), userID)
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(sessions, http.StatusOK)
+}
+
+// GetAdminUserLoginHistory godoc
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "User ID"
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Success 200 {object} parameter.ListResponse[appmodels.LoginHistoryOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/user/loginHistory/{id} [get]
+func GetAdminUserLoginHistory(ctx *app.HttpContext) error {
+	userID, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	return loginHistory(ctx, userID)
+}
+
+// ForceLogoutUser godoc
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "User ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/user/logout/{id} [post]
+func ForceLogoutUser(ctx *app.HttpContext) error {
+	userID, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := authentication.RevokeUserSessions(db.MustGormDBConn(ctx), userID, nil, nil); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+// UnlockUser godoc
+// @Summary Unlock the login of the user
+// @Description Removes the lockouts and the failed attempts of the username and the contacts of the user.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "User ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/user/unlock/{id} [post]
+func UnlockUser(ctx *app.HttpContext) error {
+	userID, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := authentication.UnlockUser(db.MustGormDBConn(ctx), userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NewRecordNotFoundError(consts.RecordNotFound, err)
+		}
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+func loginHistory(ctx *app.HttpContext, userID uuid.UUID) error {
+	baseDB := db.MustGormDBConn(ctx).Table("login_history lh").
+		Where("lh.user_id = ? AND lh.deleted_at IS NULL", userID)
+
+	response, err := parameter.New[appmodels.LoginHistoryOutPutModel](ctx, baseDB).
+		SelectColumns("lh.id, lh.created_at, lh.event, lh.ip, lh.user_agent").
+		SortDescending("lh.created_at").
+		EachItemProcess(func(_ *gorm.DB, item *appmodels.LoginHistoryOutPutModel) error {
+			item.Device = appmodels.NewDeviceOutPutModel(item.UserAgent)
+			return nil
+		}).
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*response, http.StatusOK)
+}
+
+// currentAuthTokenID returns the id of the access token of the request
+func currentAuthTokenID(ctx *app.HttpContext) *uuid.UUID {
+	value, ok := ctx.Get(consts.AuthTokenID)
+	if !ok {
+		return nil
+	}
+
+	id, err := uuid.Parse(value.(string))
+	if err != nil {
+		return nil
+	}
+
+	return &id
+}
+
+// currentFamily returns the session of the request, it is nil for the tokens which are issued before the refresh
+// tokens
+func currentFamily(ctx *app.HttpContext, baseDB *gorm.DB) *uuid.UUID {
+	authTokenID := currentAuthTokenID(ctx)
+	if authTokenID == nil {
+		return nil
+	}
+
+	familyID, err := authentication.FamilyOf(baseDB, *authTokenID)
+	if err != nil {
+		return nil
+	}
+
+	return familyID
+}
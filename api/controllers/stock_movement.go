@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/inventory"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+// GetStockMovements godoc
+// @Tags StockMovements
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param warehouseId  query  string  false  "warehouse ID"
+// @Param productItemId  query  string  false  "product item ID"
+// @Param sourceId  query  string  false  "source document ID, such as the order or the stock transfer"
+// @Param reason  query  int  false  "reason" Enums(0,1,2,3,4,5,6,7)
+// @Param ledger  query  bool  false  "only the ledger of the sellable quantities, without the locations"
+// @Success 200 {object} parameter.ListResponse[appmodels.StockMovementOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockMovement [get]
+func GetStockMovements(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.StockMovementOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("stock_movement sm").
+		Joins("LEFT JOIN warehouse w ON w.id = sm.warehouse_id").
+		Joins("INNER JOIN product_item pi2 ON pi2.id = sm.product_item_id").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("LEFT JOIN public.user u ON u.id = sm.created_by_id")
+
+	if warehouseID, ok := ctx.GetParam("warehouseId"); ok {
+		baseDB = baseDB.Where("sm.warehouse_id = ?", warehouseID)
+	}
+
+	if productItemID, ok := ctx.GetParam("productItemId"); ok {
+		baseDB = baseDB.Where("sm.product_item_id = ?", productItemID)
+	}
+
+	if sourceID, ok := ctx.GetParam("sourceId"); ok {
+		baseDB = baseDB.Where("sm.source_id = ?", sourceID)
+	}
+
+	if reason, ok := ctx.GetParam("reason"); ok {
+		baseDB = baseDB.Where("sm.reason = ?", reason)
+	}
+
+	if v, ok := ctx.GetParam("ledger"); ok {
+		if ledger, err := strconv.ParseBool(v); err == nil && ledger {
+			baseDB = baseDB.Where("sm.warehouse_id IS NULL")
+		}
+	}
+
+	data, err := parameter.SelectColumns(`sm.id, sm.created_at, u.username AS created_by, sm.warehouse_id, w."name" AS warehouse_name,
+		sm.product_item_id, p."name" AS product_name, pi2.sku, sm.quantity, sm.balance, sm.reason, sm.source_id, sm.note`).
+		SearchColumns(`p."name"`, "pi2.sku", "sm.note").
+		SortDescending("sm.created_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetStockReconciliation godoc
+// @Tags StockMovements
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param mismatched  query  bool  false  "only the items which their ledger differs from their quantity"
+// @Success 200 {object} parameter.ListResponse[appmodels.StockReconciliationOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockMovement/reconcile [get]
+func GetStockReconciliation(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.StockReconciliationOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("product_item pi2").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("INNER JOIN color c ON c.id = pi2.color_id").
+		Joins(`LEFT JOIN LATERAL (SELECT COALESCE(SUM(sm.quantity), 0) AS ledger, MAX(sm.created_at) AS last_moved_at
+			FROM stock_movement sm WHERE sm.product_item_id = pi2.id AND sm.warehouse_id IS NULL) l ON TRUE`).
+		Where("pi2.deleted_at IS NULL")
+
+	if v, ok := ctx.GetParam("mismatched"); ok {
+		mismatched, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.NewBadRequestError(consts.BadRequest, err)
+		}
+
+		if mismatched {
+			baseDB = baseDB.Where("pi2.quantity <> l.ledger")
+		}
+	}
+
+	data, err := parameter.SelectColumns(`pi2.id AS product_item_id, p."name" AS product_name, c."name" AS color_name, pi2.sku,
+		pi2.quantity, l.ledger, pi2.quantity - l.ledger AS difference, l.last_moved_at`).
+		SearchColumns(`p."name"`, "pi2.sku", "pi2.barcode").
+		SortDescending("ABS(pi2.quantity - l.ledger)").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// ReconcileStock godoc
+// @Tags StockMovements
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productItemId  path  string  true  "Product item ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockMovement/reconcile/{productItemId}  [post]
+func ReconcileStock(ctx *app.HttpContext) error {
+	productItemID, err := uuid.Parse(ctx.GetPathParam("productItemId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if !db.Exists(baseDB, &models.ProductItem{}, "id = ?", productItemID) {
+		return errors.NewRecordNotFoundError(consts.ModelProductItemNotFound, nil)
+	}
+
+	baseTx := baseDB.Begin()
+
+	// the quantity of the item is the truth, the ledger is corrected by a new movement
+	if _, err := inventory.Reconcile(baseTx, productItemID); err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Commit().Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
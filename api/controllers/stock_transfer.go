@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/inventory"
+	"github.com/esmailemami/eshop/app/services/random_code"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const stockTransferColumns = `st.id, st.created_at, u.username AS created_by, st.code, st.from_warehouse_id,
+	fw."name" AS from_warehouse_name, st.to_warehouse_id, tw."name" AS to_warehouse_name, st.status, st.note, st.completed_at`
+
+// GetStockTransfers godoc
+// @Tags StockTransfers
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Param status  query  int  false  "status" Enums(0,1,2)
+// @Param warehouseId  query  string  false  "source or destination warehouse ID"
+// @Success 200 {object} parameter.ListResponse[appmodels.StockTransferOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockTransfer [get]
+func GetStockTransfers(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.StockTransferOutPutModel](ctx, baseDB)
+
+	baseDB = stockTransferQuery(baseDB)
+
+	if status, ok := ctx.GetParam("status"); ok {
+		baseDB = baseDB.Where("st.status = ?", status)
+	}
+
+	if warehouseID, ok := ctx.GetParam("warehouseId"); ok {
+		baseDB = baseDB.Where("(st.from_warehouse_id = ? OR st.to_warehouse_id = ?)", warehouseID, warehouseID)
+	}
+
+	data, err := parameter.SelectColumns(stockTransferColumns).
+		SearchColumns("st.code", "st.note").
+		SortDescending("st.created_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetStockTransfer godoc
+// @Tags StockTransfers
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} appmodels.StockTransferOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockTransfer/{id} [get]
+func GetStockTransfer(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var data appmodels.StockTransferOutPutModel
+
+	if err := stockTransferQuery(baseDB).
+		Where("st.id = ?", id).
+		Select(stockTransferColumns).
+		Take(&data).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	data.Items = []appmodels.StockTransferItemOutPutModel{}
+
+	if err := baseDB.Table("stock_transfer_item sti").
+		Joins("INNER JOIN product_item pi2 ON pi2.id = sti.product_item_id").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("INNER JOIN color c ON c.id = pi2.color_id").
+		Where("sti.stock_transfer_id = ? AND sti.deleted_at IS NULL", id).
+		Select(`sti.product_item_id, p."name" AS product_name, c."name" AS color_name, pi2.sku, sti.quantity`).
+		Order("sti.created_at").
+		Find(&data.Items).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(data, http.StatusOK)
+}
+
+// CreateStockTransfer godoc
+// @Tags StockTransfers
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param StockTransfer   body  appmodels.StockTransferReqModel  true  "Stock transfer model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockTransfer  [post]
+func CreateStockTransfer(ctx *app.HttpContext) error {
+	var inputModel appmodels.StockTransferReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var code string
+
+	// check the code is unique
+	for {
+		code = random_code.GenerateRandomDigit(9)
+
+		if !db.Exists(baseDB, &models.StockTransfer{}, "code = ?", code) {
+			break
+		}
+	}
+
+	dbModel := inputModel.ToDBModel(code)
+
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Create(dbModel).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Create(&dbModel.Items).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	baseTx.Commit()
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// CompleteStockTransfer godoc
+// @Tags StockTransfers
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockTransfer/complete/{id}  [post]
+func CompleteStockTransfer(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseTx := db.MustGormDBConn(ctx).Begin()
+
+	var transfer models.StockTransfer
+
+	// the transfer is locked so it is not completed twice
+	if err := baseTx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, "id = ?", id).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewRecordNotFoundError(consts.ModelStockTransferNotFound, nil)
+	}
+
+	if transfer.Status != models.StockTransferStatusDraft {
+		baseTx.Rollback()
+		return errors.NewBadRequestError(consts.StockTransferIsNotDraft, nil)
+	}
+
+	if err := baseTx.Where("stock_transfer_id = ?", id).Order("product_item_id").Find(&transfer.Items).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := inventory.Transfer(baseTx, &transfer); err != nil {
+		baseTx.Rollback()
+
+		if err == inventory.ErrInsufficientStock {
+			return errors.NewBadRequestError(consts.InsufficientWarehouseStock, nil)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Model(&models.StockTransfer{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"status":       models.StockTransferStatusCompleted,
+			"completed_at": time.Now(),
+		}).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Commit().Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+// CancelStockTransfer godoc
+// @Tags StockTransfers
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/stockTransfer/cancel/{id}  [post]
+func CancelStockTransfer(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	if !db.Exists(baseDB, &models.StockTransfer{}, "id = ?", id) {
+		return errors.NewRecordNotFoundError(consts.ModelStockTransferNotFound, nil)
+	}
+
+	result := baseDB.Model(&models.StockTransfer{}).
+		Where("id = ? AND status = ?", id, models.StockTransferStatusDraft).
+		UpdateColumn("status", models.StockTransferStatusCanceled)
+
+	if result.Error != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.NewBadRequestError(consts.StockTransferIsNotDraft, nil)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+func stockTransferQuery(baseDB *gorm.DB) *gorm.DB {
+	return baseDB.Table("stock_transfer st").
+		Joins("INNER JOIN warehouse fw ON fw.id = st.from_warehouse_id").
+		Joins("INNER JOIN warehouse tw ON tw.id = st.to_warehouse_id").
+		Joins("LEFT JOIN public.user u ON u.id = st.created_by_id").
+		Where("st.deleted_at IS NULL")
+}
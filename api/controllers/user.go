@@ -8,12 +8,18 @@ import (
 	"github.com/esmailemami/eshop/app/errors"
 	appmodels "github.com/esmailemami/eshop/app/models"
 	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/roles"
 	"github.com/esmailemami/eshop/db"
 	dbmodels "github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// userRolesColumns selects the ids and the names of the roles which are assigned to the user u
+const userRolesColumns = `
+	COALESCE((SELECT json_agg(r.id ORDER BY r.name) FROM user_role ur JOIN "role" r ON r.id = ur.role_id AND r.deleted_at IS NULL WHERE ur.user_id = u.id), '[]') AS role_ids,
+	COALESCE((SELECT json_agg(r.name ORDER BY r.name) FROM user_role ur JOIN "role" r ON r.id = ur.role_id AND r.deleted_at IS NULL WHERE ur.user_id = u.id), '[]') AS role_names`
+
 // GetUsers godoc
 // @Tags Users
 // @Accept json
@@ -28,13 +34,12 @@ import (
 // @Router /admin/user [get]
 func GetUsers(ctx *app.HttpContext) error {
 	baseDB := db.MustGormDBConn(ctx).Table(`"user" u`).
-		Joins(`LEFT JOIN "role" r ON r.id = u.role_id`).
 		Where("u.deleted_at IS NULL")
 
 	parameter := parameter.New[appmodels.UserOutPutModel](ctx, baseDB)
 
 	data, err := parameter.SearchColumns("first_name", "last_name", "mobile", "email", "username").
-		SelectColumns("u.id, u.created_at, u.updated_at, u.username, u.first_name, u.last_name, u.mobile, u.role_id, r.name as role_name, u.email, u.is_system, u.enabled").
+		SelectColumns("u.id, u.created_at, u.updated_at, u.username, u.first_name, u.last_name, u.mobile, u.email, u.is_system, u.enabled, u.email_verified, u.mobile_verified, "+userRolesColumns).
 		SortDescending("u.created_at", "u.updated_at").
 		Execute(baseDB)
 
@@ -62,12 +67,11 @@ func GetUser(ctx *app.HttpContext) error {
 		return errors.NewBadRequestError(consts.BadRequest, err)
 	}
 	baseDB := db.MustGormDBConn(ctx).Table(`"user" u`).
-		Joins(`LEFT JOIN "role" r ON r.id = u.role_id`).
 		Where("u.deleted_at IS NULL")
 
 	var data appmodels.UserOutPutModel
 
-	if err := baseDB.Select("u.id, u.created_at, u.updated_at, u.username, u.first_name, u.last_name, u.mobile, u.role_id, r.name as role_name, u.email, u.is_system, u.enabled").
+	if err := baseDB.Select("u.id, u.created_at, u.updated_at, u.username, u.first_name, u.last_name, u.mobile, u.email, u.is_system, u.enabled, u.email_verified, u.mobile_verified, "+userRolesColumns).
 		Limit(1).Find(&data, "u.id", id).Error; err != nil {
 		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
 	}
@@ -99,10 +103,21 @@ func CreateUser(ctx *app.HttpContext) error {
 		return errors.NewValidationError(consts.ValidationError, err)
 	}
 
-	if err := baseDB.Create(inputModel.ToDBModel()).Error; err != nil {
+	dbModel := inputModel.ToDBModel()
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Create(dbModel).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := roles.SetUserRoles(baseTx, *dbModel.ID, inputModel.RoleIDs); err != nil {
+		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	baseTx.Commit()
+
 	return ctx.QuickResponse(consts.Created, http.StatusOK)
 }
 
@@ -145,10 +160,22 @@ func EditUser(ctx *app.HttpContext) error {
 	}
 
 	inputModel.MergeWithDBData(&dbModel)
-	if baseDB.Save(&dbModel).Error != nil {
+	baseTx := baseDB.Begin()
+
+	if err := baseTx.Save(&dbModel).Error; err != nil {
+		baseTx.Rollback()
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := roles.SetUserRoles(baseTx, id, inputModel.RoleIDs); err != nil {
+		baseTx.Rollback()
 		return errors.NewInternalServerError(consts.InternalServerError, err)
 	}
 
+	baseTx.Commit()
+
+	roles.Invalidate(id)
+
 	return ctx.QuickResponse(consts.Updated, http.StatusOK)
 }
 
@@ -180,9 +207,43 @@ func DeleteUser(ctx *app.HttpContext) error {
 		return errors.NewInternalServerError(consts.InternalServerError, nil)
 	}
 
+	roles.Invalidate(id)
+
 	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
 }
 
+// GetUserPermissions godoc
+// @Summary The effective permissions of the user
+// @Description Returns the roles of the user, the roles they inherit and the merged permissions with their scopes.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} dbmodels.EffectiveRole
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/user/permissions/{id} [get]
+func GetUserPermissions(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var user dbmodels.User
+	if baseDB.First(&user, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := roles.Load(baseDB, &user); err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(user.Role, http.StatusOK)
+}
+
 // Recovery Password godoc
 // @Summary recovery user password.
 // @Description recovery user password.
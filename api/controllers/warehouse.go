@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/parameter"
+	"github.com/esmailemami/eshop/app/services/inventory"
+	"github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const warehouseColumns = `w.id, w.created_at, w.updated_at, w."name", w.code, w.type, w.postal_code, w.address, w.is_active,
+	(SELECT COALESCE(SUM(ws.quantity), 0) FROM warehouse_stock ws WHERE ws.warehouse_id = w.id AND ws.deleted_at IS NULL) AS stock`
+
+const warehouseStockColumns = `ws.warehouse_id, w."name" AS warehouse_name, w.code AS warehouse_code, ws.product_item_id,
+	p."name" AS product_name, c."name" AS color_name, pi2.sku, ws.quantity, ws.updated_at`
+
+// GetWarehouses godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.WarehouseOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse [get]
+func GetWarehouses(ctx *app.HttpContext) error {
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.WarehouseOutPutModel](ctx, baseDB)
+
+	baseDB = baseDB.Table("warehouse w").Where("w.deleted_at IS NULL")
+
+	data, err := parameter.SelectColumns(warehouseColumns).
+		SearchColumns(`w."name"`, "w.code").
+		SortDescending("w.updated_at").
+		Execute(baseDB)
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetWarehouse godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} appmodels.WarehouseOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse/{id} [get]
+func GetWarehouse(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var data appmodels.WarehouseOutPutModel
+
+	if err := baseDB.Table("warehouse w").
+		Where("w.deleted_at IS NULL AND w.id = ?", id).
+		Select(warehouseColumns).
+		Take(&data).Error; err != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	return ctx.JSON(data, http.StatusOK)
+}
+
+// CreateWarehouse godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param Warehouse   body  appmodels.WarehouseReqModel  true  "Warehouse model"
+// @Success 200 {object} helpers.SuccessDBResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse  [post]
+func CreateWarehouse(ctx *app.HttpContext) error {
+	var inputModel appmodels.WarehouseReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.ValidateCreate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	dbModel := inputModel.ToDBModel()
+
+	if err := db.MustGormDBConn(ctx).Create(dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickDBResponse(consts.Created, *dbModel.ID, http.StatusOK)
+}
+
+// EditWarehouse godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Param Warehouse   body  appmodels.WarehouseReqModel  true  "Warehouse model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse/edit/{id}  [post]
+func EditWarehouse(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	var inputModel appmodels.WarehouseReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.Warehouse
+
+	if baseDB.First(&dbModel, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	if err := inputModel.ValidateUpdate(id); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	inputModel.MergeWithDBData(&dbModel)
+
+	if err := baseDB.Save(&dbModel).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.Updated, http.StatusOK)
+}
+
+// DeleteWarehouse godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Record ID"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse/delete/{id}  [post]
+func DeleteWarehouse(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	var dbModel models.Warehouse
+
+	if baseDB.First(&dbModel, id).Error != nil {
+		return errors.NewRecordNotFoundError(consts.RecordNotFound, nil)
+	}
+
+	// the stock should be transferred to the other locations at first
+	if db.Exists(baseDB, &models.WarehouseStock{}, "warehouse_id = ? AND quantity > 0", id) {
+		return errors.NewBadRequestError(consts.WarehouseHasStock, nil)
+	}
+
+	if baseDB.Delete(&dbModel).Error != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, nil)
+	}
+
+	return ctx.QuickResponse(consts.Deleted, http.StatusOK)
+}
+
+// GetWarehouseStocks godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id  path  string  true  "Warehouse ID"
+// @Param page  query  string  false  "page size"
+// @Param limit  query  string  false  "length of records to show"
+// @Param searchTerm  query  string  false  "search for item"
+// @Success 200 {object} parameter.ListResponse[appmodels.WarehouseStockOutPutModel]
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse/stock/{id} [get]
+func GetWarehouseStocks(ctx *app.HttpContext) error {
+	id, err := uuid.Parse(ctx.GetPathParam("id"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+	parameter := parameter.New[appmodels.WarehouseStockOutPutModel](ctx, baseDB)
+
+	data, err := parameter.SelectColumns(warehouseStockColumns).
+		SearchColumns(`p."name"`, "pi2.sku", "pi2.barcode").
+		SortDescending("ws.updated_at").
+		Execute(warehouseStockQuery(baseDB).Where("ws.warehouse_id = ?", id))
+
+	if err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(*data, http.StatusOK)
+}
+
+// GetProductItemStocks godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param productItemId  path  string  true  "Product item ID"
+// @Success 200 {object} []appmodels.WarehouseStockOutPutModel
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse/productItem/{productItemId} [get]
+func GetProductItemStocks(ctx *app.HttpContext) error {
+	productItemID, err := uuid.Parse(ctx.GetPathParam("productItemId"))
+	if err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	baseDB := db.MustGormDBConn(ctx)
+
+	data := []appmodels.WarehouseStockOutPutModel{}
+
+	if err := warehouseStockQuery(baseDB).
+		Where("ws.product_item_id = ?", productItemID).
+		Select(warehouseStockColumns).
+		Order(`w."name"`).
+		Find(&data).Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.JSON(data, http.StatusOK)
+}
+
+// AdjustWarehouseStock godoc
+// @Tags Warehouses
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param StockAdjustment   body  appmodels.StockAdjustmentReqModel  true  "Stock adjustment model"
+// @Success 200 {object} helpers.SuccessResponse
+// @Failure 400 {object} map[string]any
+// @Failure 401 {object} map[string]any
+// @Router /admin/warehouse/stock/adjust  [post]
+func AdjustWarehouseStock(ctx *app.HttpContext) error {
+	var inputModel appmodels.StockAdjustmentReqModel
+
+	if err := ctx.BlindBind(&inputModel); err != nil {
+		return errors.NewBadRequestError(consts.BadRequest, err)
+	}
+
+	if err := inputModel.Validate(); err != nil {
+		return errors.NewValidationError(consts.ValidationError, err)
+	}
+
+	baseTx := db.MustGormDBConn(ctx).Begin()
+
+	if err := inventory.Move(baseTx, inputModel.WarehouseID, inputModel.ProductItemID, inputModel.Quantity,
+		models.StockMovementReasonAdjustment, nil, inputModel.Note); err != nil {
+		baseTx.Rollback()
+
+		if err == inventory.ErrInsufficientStock {
+			return errors.NewBadRequestError(consts.InsufficientWarehouseStock, nil)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	// the sellable quantity of the item follows the stock of the locations
+	if err := inventory.ChangeItemQuantity(baseTx, inputModel.ProductItemID, inputModel.Quantity,
+		models.StockMovementReasonAdjustment, nil, inputModel.Note); err != nil {
+		baseTx.Rollback()
+
+		if err == inventory.ErrInsufficientStock {
+			return errors.NewBadRequestError(consts.InvalidQuantity, nil)
+		}
+
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	if err := baseTx.Commit().Error; err != nil {
+		return errors.NewInternalServerError(consts.InternalServerError, err)
+	}
+
+	return ctx.QuickResponse(consts.OperationDone, http.StatusOK)
+}
+
+func warehouseStockQuery(baseDB *gorm.DB) *gorm.DB {
+	return baseDB.Table("warehouse_stock ws").
+		Joins("INNER JOIN warehouse w ON w.id = ws.warehouse_id").
+		Joins("INNER JOIN product_item pi2 ON pi2.id = ws.product_item_id").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("INNER JOIN color c ON c.id = pi2.color_id").
+		Where("ws.deleted_at IS NULL AND w.deleted_at IS NULL")
+}
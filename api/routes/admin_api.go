@@ -23,12 +23,17 @@ func loadAdminRoutes(root chi.Router) {
 		loadAdminProductFeatureValueRoutes(r)
 		loadAdminAppPicRoutes(r)
 		loadAdminProductRoutes(r)
+		loadAdminProductRevisionRoutes(r)
+		loadAdminProductRelationRoutes(r)
+		loadAdminBundleRoutes(r)
+		loadAdminWarehouseRoutes(r)
 		loadAdminAddressRoutes(r)
 		loadAdminColorRoutes(r)
 		loadAdminCategoryRoutes(r)
 		loadAdminBrandRoutes(r)
 		loadAdminProfileRoutes(r)
 		loadAdminCommentRoutes(r)
+		loadAdminProductQuestionRoutes(r)
 		loadAdminProductItemRoutes(r)
 		loadAdminRoleRoutes(r)
 		loadAdminReportRoutes(r)
@@ -12,15 +12,27 @@ func loadUserAnonymousRoutes(r chi.Router) {
 
 	// ##### Auth #####
 	r.Post("/login", app.Handler(controllers.LoginUser))
+	r.Post("/login/mfa", app.Handler(controllers.LoginByMfa))
+	r.Post("/login/mfa/enroll", app.Handler(controllers.StartLoginMfaEnrollment))
+	r.Post("/login/mfa/enroll/confirm", app.Handler(controllers.ConfirmLoginMfaEnrollment))
+	r.Post("/refresh", app.Handler(controllers.RefreshUserToken))
 	r.Post("/register", app.Handler(controllers.Register))
+	r.Post("/otp/send", app.Handler(controllers.SendOtp))
+	r.Post("/otp/verify", app.Handler(controllers.LoginByOtp))
+	r.Get("/oauth/providers", app.Handler(controllers.GetOAuthProviders))
+	r.Get("/oauth/{provider}", app.Handler(controllers.StartOAuth))
+	r.Post("/oauth/{provider}/callback", app.Handler(controllers.OAuthCallback))
 	r.Post("/recoveryPasword", app.Handler(controllers.SendRecoveryPasswordRequest))
 	r.Post("/recoveryPasword/{key}", app.Handler(controllers.RecoveryPassword))
 	r.Get("/logout", app.Handler(controllers.Logout))
 	// ##### Auth #####
 
 	loadAnonymousProductRoutes(r)
+	loadAnonymousProductComparisonRoutes(r)
+	loadAnonymousBundleRoutes(r)
 	loadAnonymousAppPicRoutes(r)
 	loadAnonymousCommentRoutes(r)
+	loadAnonymousProductQuestionRoutes(r)
 	loadAnonymousBrandRoutes(r)
 	loadAnonymousCategoryRoutes(r)
 	loadAnonymousColorRoutes(r)
@@ -30,6 +42,10 @@ func loadUserAnonymousRoutes(r chi.Router) {
 func loadAdminAnonymousRoutes(r chi.Router) {
 	// ##### Auth #####
 	r.Post("/login", app.Handler(controllers.LoginAdmin))
+	r.Post("/login/mfa", app.Handler(controllers.LoginByMfa))
+	r.Post("/login/mfa/enroll", app.Handler(controllers.StartLoginMfaEnrollment))
+	r.Post("/login/mfa/enroll/confirm", app.Handler(controllers.ConfirmLoginMfaEnrollment))
+	r.Post("/refresh", app.Handler(controllers.RefreshAdminToken))
 	r.Get("/logout", app.Handler(controllers.Logout))
 	// ##### Auth #####
 }
@@ -3,6 +3,8 @@ package routes
 import (
 	"net/http"
 
+	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/app"
 	"github.com/esmailemami/eshop/docs"
 	"github.com/go-chi/chi/v5"
 	"github.com/spf13/viper"
@@ -23,6 +25,8 @@ func LoadApiRoutes(root *chi.Mux) {
 		_, _ = w.Write([]byte("Eshop API Server"))
 	})
 
+	root.Get("/.well-known/jwks.json", app.Handler(controllers.GetJWKS))
+
 	docs.SwaggerInfo.Title = "Eshop API doc"
 	docs.SwaggerInfo.Description = "Eshop API."
 	docs.SwaggerInfo.Version = "1.0"
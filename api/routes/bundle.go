@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/api/middlewares"
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func loadAdminBundleRoutes(r chi.Router) {
+	r.Get("/bundle", app.Handler(controllers.GetAdminBundles,
+		middlewares.Permitted(models.ACTION_BUNDLE_ADMIN_LIST),
+	))
+	r.Get("/bundle/{id}", app.Handler(controllers.GetAdminBundle,
+		middlewares.Permitted(models.ACTION_BUNDLE_ADMIN_INFO),
+	))
+	r.Post("/bundle", app.Handler(controllers.CreateBundle,
+		middlewares.Permitted(models.ACTION_BUNDLE_ADMIN_CREATE),
+	))
+	r.Post("/bundle/edit/{id}", app.Handler(controllers.EditBundle,
+		middlewares.Permitted(models.ACTION_BUNDLE_ADMIN_UPDATE),
+	))
+	r.Post("/bundle/delete/{id}", app.Handler(controllers.DeleteBundle,
+		middlewares.Permitted(models.ACTION_BUNDLE_ADMIN_DELETE),
+	))
+}
+
+func loadAnonymousBundleRoutes(r chi.Router) {
+	r.Get("/bundle", app.Handler(controllers.GetBundles))
+	r.Get("/bundle/{id}", app.Handler(controllers.GetBundle))
+}
@@ -14,6 +14,9 @@ func loadUserCommentRoutes(r chi.Router) {
 	r.Post("/comment", app.Handler(controllers.CreateComment))
 	r.Post("/comment/edit/{id}", app.Handler(controllers.EditComment))
 	r.Post("/comment/delete/{id}", app.Handler(controllers.DeleteComment))
+	r.Post("/comment/photo/{id}", app.Handler(controllers.UploadCommentPhoto))
+	r.Post("/comment/photo/delete/{fileId}", app.Handler(controllers.DeleteCommentPhoto))
+	r.Post("/comment/vote/{id}", app.Handler(controllers.VoteComment))
 }
 
 func loadAdminCommentRoutes(r chi.Router) {
@@ -17,4 +17,7 @@ func loadAdminOrderRoutes(r chi.Router) {
 	r.Get("/order", app.Handler(controllers.GetAdminOrders,
 		middlewares.Permitted(models.ACTION_ORDER_ADMIN_LIST),
 	))
+	r.Get("/order/items/{id}", app.Handler(controllers.GetAdminOrderItems,
+		middlewares.Permitted(models.ACTION_ORDER_ADMIN_INFO),
+	))
 }
@@ -9,4 +9,6 @@ import (
 func loadUserOrderItemRoutes(r chi.Router) {
 	r.Post("/orderItem", app.Handler(controllers.CreateOrderItem))
 	r.Post("/orderItem/delete/{productItemId}", app.Handler(controllers.DeleteOrderItem))
+	r.Post("/orderItem/bundle", app.Handler(controllers.CreateBundleOrderItem))
+	r.Post("/orderItem/bundle/delete/{bundleId}", app.Handler(controllers.DeleteBundleOrderItem))
 }
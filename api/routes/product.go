@@ -25,6 +25,18 @@ func loadAdminProductRoutes(r chi.Router) {
 		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_DELETE),
 	))
 	r.Get("/product/selectList", app.Handler(controllers.GetProductsSelectList))
+	r.Post("/product/import", app.Handler(controllers.ImportProducts,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_IMPORT),
+	))
+	r.Get("/product/import", app.Handler(controllers.GetProductImports,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_IMPORT),
+	))
+	r.Get("/product/import/{id}", app.Handler(controllers.GetProductImport,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_IMPORT),
+	))
+	r.Get("/product/export", app.Handler(controllers.ExportProducts,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_EXPORT),
+	))
 }
 
 func loadAnonymousProductRoutes(r chi.Router) {
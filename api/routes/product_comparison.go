@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/app"
+	"github.com/go-chi/chi/v5"
+)
+
+func loadAnonymousProductComparisonRoutes(r chi.Router) {
+	r.Get("/product/compare", app.Handler(controllers.CompareProducts))
+}
+
+func loadUserProductComparisonRoutes(r chi.Router) {
+	r.Get("/productComparison", app.Handler(controllers.GetProductComparisons))
+	r.Get("/productComparison/{id}", app.Handler(controllers.GetProductComparison))
+	r.Post("/productComparison", app.Handler(controllers.CreateProductComparison))
+	r.Post("/productComparison/edit/{id}", app.Handler(controllers.EditProductComparison))
+	r.Post("/productComparison/delete/{id}", app.Handler(controllers.DeleteProductComparison))
+}
@@ -2,17 +2,46 @@ package routes
 
 import (
 	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/api/middlewares"
 	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/models"
 	"github.com/go-chi/chi/v5"
 )
 
 func loadAdminProductItemRoutes(r chi.Router) {
-	r.Post("/productItem", app.Handler(controllers.CreateProductItem))
-	r.Post("/productItem/edit/{id}", app.Handler(controllers.EditProductItem))
-	r.Post("/productItem/delete/{id}", app.Handler(controllers.DeleteProductItem))
-	r.Get("/productItem/product/{productId}", app.Handler(controllers.GetProductItems))
-	r.Get("/productItem/selectList/{productId}", app.Handler(controllers.GetProductItemsSelectList))
-
+	r.Post("/productItem", app.Handler(controllers.CreateProductItem,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_UPDATE),
+	))
+	r.Post("/productItem/edit/{id}", app.Handler(controllers.EditProductItem,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_UPDATE),
+	))
+	r.Post("/productItem/delete/{id}", app.Handler(controllers.DeleteProductItem,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_UPDATE),
+	))
+	r.Get("/productItem/product/{productId}", app.Handler(controllers.GetProductItems,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_INFO),
+	))
+	r.Get("/productItem/selectList/{productId}", app.Handler(controllers.GetProductItemsSelectList,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_INFO),
+	))
+	r.Get("/productItem/lookup", app.Handler(controllers.LookupProductItem,
+		middlewares.Permitted(models.ACTION_PRODUCT_ITEM_ADMIN_LOOKUP),
+	))
+	r.Post("/productItem/barcode/{id}", app.Handler(controllers.GenerateProductItemBarcode,
+		middlewares.Permitted(models.ACTION_PRODUCT_ITEM_ADMIN_BARCODE),
+	))
+	r.Get("/productItem/priceHistory/{id}", app.Handler(controllers.GetAdminProductItemPriceHistory,
+		middlewares.Permitted(models.ACTION_PRODUCT_ITEM_ADMIN_PRICE),
+	))
+	r.Get("/productItem/priceSchedule/{productItemId}", app.Handler(controllers.GetProductItemPriceSchedules,
+		middlewares.Permitted(models.ACTION_PRODUCT_ITEM_ADMIN_PRICE),
+	))
+	r.Post("/productItem/priceSchedule/{productItemId}", app.Handler(controllers.CreateProductItemPriceSchedule,
+		middlewares.Permitted(models.ACTION_PRODUCT_ITEM_ADMIN_PRICE),
+	))
+	r.Post("/productItem/priceSchedule/cancel/{id}", app.Handler(controllers.CancelProductItemPriceSchedule,
+		middlewares.Permitted(models.ACTION_PRODUCT_ITEM_ADMIN_PRICE),
+	))
 }
 
 func loadUserProductItemRoutes(r chi.Router) {
@@ -21,4 +50,5 @@ func loadUserProductItemRoutes(r chi.Router) {
 
 func loadAnonymousProductItemRoutes(r chi.Router) {
 	r.Get("/productItem/{id}", app.Handler(controllers.GetProductItem))
+	r.Get("/productItem/priceHistory/{id}", app.Handler(controllers.GetProductItemPriceHistory))
 }
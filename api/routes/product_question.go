@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/api/middlewares"
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func loadUserProductQuestionRoutes(r chi.Router) {
+	r.Get("/question", app.Handler(controllers.GetUserProductQuestions))
+	r.Post("/question", app.Handler(controllers.CreateProductQuestion))
+	r.Post("/question/delete/{id}", app.Handler(controllers.DeleteProductQuestion))
+	r.Post("/question/upvote/{id}", app.Handler(controllers.UpvoteProductQuestion))
+	r.Post("/question/answer", app.Handler(controllers.CreateProductAnswer))
+	r.Post("/question/answer/upvote/{id}", app.Handler(controllers.UpvoteProductAnswer))
+}
+
+func loadAdminProductQuestionRoutes(r chi.Router) {
+	r.Get("/question", app.Handler(controllers.GetAdminProductQuestions,
+		middlewares.Permitted(models.ACTION_QUESTION_ADMIN_LIST)),
+	)
+	r.Post("/question/changeStatus/{id}", app.Handler(controllers.ChangeProductQuestionStatus,
+		middlewares.Permitted(models.ACTION_QUESTION_ADMIN_CHANGE_STATUS)),
+	)
+	r.Get("/question/answer", app.Handler(controllers.GetAdminProductAnswers,
+		middlewares.Permitted(models.ACTION_QUESTION_ADMIN_LIST)),
+	)
+	r.Post("/question/answer", app.Handler(controllers.CreateAdminProductAnswer,
+		middlewares.Permitted(models.ACTION_QUESTION_ADMIN_ANSWER)),
+	)
+	r.Post("/question/answer/changeStatus/{id}", app.Handler(controllers.ChangeProductAnswerStatus,
+		middlewares.Permitted(models.ACTION_QUESTION_ADMIN_CHANGE_STATUS)),
+	)
+}
+
+func loadAnonymousProductQuestionRoutes(r chi.Router) {
+	r.Get("/question/product/{productId}", app.Handler(controllers.GetProductQuestions))
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/api/middlewares"
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func loadAdminProductRelationRoutes(r chi.Router) {
+	r.Get("/productRelation/{productId}", app.Handler(controllers.GetProductRelations,
+		middlewares.Permitted(models.ACTION_PRODUCT_RELATION_ADMIN_LIST)),
+	)
+	r.Post("/productRelation/{productId}", app.Handler(controllers.CreateProductRelation,
+		middlewares.Permitted(models.ACTION_PRODUCT_RELATION_ADMIN_CREATE)),
+	)
+	r.Post("/productRelation/delete/{id}", app.Handler(controllers.DeleteProductRelation,
+		middlewares.Permitted(models.ACTION_PRODUCT_RELATION_ADMIN_DELETE)),
+	)
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/api/middlewares"
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func loadAdminProductRevisionRoutes(r chi.Router) {
+	r.Get("/productRevision/product/{productId}", app.Handler(controllers.GetProductRevisions,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_INFO)),
+	)
+	r.Get("/productRevision/{id}", app.Handler(controllers.GetProductRevision,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_INFO)),
+	)
+	r.Post("/productRevision/{productId}", app.Handler(controllers.CreateProductRevision,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_UPDATE)),
+	)
+	r.Post("/productRevision/edit/{id}", app.Handler(controllers.EditProductRevision,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_UPDATE)),
+	)
+	r.Post("/productRevision/restore/{id}", app.Handler(controllers.RestoreProductRevision,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_UPDATE)),
+	)
+	r.Post("/productRevision/approve/{id}", app.Handler(controllers.ApproveProductRevision,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_REVIEW)),
+	)
+	r.Post("/productRevision/reject/{id}", app.Handler(controllers.RejectProductRevision,
+		middlewares.Permitted(models.ACTION_PRODUCT_ADMIN_REVIEW)),
+	)
+}
@@ -9,12 +9,29 @@ import (
 )
 
 func loadUserProfileRoutes(r chi.Router) {
-	r.Get("/profile", app.Handler(controllers.GetUser))
+	r.Get("/profile", app.Handler(controllers.GetProfile))
+	r.Post("/profile/edit", app.Handler(controllers.EditProfile))
+	r.Post("/profile/email/sendCode", app.Handler(controllers.SendProfileEmailCode))
+	r.Post("/profile/email/verify", app.Handler(controllers.VerifyProfileEmail))
+	r.Post("/profile/mobile/sendCode", app.Handler(controllers.SendProfileMobileCode))
+	r.Post("/profile/mobile/verify", app.Handler(controllers.VerifyProfileMobile))
 	r.Get("/profile/orders", app.Handler(controllers.GetUserOrders))
 	r.Get("/profile/favoriteProducts", app.Handler(controllers.GetUserFavoriteProducts))
+	r.Get("/profile/sessions", app.Handler(controllers.GetUserSessions))
+	r.Post("/profile/sessions/revoke/{id}", app.Handler(controllers.RevokeUserSession))
+	r.Post("/profile/sessions/revokeOthers", app.Handler(controllers.RevokeOtherUserSessions))
+	r.Get("/profile/loginHistory", app.Handler(controllers.GetUserLoginHistory))
+	r.Get("/profile/identities", app.Handler(controllers.GetUserIdentities))
+	r.Post("/profile/identities/unlink/{id}", app.Handler(controllers.UnlinkUserIdentity))
 }
 
 func loadAdminProfileRoutes(r chi.Router) {
+	r.Get("/profile/mfa", app.Handler(controllers.GetProfileMfa))
+	r.Post("/profile/mfa/enroll", app.Handler(controllers.EnrollProfileMfa))
+	r.Post("/profile/mfa/enroll/confirm", app.Handler(controllers.ConfirmProfileMfa))
+	r.Post("/profile/mfa/disable", app.Handler(controllers.DisableProfileMfa))
+	r.Post("/profile/mfa/recoveryCodes", app.Handler(controllers.RegenerateProfileMfaRecoveryCodes))
+	r.Get("/profile/permissions", app.Handler(controllers.GetProfilePermissions))
 	r.Get("/profile/orders/{userId}", app.Handler(controllers.GetAdminUserOrders,
 		middlewares.Permitted(models.ACTION_USER_ADMIN_ORDER_LIST),
 	))
@@ -14,4 +14,12 @@ func loadAdminReportRoutes(r chi.Router) {
 	))
 
 	r.Get("/report/sellsChart", app.Handler(controllers.ReportSellsChart))
+
+	r.Get("/report/lowStock", app.Handler(controllers.ReportLowStock,
+		middlewares.Permitted(models.ACTION_REPORT_ADMIN_LOW_STOCK),
+	))
+
+	r.Get("/report/atRisk", app.Handler(controllers.ReportAtRiskItems,
+		middlewares.Permitted(models.ACTION_REPORT_ADMIN_AT_RISK),
+	))
 }
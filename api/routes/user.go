@@ -27,4 +27,22 @@ func loadAdminUserRoutes(r chi.Router) {
 	r.Post("/user/recoveryPasword/{id}", app.Handler(controllers.AdminUserRecoveryPassword,
 		middlewares.Permitted(models.ACTION_USER_ADMIN_RECOVERY_PASSWORD),
 	))
+	r.Get("/user/sessions/{id}", app.Handler(controllers.GetAdminUserSessions,
+		middlewares.Permitted(models.ACTION_USER_ADMIN_SESSION_LIST),
+	))
+	r.Get("/user/loginHistory/{id}", app.Handler(controllers.GetAdminUserLoginHistory,
+		middlewares.Permitted(models.ACTION_USER_ADMIN_SESSION_LIST),
+	))
+	r.Post("/user/logout/{id}", app.Handler(controllers.ForceLogoutUser,
+		middlewares.Permitted(models.ACTION_USER_ADMIN_FORCE_LOGOUT),
+	))
+	r.Post("/user/unlock/{id}", app.Handler(controllers.UnlockUser,
+		middlewares.Permitted(models.ACTION_USER_ADMIN_UNLOCK),
+	))
+	r.Post("/user/mfa/reset/{id}", app.Handler(controllers.ResetUserMfa,
+		middlewares.Permitted(models.ACTION_USER_ADMIN_MFA_RESET),
+	))
+	r.Get("/user/permissions/{id}", app.Handler(controllers.GetUserPermissions,
+		middlewares.Permitted(models.ACTION_USER_ADMIN_PERMISSIONS),
+	))
 }
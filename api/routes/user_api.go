@@ -22,7 +22,9 @@ func loadUserRoutes(root chi.Router) {
 		loadUserAddressRoutes(r)
 		loadUserProfileRoutes(r)
 		loadUserFavoriteProductItemRoutes(r)
+		loadUserProductComparisonRoutes(r)
 		loadUserCommentRoutes(r)
+		loadUserProductQuestionRoutes(r)
 		loadUserProductItemRoutes(r)
 		loadUserFileRoutes(r)
 		loadUserBrandRoutes(r)
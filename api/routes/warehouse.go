@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"github.com/esmailemami/eshop/api/controllers"
+	"github.com/esmailemami/eshop/api/middlewares"
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func loadAdminWarehouseRoutes(r chi.Router) {
+	r.Get("/warehouse", app.Handler(controllers.GetWarehouses,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_LIST),
+	))
+	r.Get("/warehouse/{id}", app.Handler(controllers.GetWarehouse,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_INFO),
+	))
+	r.Post("/warehouse", app.Handler(controllers.CreateWarehouse,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_CREATE),
+	))
+	r.Post("/warehouse/edit/{id}", app.Handler(controllers.EditWarehouse,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_UPDATE),
+	))
+	r.Post("/warehouse/delete/{id}", app.Handler(controllers.DeleteWarehouse,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_DELETE),
+	))
+	r.Get("/warehouse/stock/{id}", app.Handler(controllers.GetWarehouseStocks,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_STOCK),
+	))
+	r.Get("/warehouse/productItem/{productItemId}", app.Handler(controllers.GetProductItemStocks,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_STOCK),
+	))
+	r.Post("/warehouse/stock/adjust", app.Handler(controllers.AdjustWarehouseStock,
+		middlewares.Permitted(models.ACTION_WAREHOUSE_ADMIN_ADJUST),
+	))
+
+	r.Get("/stockMovement", app.Handler(controllers.GetStockMovements,
+		middlewares.Permitted(models.ACTION_STOCK_MOVEMENT_ADMIN_LIST),
+	))
+	r.Get("/stockMovement/reconcile", app.Handler(controllers.GetStockReconciliation,
+		middlewares.Permitted(models.ACTION_STOCK_MOVEMENT_ADMIN_RECONCILE),
+	))
+	r.Post("/stockMovement/reconcile/{productItemId}", app.Handler(controllers.ReconcileStock,
+		middlewares.Permitted(models.ACTION_STOCK_MOVEMENT_ADMIN_RECONCILE),
+	))
+
+	r.Get("/stockTransfer", app.Handler(controllers.GetStockTransfers,
+		middlewares.Permitted(models.ACTION_STOCK_TRANSFER_ADMIN_LIST),
+	))
+	r.Get("/stockTransfer/{id}", app.Handler(controllers.GetStockTransfer,
+		middlewares.Permitted(models.ACTION_STOCK_TRANSFER_ADMIN_INFO),
+	))
+	r.Post("/stockTransfer", app.Handler(controllers.CreateStockTransfer,
+		middlewares.Permitted(models.ACTION_STOCK_TRANSFER_ADMIN_CREATE),
+	))
+	r.Post("/stockTransfer/complete/{id}", app.Handler(controllers.CompleteStockTransfer,
+		middlewares.Permitted(models.ACTION_STOCK_TRANSFER_ADMIN_COMPLETE),
+	))
+	r.Post("/stockTransfer/cancel/{id}", app.Handler(controllers.CancelStockTransfer,
+		middlewares.Permitted(models.ACTION_STOCK_TRANSFER_ADMIN_CANCEL),
+	))
+}
@@ -9,6 +9,7 @@ const (
 	TokenContext       = "token"
 	TokenStringContext = "tokenString"
 	AuthTokenID        = "authTokenID"
+	PermissionScope    = "permissionScope"
 
 	// Notifiers
 	NOTIFIER_SMS   = "sms"
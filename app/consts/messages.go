@@ -9,6 +9,10 @@ const (
 	RegistrationDone     = "Registration completed successfully."
 	LoggedOut            = "Logged out successfully."
 	OperationDone        = "Operation completed successfully"
+	RevisionApproved     = "Revision approved and published successfully."
+	RevisionRejected     = "Revision rejected successfully."
+	RevisionDrafted      = "The content changes are drafted and they are published after the review."
+	ProductDrafted       = "The product is created and it is published after its content is reviewed."
 
 	// Errors
 	CacheRecordNotFound              = "Cache Record Not Found"
@@ -72,4 +76,60 @@ const (
 	InvalidPostalCode                = "Invalid postal code entered."
 	RecoveryPasswordReqDone          = "If the provided information is accurate, an email has been dispatched to facilitate the process of password recovery for your account."
 	TimeGreaterThanNow               = "The entered date time must be greater than now."
+	ExistedSKU                       = "The entered SKU has already been registered."
+	ExistedBarcode                   = "The entered barcode has already been registered."
+	InvalidBarcode                   = "Invalid barcode entered. Only GTIN-8, GTIN-12, GTIN-13 and GTIN-14 are allowed."
+	InvalidProductStatus             = "Invalid product status entered."
+	UnpublishBeforePublish           = "The unpublish date time must be after the publish date time."
+	RevisionIsNotDraft               = "Only draft revisions can be changed or reviewed."
+	RevisionIsOutdated               = "The product content has been changed since the revision was drafted. Draft the changes again on the current content."
+	InvalidRelatedProduct            = "A product cannot be related to itself."
+	ExistedProductRelation           = "The selected product has already been linked with this relation type."
+	InvalidProductRelationType       = "Invalid product relation type entered."
+	InvalidComparedProductsCount     = "Between 2 and 4 products can be compared."
+	InvalidBundleItemsCount          = "A bundle must contain at least 2 product items."
+	DuplicatedBundleItem             = "A product item cannot be added to a bundle more than once."
+	PriceScheduleIsNotPending        = "Only pending price schedules can be canceled."
+	InvalidPriceHistoryDays          = "The price history days must be between 1 and 365."
+	InvalidWarehouseType             = "Invalid warehouse type entered."
+	SameTransferWarehouses           = "The source and the destination of a stock transfer must be different."
+	DuplicatedStockTransferItem      = "A product item cannot be added to a stock transfer more than once."
+	StockTransferIsNotDraft          = "Only draft stock transfers can be completed or canceled."
+	InsufficientWarehouseStock       = "The stock of the location is not enough."
+	QuantityManagedByWarehouses      = "The quantity of the item follows the stock of its locations, change it by a stock adjustment of a location."
+	WarehouseHasStock                = "The warehouse has stock, transfer it to the other locations at first."
+	QuestionIsNotAccepted            = "Only the accepted questions can be answered or voted."
+	AnswerIsNotAccepted              = "Only the accepted answers can be voted."
+	OnlyBuyersCanReview              = "Only the customers who have received the product can review it."
+	TooManyReviewPhotos              = "The review has reached the maximum number of photos."
+	CannotVoteOwnReview              = "You cannot vote on your own review."
+	NotReviewAuthor                  = "Only the author of the review can change its photos."
+	InvalidRefreshToken              = "The refresh token is invalid or expired, please login again."
+	RefreshTokenReused               = "The refresh token has already been used, all the sessions of this login are revoked."
+	TooManyVerificationCodeRequests  = "Too many verification codes have been requested for this mobile number. Please try again later."
+	VerificationCodeAttemptsExceeded = "Too many wrong attempts, please request a new verification code."
+	InvalidEmail                     = "Invalid email address entered."
+	EmailAlreadyExists               = "The entered email address is already in use."
+	NoContactToVerify                = "There is not any unverified contact detail to verify."
+	ContactVerificationSent          = "A verification code has been sent to the new contact details, the change takes effect once it is verified."
+	ContactVerified                  = "The contact details are verified successfully."
+	InvalidMfaCode                   = "The entered two-factor authentication code is invalid."
+	InvalidMfaToken                  = "The two-factor authentication session is invalid or expired, please login again."
+	MfaAlreadyEnabled                = "Two-factor authentication is already enabled."
+	MfaNotEnrolled                   = "Two-factor authentication is not enrolled, please start the enrollment first."
+	MfaRequiredByRole                = "Two-factor authentication is mandatory for your role and cannot be disabled."
+	MfaRequiresAdminRole             = "Two-factor authentication can only be required for the roles which can login as admin."
+	MfaDisabled                      = "Two-factor authentication is disabled."
+	TooManyFailedAttempts            = "Too many failed attempts, please wait a moment and try again."
+	AttemptsLocked                   = "Too many failed attempts, the access is temporarily locked. Please try again later."
+	UnknownOAuthProvider             = "The login provider is not supported."
+	InvalidOAuthState                = "The login session is invalid or expired, please try again."
+	OAuthLoginFailed                 = "Login with the provider failed, please try again."
+	IdentityIsLastLoginMethod        = "The linked account is the only way to login, set a password or verify a contact detail first."
+	OutOfPermissionScope             = "The resource is out of the scope of your permissions."
+	UnknownPermission                = "The permission is not defined."
+	PermissionNotGranted             = "The scope is defined for a permission which is not granted to the role."
+	PermissionNotScopable            = "The permission cannot be limited by this scope."
+	EmptyPermissionScope             = "The scope must limit the permission to at least one category or brand."
+	RoleInheritanceCycle             = "The role cannot inherit a role which inherits it."
 )
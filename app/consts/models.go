@@ -14,4 +14,9 @@ const (
 	ModelProductItemNotFound            = "Product item not found."
 	ModelUserNotFound                   = "User not found."
 	ModelDiscountNotFound               = "Discount not found."
+	ModelBundleNotFound                 = "Bundle not found."
+	ModelWarehouseNotFound              = "Warehouse not found."
+	ModelStockTransferNotFound          = "Stock transfer not found."
+	ModelProductQuestionNotFound        = "Product question not found."
+	ModelProductAnswerNotFound          = "Product answer not found."
 )
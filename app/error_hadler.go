@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/esmailemami/eshop/app/consts"
 	"github.com/esmailemami/eshop/app/errors"
@@ -53,6 +54,17 @@ func ErrorResponseHandler(ctx *HttpContext, err error) {
 				logger.Default().WithField("StatusCode", strconv.Itoa(e.StatusCode)).Error(e.LogError.Error())
 			}
 		}
+	case *errors.TooManyRequestsError:
+		{
+			if e.RetryAfter > 0 {
+				// the header is in seconds, the remainder is rounded up so the client does not retry too early
+				ctx.ResponseWriter.Header().Set("Retry-After", strconv.Itoa(int((e.RetryAfter+time.Second-1)/time.Second)))
+			}
+			_ = ctx.QuickResponse(e.Error(), e.StatusCode)
+			if e.LogError != nil {
+				logger.Default().WithField("StatusCode", strconv.Itoa(e.StatusCode)).Error(e.LogError.Error())
+			}
+		}
 	case *errors.InternalServerError:
 		{
 			_ = ctx.QuickResponse(e.Error(), e.StatusCode)
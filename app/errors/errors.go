@@ -3,6 +3,7 @@ package errors
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
@@ -130,3 +131,18 @@ func (e *ForbiddenError) Error() string {
 func NewForbiddenError(message string, logError error) *ForbiddenError {
 	return &ForbiddenError{Message: message, StatusCode: http.StatusForbidden, LogError: logError}
 }
+
+type TooManyRequestsError struct {
+	Message    string        `json:"message"`
+	StatusCode int           `json:"-"`
+	RetryAfter time.Duration `json:"-"`
+	LogError   error         `json:"-"`
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return e.Message
+}
+
+func NewTooManyRequestsError(message string, retryAfter time.Duration, logError error) *TooManyRequestsError {
+	return &TooManyRequestsError{Message: message, StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter, LogError: logError}
+}
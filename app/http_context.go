@@ -3,6 +3,7 @@ package app
 import (
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/esmailemami/eshop/app/helpers"
 	"github.com/esmailemami/eshop/models"
 	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
 )
 
 type TransformableObject interface {
@@ -100,16 +102,74 @@ func (ctx *HttpContext) Value(key interface{}) interface{} {
 	return ctx.Request.Context().Value(key)
 }
 
+// ClientIP returns the address of the client. The X-Forwarded-For header is only used when the request comes from
+// one of the proxies of server.trusted-proxies, and the first address which is not a trusted proxy from the right
+// of the header is the client, so the clients cannot choose their address by sending the header.
 func (ctx *HttpContext) ClientIP() string {
-	clientIP := ctx.Request.Header.Get("X-Forwarded-For")
+	return clientIP(ctx.Request, trustedProxies())
+}
+
+func clientIP(r *http.Request, proxies []*net.IPNet) string {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+
+	if !isTrusted(remoteIP, proxies) {
+		return remoteIP
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+
+		if !isTrusted(ip, proxies) {
+			return ip
+		}
+	}
+
+	return remoteIP
+}
+
+// trustedProxies parses the addresses and the CIDRs of server.trusted-proxies, the invalid ones are ignored
+func trustedProxies() []*net.IPNet {
+	values := viper.GetStringSlice("server.trusted-proxies")
+	proxies := make([]*net.IPNet, 0, len(values))
+
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			proxies = append(proxies, network)
+		}
+	}
+
+	return proxies
+}
+
+func isTrusted(ip string, proxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
 
-	if clientIP == "" {
-		clientIP = ctx.Request.RemoteAddr
-	} else {
-		clientIP = strings.TrimSpace(strings.Split(clientIP, ",")[0])
+	for _, proxy := range proxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
 	}
 
-	return clientIP
+	return false
 }
 
 func (ctx *HttpContext) UserAgent() string {
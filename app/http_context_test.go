@@ -0,0 +1,42 @@
+package app
+
+import (
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		proxies    []*net.IPNet
+		want       string
+	}{
+		{name: "without proxy", remoteAddr: "1.2.3.4:5000", want: "1.2.3.4"},
+		{name: "spoofed header", remoteAddr: "1.2.3.4:5000", forwarded: []string{"5.6.7.8"}, proxies: trusted, want: "1.2.3.4"},
+		{name: "header without trusted proxies", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8"}, want: "10.0.0.1"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8"}, proxies: trusted, want: "5.6.7.8"},
+		// the client prepends the spoofed address to the header
+		{name: "spoofed by the client", remoteAddr: "10.0.0.1:5000", forwarded: []string{"9.9.9.9, 5.6.7.8, 10.0.0.2"}, proxies: trusted, want: "5.6.7.8"},
+		{name: "multiple headers", remoteAddr: "10.0.0.1:5000", forwarded: []string{"9.9.9.9", "5.6.7.8"}, proxies: trusted, want: "5.6.7.8"},
+		{name: "invalid header", remoteAddr: "10.0.0.1:5000", forwarded: []string{"unknown"}, proxies: trusted, want: "10.0.0.1"},
+		{name: "only proxies", remoteAddr: "10.0.0.1:5000", forwarded: []string{"10.0.0.2"}, proxies: trusted, want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &http.Request{RemoteAddr: tt.remoteAddr, Header: http.Header{}}
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := clientIP(r, tt.proxies); got != tt.want {
+				t.Errorf("clientIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -36,11 +36,34 @@ func (model LoginInputModel) Validate() error {
 }
 
 type LoginOutputModel struct {
-	TokenID   uuid.UUID            `json:"-"`
-	Token     string               `json:"token"`
-	ExpiresAt time.Time            `json:"expiresAt"`
-	ExpiresIn int64                `json:"expiresIn"`
-	User      LoginOutputUserModel `json:"user"`
+	TokenID          uuid.UUID            `json:"-"`
+	RefreshTokenID   uuid.UUID            `json:"-"`
+	Token            string               `json:"token"`
+	ExpiresAt        time.Time            `json:"expiresAt"`
+	ExpiresIn        int64                `json:"expiresIn"`
+	RefreshToken     string               `json:"refreshToken"`
+	RefreshExpiresAt time.Time            `json:"refreshExpiresAt"`
+	RefreshExpiresIn int64                `json:"refreshExpiresIn"`
+	User             LoginOutputUserModel `json:"user"`
+
+	// Mfa is set instead of the tokens when the login waits for the second factor
+	Mfa *MfaPendingOutputModel `json:"mfa,omitempty"`
+	// RecoveryCodes are returned once when the 2FA is enrolled during the login
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+}
+
+type RefreshTokenInputModel struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (model RefreshTokenInputModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(
+			&model.RefreshToken,
+			validation.Required.Error(consts.Required),
+		),
+	)
 }
 
 type LoginOutputUserModel struct {
@@ -50,9 +73,11 @@ type LoginOutputUserModel struct {
 }
 
 type RegisterInputModel struct {
-	Username             string `json:"username"`
-	Password             string `json:"password"`
-	PasswordConfirmation string `json:"passwordConfirmation"`
+	Username             string  `json:"username"`
+	Password             string  `json:"password"`
+	PasswordConfirmation string  `json:"passwordConfirmation"`
+	Mobile               *string `json:"mobile,omitempty"`
+	Email                *string `json:"email,omitempty"`
 }
 
 func (model RegisterInputModel) Validate() error {
@@ -75,9 +100,20 @@ func (model RegisterInputModel) Validate() error {
 				return nil
 			}),
 		),
+		validation.Field(
+			&model.Mobile,
+			validation.By(validations.IsValidMobileNumber()),
+			validation.By(validations.NotExistsInDBWithCond(&models.User{}, "mobile", consts.MobileAlreadyExists, "mobile_verified = true")),
+		),
+		validation.Field(
+			&model.Email,
+			validation.By(validations.IsValidEmail()),
+			validation.By(validations.NotExistsInDB(&models.User{}, "email", consts.EmailAlreadyExists)),
+		),
 	)
 }
 
+// ToDBModel creates the user, the mobile and email are not set until they are verified
 func (model RegisterInputModel) ToDBModel() *models.User {
 	pass, _ := bcrypt.GenerateFromPassword([]byte(model.Password), bcrypt.DefaultCost)
 
@@ -130,3 +166,46 @@ func (model RecoveryPasswordReqModel) Validate() error {
 		),
 	)
 }
+
+type OtpSendInputModel struct {
+	Mobile string `json:"mobile"`
+}
+
+func (model OtpSendInputModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(
+			&model.Mobile,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.IsValidMobileNumber()),
+		),
+	)
+}
+
+type OtpSendOutputModel struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+	ExpiresIn int64     `json:"expiresIn"`
+	ResendIn  int64     `json:"resendIn"`
+}
+
+type OtpLoginInputModel struct {
+	Mobile    string `json:"mobile"`
+	Code      string `json:"code"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (model OtpLoginInputModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(
+			&model.Mobile,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.IsValidMobileNumber()),
+		),
+		validation.Field(
+			&model.Code,
+			validation.Required.Error(consts.Required),
+		),
+	)
+}
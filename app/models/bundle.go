@@ -0,0 +1,187 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/validations"
+	dbmodels "github.com/esmailemami/eshop/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type BundleReqModel struct {
+	Name        string                 `json:"name"`
+	Code        string                 `json:"code"`
+	Description string                 `json:"description"`
+	Price       float64                `json:"price"`
+	Status      dbmodels.ProductStatus `json:"status"`
+	Items       []BundleItemReqModel   `json:"items"`
+}
+
+type BundleItemReqModel struct {
+	ProductItemID uuid.UUID `json:"productItemId"`
+	Quantity      int       `json:"quantity"`
+}
+
+func (model BundleItemReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.ProductItemID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.ProductItem{}, "id", consts.ModelProductItemNotFound)),
+		),
+		validation.Field(&model.Quantity,
+			validation.Required.Error(consts.Required),
+			validation.Min(1).Error(consts.InvalidQuantity),
+		),
+	)
+}
+
+func (model BundleReqModel) ValidateCreate() error {
+	return model.validate(validations.NotExistsInDB(&dbmodels.Bundle{}, "code", consts.ExistedCode))
+}
+
+func (model BundleReqModel) ValidateUpdate(id uuid.UUID) error {
+	return model.validate(validations.NotExistsInDBWithID(&dbmodels.Bundle{}, "code", id, consts.ExistedCode))
+}
+
+func (model BundleReqModel) validate(codeRule func(value interface{}) error) error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Name,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.Code,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.Code()),
+			validation.By(codeRule),
+		),
+		validation.Field(&model.Description,
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.Price,
+			validation.Required.Error(consts.Required),
+			validation.Min(0.0).Error(consts.MinIsZero),
+		),
+		validation.Field(&model.Status,
+			validation.In(dbmodels.ProductStatusPublish, dbmodels.ProductStatusInActive).Error(consts.InvalidProductStatus),
+		),
+		validation.Field(&model.Items,
+			validation.Required.Error(consts.Required),
+			validation.Length(2, 0).Error(consts.InvalidBundleItemsCount),
+			validation.By(uniqueBundleItems),
+		),
+	)
+}
+
+func uniqueBundleItems(value interface{}) error {
+	items, _ := value.([]BundleItemReqModel)
+
+	seen := map[uuid.UUID]bool{}
+	for _, item := range items {
+		if seen[item.ProductItemID] {
+			return errors.New(consts.DuplicatedBundleItem)
+		}
+		seen[item.ProductItemID] = true
+	}
+
+	return nil
+}
+
+func (model BundleReqModel) ToDBModel() *dbmodels.Bundle {
+	dbModel := &dbmodels.Bundle{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+	}
+
+	model.MergeWithDBData(dbModel)
+
+	return dbModel
+}
+
+// MergeWithDBData sets the fields and the new items of the bundle, the last items should be deleted by the caller
+func (model BundleReqModel) MergeWithDBData(dbmodel *dbmodels.Bundle) {
+	dbmodel.Name = model.Name
+	dbmodel.Code = model.Code
+	dbmodel.Description = model.Description
+	dbmodel.Price = model.Price
+	dbmodel.Status = model.Status
+	dbmodel.Items = make([]dbmodels.BundleItem, len(model.Items))
+
+	for i, item := range model.Items {
+		dbmodel.Items[i] = dbmodels.BundleItem{
+			Model: dbmodels.Model{
+				ID: dbmodels.NewID(),
+			},
+			BundleID:      *dbmodel.ID,
+			ProductItemID: item.ProductItemID,
+			Quantity:      item.Quantity,
+		}
+	}
+}
+
+type BundleOutPutModel struct {
+	ID          *uuid.UUID              `gorm:"column:id"              json:"id"`
+	CreatedAt   time.Time               `gorm:"column:created_at"      json:"createdAt"`
+	UpdatedAt   time.Time               `gorm:"column:updated_at"      json:"updatedAt"`
+	Name        string                  `gorm:"column:name"            json:"name"`
+	Code        string                  `gorm:"column:code"            json:"code"`
+	Description string                  `gorm:"column:description"     json:"description"`
+	Price       float64                 `gorm:"column:price"           json:"price"`
+	Status      dbmodels.ProductStatus  `gorm:"column:status"          json:"status"`
+	Stock       int                     `gorm:"column:stock"           json:"stock"`
+	ListPrice   float64                 `gorm:"column:list_price"      json:"listPrice"`
+	Items       []BundleItemOutPutModel `gorm:"-"                      json:"items"`
+}
+
+type BundleItemOutPutModel struct {
+	ProductItemID uuid.UUID `gorm:"column:product_item_id"   json:"productItemId"`
+	ProductID     uuid.UUID `gorm:"column:product_id"        json:"productId"`
+	ProductName   string    `gorm:"column:product_name"      json:"productName"`
+	ColorName     string    `gorm:"column:color_name"        json:"colorName"`
+	Price         float64   `gorm:"column:price"             json:"price"`
+	Quantity      int       `gorm:"column:quantity"          json:"quantity"`
+}
+
+type BundleOrderItemReqModel struct {
+	BundleID uuid.UUID `json:"bundleId"`
+	Quantity int       `json:"quantity"`
+}
+
+func (model BundleOrderItemReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.BundleID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.Bundle{}, "id", consts.ModelBundleNotFound)),
+		),
+		validation.Field(&model.Quantity,
+			validation.Required.Error(consts.Required),
+			validation.Min(1).Error(consts.InvalidQuantity),
+		),
+	)
+}
+
+type OrderItemComponentOutPutModel struct {
+	OrderItemID   uuid.UUID `gorm:"column:order_item_id"     json:"-"`
+	ProductItemID uuid.UUID `gorm:"column:product_item_id"   json:"productItemId"`
+	ProductName   string    `gorm:"column:product_name"      json:"productName"`
+	Quantity      int       `gorm:"column:quantity"          json:"quantity"`
+	ListPrice     float64   `gorm:"column:list_price"        json:"listPrice"`
+	Price         float64   `gorm:"column:price"             json:"price"`
+}
+
+type AdminOrderItemOutPutModel struct {
+	ID            uuid.UUID                       `gorm:"column:id"                json:"id"`
+	ProductItemID *uuid.UUID                      `gorm:"column:product_item_id"   json:"productItemId"`
+	BundleID      *uuid.UUID                      `gorm:"column:bundle_id"         json:"bundleId"`
+	Name          string                          `gorm:"column:name"              json:"name"`
+	Quantity      int                             `gorm:"column:quantity"          json:"quantity"`
+	Price         float64                         `gorm:"column:price"             json:"price"`
+	TotalPrice    float64                         `gorm:"column:total_price"       json:"totalPrice"`
+	Components    []OrderItemComponentOutPutModel `gorm:"-"                        json:"components"`
+}
@@ -13,6 +13,9 @@ import (
 type CategoryReqModel struct {
 	Name string `json:"name"`
 	Code string `json:"code"`
+
+	// the reorder threshold of the items which do not have their own threshold
+	ReorderThreshold *int `json:"reorderThreshold"`
 }
 
 func (model CategoryReqModel) ValidateCreate() error {
@@ -27,6 +30,7 @@ func (model CategoryReqModel) ValidateCreate() error {
 			validation.By(validations.Code()),
 			validation.By(validations.NotExistsInDB(&dbmodels.Category{}, "code", consts.ExistedCode)),
 		),
+		validation.Field(&model.ReorderThreshold, validation.Min(0).Error(consts.MinIsZero)),
 	)
 }
 
@@ -42,6 +46,7 @@ func (model CategoryReqModel) ValidateUpdate(id uuid.UUID) error {
 			validation.By(validations.Code()),
 			validation.By(validations.NotExistsInDBWithID(&dbmodels.Category{}, "code", id, consts.ExistedCode)),
 		),
+		validation.Field(&model.ReorderThreshold, validation.Min(0).Error(consts.MinIsZero)),
 	)
 }
 
@@ -50,20 +55,23 @@ func (model CategoryReqModel) ToDBModel() *dbmodels.Category {
 		Model: dbmodels.Model{
 			ID: dbmodels.NewID(),
 		},
-		Name: model.Name,
-		Code: model.Code,
+		Name:             model.Name,
+		Code:             model.Code,
+		ReorderThreshold: model.ReorderThreshold,
 	}
 }
 
 func (model CategoryReqModel) MergeWithDBData(dbmodel *dbmodels.Category) {
 	dbmodel.Name = model.Name
 	dbmodel.Code = model.Code
+	dbmodel.ReorderThreshold = model.ReorderThreshold
 }
 
 type CategoryOutPutModel struct {
-	ID        *uuid.UUID `gorm:"column:id"         json:"id"`
-	CreatedAt time.Time  `gorm:"column:created_at" json:"createdAt"`
-	UpdatedAt time.Time  `gorm:"column:updated_at" json:"updatedAt"`
-	Name      string     `gorm:"column:name"       json:"name"`
-	Code      string     `gorm:"column:code"       json:"code"`
+	ID               *uuid.UUID `gorm:"column:id"         json:"id"`
+	CreatedAt        time.Time  `gorm:"column:created_at" json:"createdAt"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at" json:"updatedAt"`
+	Name             string     `gorm:"column:name"       json:"name"`
+	Code             string     `gorm:"column:code"       json:"code"`
+	ReorderThreshold *int       `gorm:"column:reorder_threshold" json:"reorderThreshold"`
 }
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/parameter"
 	"github.com/esmailemami/eshop/app/validations"
 	dbmodels "github.com/esmailemami/eshop/models"
 	datatypes "github.com/esmailemami/eshop/models/data_types"
@@ -84,6 +85,12 @@ type ProductCommentOutPutModel struct {
 	StrengthPoints datatypes.StringArray `gorm:"column:strength_points" json:"strengthPoints"`
 	WeakPonits     datatypes.StringArray `gorm:"column:weak_ponits"     json:"weakPonits"`
 	Username       string                `gorm:"column:username"        json:"username"`
+
+	IsVerifiedPurchase bool `gorm:"column:is_verified_purchase" json:"isVerifiedPurchase"`
+	HelpfulVotes       int  `gorm:"column:helpful_votes"        json:"helpfulVotes"`
+	UnhelpfulVotes     int  `gorm:"column:unhelpful_votes"      json:"unhelpfulVotes"`
+
+	Photos []ReviewPhotoOutPutModel `gorm:"-" json:"photos"`
 }
 
 type AdminCommentOutPutModel struct {
@@ -99,6 +106,10 @@ type AdminCommentOutPutModel struct {
 	ProductName    string                 `gorm:"product_name"           json:"productName"`
 	CommentStatus  dbmodels.CommentStatus `gorm:"comment_status"         json:"commentStatus"`
 	AdminNote      *string                `gorm:"admin_note"                            json:"adminNote"`
+
+	IsVerifiedPurchase bool `gorm:"column:is_verified_purchase" json:"isVerifiedPurchase"`
+	HelpfulVotes       int  `gorm:"column:helpful_votes"        json:"helpfulVotes"`
+	UnhelpfulVotes     int  `gorm:"column:unhelpful_votes"      json:"unhelpfulVotes"`
 }
 
 type UserCommentOutPutModel struct {
@@ -113,6 +124,10 @@ type UserCommentOutPutModel struct {
 	ProductName    string                 `gorm:"product_name"           json:"productName"`
 	CommentStatus  dbmodels.CommentStatus `gorm:"comment_status"         json:"commentStatus"`
 	AdminNote      *string                `gorm:"admin_note"                            json:"adminNote"`
+
+	IsVerifiedPurchase bool `gorm:"column:is_verified_purchase" json:"isVerifiedPurchase"`
+	HelpfulVotes       int  `gorm:"column:helpful_votes"        json:"helpfulVotes"`
+	UnhelpfulVotes     int  `gorm:"column:unhelpful_votes"      json:"unhelpfulVotes"`
 }
 
 type ChangeCommentStatus struct {
@@ -124,3 +139,51 @@ func (model *ChangeCommentStatus) MergeWithDBData(dbmodel *dbmodels.Comment) {
 	dbmodel.Status = model.Status
 	dbmodel.AdminNote = model.Note
 }
+
+type ReviewPhotoOutPutModel struct {
+	ID             *uuid.UUID        `gorm:"column:id"               json:"id"`
+	CommentID      uuid.UUID         `gorm:"column:comment_id"       json:"commentId"`
+	OriginalName   string            `gorm:"column:original_name"    json:"originalName"`
+	UniqueFileName string            `gorm:"column:unique_file_name" json:"uniqueFineName"`
+	FileType       dbmodels.FileType `gorm:"column:file_type"        json:"fileType"`
+	Priority       int               `gorm:"column:priority"         json:"priority"`
+	FileUrl        string            `gorm:"-"                       json:"fileUrl"`
+}
+
+type CommentVoteReqModel struct {
+	Helpful *bool `json:"helpful"`
+}
+
+func (model CommentVoteReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Helpful,
+			validation.NotNil.Error(consts.Required),
+		),
+	)
+}
+
+type CommentVoteOutPutModel struct {
+	// Helpful is nil when the vote of the user is removed
+	Helpful        *bool `json:"helpful"`
+	HelpfulVotes   int   `json:"helpfulVotes"`
+	UnhelpfulVotes int   `json:"unhelpfulVotes"`
+}
+
+type RatingBucketOutPutModel struct {
+	Rate    int     `json:"rate"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+type RatingSummaryOutPutModel struct {
+	Rate      float64                   `json:"rate"`
+	Count     int                       `json:"count"`
+	Histogram []RatingBucketOutPutModel `json:"histogram"`
+}
+
+type ProductCommentsOutPutModel struct {
+	parameter.ListResponse[ProductCommentOutPutModel]
+
+	Rating RatingSummaryOutPutModel `json:"rating"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// MfaPendingOutputModel is returned by the login instead of the tokens when the second factor is needed
+type MfaPendingOutputModel struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	ExpiresIn int64     `json:"expiresIn"`
+	// EnrollmentRequired means the role requires the 2FA but the user has not enrolled it yet
+	EnrollmentRequired bool `json:"enrollmentRequired"`
+}
+
+type MfaLoginInputModel struct {
+	Token     string `json:"token"`
+	Code      string `json:"code"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (model MfaLoginInputModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(
+			&model.Token,
+			validation.Required.Error(consts.Required),
+		),
+		validation.Field(
+			&model.Code,
+			validation.Required.Error(consts.Required),
+		),
+	)
+}
+
+type MfaTokenInputModel struct {
+	Token string `json:"token"`
+}
+
+func (model MfaTokenInputModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(
+			&model.Token,
+			validation.Required.Error(consts.Required),
+		),
+	)
+}
+
+type MfaCodeReqModel struct {
+	Code string `json:"code"`
+}
+
+func (model MfaCodeReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Code,
+			validation.Required.Error(consts.Required),
+		),
+	)
+}
+
+type MfaEnrollmentOutPutModel struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+	QRCode string `json:"qrCode"`
+}
+
+type MfaStatusOutPutModel struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recoveryCodesLeft"`
+}
+
+type MfaRecoveryCodesOutPutModel struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
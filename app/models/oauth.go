@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type OAuthStartOutputModel struct {
+	URL       string    `json:"url"`
+	State     string    `json:"state"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// OAuthCallbackInputModel is the query of the redirect uri of the provider which is sent by the client
+type OAuthCallbackInputModel struct {
+	Code      string `json:"code"`
+	State     string `json:"state"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (model OAuthCallbackInputModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(
+			&model.Code,
+			validation.Required.Error(consts.Required),
+		),
+		validation.Field(
+			&model.State,
+			validation.Required.Error(consts.Required),
+		),
+	)
+}
+
+type UserIdentityOutPutModel struct {
+	ID        uuid.UUID `gorm:"column:id"         json:"id"`
+	Provider  string    `gorm:"column:provider"   json:"provider"`
+	Email     *string   `gorm:"column:email"      json:"email"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
+}
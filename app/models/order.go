@@ -10,6 +10,9 @@ import (
 type OrderOutPutModel struct {
 	Price float64                `gorm:"-" json:"price"`
 	Items []OrderItemOutPutModel `gorm:"-" json:"items"`
+
+	// the related products of the items which are not in the cart
+	Relations *RelatedProductsOutPutModel `gorm:"-" json:"relations"`
 }
 
 type AdminOrderOutPutModel struct {
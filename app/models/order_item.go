@@ -46,7 +46,7 @@ func (model *OrderItemReqModel) ToDBModel() *dbmodels.OrderItem {
 		Model: dbmodels.Model{
 			ID: dbmodels.NewID(),
 		},
-		ProductItemID: model.ProductItemID,
+		ProductItemID: &model.ProductItemID,
 		OrderID:       model.OrderID,
 		Quantity:      model.Quantity,
 		Price:         model.Price,
@@ -54,7 +54,9 @@ func (model *OrderItemReqModel) ToDBModel() *dbmodels.OrderItem {
 }
 
 func (model OrderItemReqModel) MergeWithDBData(dbmodel *dbmodels.OrderItem) {
-	dbmodel.ProductItemID = model.ProductItemID
+	productItemID := model.ProductItemID
+	dbmodel.ProductItemID = &productItemID
+	dbmodel.BundleID = nil
 	dbmodel.OrderID = model.OrderID
 	dbmodel.Quantity = model.Quantity
 	dbmodel.Price = model.Price
@@ -62,7 +64,9 @@ func (model OrderItemReqModel) MergeWithDBData(dbmodel *dbmodels.OrderItem) {
 
 type OrderItemOutPutModel struct {
 	ID               uuid.UUID              `gorm:"column:id"                      json:"id"`
-	ProductItemID    uuid.UUID              `gorm:"column:product_item_id"         json:"productItemId"`
+	ProductItemID    *uuid.UUID             `gorm:"column:product_item_id"         json:"productItemId"`
+	ProductID        *uuid.UUID             `gorm:"column:product_id"              json:"productId"`
+	BundleID         *uuid.UUID             `gorm:"column:bundle_id"               json:"bundleId"`
 	ProductName      string                 `gorm:"column:product_name"            json:"productName"`
 	Price            float64                `gorm:"column:price"                   json:"price"`
 	Quantity         int                    `gorm:"column:quantity"                json:"quantity"`
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"time"
+
 	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/product_revision"
 	"github.com/esmailemami/eshop/app/validations"
 	dbmodels "github.com/esmailemami/eshop/models"
 	datatypes "github.com/esmailemami/eshop/models/data_types"
@@ -11,13 +14,16 @@ import (
 )
 
 type ProductReqModel struct {
-	Name             string                `json:"name"`
-	Code             string                `json:"code"`
-	BrandID          uuid.UUID             `json:"brandId"`
-	CategoryID       uuid.UUID             `json:"categoryId"`
-	Description      string                `json:"description"`
-	ShortDescription string                `json:"shortDescription"`
-	TopFeatures      datatypes.StringArray `json:"topFeatures"`
+	Name             string                 `json:"name"`
+	Code             string                 `json:"code"`
+	BrandID          uuid.UUID              `json:"brandId"`
+	CategoryID       uuid.UUID              `json:"categoryId"`
+	Description      string                 `json:"description"`
+	ShortDescription string                 `json:"shortDescription"`
+	TopFeatures      datatypes.StringArray  `json:"topFeatures"`
+	Status           dbmodels.ProductStatus `json:"status"`
+	PublishAt        *time.Time             `json:"publishAt"`
+	UnpublishAt      *time.Time             `json:"unpublishAt"`
 }
 
 func (model ProductReqModel) ValidateCreate(db *gorm.DB) error {
@@ -38,20 +44,26 @@ func (model ProductReqModel) ValidateCreate(db *gorm.DB) error {
 		validation.Field(&model.Code,
 			validation.Required.Error(consts.Required),
 			validation.By(validations.Code()),
-			validation.By(validations.NotExistsInDB(&dbmodels.Product{}, "code", consts.ExistedCode)),
+			validation.By(validations.NotExistsInTx(db, &dbmodels.Product{}, "code", consts.ExistedCode)),
 		),
 		validation.Field(&model.BrandID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Brand{}, "id", consts.ModelBrandNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Brand{}, "id", consts.ModelBrandNotFound)),
 		),
 		validation.Field(&model.CategoryID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Category{}, "id", consts.ModelCategoryNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Category{}, "id", consts.ModelCategoryNotFound)),
+		),
+		validation.Field(&model.Status,
+			validation.In(dbmodels.ProductStatusPublish, dbmodels.ProductStatusInActive).Error(consts.InvalidProductStatus),
+		),
+		validation.Field(&model.UnpublishAt,
+			validation.By(validations.TimeAfter(model.PublishAt, consts.UnpublishBeforePublish)),
 		),
 	)
 }
 
-func (model ProductReqModel) ValidateUpdate(id uuid.UUID) error {
+func (model ProductReqModel) ValidateUpdate(db *gorm.DB, id uuid.UUID) error {
 	return validation.ValidateStruct(
 		&model,
 		validation.Field(&model.Name,
@@ -69,15 +81,21 @@ func (model ProductReqModel) ValidateUpdate(id uuid.UUID) error {
 		validation.Field(&model.Code,
 			validation.Required.Error(consts.Required),
 			validation.By(validations.Code()),
-			validation.By(validations.NotExistsInDBWithID(&dbmodels.Product{}, "code", id, consts.ExistedCode)),
+			validation.By(validations.NotExistsInTxWithID(db, &dbmodels.Product{}, "code", id, consts.ExistedCode)),
 		),
 		validation.Field(&model.BrandID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Brand{}, "id", consts.ModelBrandNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Brand{}, "id", consts.ModelBrandNotFound)),
 		),
 		validation.Field(&model.CategoryID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Category{}, "id", consts.ModelCategoryNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Category{}, "id", consts.ModelCategoryNotFound)),
+		),
+		validation.Field(&model.Status,
+			validation.In(dbmodels.ProductStatusPublish, dbmodels.ProductStatusInActive).Error(consts.InvalidProductStatus),
+		),
+		validation.Field(&model.UnpublishAt,
+			validation.By(validations.TimeAfter(model.PublishAt, consts.UnpublishBeforePublish)),
 		),
 	)
 }
@@ -94,17 +112,28 @@ func (model *ProductReqModel) ToDBModel() *dbmodels.Product {
 		ShortDescription: model.ShortDescription,
 		Description:      model.Description,
 		TopFeatures:      model.TopFeatures,
+		Status:           model.Status,
+		PublishAt:        model.PublishAt,
+		UnpublishAt:      model.UnpublishAt,
 	}
 }
 
+// MergeWithDBData merges the fields which are not reviewed, the content is changed by the revisions
 func (model *ProductReqModel) MergeWithDBData(dbmodel *dbmodels.Product) {
-	dbmodel.Name = model.Name
 	dbmodel.Code = model.Code
 	dbmodel.BrandID = model.BrandID
 	dbmodel.CategoryID = model.CategoryID
-	dbmodel.ShortDescription = model.ShortDescription
-	dbmodel.Description = model.Description
-	dbmodel.TopFeatures = model.TopFeatures
+	dbmodel.Status = model.Status
+	dbmodel.PublishAt = model.PublishAt
+	dbmodel.UnpublishAt = model.UnpublishAt
+}
+
+// MergeWithContent merges the reviewable fields into the content of the product
+func (model *ProductReqModel) MergeWithContent(content *product_revision.Content) {
+	content.Name = model.Name
+	content.ShortDescription = model.ShortDescription
+	content.Description = model.Description
+	content.TopFeatures = model.TopFeatures
 }
 
 type ProductWithItemOutPutModel struct {
@@ -140,6 +169,8 @@ type ProductOutPutModel struct {
 	BrandFileName string            `gorm:"column:brand_file_name"  json:"-"`
 	BrandFileUrl  string            `gorm:"column:brand_file_url"   json:"brandFileUrl"`
 	Rate          float64           `gorm:"column:rate"             json:"rate"`
+
+	Relations *RelatedProductsOutPutModel `gorm:"-" json:"relations"`
 }
 
 type SuggestionProductOutPutModel struct {
@@ -154,16 +185,20 @@ type SuggestionProductOutPutModel struct {
 }
 
 type ProductAdminOutPutModel struct {
-	ID               *uuid.UUID            `gorm:"column:id"                              json:"id"`
-	Name             string                `gorm:"column:name"                            json:"name"`
-	Code             string                `gorm:"column:code"                            json:"code"`
-	BrandID          uuid.UUID             `gorm:"column:brand_id"         json:"brandId"`
-	BrandName        string                `gorm:"column:brand_name"       json:"brandName"`
-	CategoryID       uuid.UUID             `gorm:"column:category_id"      json:"categoryId"`
-	CategoryName     string                `gorm:"column:category_name"    json:"categoryName"`
-	Description      string                `gorm:"column:description"                     json:"description"`
-	ShortDescription string                `gorm:"column:short_description"               json:"shortDescription"`
-	TopFeatures      datatypes.StringArray `gorm:"column:top_features"                    json:"topFeatures"`
+	ID               *uuid.UUID             `gorm:"column:id"                              json:"id"`
+	Name             string                 `gorm:"column:name"                            json:"name"`
+	Code             string                 `gorm:"column:code"                            json:"code"`
+	BrandID          uuid.UUID              `gorm:"column:brand_id"         json:"brandId"`
+	BrandName        string                 `gorm:"column:brand_name"       json:"brandName"`
+	CategoryID       uuid.UUID              `gorm:"column:category_id"      json:"categoryId"`
+	CategoryName     string                 `gorm:"column:category_name"    json:"categoryName"`
+	Description      string                 `gorm:"column:description"                     json:"description"`
+	ShortDescription string                 `gorm:"column:short_description"               json:"shortDescription"`
+	TopFeatures      datatypes.StringArray  `gorm:"column:top_features"                    json:"topFeatures"`
+	Status           dbmodels.ProductStatus `gorm:"column:status"                          json:"status"`
+	PublishAt        *time.Time             `gorm:"column:publish_at"                      json:"publishAt"`
+	UnpublishAt      *time.Time             `gorm:"column:unpublish_at"                    json:"unpublishAt"`
+	ReviewedAt       *time.Time             `gorm:"column:reviewed_at"                     json:"reviewedAt"`
 }
 
 type ProductAdminSelectListOutPutModel struct {
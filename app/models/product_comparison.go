@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/product_compare"
+	"github.com/esmailemami/eshop/app/validations"
+	dbmodels "github.com/esmailemami/eshop/models"
+	datatypes "github.com/esmailemami/eshop/models/data_types"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type ProductComparisonReqModel struct {
+	Name       string      `json:"name"`
+	ProductIDs []uuid.UUID `json:"productIds"`
+}
+
+func (model ProductComparisonReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Name,
+			validation.Required.Error(consts.Required),
+			validation.Length(1, 200).Error(consts.InvalidValue),
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.ProductIDs,
+			validation.Required.Error(consts.Required),
+			validation.Length(product_compare.MinProducts, product_compare.MaxProducts).Error(consts.InvalidComparedProductsCount),
+			validation.Each(validation.By(validations.ExistsInDB(&dbmodels.Product{}, "id", consts.ModelProductNotFound))),
+		),
+	)
+}
+
+func (model ProductComparisonReqModel) ToDBModel() *dbmodels.ProductComparison {
+	dbModel := &dbmodels.ProductComparison{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+	}
+
+	model.MergeWithDBData(dbModel)
+
+	return dbModel
+}
+
+func (model ProductComparisonReqModel) MergeWithDBData(dbmodel *dbmodels.ProductComparison) {
+	productIDs := make(datatypes.StringArray, len(model.ProductIDs))
+	for i, id := range model.ProductIDs {
+		productIDs[i] = id.String()
+	}
+
+	dbmodel.Name = model.Name
+	dbmodel.ProductIDs = productIDs
+}
+
+type ProductComparisonListOutPutModel struct {
+	ID         *uuid.UUID            `gorm:"column:id"            json:"id"`
+	Name       string                `gorm:"column:name"          json:"name"`
+	ProductIDs datatypes.StringArray `gorm:"column:product_ids"   json:"productIds"`
+	CreatedAt  time.Time             `gorm:"column:created_at"    json:"createdAt"`
+	UpdatedAt  time.Time             `gorm:"column:updated_at"    json:"updatedAt"`
+}
+
+type ComparedProductOutPutModel struct {
+	ProductID     uuid.UUID          `gorm:"column:product_id"        json:"productId"`
+	Name          string             `gorm:"column:name"              json:"name"`
+	Rate          float64            `gorm:"column:rate"              json:"rate"`
+	BrandName     string             `gorm:"column:brand_name"        json:"brandName"`
+	ProductItemID *uuid.UUID         `gorm:"column:product_item_id"   json:"productItemId"`
+	Price         *float64           `gorm:"column:price"             json:"price"`
+	FileType      *dbmodels.FileType `gorm:"column:file_type"         json:"-"`
+	FileName      *string            `gorm:"column:file_name"         json:"-"`
+	FileUrl       string             `gorm:"-"                        json:"fileUrl"`
+}
+
+type ProductComparisonOutPutModel struct {
+	Products   []ComparedProductOutPutModel `json:"products"`
+	Categories []product_compare.Category   `json:"categories"`
+}
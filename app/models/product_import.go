@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	dbmodels "github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+type ProductImportOutPutModel struct {
+	ID            *uuid.UUID                   `gorm:"column:id"             json:"id"`
+	OriginalName  string                       `gorm:"column:original_name"  json:"originalName"`
+	Format        string                       `gorm:"column:format"         json:"format"`
+	DryRun        bool                         `gorm:"column:dry_run"        json:"dryRun"`
+	Status        dbmodels.ProductImportStatus `gorm:"column:status"         json:"status"`
+	TotalRows     int                          `gorm:"column:total_rows"     json:"totalRows"`
+	SucceededRows int                          `gorm:"column:succeeded_rows" json:"succeededRows"`
+	FailedRows    int                          `gorm:"column:failed_rows"    json:"failedRows"`
+	Message       *string                      `gorm:"column:message"        json:"message"`
+	CreatedAt     time.Time                    `gorm:"column:created_at"     json:"createdAt"`
+	StartedAt     *time.Time                   `gorm:"column:started_at"     json:"startedAt"`
+	FinishedAt    *time.Time                   `gorm:"column:finished_at"    json:"finishedAt"`
+	Errors        []ProductImportErrorModel    `gorm:"-"                     json:"errors,omitempty"`
+}
+
+type ProductImportErrorModel struct {
+	Row     int    `gorm:"column:row"     json:"row"`
+	Column  string `gorm:"column:column"  json:"column"`
+	Message string `gorm:"column:message" json:"message"`
+}
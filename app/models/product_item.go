@@ -1,24 +1,38 @@
 package models
 
 import (
+	"time"
+
 	"github.com/esmailemami/eshop/app/consts"
 	"github.com/esmailemami/eshop/app/validations"
 	"github.com/esmailemami/eshop/models"
 	dbmodels "github.com/esmailemami/eshop/models"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ProductItemReqModel struct {
-	Price      float64                `json:"price"`
-	Status     dbmodels.ProductStatus `json:"status"`
-	ColorID    uuid.UUID              `json:"colorId"`
-	ProductID  uuid.UUID              `json:"productId"`
-	Quantity   int                    `json:"quantity"`
-	IsMainItem bool                   `json:"isMainItem"`
+	Price       float64                `json:"price"`
+	Status      dbmodels.ProductStatus `json:"status"`
+	ColorID     uuid.UUID              `json:"colorId"`
+	ProductID   uuid.UUID              `json:"productId"`
+	Quantity    int                    `json:"quantity"`
+	IsMainItem  bool                   `json:"isMainItem"`
+	SKU         *string                `json:"sku"`
+	Barcode     *string                `json:"barcode"`
+	Weight      *float64               `json:"weight"`
+	Length      *float64               `json:"length"`
+	Width       *float64               `json:"width"`
+	Height      *float64               `json:"height"`
+	PublishAt   *time.Time             `json:"publishAt"`
+	UnpublishAt *time.Time             `json:"unpublishAt"`
+
+	// the item uses the threshold of its category when it is nil
+	ReorderThreshold *int `json:"reorderThreshold"`
 }
 
-func (model ProductItemReqModel) ValidateCreate() error {
+func (model ProductItemReqModel) ValidateCreate(db *gorm.DB) error {
 	return validation.ValidateStruct(
 		&model,
 		validation.Field(&model.Price,
@@ -27,16 +41,35 @@ func (model ProductItemReqModel) ValidateCreate() error {
 			validation.Required.Error(consts.Required)),
 		validation.Field(&model.ColorID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Color{}, "id", consts.ModelColorNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Color{}, "id", consts.ModelColorNotFound)),
 		),
 		validation.Field(&model.ProductID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Product{}, "id", consts.ModelProductNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Product{}, "id", consts.ModelProductNotFound)),
+		),
+		validation.Field(&model.SKU,
+			validation.By(validations.Code()),
+			validation.By(validations.NotExistsInTx(db, &dbmodels.ProductItem{}, "sku", consts.ExistedSKU)),
+		),
+		validation.Field(&model.Barcode,
+			validation.By(validations.GTIN()),
+			validation.By(validations.NotExistsInTx(db, &dbmodels.ProductItem{}, "barcode", consts.ExistedBarcode)),
+		),
+		validation.Field(&model.Weight, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Length, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Width, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Height, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Status,
+			validation.In(dbmodels.ProductStatusPublish, dbmodels.ProductStatusInActive).Error(consts.InvalidProductStatus),
+		),
+		validation.Field(&model.UnpublishAt,
+			validation.By(validations.TimeAfter(model.PublishAt, consts.UnpublishBeforePublish)),
 		),
+		validation.Field(&model.ReorderThreshold, validation.Min(0).Error(consts.MinIsZero)),
 	)
 }
 
-func (model ProductItemReqModel) ValidateUpdate() error {
+func (model ProductItemReqModel) ValidateUpdate(db *gorm.DB, id uuid.UUID) error {
 	return validation.ValidateStruct(
 		&model,
 		validation.Field(&model.Price,
@@ -45,12 +78,31 @@ func (model ProductItemReqModel) ValidateUpdate() error {
 			validation.Required.Error(consts.Required)),
 		validation.Field(&model.ColorID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Color{}, "id", consts.ModelColorNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Color{}, "id", consts.ModelColorNotFound)),
 		),
 		validation.Field(&model.ProductID,
 			validation.Required.Error(consts.Required),
-			validation.By(validations.ExistsInDB(&dbmodels.Product{}, "id", consts.ModelProductNotFound)),
+			validation.By(validations.ExistsInTx(db, &dbmodels.Product{}, "id", consts.ModelProductNotFound)),
 		),
+		validation.Field(&model.SKU,
+			validation.By(validations.Code()),
+			validation.By(validations.NotExistsInTxWithID(db, &dbmodels.ProductItem{}, "sku", id, consts.ExistedSKU)),
+		),
+		validation.Field(&model.Barcode,
+			validation.By(validations.GTIN()),
+			validation.By(validations.NotExistsInTxWithID(db, &dbmodels.ProductItem{}, "barcode", id, consts.ExistedBarcode)),
+		),
+		validation.Field(&model.Weight, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Length, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Width, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Height, validation.Min(0.0).Error(consts.MinIsZero)),
+		validation.Field(&model.Status,
+			validation.In(dbmodels.ProductStatusPublish, dbmodels.ProductStatusInActive).Error(consts.InvalidProductStatus),
+		),
+		validation.Field(&model.UnpublishAt,
+			validation.By(validations.TimeAfter(model.PublishAt, consts.UnpublishBeforePublish)),
+		),
+		validation.Field(&model.ReorderThreshold, validation.Min(0).Error(consts.MinIsZero)),
 	)
 }
 
@@ -59,12 +111,21 @@ func (model *ProductItemReqModel) ToDBModel() *dbmodels.ProductItem {
 		Model: dbmodels.Model{
 			ID: dbmodels.NewID(),
 		},
-		Price:          model.Price,
-		Status:         model.Status,
-		ColorID:        model.ColorID,
-		ProductID:      model.ProductID,
-		BoughtQuantity: 0,
-		Quantity:       model.Quantity,
+		Price:            model.Price,
+		Status:           model.Status,
+		ColorID:          model.ColorID,
+		ProductID:        model.ProductID,
+		BoughtQuantity:   0,
+		Quantity:         model.Quantity,
+		SKU:              model.SKU,
+		Barcode:          model.Barcode,
+		Weight:           model.Weight,
+		Length:           model.Length,
+		Width:            model.Width,
+		Height:           model.Height,
+		PublishAt:        model.PublishAt,
+		UnpublishAt:      model.UnpublishAt,
+		ReorderThreshold: model.ReorderThreshold,
 	}
 }
 
@@ -74,18 +135,45 @@ func (model ProductItemReqModel) MergeWithDBData(dbmodel *dbmodels.ProductItem)
 	dbmodel.ColorID = model.ColorID
 	dbmodel.ProductID = model.ProductID
 	dbmodel.Quantity = model.Quantity
+	dbmodel.Weight = model.Weight
+	dbmodel.Length = model.Length
+	dbmodel.Width = model.Width
+	dbmodel.Height = model.Height
+	dbmodel.PublishAt = model.PublishAt
+	dbmodel.UnpublishAt = model.UnpublishAt
+	dbmodel.ReorderThreshold = model.ReorderThreshold
+	dbmodel.SKU = model.SKU
+	dbmodel.Barcode = model.Barcode
+}
+
+// CodeChanged reports whether the codes of the barcode image are changed, it should be called before the merge
+func (model ProductItemReqModel) CodeChanged(dbmodel *dbmodels.ProductItem) bool {
+	return !equalStringPtr(dbmodel.Barcode, model.Barcode) || !equalStringPtr(dbmodel.SKU, model.SKU)
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
 }
 
 type ProductItemOutPutModel struct {
-	ID           *uuid.UUID             `gorm:"column:id"                        json:"id"`
-	Price        float64                `gorm:"column:price"                     json:"price"`
-	Status       dbmodels.ProductStatus `gorm:"column:status"                    json:"status"`
-	ColorID      uuid.UUID              `gorm:"column:color_id"                  json:"colorId"`
-	ColorName    string                 `gorm:"column:color_name"                json:"color"`
-	ProductID    uuid.UUID              `gorm:"column:product_id"                json:"productId"`
-	ProductTitle string                 `gorm:"column:product_title"             json:"productTitle"`
-	ProductCode  string                 `gorm:"column:product_code"              json:"productCode"`
-	Quantity     int                    `gorm:"column:quantity"                  json:"quantity"`
+	ID               *uuid.UUID             `gorm:"column:id"                        json:"id"`
+	Price            float64                `gorm:"column:price"                     json:"price"`
+	Status           dbmodels.ProductStatus `gorm:"column:status"                    json:"status"`
+	ColorID          uuid.UUID              `gorm:"column:color_id"                  json:"colorId"`
+	ColorName        string                 `gorm:"column:color_name"                json:"color"`
+	ProductID        uuid.UUID              `gorm:"column:product_id"                json:"productId"`
+	ProductTitle     string                 `gorm:"column:product_title"             json:"productTitle"`
+	ProductCode      string                 `gorm:"column:product_code"              json:"productCode"`
+	Quantity         int                    `gorm:"column:quantity"                  json:"quantity"`
+	SKU              *string                `gorm:"column:sku"                       json:"sku"`
+	Barcode          *string                `gorm:"column:barcode"                   json:"barcode"`
+	PublishAt        *time.Time             `gorm:"column:publish_at"                json:"publishAt"`
+	UnpublishAt      *time.Time             `gorm:"column:unpublish_at"              json:"unpublishAt"`
+	ReorderThreshold *int                   `gorm:"column:reorder_threshold"         json:"reorderThreshold"`
 }
 
 type ProductItemInfoOutPutModel struct {
@@ -107,6 +195,15 @@ type ProductItemInfoOutPutModel struct {
 	Features                []ProductItemCategoryFeatureModel `gorm:"-"                                json:"features"`
 	Colors                  []ProductItemInfoColorOutPutModel `gorm:"-"                                json:"colors"`
 	Rate                    float64                           `gorm:"column:rate"                      json:"rate"`
+	SKU                     *string                           `gorm:"column:sku"                       json:"sku"`
+	Barcode                 *string                           `gorm:"column:barcode"                   json:"barcode"`
+	Weight                  *float64                          `gorm:"column:weight"                    json:"weight"`
+	Length                  *float64                          `gorm:"column:length"                    json:"length"`
+	Width                   *float64                          `gorm:"column:width"                     json:"width"`
+	Height                  *float64                          `gorm:"column:height"                    json:"height"`
+
+	// the lowest price of the last 30 days which the discounts are compared with
+	LowestPrice float64 `gorm:"-"                                json:"lowestPrice"`
 }
 
 type ProductItemCategoryFeatureModel struct {
@@ -139,3 +236,26 @@ type ProductItemAdminSelectListOutPutModel struct {
 	ColorID   uuid.UUID  `gorm:"column:color_id"                  json:"colorId"`
 	ColorName string     `gorm:"column:color_name"                json:"color"`
 }
+
+type ProductItemLookupOutPutModel struct {
+	ID           *uuid.UUID             `gorm:"column:id"            json:"id"`
+	Price        float64                `gorm:"column:price"         json:"price"`
+	Status       dbmodels.ProductStatus `gorm:"column:status"        json:"status"`
+	ColorName    string                 `gorm:"column:color_name"    json:"color"`
+	ProductID    uuid.UUID              `gorm:"column:product_id"    json:"productId"`
+	ProductTitle string                 `gorm:"column:product_title" json:"productTitle"`
+	ProductCode  string                 `gorm:"column:product_code"  json:"productCode"`
+	Quantity     int                    `gorm:"column:quantity"      json:"quantity"`
+	SKU          *string                `gorm:"column:sku"           json:"sku"`
+	Barcode      *string                `gorm:"column:barcode"       json:"barcode"`
+	Weight       *float64               `gorm:"column:weight"        json:"weight"`
+	Length       *float64               `gorm:"column:length"        json:"length"`
+	Width        *float64               `gorm:"column:width"         json:"width"`
+	Height       *float64               `gorm:"column:height"        json:"height"`
+}
+
+type ProductItemBarcodeOutPutModel struct {
+	FileID  uuid.UUID `json:"fileId"`
+	Code    string    `json:"code"`
+	FileUrl string    `json:"fileUrl"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/validations"
+	dbmodels "github.com/esmailemami/eshop/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type ProductItemPriceScheduleReqModel struct {
+	ProductItemID uuid.UUID `json:"-"`
+	Price         float64   `json:"price"`
+	ApplyAt       time.Time `json:"applyAt"`
+}
+
+func (model ProductItemPriceScheduleReqModel) ValidateCreate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Price,
+			validation.Required.Error(consts.Required),
+			validation.Min(0.0).Error(consts.MinIsZero),
+		),
+		validation.Field(&model.ApplyAt,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.TimeGreaterThanNow()),
+		),
+	)
+}
+
+func (model ProductItemPriceScheduleReqModel) ToDBModel() *dbmodels.ProductItemPriceSchedule {
+	return &dbmodels.ProductItemPriceSchedule{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+		ProductItemID: model.ProductItemID,
+		Price:         model.Price,
+		ApplyAt:       model.ApplyAt,
+		Status:        dbmodels.ProductItemPriceSchedulePending,
+	}
+}
+
+type ProductItemPriceScheduleOutPutModel struct {
+	ID        *uuid.UUID                              `gorm:"column:id"            json:"id"`
+	CreatedAt time.Time                               `gorm:"column:created_at"    json:"createdAt"`
+	CreatedBy *string                                 `gorm:"column:created_by"    json:"createdBy"`
+	Price     float64                                 `gorm:"column:price"         json:"price"`
+	ApplyAt   time.Time                               `gorm:"column:apply_at"      json:"applyAt"`
+	Status    dbmodels.ProductItemPriceScheduleStatus `gorm:"column:status"        json:"status"`
+	AppliedAt *time.Time                              `gorm:"column:applied_at"    json:"appliedAt"`
+}
+
+type ProductItemPriceOutPutModel struct {
+	ID            *uuid.UUID                 `gorm:"column:id"              json:"id"`
+	CreatedAt     time.Time                  `gorm:"column:created_at"      json:"createdAt"`
+	CreatedBy     *string                    `gorm:"column:created_by"      json:"createdBy"`
+	Price         float64                    `gorm:"column:price"           json:"price"`
+	PreviousPrice *float64                   `gorm:"column:previous_price"  json:"previousPrice"`
+	Source        dbmodels.PriceChangeSource `gorm:"column:source"          json:"source"`
+	ScheduleID    *uuid.UUID                 `gorm:"column:schedule_id"     json:"scheduleId"`
+}
+
+type PricePointOutPutModel struct {
+	Price     float64   `gorm:"column:price"        json:"price"`
+	ChangedAt time.Time `gorm:"column:changed_at"   json:"changedAt"`
+}
+
+type ProductItemPriceHistoryOutPutModel struct {
+	ProductItemID uuid.UUID               `json:"productItemId"`
+	Price         float64                 `json:"price"`
+	LowestPrice   float64                 `json:"lowestPrice"`
+	LowestDays    int                     `json:"lowestDays"`
+	Points        []PricePointOutPutModel `json:"points"`
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/validations"
+	dbmodels "github.com/esmailemami/eshop/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type ProductQuestionReqModel struct {
+	ProductID uuid.UUID `json:"productId"`
+	Text      string    `json:"text"`
+}
+
+func (model ProductQuestionReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.ProductID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.Product{}, "id", consts.ModelProductNotFound)),
+		),
+		validation.Field(&model.Text,
+			validation.Required.Error(consts.Required),
+			validation.Length(1, 1000).Error(consts.InvalidValue),
+			validation.By(validations.ClearText()),
+		),
+	)
+}
+
+func (model ProductQuestionReqModel) ToDBModel() *dbmodels.ProductQuestion {
+	return &dbmodels.ProductQuestion{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+		ProductID: model.ProductID,
+		Text:      model.Text,
+		Status:    dbmodels.CommentStatusPending,
+	}
+}
+
+type ProductAnswerReqModel struct {
+	QuestionID uuid.UUID `json:"questionId"`
+	Text       string    `json:"text"`
+}
+
+func (model ProductAnswerReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.QuestionID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.ProductQuestion{}, "id", consts.ModelProductQuestionNotFound)),
+		),
+		validation.Field(&model.Text,
+			validation.Required.Error(consts.Required),
+			validation.Length(1, 2000).Error(consts.InvalidValue),
+			validation.By(validations.ClearText()),
+		),
+	)
+}
+
+// ToDBModel returns the answer of a customer which is pending until it is moderated
+func (model ProductAnswerReqModel) ToDBModel(isVerifiedBuyer bool) *dbmodels.ProductAnswer {
+	return &dbmodels.ProductAnswer{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+		QuestionID:      model.QuestionID,
+		Text:            model.Text,
+		Status:          dbmodels.CommentStatusPending,
+		IsVerifiedBuyer: isVerifiedBuyer,
+	}
+}
+
+// ToStaffDBModel returns the answer of the staff which is accepted at once
+func (model ProductAnswerReqModel) ToStaffDBModel() *dbmodels.ProductAnswer {
+	return &dbmodels.ProductAnswer{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+		QuestionID: model.QuestionID,
+		Text:       model.Text,
+		Status:     dbmodels.CommntStatusAccept,
+		IsStaff:    true,
+	}
+}
+
+type ChangeProductQAStatusReqModel struct {
+	Status dbmodels.CommentStatus `json:"status"`
+	Note   *string                `json:"note,omitempty"`
+}
+
+func (model ChangeProductQAStatusReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Status,
+			validation.In(dbmodels.CommentStatusPending, dbmodels.CommntStatusAccept, dbmodels.CommentStatusReject).Error(consts.InvalidValue),
+		),
+		validation.Field(&model.Note,
+			validation.By(validations.ClearText()),
+		),
+	)
+}
+
+type ProductQuestionOutPutModel struct {
+	ID        *uuid.UUID                 `gorm:"column:id"           json:"id"`
+	CreatedAt time.Time                  `gorm:"column:created_at"   json:"createdAt"`
+	Text      string                     `gorm:"column:text"         json:"text"`
+	Username  string                     `gorm:"column:username"     json:"username"`
+	Upvotes   int                        `gorm:"column:upvotes"      json:"upvotes"`
+	Answers   []ProductAnswerOutPutModel `gorm:"-"                   json:"answers"`
+}
+
+type ProductAnswerOutPutModel struct {
+	ID              *uuid.UUID `gorm:"column:id"                  json:"id"`
+	QuestionID      uuid.UUID  `gorm:"column:question_id"         json:"-"`
+	CreatedAt       time.Time  `gorm:"column:created_at"          json:"createdAt"`
+	Text            string     `gorm:"column:text"                json:"text"`
+	Username        string     `gorm:"column:username"            json:"username"`
+	Upvotes         int        `gorm:"column:upvotes"             json:"upvotes"`
+	IsStaff         bool       `gorm:"column:is_staff"            json:"isStaff"`
+	IsVerifiedBuyer bool       `gorm:"column:is_verified_buyer"   json:"isVerifiedBuyer"`
+}
+
+type AdminProductQuestionOutPutModel struct {
+	ID           *uuid.UUID             `gorm:"column:id"              json:"id"`
+	CreatedAt    time.Time              `gorm:"column:created_at"      json:"createdAt"`
+	Text         string                 `gorm:"column:text"            json:"text"`
+	Username     string                 `gorm:"column:username"        json:"username"`
+	ProductID    uuid.UUID              `gorm:"column:product_id"      json:"productId"`
+	ProductName  string                 `gorm:"column:product_name"    json:"productName"`
+	Status       dbmodels.CommentStatus `gorm:"column:status"          json:"status"`
+	AdminNote    *string                `gorm:"column:admin_note"      json:"adminNote"`
+	Upvotes      int                    `gorm:"column:upvotes"         json:"upvotes"`
+	AnswersCount int                    `gorm:"column:answers_count"   json:"answersCount"`
+}
+
+type AdminProductAnswerOutPutModel struct {
+	ID              *uuid.UUID             `gorm:"column:id"                  json:"id"`
+	CreatedAt       time.Time              `gorm:"column:created_at"          json:"createdAt"`
+	QuestionID      uuid.UUID              `gorm:"column:question_id"         json:"questionId"`
+	Question        string                 `gorm:"column:question"            json:"question"`
+	ProductName     string                 `gorm:"column:product_name"        json:"productName"`
+	Text            string                 `gorm:"column:text"                json:"text"`
+	Username        string                 `gorm:"column:username"            json:"username"`
+	Status          dbmodels.CommentStatus `gorm:"column:status"              json:"status"`
+	AdminNote       *string                `gorm:"column:admin_note"          json:"adminNote"`
+	Upvotes         int                    `gorm:"column:upvotes"             json:"upvotes"`
+	IsStaff         bool                   `gorm:"column:is_staff"            json:"isStaff"`
+	IsVerifiedBuyer bool                   `gorm:"column:is_verified_buyer"   json:"isVerifiedBuyer"`
+}
+
+type UserProductQuestionOutPutModel struct {
+	ID           *uuid.UUID             `gorm:"column:id"              json:"id"`
+	CreatedAt    time.Time              `gorm:"column:created_at"      json:"createdAt"`
+	Text         string                 `gorm:"column:text"            json:"text"`
+	ProductID    uuid.UUID              `gorm:"column:product_id"      json:"productId"`
+	ProductName  string                 `gorm:"column:product_name"    json:"productName"`
+	Status       dbmodels.CommentStatus `gorm:"column:status"          json:"status"`
+	AdminNote    *string                `gorm:"column:admin_note"      json:"adminNote"`
+	Upvotes      int                    `gorm:"column:upvotes"         json:"upvotes"`
+	AnswersCount int                    `gorm:"column:answers_count"   json:"answersCount"`
+}
+
+type ProductQAVoteOutPutModel struct {
+	Upvoted bool `json:"upvoted"`
+	Upvotes int  `json:"upvotes"`
+}
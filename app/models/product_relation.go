@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/validations"
+	dbmodels "github.com/esmailemami/eshop/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type ProductRelationReqModel struct {
+	ProductID        uuid.UUID                    `json:"-"`
+	RelatedProductID uuid.UUID                    `json:"relatedProductId"`
+	Type             dbmodels.ProductRelationType `json:"type"`
+	Priority         int                          `json:"priority"`
+}
+
+func (model ProductRelationReqModel) ValidateCreate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.RelatedProductID,
+			validation.Required.Error(consts.Required),
+			validation.NotIn(model.ProductID).Error(consts.InvalidRelatedProduct),
+			validation.By(validations.ExistsInDB(&dbmodels.Product{}, "id", consts.ModelProductNotFound)),
+			validation.By(validations.NotExistsInDBWithCond(&dbmodels.ProductRelation{}, "related_product_id", consts.ExistedProductRelation,
+				"product_id = ? AND type = ?", model.ProductID, model.Type)),
+		),
+		validation.Field(&model.Type,
+			validation.In(
+				dbmodels.ProductRelationTypeRelated,
+				dbmodels.ProductRelationTypeCrossSell,
+				dbmodels.ProductRelationTypeUpSell,
+			).Error(consts.InvalidProductRelationType),
+		),
+		validation.Field(&model.Priority,
+			validation.Min(0).Error(consts.MinIsZero),
+		),
+	)
+}
+
+func (model ProductRelationReqModel) ToDBModel() *dbmodels.ProductRelation {
+	return &dbmodels.ProductRelation{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+		ProductID:        model.ProductID,
+		RelatedProductID: model.RelatedProductID,
+		Type:             model.Type,
+		Priority:         model.Priority,
+	}
+}
+
+type ProductRelationOutPutModel struct {
+	ID                 *uuid.UUID                   `gorm:"column:id"                    json:"id"`
+	CreatedAt          time.Time                    `gorm:"column:created_at"            json:"createdAt"`
+	RelatedProductID   uuid.UUID                    `gorm:"column:related_product_id"    json:"relatedProductId"`
+	RelatedProductName string                       `gorm:"column:related_product_name"  json:"relatedProductName"`
+	RelatedProductCode string                       `gorm:"column:related_product_code"  json:"relatedProductCode"`
+	Type               dbmodels.ProductRelationType `gorm:"column:type"                  json:"type"`
+	Priority           int                          `gorm:"column:priority"              json:"priority"`
+	Score              int                          `gorm:"column:score"                 json:"score"`
+}
+
+type RelatedProductOutPutModel struct {
+	ProductID     uuid.UUID                    `gorm:"column:product_id"        json:"productId"`
+	Name          string                       `gorm:"column:name"              json:"name"`
+	Rate          float64                      `gorm:"column:rate"              json:"rate"`
+	ProductItemID uuid.UUID                    `gorm:"column:product_item_id"   json:"productItemId"`
+	Price         float64                      `gorm:"column:price"             json:"price"`
+	Type          dbmodels.ProductRelationType `gorm:"column:type"              json:"-"`
+	FileType      dbmodels.FileType            `gorm:"column:file_type"         json:"-"`
+	FileName      string                       `gorm:"column:file_name"         json:"-"`
+	FileUrl       string                       `gorm:"-"                        json:"fileUrl"`
+}
+
+type RelatedProductsOutPutModel struct {
+	Related        []RelatedProductOutPutModel `json:"related"`
+	CrossSell      []RelatedProductOutPutModel `json:"crossSell"`
+	UpSell         []RelatedProductOutPutModel `json:"upSell"`
+	BoughtTogether []RelatedProductOutPutModel `json:"boughtTogether"`
+}
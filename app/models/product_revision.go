@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/product_revision"
+	"github.com/esmailemami/eshop/app/validations"
+	dbmodels "github.com/esmailemami/eshop/models"
+	datatypes "github.com/esmailemami/eshop/models/data_types"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type ProductRevisionReqModel struct {
+	Name             string                           `json:"name"`
+	ShortDescription string                           `json:"shortDescription"`
+	Description      string                           `json:"description"`
+	TopFeatures      datatypes.StringArray            `json:"topFeatures"`
+	Features         []ProductRevisionFeatureReqModel `json:"features"`
+	Note             *string                          `json:"note"`
+}
+
+type ProductRevisionFeatureReqModel struct {
+	ProductFeatureKeyID uuid.UUID `json:"productFeatureKeyId"`
+	Value               string    `json:"value"`
+}
+
+func (model ProductRevisionFeatureReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Value,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.ProductFeatureKeyID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.ProductFeatureKey{}, "id", consts.ModelProductFeatureKeyNotFound)),
+		),
+	)
+}
+
+func (model ProductRevisionReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Name,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.ShortDescription,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.Description,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.Features),
+	)
+}
+
+// ToContent converts the model to the revision content, keys are the feature key names by their id
+func (model ProductRevisionReqModel) ToContent(keys map[uuid.UUID]string) product_revision.Content {
+	features := make([]dbmodels.ProductRevisionFeature, len(model.Features))
+
+	for i, feature := range model.Features {
+		features[i] = dbmodels.ProductRevisionFeature{
+			ProductFeatureKeyID: feature.ProductFeatureKeyID,
+			Key:                 keys[feature.ProductFeatureKeyID],
+			Value:               feature.Value,
+		}
+	}
+
+	return product_revision.Content{
+		Name:             model.Name,
+		ShortDescription: model.ShortDescription,
+		Description:      model.Description,
+		TopFeatures:      model.TopFeatures,
+		Features:         features,
+	}
+}
+
+type ProductRevisionReviewReqModel struct {
+	Note *string `json:"note"`
+}
+
+type ProductRevisionOutPutModel struct {
+	ID             *uuid.UUID                     `gorm:"column:id"                json:"id"`
+	ProductID      uuid.UUID                      `gorm:"column:product_id"        json:"productId"`
+	Number         int                            `gorm:"column:number"            json:"number"`
+	Status         dbmodels.ProductRevisionStatus `gorm:"column:status"            json:"status"`
+	Name           string                         `gorm:"column:name"              json:"name"`
+	Note           *string                        `gorm:"column:note"              json:"note"`
+	RestoredFromID *uuid.UUID                     `gorm:"column:restored_from_id"  json:"restoredFromId"`
+	CreatedAt      time.Time                      `gorm:"column:created_at"        json:"createdAt"`
+	CreatedBy      *string                        `gorm:"column:created_by"        json:"createdBy"`
+	ReviewedBy     *string                        `gorm:"column:reviewed_by"       json:"reviewedBy"`
+	ReviewedAt     *time.Time                     `gorm:"column:reviewed_at"       json:"reviewedAt"`
+	ReviewNote     *string                        `gorm:"column:review_note"       json:"reviewNote"`
+}
+
+type ProductRevisionInfoOutPutModel struct {
+	Revision     dbmodels.ProductRevision  `json:"revision"`
+	ComparedWith *uuid.UUID                `json:"comparedWith"`
+	Changes      []product_revision.Change `json:"changes"`
+}
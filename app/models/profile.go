@@ -16,8 +16,13 @@ type UserDashboardInfoOutPutModel struct {
 	FirstName *string    `gorm:"column:first_name"                      json:"firstName"`
 	LastName  *string    `gorm:"column:last_name"                       json:"lastName"`
 	Mobile    *string    `gorm:"column:mobile"                          json:"mobile"`
-	RoleName  string     `gorm:"column:role_name"                       json:"roleName"`
+	RoleNames []string   `gorm:"-"                                      json:"roleNames"`
 	Email     *string    `gorm:"column:email"                           json:"email"`
+
+	EmailVerified  bool    `gorm:"column:email_verified"  json:"emailVerified"`
+	MobileVerified bool    `gorm:"column:mobile_verified" json:"mobileVerified"`
+	PendingEmail   *string `gorm:"column:pending_email"   json:"pendingEmail"`
+	PendingMobile  *string `gorm:"column:pending_mobile"  json:"pendingMobile"`
 }
 
 type UserOrderOutPutModel struct {
@@ -59,13 +64,43 @@ func (model UserProfileUpdateModel) ValidateUpdate(id uuid.UUID) error {
 		validation.Field(&model.Mobile,
 			validation.By(validations.IsValidMobileNumber()),
 		),
+		validation.Field(&model.Email,
+			validation.By(validations.IsValidEmail()),
+		),
 	)
 }
 
+// MergeWithDBData merges the profile, the new mobile and email take effect when they are verified, so only their
+// removal is merged here
 func (model *UserProfileUpdateModel) MergeWithDBData(dbmodel *dbmodels.User) {
 	dbmodel.Username = model.Username
 	dbmodel.FirstName = model.FirstName
 	dbmodel.LastName = model.LastName
-	dbmodel.Mobile = model.Mobile
-	dbmodel.Email = model.Email
+
+	if model.Mobile == nil {
+		dbmodel.SetMobile(nil)
+		dbmodel.PendingMobile = nil
+	}
+
+	if model.Email == nil {
+		dbmodel.SetEmail(nil)
+		dbmodel.PendingEmail = nil
+	}
+}
+
+type VerifyContactReqModel struct {
+	Code string `json:"code"`
+}
+
+func (model VerifyContactReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Code,
+			validation.Required.Error(consts.Required),
+		),
+	)
+}
+
+type ContactCodeOutPutModel struct {
+	ExpiresAt time.Time `json:"expiresAt"`
 }
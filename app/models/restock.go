@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AtRiskItemOutPutModel struct {
+	ProductItemID     uuid.UUID  `gorm:"column:product_item_id"        json:"productItemId"`
+	ProductID         uuid.UUID  `gorm:"column:product_id"             json:"productId"`
+	ProductName       string     `gorm:"column:product_name"           json:"productName"`
+	ColorName         string     `gorm:"column:color_name"             json:"colorName"`
+	SKU               *string    `gorm:"column:sku"                    json:"sku"`
+	Quantity          int        `gorm:"column:quantity"               json:"quantity"`
+	Threshold         int        `gorm:"column:threshold"              json:"threshold"`
+	SoldQuantity      int        `gorm:"column:sold_quantity"          json:"soldQuantity"`
+	LowStockAlertedAt *time.Time `gorm:"column:low_stock_alerted_at"   json:"lowStockAlertedAt"`
+
+	// velocity is the sold quantity per day and days of cover is nil when the item is not sold in the period
+	Velocity    float64  `gorm:"column:velocity"               json:"velocity"`
+	DaysOfCover *float64 `gorm:"column:days_of_cover"          json:"daysOfCover"`
+}
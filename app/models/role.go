@@ -1,10 +1,14 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/roles"
 	"github.com/esmailemami/eshop/app/validations"
+	dbpkg "github.com/esmailemami/eshop/db"
 	dbmodels "github.com/esmailemami/eshop/models"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
@@ -15,6 +19,12 @@ type RoleReqModel struct {
 	Code        string                   `json:"code"`
 	IsSystem    bool                     `json:"isSystem"`
 	Permissions dbmodels.RolePermissions `json:"permissions"`
+	MfaRequired bool                     `json:"mfaRequired"`
+
+	PermissionScopes dbmodels.RolePermissionScopes `json:"permissionScopes"`
+
+	// ParentIDs is the roles which the role inherits the permissions of
+	ParentIDs []uuid.UUID `json:"parentIds"`
 }
 
 func (model RoleReqModel) ValidateCreate() error {
@@ -32,6 +42,15 @@ func (model RoleReqModel) ValidateCreate() error {
 			validation.By(validations.Code()),
 			validation.By(validations.NotExistsInDB(&dbmodels.Role{}, "code", consts.ExistedCode)),
 		),
+		validation.Field(&model.MfaRequired,
+			validation.By(model.validateMfaRequired),
+		),
+		validation.Field(&model.PermissionScopes,
+			validation.By(model.validatePermissionScopes),
+		),
+		validation.Field(&model.ParentIDs,
+			validation.Each(validation.By(validations.ExistsInDB(&dbmodels.Role{}, "id", consts.ModelRoleNotFound))),
+		),
 	)
 }
 
@@ -50,9 +69,97 @@ func (model RoleReqModel) ValidateUpdate(id uuid.UUID) error {
 			validation.By(validations.Code()),
 			validation.By(validations.NotExistsInDBWithID(&dbmodels.Role{}, "code", id, consts.ExistedCode)),
 		),
+		validation.Field(&model.MfaRequired,
+			validation.By(model.validateMfaRequired),
+		),
+		validation.Field(&model.PermissionScopes,
+			validation.By(model.validatePermissionScopes),
+		),
+		validation.Field(&model.ParentIDs,
+			validation.Each(validation.By(validations.ExistsInDB(&dbmodels.Role{}, "id", consts.ModelRoleNotFound))),
+			validation.By(model.validateParents(id)),
+		),
+	)
+}
+
+// validateParents checks that the role does not inherit itself, a new role cannot make a cycle because no role
+// inherits it yet
+func (model RoleReqModel) validateParents(id uuid.UUID) func(value interface{}) error {
+	return func(value interface{}) error {
+		if len(model.ParentIDs) == 0 {
+			return nil
+		}
+
+		return roles.CheckInheritance(dbpkg.MustGormDBConn(context.Background()), id, model.ParentIDs)
+	}
+}
+
+// validateMfaRequired checks that the 2FA is only required for the roles which can login as admin
+func (model RoleReqModel) validateMfaRequired(value interface{}) error {
+	if !model.MfaRequired {
+		return nil
+	}
+
+	role := dbmodels.Role{Permissions: model.Permissions}
+	if !role.Permitted(dbmodels.ACTION_CAN_LOGIN_ADMIN) {
+		return errors.New(consts.MfaRequiresAdminRole)
+	}
+
+	return nil
+}
+
+// validatePermissionScopes checks the scopes against the registry, a scope is only defined for a granted permission
+// which can be limited by the attributes of the scope
+func (model RoleReqModel) validatePermissionScopes(value interface{}) error {
+	errs := validation.Errors{}
+
+	for code, scope := range model.PermissionScopes {
+		if err := model.validatePermissionScope(code, scope); err != nil {
+			errs[code] = err
+		}
+	}
+
+	return errs.Filter()
+}
+
+func (model RoleReqModel) validatePermissionScope(code string, scope dbmodels.PermissionScope) error {
+	permission, ok := dbmodels.LookupPermission(code)
+	if !ok {
+		return errors.New(consts.UnknownPermission)
+	}
+
+	role := dbmodels.Role{Permissions: model.Permissions}
+	if !role.Permitted(code) {
+		return errors.New(consts.PermissionNotGranted)
+	}
+
+	if scope.Empty() {
+		return errors.New(consts.EmptyPermissionScope)
+	}
+
+	return validation.ValidateStruct(
+		&scope,
+		validation.Field(&scope.CategoryIDs,
+			validation.By(scopableBy(permission, dbmodels.PermissionScopeCategory)),
+			validation.Each(validation.By(validations.ExistsInDB(&dbmodels.Category{}, "id", consts.ModelCategoryNotFound))),
+		),
+		validation.Field(&scope.BrandIDs,
+			validation.By(scopableBy(permission, dbmodels.PermissionScopeBrand)),
+			validation.Each(validation.By(validations.ExistsInDB(&dbmodels.Brand{}, "id", consts.ModelBrandNotFound))),
+		),
 	)
 }
 
+func scopableBy(permission dbmodels.Permission, scopeType dbmodels.PermissionScopeType) func(value interface{}) error {
+	return func(value interface{}) error {
+		if validation.IsEmpty(value) || permission.Scopable(scopeType) {
+			return nil
+		}
+
+		return errors.New(consts.PermissionNotScopable)
+	}
+}
+
 func (model *RoleReqModel) ToDBModel() *dbmodels.Role {
 	return &dbmodels.Role{
 		Model: dbmodels.Model{
@@ -62,6 +169,9 @@ func (model *RoleReqModel) ToDBModel() *dbmodels.Role {
 		Code:        model.Code,
 		IsSystem:    model.IsSystem,
 		Permissions: model.Permissions,
+		MfaRequired: model.MfaRequired,
+
+		PermissionScopes: model.PermissionScopes,
 	}
 }
 
@@ -70,6 +180,8 @@ func (model *RoleReqModel) MergeWithDBData(dbmodel *dbmodels.Role) {
 	dbmodel.Code = model.Code
 	dbmodel.IsSystem = model.IsSystem
 	dbmodel.Permissions = model.Permissions
+	dbmodel.MfaRequired = model.MfaRequired
+	dbmodel.PermissionScopes = model.PermissionScopes
 }
 
 type RoleOutPutModel struct {
@@ -80,4 +192,9 @@ type RoleOutPutModel struct {
 	Code        string                   `gorm:"column:code"        json:"code"`
 	IsSystem    bool                     `gorm:"column:is_system"   json:"isSystem"`
 	Permissions dbmodels.RolePermissions `gorm:"column:permissions" json:"permissions"`
+	MfaRequired bool                     `gorm:"column:mfa_required" json:"mfaRequired"`
+
+	PermissionScopes dbmodels.RolePermissionScopes `gorm:"column:permission_scopes" json:"permissionScopes"`
+
+	ParentIDs []uuid.UUID `gorm:"-" json:"parentIds,omitempty"`
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/user_agent"
+	"github.com/google/uuid"
+)
+
+type SessionOutPutModel struct {
+	ID           uuid.UUID  `gorm:"column:id"             json:"id"`
+	ActAs        string     `gorm:"column:act_as"         json:"actAs"`
+	IP           *string    `gorm:"column:ip"             json:"ip"`
+	UserAgent    *string    `gorm:"column:user_agent"     json:"userAgent"`
+	LoggedInAt   *time.Time `gorm:"column:logged_in_at"   json:"loggedInAt"`
+	LastActiveAt time.Time  `gorm:"column:last_active_at" json:"lastActiveAt"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at"     json:"expiresAt"`
+	Current      bool       `gorm:"-"                     json:"current"`
+
+	Device DeviceOutPutModel `gorm:"-" json:"device"`
+}
+
+type LoginHistoryOutPutModel struct {
+	ID        uuid.UUID `gorm:"column:id"         json:"id"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"createdAt"`
+	Event     string    `gorm:"column:event"      json:"event"`
+	IP        *string   `gorm:"column:ip"         json:"ip"`
+	UserAgent *string   `gorm:"column:user_agent" json:"userAgent"`
+
+	Device DeviceOutPutModel `gorm:"-" json:"device"`
+}
+
+type DeviceOutPutModel struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+	Type    string `json:"type"`
+}
+
+func NewDeviceOutPutModel(userAgent *string) DeviceOutPutModel {
+	ua := ""
+	if userAgent != nil {
+		ua = *userAgent
+	}
+
+	device := user_agent.Parse(ua)
+
+	return DeviceOutPutModel{
+		Browser: device.Browser,
+		OS:      device.OS,
+		Type:    device.Type,
+	}
+}
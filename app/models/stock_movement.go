@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	dbmodels "github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+type StockMovementOutPutModel struct {
+	ID            *uuid.UUID                   `gorm:"column:id"                json:"id"`
+	CreatedAt     time.Time                    `gorm:"column:created_at"        json:"createdAt"`
+	CreatedBy     *string                      `gorm:"column:created_by"        json:"createdBy"`
+	WarehouseID   *uuid.UUID                   `gorm:"column:warehouse_id"      json:"warehouseId"`
+	WarehouseName *string                      `gorm:"column:warehouse_name"    json:"warehouseName"`
+	ProductItemID uuid.UUID                    `gorm:"column:product_item_id"   json:"productItemId"`
+	ProductName   string                       `gorm:"column:product_name"      json:"productName"`
+	SKU           *string                      `gorm:"column:sku"               json:"sku"`
+	Quantity      int                          `gorm:"column:quantity"          json:"quantity"`
+	Balance       int                          `gorm:"column:balance"           json:"balance"`
+	Reason        dbmodels.StockMovementReason `gorm:"column:reason"            json:"reason"`
+	SourceID      *uuid.UUID                   `gorm:"column:source_id"         json:"sourceId"`
+	Note          *string                      `gorm:"column:note"              json:"note"`
+}
+
+type StockReconciliationOutPutModel struct {
+	ProductItemID uuid.UUID  `gorm:"column:product_item_id"   json:"productItemId"`
+	ProductName   string     `gorm:"column:product_name"      json:"productName"`
+	ColorName     string     `gorm:"column:color_name"        json:"colorName"`
+	SKU           *string    `gorm:"column:sku"               json:"sku"`
+	Quantity      int        `gorm:"column:quantity"          json:"quantity"`
+	Ledger        int        `gorm:"column:ledger"            json:"ledger"`
+	Difference    int        `gorm:"column:difference"        json:"difference"`
+	LastMovedAt   *time.Time `gorm:"column:last_moved_at"    json:"lastMovedAt"`
+}
+
+type LowStockOutPutModel struct {
+	ProductItemID  uuid.UUID `gorm:"column:product_item_id"   json:"productItemId"`
+	ProductID      uuid.UUID `gorm:"column:product_id"        json:"productId"`
+	ProductName    string    `gorm:"column:product_name"      json:"productName"`
+	ColorName      string    `gorm:"column:color_name"        json:"colorName"`
+	SKU            *string   `gorm:"column:sku"               json:"sku"`
+	Quantity       int       `gorm:"column:quantity"          json:"quantity"`
+	WarehouseStock int       `gorm:"column:warehouse_stock"   json:"warehouseStock"`
+	SoldQuantity   int       `gorm:"column:sold_quantity"     json:"soldQuantity"`
+}
@@ -6,19 +6,20 @@ import (
 	"github.com/esmailemami/eshop/app/consts"
 	"github.com/esmailemami/eshop/app/validations"
 	dbmodels "github.com/esmailemami/eshop/models"
+	datatypes "github.com/esmailemami/eshop/models/data_types"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
 )
 
 type UserReqModel struct {
-	Username  string     `json:"username"`
-	FirstName *string    `json:"firstName,omitempty"`
-	LastName  *string    `json:"lastName,omitempty"`
-	Mobile    *string    `json:"mobile,omitempty"`
-	RoleID    *uuid.UUID `json:"roleId,omitempty"`
-	Email     *string    `json:"email,omitempty"`
-	IsSystem  bool       `json:"isSystem"`
-	Enabled   bool       `json:"enabled"`
+	Username  string      `json:"username"`
+	FirstName *string     `json:"firstName,omitempty"`
+	LastName  *string     `json:"lastName,omitempty"`
+	Mobile    *string     `json:"mobile,omitempty"`
+	RoleIDs   []uuid.UUID `json:"roleIds"`
+	Email     *string     `json:"email,omitempty"`
+	IsSystem  bool        `json:"isSystem"`
+	Enabled   bool        `json:"enabled"`
 }
 
 func (model UserReqModel) ValidateCreate() error {
@@ -36,8 +37,8 @@ func (model UserReqModel) ValidateCreate() error {
 				),
 			),
 		),
-		validation.Field(&model.RoleID,
-			validation.By(validations.ExistsInDB(&dbmodels.Role{}, "id", consts.ModelRoleNotFound)),
+		validation.Field(&model.RoleIDs,
+			validation.Each(validation.By(validations.ExistsInDB(&dbmodels.Role{}, "id", consts.ModelRoleNotFound))),
 		),
 	)
 }
@@ -58,8 +59,8 @@ func (model UserReqModel) ValidateUpdate(id uuid.UUID) error {
 				),
 			),
 		),
-		validation.Field(&model.RoleID,
-			validation.By(validations.ExistsInDB(&dbmodels.Role{}, "id", consts.ModelRoleNotFound)),
+		validation.Field(&model.RoleIDs,
+			validation.Each(validation.By(validations.ExistsInDB(&dbmodels.Role{}, "id", consts.ModelRoleNotFound))),
 		),
 	)
 }
@@ -73,7 +74,6 @@ func (model *UserReqModel) ToDBModel() *dbmodels.User {
 		FirstName: model.FirstName,
 		LastName:  model.LastName,
 		Mobile:    model.Mobile,
-		RoleID:    model.RoleID,
 		Email:     model.Email,
 		IsSystem:  model.IsSystem,
 		Enabled:   model.Enabled,
@@ -84,9 +84,8 @@ func (model *UserReqModel) MergeWithDBData(dbmodel *dbmodels.User) {
 	dbmodel.Username = model.Username
 	dbmodel.FirstName = model.FirstName
 	dbmodel.LastName = model.LastName
-	dbmodel.Mobile = model.Mobile
-	dbmodel.RoleID = model.RoleID
-	dbmodel.Email = model.Email
+	dbmodel.SetMobile(model.Mobile)
+	dbmodel.SetEmail(model.Email)
 	dbmodel.IsSystem = model.IsSystem
 	dbmodel.Enabled = model.Enabled
 }
@@ -99,9 +98,13 @@ type UserOutPutModel struct {
 	FirstName *string    `gorm:"column:first_name" json:"firstName"`
 	LastName  *string    `gorm:"column:last_name"  json:"lastName"`
 	Mobile    *string    `gorm:"column:mobile"     json:"mobile"`
-	RoleID    *uuid.UUID `gorm:"column:role_id"    json:"roleId"`
-	RoleName  *string    `gorm:"column:role_name"  json:"roleName"`
 	Email     *string    `gorm:"email"             json:"email"`
 	IsSystem  bool       `gorm:"column:is_system"  json:"isSystem"`
 	Enabled   bool       `gorm:"column:enabled"    json:"enabled"`
+
+	EmailVerified  bool `gorm:"column:email_verified"  json:"emailVerified"`
+	MobileVerified bool `gorm:"column:mobile_verified" json:"mobileVerified"`
+
+	RoleIDs   datatypes.StringArray `gorm:"column:role_ids"   json:"roleIds"`
+	RoleNames datatypes.StringArray `gorm:"column:role_names" json:"roleNames"`
 }
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/validations"
+	dbmodels "github.com/esmailemami/eshop/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+)
+
+type WarehouseReqModel struct {
+	Name       string                 `json:"name"`
+	Code       string                 `json:"code"`
+	Type       dbmodels.WarehouseType `json:"type"`
+	PostalCode string                 `json:"postalCode"`
+	Address    string                 `json:"address"`
+	IsActive   bool                   `json:"isActive"`
+}
+
+func (model WarehouseReqModel) ValidateCreate() error {
+	return model.validate(validations.NotExistsInDB(&dbmodels.Warehouse{}, "code", consts.ExistedCode))
+}
+
+func (model WarehouseReqModel) ValidateUpdate(id uuid.UUID) error {
+	return model.validate(validations.NotExistsInDBWithID(&dbmodels.Warehouse{}, "code", id, consts.ExistedCode))
+}
+
+func (model WarehouseReqModel) validate(codeRule func(value interface{}) error) error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.Name,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.Code,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.Code()),
+			validation.By(codeRule),
+		),
+		validation.Field(&model.Type,
+			validation.In(dbmodels.WarehouseTypeWarehouse, dbmodels.WarehouseTypeStore).Error(consts.InvalidWarehouseType),
+		),
+		validation.Field(&model.PostalCode,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.IsValidPostalCode()),
+		),
+		validation.Field(&model.Address,
+			validation.By(validations.ClearText()),
+		),
+	)
+}
+
+func (model WarehouseReqModel) ToDBModel() *dbmodels.Warehouse {
+	dbModel := &dbmodels.Warehouse{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+	}
+
+	model.MergeWithDBData(dbModel)
+
+	return dbModel
+}
+
+func (model WarehouseReqModel) MergeWithDBData(dbmodel *dbmodels.Warehouse) {
+	dbmodel.Name = model.Name
+	dbmodel.Code = model.Code
+	dbmodel.Type = model.Type
+	dbmodel.PostalCode = model.PostalCode
+	dbmodel.Address = model.Address
+	dbmodel.IsActive = model.IsActive
+}
+
+type WarehouseOutPutModel struct {
+	ID         *uuid.UUID             `gorm:"column:id"              json:"id"`
+	CreatedAt  time.Time              `gorm:"column:created_at"      json:"createdAt"`
+	UpdatedAt  time.Time              `gorm:"column:updated_at"      json:"updatedAt"`
+	Name       string                 `gorm:"column:name"            json:"name"`
+	Code       string                 `gorm:"column:code"            json:"code"`
+	Type       dbmodels.WarehouseType `gorm:"column:type"            json:"type"`
+	PostalCode string                 `gorm:"column:postal_code"     json:"postalCode"`
+	Address    string                 `gorm:"column:address"         json:"address"`
+	IsActive   bool                   `gorm:"column:is_active"       json:"isActive"`
+	Stock      int                    `gorm:"column:stock"           json:"stock"`
+}
+
+type WarehouseStockOutPutModel struct {
+	WarehouseID   uuid.UUID `gorm:"column:warehouse_id"      json:"warehouseId"`
+	WarehouseName string    `gorm:"column:warehouse_name"    json:"warehouseName"`
+	WarehouseCode string    `gorm:"column:warehouse_code"    json:"warehouseCode"`
+	ProductItemID uuid.UUID `gorm:"column:product_item_id"   json:"productItemId"`
+	ProductName   string    `gorm:"column:product_name"      json:"productName"`
+	ColorName     string    `gorm:"column:color_name"        json:"colorName"`
+	SKU           *string   `gorm:"column:sku"               json:"sku"`
+	Quantity      int       `gorm:"column:quantity"          json:"quantity"`
+	UpdatedAt     time.Time `gorm:"column:updated_at"        json:"updatedAt"`
+}
+
+// StockAdjustmentReqModel changes the stock of a location, Quantity is negative for the decrease
+type StockAdjustmentReqModel struct {
+	WarehouseID   uuid.UUID `json:"warehouseId"`
+	ProductItemID uuid.UUID `json:"productItemId"`
+	Quantity      int       `json:"quantity"`
+	Note          *string   `json:"note"`
+}
+
+func (model StockAdjustmentReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.WarehouseID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.Warehouse{}, "id", consts.ModelWarehouseNotFound)),
+		),
+		validation.Field(&model.ProductItemID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.ProductItem{}, "id", consts.ModelProductItemNotFound)),
+		),
+		validation.Field(&model.Quantity,
+			validation.Required.Error(consts.Required),
+		),
+		validation.Field(&model.Note,
+			validation.By(validations.ClearText()),
+		),
+	)
+}
+
+type StockTransferReqModel struct {
+	FromWarehouseID uuid.UUID                   `json:"fromWarehouseId"`
+	ToWarehouseID   uuid.UUID                   `json:"toWarehouseId"`
+	Note            *string                     `json:"note"`
+	Items           []StockTransferItemReqModel `json:"items"`
+}
+
+type StockTransferItemReqModel struct {
+	ProductItemID uuid.UUID `json:"productItemId"`
+	Quantity      int       `json:"quantity"`
+}
+
+func (model StockTransferItemReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.ProductItemID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.ProductItem{}, "id", consts.ModelProductItemNotFound)),
+		),
+		validation.Field(&model.Quantity,
+			validation.Required.Error(consts.Required),
+			validation.Min(1).Error(consts.InvalidQuantity),
+		),
+	)
+}
+
+func (model StockTransferReqModel) Validate() error {
+	return validation.ValidateStruct(
+		&model,
+		validation.Field(&model.FromWarehouseID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.Warehouse{}, "id", consts.ModelWarehouseNotFound)),
+		),
+		validation.Field(&model.ToWarehouseID,
+			validation.Required.Error(consts.Required),
+			validation.By(validations.ExistsInDB(&dbmodels.Warehouse{}, "id", consts.ModelWarehouseNotFound)),
+			validation.NotIn(model.FromWarehouseID).Error(consts.SameTransferWarehouses),
+		),
+		validation.Field(&model.Note,
+			validation.By(validations.ClearText()),
+		),
+		validation.Field(&model.Items,
+			validation.Required.Error(consts.Required),
+			validation.By(uniqueStockTransferItems),
+		),
+	)
+}
+
+func uniqueStockTransferItems(value interface{}) error {
+	items, _ := value.([]StockTransferItemReqModel)
+
+	seen := map[uuid.UUID]bool{}
+	for _, item := range items {
+		if seen[item.ProductItemID] {
+			return errors.New(consts.DuplicatedStockTransferItem)
+		}
+		seen[item.ProductItemID] = true
+	}
+
+	return nil
+}
+
+func (model StockTransferReqModel) ToDBModel(code string) *dbmodels.StockTransfer {
+	dbModel := &dbmodels.StockTransfer{
+		Model: dbmodels.Model{
+			ID: dbmodels.NewID(),
+		},
+		Code:            code,
+		FromWarehouseID: model.FromWarehouseID,
+		ToWarehouseID:   model.ToWarehouseID,
+		Status:          dbmodels.StockTransferStatusDraft,
+		Note:            model.Note,
+		Items:           make([]dbmodels.StockTransferItem, len(model.Items)),
+	}
+
+	for i, item := range model.Items {
+		dbModel.Items[i] = dbmodels.StockTransferItem{
+			Model: dbmodels.Model{
+				ID: dbmodels.NewID(),
+			},
+			StockTransferID: *dbModel.ID,
+			ProductItemID:   item.ProductItemID,
+			Quantity:        item.Quantity,
+		}
+	}
+
+	return dbModel
+}
+
+type StockTransferOutPutModel struct {
+	ID                *uuid.UUID                     `gorm:"column:id"                    json:"id"`
+	CreatedAt         time.Time                      `gorm:"column:created_at"            json:"createdAt"`
+	CreatedBy         *string                        `gorm:"column:created_by"            json:"createdBy"`
+	Code              string                         `gorm:"column:code"                  json:"code"`
+	FromWarehouseID   uuid.UUID                      `gorm:"column:from_warehouse_id"     json:"fromWarehouseId"`
+	FromWarehouseName string                         `gorm:"column:from_warehouse_name"   json:"fromWarehouseName"`
+	ToWarehouseID     uuid.UUID                      `gorm:"column:to_warehouse_id"       json:"toWarehouseId"`
+	ToWarehouseName   string                         `gorm:"column:to_warehouse_name"     json:"toWarehouseName"`
+	Status            dbmodels.StockTransferStatus   `gorm:"column:status"                json:"status"`
+	Note              *string                        `gorm:"column:note"                  json:"note"`
+	CompletedAt       *time.Time                     `gorm:"column:completed_at"          json:"completedAt"`
+	Items             []StockTransferItemOutPutModel `gorm:"-"                            json:"items,omitempty"`
+}
+
+type StockTransferItemOutPutModel struct {
+	ProductItemID uuid.UUID `gorm:"column:product_item_id"   json:"productItemId"`
+	ProductName   string    `gorm:"column:product_name"      json:"productName"`
+	ColorName     string    `gorm:"column:color_name"        json:"colorName"`
+	SKU           *string   `gorm:"column:sku"               json:"sku"`
+	Quantity      int       `gorm:"column:quantity"          json:"quantity"`
+}
@@ -81,6 +81,13 @@ func (p *Parameter[T]) SortDescending(columns ...string) *Parameter[T] {
 	return p
 }
 
+// Sort orders the records by the clauses which have their own directions, e.g. "created_at DESC"
+func (p *Parameter[T]) Sort(clauses ...string) *Parameter[T] {
+	p.sortColumns = clauses
+	p.sortOrder = ""
+	return p
+}
+
 func (p *Parameter[T]) SearchColumns(columns ...string) *Parameter[T] {
 	p.searchColumns = columns
 
@@ -134,7 +141,10 @@ func (p *Parameter[T]) Execute(db *gorm.DB) (*ListResponse[T], error) {
 		}
 
 		if len(p.sortColumns) > 0 {
-			orderClause := strings.Join(p.sortColumns, ",") + " " + p.sortOrder
+			orderClause := strings.Join(p.sortColumns, ",")
+			if p.sortOrder != "" {
+				orderClause += " " + p.sortOrder
+			}
 			qry = qry.Order(orderClause)
 		}
 
@@ -7,31 +7,52 @@ import (
 
 	"github.com/esmailemami/eshop/app/consts"
 	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/bruteforce"
+	"github.com/esmailemami/eshop/app/services/roles"
 	"github.com/esmailemami/eshop/app/services/token"
 	"github.com/esmailemami/eshop/app/services/user"
 	dbpkg "github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/jwt"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func LoginByUsername(ctx context.Context, input appmodels.LoginInputModel) (*appmodels.LoginOutputModel, error) {
 	db := dbpkg.MustGormDBConn(ctx)
 
+	guards := loginGuards(input.Username, input.IP)
+	if err := bruteforce.Check(guards...); err != nil {
+		return nil, err
+	}
+
 	user, err := user.GetUserByUsername(input.Username)
 
 	if err != nil {
+		loginFailed(db, nil, guards, input.IP, input.UserAgent)
 		return nil, errors.New(consts.LoginFailed)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password))
 	if err != nil {
+		loginFailed(db, user, guards, input.IP, input.UserAgent)
 		return nil, errors.New(consts.LoginFailed)
 	}
 
+	// the failures of the ip address are kept, a valid account must not reset them
+	bruteforce.Reset(guards[0])
+
+	actAs := ctx.Value(consts.UserActAsContext).(string)
+
+	pending, err := mfaPending(db, *user, actAs)
+	if err != nil || pending != nil {
+		return pending, err
+	}
+
 	tx := db.Begin()
-	loginData, err := LoginUserInstance(tx, *user, ctx.Value(consts.UserActAsContext).(string))
+	loginData, err := LoginUserInstance(tx, *user, actAs)
 	if err != nil {
 		return nil, err
 	}
@@ -63,9 +84,15 @@ func LoginByUsername(ctx context.Context, input appmodels.LoginInputModel) (*app
 func LoginByUsernameOrMobile(ctx context.Context, input appmodels.LoginInputModel) (*appmodels.LoginOutputModel, error) {
 	db := dbpkg.MustGormDBConn(ctx)
 
+	guards := loginGuards(input.Username, input.IP)
+	if err := bruteforce.Check(guards...); err != nil {
+		return nil, err
+	}
+
 	user, err := user.GetUserByUsername(input.Username)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
+			loginFailed(db, nil, guards, input.IP, input.UserAgent)
 			return nil, errors.New(consts.LoginFailed)
 		}
 		return nil, errors.New(consts.InternalServerError)
@@ -73,10 +100,22 @@ func LoginByUsernameOrMobile(ctx context.Context, input appmodels.LoginInputMode
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password))
 	if err != nil {
+		loginFailed(db, user, guards, input.IP, input.UserAgent)
 		return nil, errors.New(consts.LoginFailed)
 	}
+
+	// the failures of the ip address are kept, a valid account must not reset them
+	bruteforce.Reset(guards[0])
+
+	actAs := ctx.Value(consts.UserActAsContext).(string)
+
+	pending, err := mfaPending(db, *user, actAs)
+	if err != nil || pending != nil {
+		return pending, err
+	}
+
 	tx := db.Begin()
-	loginData, err := LoginUserInstance(tx, *user, ctx.Value(consts.UserActAsContext).(string))
+	loginData, err := LoginUserInstance(tx, *user, actAs)
 	if err != nil {
 		return nil, err
 	}
@@ -106,49 +145,73 @@ func LoginByUsernameOrMobile(ctx context.Context, input appmodels.LoginInputMode
 }
 
 func LoginUserInstance(dbConn *gorm.DB, user models.User, actAs string) (*appmodels.LoginOutputModel, error) {
+	if err := checkActAs(user, actAs); err != nil {
+		return nil, err
+	}
+
+	// every login starts a new family of refresh tokens
+	return issueTokens(dbConn, user, actAs, uuid.New())
+}
 
+// checkActAs checks that the user can login from the route tree
+func checkActAs(user models.User, actAs string) error {
 	switch actAs {
 	// کاربر میخواهد از روت ادمین لاگین کند
 	case consts.UserActAsAdmin:
 		{
 			if !user.Role.Permitted(models.ACTION_CAN_LOGIN_ADMIN) {
-				return nil, errors.New(consts.LoginFailed)
+				return errors.New(consts.LoginFailed)
 			}
 		}
 	// کاربر میخواهد از روت کاربر لاگین کند
 	case consts.UserActAsUser:
 		{
 			if !user.Role.Permitted(models.ACTION_CAN_LOGIN_USER) {
-				return nil, errors.New(consts.LoginFailed)
+				return errors.New(consts.LoginFailed)
 			}
 		}
 	}
 
+	if !user.Enabled {
+		return errors.New(consts.UserIsDisabled)
+	}
+
+	return nil
+}
+
+// issueTokens creates a short lived access token and a refresh token of the family
+func issueTokens(dbConn *gorm.DB, user models.User, actAs string, familyID uuid.UUID) (*appmodels.LoginOutputModel, error) {
 	jwtToken := token.NewToken(map[string]interface{}{
-		"userID":   user.ID,
-		"username": user.Username,
+		"userID":          user.ID,
+		"username":        user.Username,
+		jwt.ExpirationKey: time.Now().UTC().Add(token.AccessTokenTTL()),
 	})
 
-	if !user.Enabled {
-		return nil, errors.New(consts.UserIsDisabled)
+	tokenStr, err := token.String(jwtToken)
+
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
 	}
 
-	tokenStr, err := token.String(jwtToken)
+	refreshStr, refreshHash, err := token.NewRefreshToken()
 
 	if err != nil {
 		return nil, errors.New(consts.InternalServerError)
 	}
 
 	output := &appmodels.LoginOutputModel{
-		Token:     tokenStr,
-		ExpiresAt: jwtToken.Expiration(),
-		ExpiresIn: jwtToken.Expiration().Unix() - time.Now().Unix(),
+		Token:            tokenStr,
+		ExpiresAt:        jwtToken.Expiration(),
+		ExpiresIn:        jwtToken.Expiration().Unix() - time.Now().Unix(),
+		RefreshToken:     refreshStr,
+		RefreshExpiresAt: time.Now().UTC().Add(token.RefreshTokenTTL()),
 		User: appmodels.LoginOutputUserModel{
 			Username:  user.Username,
 			FirstName: user.FirstName,
 			LastName:  user.LastName,
 		},
 	}
+	output.RefreshExpiresIn = output.RefreshExpiresAt.Unix() - time.Now().Unix()
 
 	authToken := models.AuthToken{
 		BasicModel: models.BasicModel{
@@ -160,16 +223,122 @@ func LoginUserInstance(dbConn *gorm.DB, user models.User, actAs string) (*appmod
 		UserID:    *user.ID,
 		ExpiresAt: output.ExpiresAt,
 		Revoked:   false,
+		FamilyID:  &familyID,
 	}
 	err = dbConn.Create(&authToken).Error
 	if err != nil {
 		return nil, errors.New(consts.InternalServerError)
 	}
 
+	refreshToken := models.RefreshToken{
+		BasicModel: models.BasicModel{
+			ID: models.NewID(),
+		},
+		FamilyID:    familyID,
+		UserID:      *user.ID,
+		AuthTokenID: *authToken.ID,
+		TokenHash:   refreshHash,
+		ActAs:       actAs,
+		ExpiresAt:   output.RefreshExpiresAt,
+	}
+	err = dbConn.Create(&refreshToken).Error
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
 	output.TokenID = *authToken.ID
+	output.RefreshTokenID = *refreshToken.ID
+	return output, nil
+}
+
+// Refresh exchanges the refresh token for a new access token and a new refresh token. Every refresh token is used
+// once, using a rotated token means it is stolen, so the whole family is revoked.
+func Refresh(db *gorm.DB, refreshToken string, actAs string) (*appmodels.LoginOutputModel, error) {
+	var (
+		output *appmodels.LoginOutputModel
+		reused bool
+	)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+
+		// the concurrent refreshes of the same token wait here, so only the first one rotates it
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", token.HashRefreshToken(refreshToken)).
+			First(&current).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.New(consts.InvalidRefreshToken)
+			}
+			return errors.New(consts.InternalServerError)
+		}
+
+		if current.UsedAt != nil {
+			reused = true
+			return RevokeFamily(tx, current.FamilyID)
+		}
+
+		if current.Revoked || current.ActAs != actAs || current.ExpiresAt.Before(time.Now()) {
+			return errors.New(consts.InvalidRefreshToken)
+		}
+
+		var user models.User
+
+		if err := tx.Where(`"id"=?`, current.UserID).First(&user).Error; err != nil {
+			return errors.New(consts.InvalidRefreshToken)
+		}
+
+		if err := roles.Load(tx, &user); err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		if err := checkActAs(user, actAs); err != nil {
+			return err
+		}
+
+		var err error
+
+		output, err = issueTokens(tx, user, actAs, current.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		if err := tx.Model(&current).UpdateColumns(map[string]any{
+			"used_at":        now,
+			"replaced_by_id": output.RefreshTokenID,
+		}).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		// the access token of the rotated refresh token is not needed anymore
+		return RevokeAuthTokenByID(tx, current.AuthTokenID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return nil, errors.New(consts.RefreshTokenReused)
+	}
+
 	return output, nil
 }
 
+// RevokeFamily revokes the refresh tokens and the access tokens of the family
+func RevokeFamily(db *gorm.DB, familyID uuid.UUID) error {
+	if err := db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked = false", familyID).
+		UpdateColumn("revoked", true).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&models.AuthToken{}).
+		Where("family_id = ? AND revoked = false", familyID).
+		UpdateColumn("revoked", true).Error
+}
+
 // RevokeAuthTokenByID
 func RevokeAuthTokenByID(db *gorm.DB, id uuid.UUID) error {
 	return db.Model(&models.AuthToken{}).
@@ -177,3 +346,18 @@ func RevokeAuthTokenByID(db *gorm.DB, id uuid.UUID) error {
 		UpdateColumn("revoked", true).
 		Error
 }
+
+// RevokeSession revokes the access token and the family of its refresh tokens
+func RevokeSession(db *gorm.DB, authTokenID uuid.UUID) error {
+	var authToken models.AuthToken
+
+	if err := db.Where(`"id"=?`, authTokenID).First(&authToken).Error; err != nil {
+		return err
+	}
+
+	if authToken.FamilyID != nil {
+		return RevokeFamily(db, *authToken.FamilyID)
+	}
+
+	return RevokeAuthTokenByID(db, authTokenID)
+}
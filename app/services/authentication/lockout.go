@@ -0,0 +1,97 @@
+package authentication
+
+import (
+	"github.com/esmailemami/eshop/app/services/bruteforce"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// loginGuards returns the counters of the failed logins, the account counter comes first
+func loginGuards(username, ip string) []bruteforce.Guard {
+	return []bruteforce.Guard{
+		bruteforce.LoginAccount.Guard(username),
+		bruteforce.LoginIP.Guard(ip),
+	}
+}
+
+// loginFailed counts the failed login and records the lockouts, the lockout of the account is added to the login
+// history of the user
+func loginFailed(db *gorm.DB, user *models.User, guards []bruteforce.Guard, ip, userAgent string) {
+	for _, g := range bruteforce.Fail(guards...) {
+		logger.Default().
+			WithField("policy", g.Policy.Name).
+			WithField("key", g.Key).
+			WithField("ip", ip).
+			Warning("locked by the failed login attempts")
+
+		if user == nil || g.Policy.Name != bruteforce.LoginAccount.Name {
+			continue
+		}
+
+		history := models.LoginHistory{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			Event:     models.LoginEventLockout,
+			UserID:    *user.ID,
+			UserAgent: &userAgent,
+			IP:        &ip,
+		}
+
+		if err := db.Create(&history).Error; err != nil {
+			logger.Default().WithField("user", user.ID.String()).Error(err.Error())
+		}
+	}
+}
+
+// RecoveryAttempt counts the password recovery request of the mobile number or the email address, every request
+// is counted because it sends a message
+func RecoveryAttempt(identifier, ip string) error {
+	guards := []bruteforce.Guard{
+		bruteforce.RecoveryAccount.Guard(identifier),
+		bruteforce.RecoveryIP.Guard(ip),
+	}
+
+	if err := bruteforce.Check(guards...); err != nil {
+		return err
+	}
+
+	for _, g := range bruteforce.Fail(guards...) {
+		logger.Default().
+			WithField("policy", g.Policy.Name).
+			WithField("key", g.Key).
+			WithField("ip", ip).
+			Warning("locked by the password recovery requests")
+	}
+
+	return nil
+}
+
+// UnlockUser removes the lockouts of the username and the contacts of the user
+func UnlockUser(db *gorm.DB, userID uuid.UUID) error {
+	var user models.User
+
+	if err := db.Where(`"id" = ?`, userID).First(&user).Error; err != nil {
+		return err
+	}
+
+	guards := []bruteforce.Guard{
+		bruteforce.LoginAccount.Guard(user.Username),
+	}
+
+	if user.Mobile != nil {
+		guards = append(guards, bruteforce.RecoveryAccount.Guard(*user.Mobile))
+	}
+
+	if user.Email != nil {
+		guards = append(guards, bruteforce.RecoveryAccount.Guard(*user.Email))
+	}
+
+	bruteforce.Unlock(guards...)
+
+	logger.Default().WithField("user", userID.String()).Info("the login lockouts are removed")
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/bruteforce"
+	"github.com/esmailemami/eshop/app/services/mfa"
+	"github.com/esmailemami/eshop/app/services/roles"
+	dbpkg "github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// mfaPending starts the "mfa pending" state when the user has enabled the 2FA or the role requires it, it returns nil
+// when the tokens can be issued right away. The access tokens are not bound to the route tree, so the second factor
+// is asked on every route.
+func mfaPending(db *gorm.DB, user models.User, actAs string) (*appmodels.LoginOutputModel, error) {
+	if err := checkActAs(user, actAs); err != nil {
+		return nil, err
+	}
+
+	status, err := mfa.GetStatus(db, user)
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if !status.Enabled && !status.Required {
+		return nil, nil
+	}
+
+	tokenString, expiresAt, err := mfa.NewChallenge(db, *user.ID, actAs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appmodels.LoginOutputModel{
+		User: appmodels.LoginOutputUserModel{
+			Username:  user.Username,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		},
+		Mfa: &appmodels.MfaPendingOutputModel{
+			Token:              tokenString,
+			ExpiresAt:          expiresAt,
+			ExpiresIn:          expiresAt.Unix() - time.Now().Unix(),
+			EnrollmentRequired: !status.Enabled,
+		},
+	}, nil
+}
+
+// LoginByMfa issues the tokens of the pending login when the TOTP code or a recovery code is verified
+func LoginByMfa(ctx context.Context, input appmodels.MfaLoginInputModel) (*appmodels.LoginOutputModel, error) {
+	db := dbpkg.MustGormDBConn(ctx)
+
+	challenge, err := passChallenge(db, input, func(tx *gorm.DB, userID uuid.UUID) error {
+		return mfa.VerifyCode(tx, userID, input.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loginChallenge(db, challenge, input.IP, input.UserAgent)
+}
+
+// StartMfaEnrollment creates the secret of the user whose role requires the 2FA but has not enrolled it yet, the
+// enrollment is done during the login by the pending token
+func StartMfaEnrollment(ctx context.Context, tokenString string) (*mfa.Enrollment, error) {
+	db := dbpkg.MustGormDBConn(ctx)
+
+	userID, err := mfa.ChallengeUser(db, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+
+	if err := db.Where(`"id" = ?`, userID).First(&user).Error; err != nil {
+		return nil, errors.New(consts.InvalidMfaToken)
+	}
+
+	return mfa.Enroll(db, user)
+}
+
+// ConfirmMfaEnrollment enables the 2FA by the first code of the enrolled secret and issues the tokens of the pending
+// login, the recovery codes are returned once with the tokens
+func ConfirmMfaEnrollment(ctx context.Context, input appmodels.MfaLoginInputModel) (*appmodels.LoginOutputModel, error) {
+	db := dbpkg.MustGormDBConn(ctx)
+
+	var recoveryCodes []string
+
+	challenge, err := passChallenge(db, input, func(tx *gorm.DB, userID uuid.UUID) error {
+		var err error
+		recoveryCodes, err = mfa.Confirm(tx, userID, input.Code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loginData, err := loginChallenge(db, challenge, input.IP, input.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	loginData.RecoveryCodes = recoveryCodes
+	return loginData, nil
+}
+
+// passChallenge runs the check of the pending login, the wrong codes are counted with the wrong passwords of the
+// account, so the limited attempts of a challenge cannot be renewed by the password forever
+func passChallenge(db *gorm.DB, input appmodels.MfaLoginInputModel, check func(tx *gorm.DB, userID uuid.UUID) error) (*models.MfaChallenge, error) {
+	userID, err := mfa.ChallengeUser(db, input.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+
+	if err := db.Where(`"id" = ?`, userID).First(&user).Error; err != nil {
+		return nil, errors.New(consts.InvalidMfaToken)
+	}
+
+	guards := loginGuards(user.Username, input.IP)
+	if err := bruteforce.Check(guards...); err != nil {
+		return nil, err
+	}
+
+	challenge, err := mfa.PassChallenge(db, input.Token, check)
+	if err != nil {
+		if err.Error() == consts.InvalidMfaCode {
+			loginFailed(db, &user, guards, input.IP, input.UserAgent)
+		}
+		return nil, err
+	}
+
+	bruteforce.Reset(guards[0])
+
+	return challenge, nil
+}
+
+// loginChallenge issues the tokens of the passed challenge and records the login
+func loginChallenge(db *gorm.DB, challenge *models.MfaChallenge, ip, userAgent string) (*appmodels.LoginOutputModel, error) {
+	var loginData *appmodels.LoginOutputModel
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+
+		if err := tx.Where(`"id" = ?`, challenge.UserID).First(&user).Error; err != nil {
+			return errors.New(consts.InvalidMfaToken)
+		}
+
+		if err := roles.Load(tx, &user); err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		var err error
+		loginData, err = LoginUserInstance(tx, user, challenge.ActAs)
+		if err != nil {
+			return err
+		}
+
+		history := models.LoginHistory{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			UserID:    *user.ID,
+			TokenID:   &loginData.TokenID,
+			UserAgent: &userAgent,
+			IP:        &ip,
+		}
+
+		if err := tx.Create(&history).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loginData, nil
+}
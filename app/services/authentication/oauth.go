@@ -0,0 +1,331 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/cachedirver"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/app/services/oauth"
+	"github.com/esmailemami/eshop/app/services/roles"
+	"github.com/esmailemami/eshop/app/services/sanitize"
+	dbpkg "github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OAuthStateTTL is the time the user has to finish the consent of the provider
+const OAuthStateTTL = 10 * time.Minute
+
+// oauthState is kept in the cache by the state parameter until the callback
+type oauthState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+}
+
+func oauthStateKey(state string) string {
+	return "oauth:state:" + state
+}
+
+// StartOAuth returns the consent url of the provider
+func StartOAuth(ctx context.Context, providerName string) (*appmodels.OAuthStartOutputModel, error) {
+	provider, err := oauth.Get(providerName)
+	if err != nil {
+		return nil, errors.New(consts.UnknownOAuthProvider)
+	}
+
+	request, err := oauth.NewAuthRequest()
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	url, err := provider.AuthCodeURL(ctx, request)
+	if err != nil {
+		logger.Default().WithField("provider", providerName).Error(err.Error())
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	state := oauthState{
+		Provider:     providerName,
+		Nonce:        request.Nonce,
+		CodeVerifier: request.CodeVerifier,
+	}
+
+	if err := cachedirver.GetConnection().Set(oauthStateKey(request.State), state, OAuthStateTTL); err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	return &appmodels.OAuthStartOutputModel{
+		URL:       url,
+		State:     request.State,
+		ExpiresAt: time.Now().Add(OAuthStateTTL),
+	}, nil
+}
+
+// LoginByOAuth logs the user in by the authorization code of the provider, the identity is linked to the user with
+// the same verified email or a new user is registered
+func LoginByOAuth(ctx context.Context, providerName string, input appmodels.OAuthCallbackInputModel) (*appmodels.LoginOutputModel, error) {
+	provider, err := oauth.Get(providerName)
+	if err != nil {
+		return nil, errors.New(consts.UnknownOAuthProvider)
+	}
+
+	state, err := consumeOAuthState(input.State)
+	if err != nil || state.Provider != providerName {
+		return nil, errors.New(consts.InvalidOAuthState)
+	}
+
+	identity, err := provider.Exchange(ctx, input.Code, oauth.AuthRequest{
+		State:        input.State,
+		Nonce:        state.Nonce,
+		CodeVerifier: state.CodeVerifier,
+	})
+	if err != nil {
+		logger.Default().WithField("provider", providerName).Error(err.Error())
+		return nil, errors.New(consts.OAuthLoginFailed)
+	}
+
+	db := dbpkg.MustGormDBConn(ctx)
+
+	var loginData *appmodels.LoginOutputModel
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		user, err := userByIdentity(tx, identity)
+		if err != nil {
+			return err
+		}
+
+		// the provider does not replace the 2FA of the user
+		loginData, err = mfaPending(tx, *user, consts.UserActAsUser)
+		if err != nil || loginData != nil {
+			return err
+		}
+
+		loginData, err = LoginUserInstance(tx, *user, consts.UserActAsUser)
+		if err != nil {
+			return err
+		}
+
+		history := models.LoginHistory{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			UserID:    *user.ID,
+			TokenID:   &loginData.TokenID,
+			UserAgent: &input.UserAgent,
+			IP:        &input.IP,
+		}
+
+		if err := tx.Create(&history).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loginData, nil
+}
+
+// consumeOAuthState returns the state and removes it, so a callback is accepted once
+func consumeOAuthState(state string) (*oauthState, error) {
+	cache := cachedirver.GetConnection()
+
+	var value oauthState
+	if err := cache.UnmarshalToObject(oauthStateKey(state), &value); err != nil {
+		return nil, err
+	}
+
+	// the concurrent callbacks of the same state can read it, only the one which deletes it goes on
+	if err := cache.Delete(oauthStateKey(state)); err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+// userByIdentity returns the user of the linked identity, the identity is linked to the owner of the verified email
+// on the first login and a new user is registered when there is not any
+func userByIdentity(tx *gorm.DB, identity *oauth.Identity) (*models.User, error) {
+	var link models.UserIdentity
+
+	err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&link).Error
+	if err == nil {
+		var user models.User
+		if err := tx.Where(`"id" = ?`, link.UserID).First(&user).Error; err != nil {
+			return nil, errors.New(consts.InternalServerError)
+		}
+		if err := roles.Load(tx, &user); err != nil {
+			return nil, errors.New(consts.InternalServerError)
+		}
+		return &user, nil
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	var email *string
+	if identity.EmailVerified && identity.Email != "" {
+		email = &identity.Email
+	}
+
+	user, err := userByVerifiedEmail(tx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		if user, err = registerIdentityUser(tx, identity, email); err != nil {
+			return nil, err
+		}
+	}
+
+	link = models.UserIdentity{
+		BasicModel: models.BasicModel{
+			ID: models.NewID(),
+		},
+		UserID:   *user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    email,
+	}
+
+	if err := tx.Create(&link).Error; err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	return user, nil
+}
+
+// userByVerifiedEmail returns the user who has verified the email, only the emails which are verified by both sides
+// are trusted to link the identity
+func userByVerifiedEmail(tx *gorm.DB, email *string) (*models.User, error) {
+	if email == nil {
+		return nil, nil
+	}
+
+	var user models.User
+
+	err := tx.Where("lower(email) = lower(?) AND email_verified = true", *email).First(&user).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if err := roles.Load(tx, &user); err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	return &user, nil
+}
+
+// registerIdentityUser creates the user of the identity, the email is set as verified when nobody else has it
+func registerIdentityUser(tx *gorm.DB, identity *oauth.Identity, email *string) (*models.User, error) {
+	base := identity.Provider
+	if email != nil {
+		if local := sanitize.AsUsername(strings.SplitN(*email, "@", 2)[0]); len(local) >= 3 {
+			base = local
+		}
+	}
+	if len(base) > 30 {
+		base = base[:30]
+	}
+
+	username, err := newUsername(tx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	// the user has not any password, it can be set by the password recovery
+	user := models.User{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		Username: username,
+		IsSystem: false,
+		Enabled:  true,
+	}
+
+	if identity.GivenName != "" {
+		user.FirstName = &identity.GivenName
+	}
+	if identity.FamilyName != "" {
+		user.LastName = &identity.FamilyName
+	}
+
+	if email != nil && !dbpkg.Exists(tx, &models.User{}, "lower(email) = lower(?)", *email) {
+		now := time.Now()
+		user.Email = email
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if err := roles.AssignDefault(tx, *user.ID); err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if err := roles.Load(tx, &user); err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	return &user, nil
+}
+
+// UserIdentities returns the linked identities of the user
+func UserIdentities(db *gorm.DB, userID uuid.UUID) ([]appmodels.UserIdentityOutPutModel, error) {
+	identities := []appmodels.UserIdentityOutPutModel{}
+
+	err := db.Model(&models.UserIdentity{}).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Select("id, provider, email, created_at").
+		Scan(&identities).Error
+
+	return identities, err
+}
+
+// UnlinkIdentity removes the identity of the user, the identity is kept when the user cannot login or recover the
+// password without it. It returns
+// gorm.ErrRecordNotFound when the identity does not belong to the user.
+func UnlinkIdentity(db *gorm.DB, user models.User, identityID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var link models.UserIdentity
+
+		if err := tx.Where(`"id" = ? AND user_id = ?`, identityID, user.ID).First(&link).Error; err != nil {
+			return err
+		}
+
+		var others int64
+		if err := tx.Model(&models.UserIdentity{}).
+			Where(`user_id = ? AND "id" <> ?`, user.ID, identityID).
+			Count(&others).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		if user.Password == "" && !user.MobileVerified && !user.EmailVerified && others == 0 {
+			return errors.New(consts.IdentityIsLastLoginMethod)
+		}
+
+		if err := tx.Delete(&link).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,176 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/mfa"
+	"github.com/esmailemami/eshop/app/services/otp"
+	"github.com/esmailemami/eshop/app/services/random_code"
+	"github.com/esmailemami/eshop/app/services/roles"
+	dbpkg "github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"gorm.io/gorm"
+)
+
+// LoginByOtp logs the user in by the code which is sent to the mobile number, the account is created on the first
+// login of the mobile number
+func LoginByOtp(ctx context.Context, input appmodels.OtpLoginInputModel) (*appmodels.LoginOutputModel, error) {
+	db := dbpkg.MustGormDBConn(ctx)
+
+	if err := otp.Verify(db, models.VerificationCodeScopeMobile, input.Mobile, input.Code); err != nil {
+		return nil, err
+	}
+
+	var loginData *appmodels.LoginOutputModel
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		user, err := userByMobile(tx, input.Mobile)
+		if err != nil {
+			return err
+		}
+
+		// the code of the mobile number does not replace the 2FA of the user
+		loginData, err = mfaPending(tx, *user, consts.UserActAsUser)
+		if err != nil || loginData != nil {
+			return err
+		}
+
+		loginData, err = LoginUserInstance(tx, *user, consts.UserActAsUser)
+		if err != nil {
+			return err
+		}
+
+		history := models.LoginHistory{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			UserID:    *user.ID,
+			TokenID:   &loginData.TokenID,
+			UserAgent: &input.UserAgent,
+			IP:        &input.IP,
+		}
+
+		if err := tx.Create(&history).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loginData, nil
+}
+
+// userByMobile returns the owner of the mobile number and registers a new one when there is not any. The verified
+// owner has priority, an unverified account of the number is adopted by the owner.
+func userByMobile(tx *gorm.DB, mobile string) (*models.User, error) {
+	var user models.User
+
+	err := tx.Where("mobile = ?", mobile).Order("mobile_verified DESC, created_at").First(&user).Error
+	if err == nil {
+		if !user.MobileVerified {
+			if err := adoptUser(tx, &user); err != nil {
+				return nil, errors.New(consts.InternalServerError)
+			}
+		}
+
+		if err := roles.Load(tx, &user); err != nil {
+			return nil, errors.New(consts.InternalServerError)
+		}
+
+		return &user, nil
+	}
+
+	if err != gorm.ErrRecordNotFound {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	username, err := newUsername(tx, "u"+mobile)
+	if err != nil {
+		return nil, err
+	}
+
+	// the user has not any password, it can be set by the password recovery
+	now := time.Now()
+
+	user = models.User{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		Username:         username,
+		Mobile:           &mobile,
+		MobileVerified:   true,
+		MobileVerifiedAt: &now,
+		IsSystem:         false,
+		Enabled:          true,
+	}
+
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if err := roles.AssignDefault(tx, *user.ID); err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if err := roles.Load(tx, &user); err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	return &user, nil
+}
+
+// adoptUser verifies the mobile number of the account by the first login of its code. Anyone could register the
+// number before its owner, so the other credentials of the account are not trusted: its sessions, password,
+// external identities and 2FA are removed and its email should be verified again.
+func adoptUser(tx *gorm.DB, user *models.User) error {
+	now := time.Now()
+
+	user.Password = ""
+	user.MobileVerified = true
+	user.MobileVerifiedAt = &now
+	user.EmailVerified = false
+	user.EmailVerifiedAt = nil
+
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"password":           user.Password,
+		"mobile_verified":    true,
+		"mobile_verified_at": now,
+		"email_verified":     false,
+		"email_verified_at":  nil,
+		"updated_at":         now,
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := RevokeUserSessions(tx, *user.ID, nil, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Where("user_id = ?", *user.ID).Delete(&models.UserIdentity{}).Error; err != nil {
+		return err
+	}
+
+	return mfa.Reset(tx, *user.ID)
+}
+
+// newUsername makes a unique username from the base, random digits are added when the base is taken
+func newUsername(tx *gorm.DB, base string) (string, error) {
+	username := base
+
+	for i := 0; i < 5; i++ {
+		if !dbpkg.Exists(tx, &models.User{}, "username = ?", username) {
+			return username, nil
+		}
+		username = base + random_code.GenerateRandomDigit(4)
+	}
+
+	return "", errors.New(consts.InternalServerError)
+}
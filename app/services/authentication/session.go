@@ -0,0 +1,90 @@
+package authentication
+
+import (
+	"time"
+
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sessions returns the active logins of the user, a session is a family of refresh tokens which its last token
+// is not used, revoked or expired yet
+func Sessions(db *gorm.DB, userID uuid.UUID) ([]appmodels.SessionOutPutModel, error) {
+	sessions := []appmodels.SessionOutPutModel{}
+
+	if err := db.Table("refresh_token rt").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT lh.ip, lh.user_agent, lh.created_at FROM login_history lh
+			INNER JOIN auth_token a ON a.id = lh.token_id
+			WHERE a.family_id = rt.family_id AND lh.deleted_at IS NULL
+			ORDER BY lh.created_at LIMIT 1
+		) lh ON true`).
+		Where("rt.user_id = ? AND rt.revoked = false AND rt.used_at IS NULL AND rt.expires_at > ? AND rt.deleted_at IS NULL", userID, time.Now()).
+		Order("rt.created_at DESC").
+		Select("rt.family_id AS id, rt.act_as, lh.ip, lh.user_agent, lh.created_at AS logged_in_at, rt.created_at AS last_active_at, rt.expires_at").
+		Scan(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Device = appmodels.NewDeviceOutPutModel(sessions[i].UserAgent)
+	}
+
+	return sessions, nil
+}
+
+// FamilyOf returns the session of the access token, it is nil for the tokens which are issued before the refresh
+// tokens
+func FamilyOf(db *gorm.DB, authTokenID uuid.UUID) (*uuid.UUID, error) {
+	var authToken models.AuthToken
+
+	if err := db.Where(`"id"=?`, authTokenID).First(&authToken).Error; err != nil {
+		return nil, err
+	}
+
+	return authToken.FamilyID, nil
+}
+
+// RevokeUserSession revokes a session of the user, it returns gorm.ErrRecordNotFound when the session does not
+// belong to the user
+func RevokeUserSession(db *gorm.DB, userID, familyID uuid.UUID) error {
+	var count int64
+
+	if err := db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND user_id = ?", familyID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return RevokeFamily(db, familyID)
+}
+
+// RevokeUserSessions revokes all the sessions and the access tokens of the user except the session and the access
+// token which are kept, pass nil to revoke all of them
+func RevokeUserSessions(db *gorm.DB, userID uuid.UUID, keepFamilyID, keepAuthTokenID *uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		refreshTokens := tx.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked = false", userID)
+		authTokens := tx.Model(&models.AuthToken{}).Where("user_id = ? AND revoked = false", userID)
+
+		if keepFamilyID != nil {
+			refreshTokens = refreshTokens.Where("family_id <> ?", *keepFamilyID)
+			authTokens = authTokens.Where("family_id IS NULL OR family_id <> ?", *keepFamilyID)
+		}
+
+		if keepAuthTokenID != nil {
+			authTokens = authTokens.Where("id <> ?", *keepAuthTokenID)
+		}
+
+		if err := refreshTokens.UpdateColumn("revoked", true).Error; err != nil {
+			return err
+		}
+
+		return authTokens.UpdateColumn("revoked", true).Error
+	})
+}
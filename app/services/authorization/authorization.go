@@ -1,6 +1,7 @@
 package authorization
 
 import (
+	"context"
 	"fmt"
 
 	errs "errors"
@@ -27,10 +28,24 @@ func CanAccess(ctx *app.HttpContext, actions ...string) error {
 		)
 	}
 
-	// if one of the actions is permitted, so user should be able to continue
+	// if one of the actions is permitted, so user should be able to continue. the request is limited to the scopes
+	// of the permitted actions unless one of them is not scoped
+	var (
+		scope     Scope
+		unlimited bool
+	)
+
 	for _, a := range actions {
-		if user.Role.Permitted(a) {
-			permitted = true
+		s, ok := user.Role.PermissionScope(a)
+		if !ok {
+			continue
+		}
+
+		permitted = true
+		if s == nil {
+			unlimited = true
+		} else {
+			scope = append(scope, s...)
 		}
 	}
 
@@ -43,5 +58,10 @@ func CanAccess(ctx *app.HttpContext, actions ...string) error {
 			),
 		)
 	}
+
+	if !unlimited {
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), consts.PermissionScope, scope))
+	}
+
 	return nil
 }
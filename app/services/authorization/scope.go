@@ -0,0 +1,88 @@
+package authorization
+
+import (
+	"context"
+	"strings"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/errors"
+	"github.com/esmailemami/eshop/models"
+	"gorm.io/gorm"
+)
+
+// Scope is the scopes of the permitted actions of the request, a resource is accessible when one of the scopes
+// allows it. A nil scope is not limited.
+type Scope []models.PermissionScope
+
+// GetScope returns the scope of the request which is kept by CanAccess
+func GetScope(ctx context.Context) Scope {
+	scope, _ := ctx.Value(consts.PermissionScope).(Scope)
+	return scope
+}
+
+// Allows reports whether the resource is in the scope
+func (s Scope) Allows(resource models.PermissionResource) bool {
+	if s == nil {
+		return true
+	}
+
+	for _, scope := range s {
+		if scope.Allows(resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Where limits the query to the resources of the scope, an empty column means the resources have not that attribute
+// so the scopes which are limited by it do not match any resource
+func (s Scope) Where(db *gorm.DB, categoryColumn, brandColumn string) *gorm.DB {
+	if s == nil {
+		return db
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	for _, scope := range s {
+		if scope.Empty() {
+			return db
+		}
+
+		var parts []string
+
+		for _, limit := range []struct {
+			column string
+			ids    interface{}
+			empty  bool
+		}{
+			{column: categoryColumn, ids: scope.CategoryIDs, empty: len(scope.CategoryIDs) == 0},
+			{column: brandColumn, ids: scope.BrandIDs, empty: len(scope.BrandIDs) == 0},
+		} {
+			switch {
+			case limit.empty:
+			case limit.column == "":
+				parts = append(parts, "FALSE")
+			default:
+				parts = append(parts, limit.column+" IN ?")
+				args = append(args, limit.ids)
+			}
+		}
+
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// CanAccessResource checks the resource against the scope of the request
+func CanAccessResource(ctx context.Context, resource models.PermissionResource) error {
+	if !GetScope(ctx).Allows(resource) {
+		return errors.NewForbiddenError(consts.OutOfPermissionScope, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package authorization
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/esmailemami/eshop/app"
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+func newContext(t *testing.T, role models.Role) *app.HttpContext {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodGet, "somewhere", nil)
+	req = req.WithContext(context.WithValue(req.Context(), consts.UserContext, models.User{Role: models.NewEffectiveRole([]models.Role{role}, nil)}))
+
+	return app.NewHttpContext(nil, req)
+}
+
+func TestCanAccessKeepsScope(t *testing.T) {
+	brand, otherBrand, category := uuid.New(), uuid.New(), uuid.New()
+
+	role := models.Role{
+		Permissions: models.RolePermissions{models.ACTION_PRODUCT_ADMIN_UPDATE, models.ACTION_PRODUCT_ADMIN_LIST},
+		PermissionScopes: models.RolePermissionScopes{
+			models.ACTION_PRODUCT_ADMIN_UPDATE: {BrandIDs: []uuid.UUID{brand}},
+		},
+	}
+
+	t.Run("scoped", func(t *testing.T) {
+		ctx := newContext(t, role)
+
+		if err := CanAccess(ctx, models.ACTION_PRODUCT_ADMIN_UPDATE); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := CanAccessResource(ctx, models.PermissionResource{CategoryID: &category, BrandID: &brand}); err != nil {
+			t.Errorf("CanAccessResource() of the brand = %v, want nil", err)
+		}
+
+		if err := CanAccessResource(ctx, models.PermissionResource{CategoryID: &category, BrandID: &otherBrand}); err == nil {
+			t.Errorf("CanAccessResource() of another brand = nil, want forbidden")
+		}
+	})
+
+	t.Run("unscoped", func(t *testing.T) {
+		ctx := newContext(t, role)
+
+		if err := CanAccess(ctx, models.ACTION_PRODUCT_ADMIN_LIST); err != nil {
+			t.Fatal(err)
+		}
+
+		if GetScope(ctx) != nil {
+			t.Errorf("GetScope() = %v, want nil", GetScope(ctx))
+		}
+	})
+
+	t.Run("one of the actions is not scoped", func(t *testing.T) {
+		ctx := newContext(t, role)
+
+		if err := CanAccess(ctx, models.ACTION_PRODUCT_ADMIN_UPDATE, models.ACTION_PRODUCT_ADMIN_LIST); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := CanAccessResource(ctx, models.PermissionResource{BrandID: &otherBrand}); err != nil {
+			t.Errorf("CanAccessResource() = %v, want nil", err)
+		}
+	})
+
+	t.Run("not permitted", func(t *testing.T) {
+		if err := CanAccess(newContext(t, role), models.ACTION_PRODUCT_ADMIN_DELETE); err == nil {
+			t.Errorf("CanAccess() = nil, want forbidden")
+		}
+	})
+}
+
+func TestScopeAllows(t *testing.T) {
+	brand, category, other := uuid.New(), uuid.New(), uuid.New()
+
+	scope := Scope{
+		{CategoryIDs: []uuid.UUID{category}, BrandIDs: []uuid.UUID{brand}},
+		{BrandIDs: []uuid.UUID{other}},
+	}
+
+	tests := []struct {
+		name     string
+		resource models.PermissionResource
+		want     bool
+	}{
+		{name: "category and brand", resource: models.PermissionResource{CategoryID: &category, BrandID: &brand}, want: true},
+		{name: "brand of another category", resource: models.PermissionResource{CategoryID: &other, BrandID: &brand}, want: false},
+		{name: "brand of the second scope", resource: models.PermissionResource{CategoryID: &other, BrandID: &other}, want: true},
+		{name: "without brand", resource: models.PermissionResource{CategoryID: &category}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scope.Allows(tt.resource); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !Scope(nil).Allows(models.PermissionResource{}) {
+		t.Errorf("Allows() of the nil scope = false, want true")
+	}
+}
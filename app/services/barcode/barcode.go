@@ -0,0 +1,112 @@
+package barcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// quiet zone around the bars, calculated by module
+	quietZone = 10
+)
+
+var digitsRegex = regexp.MustCompile("^[0-9]+$")
+
+// ValidGTIN checks the GTIN-8, GTIN-12 (UPC), GTIN-13 (EAN) and GTIN-14 formats and their check digit
+func ValidGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	if !digitsRegex.MatchString(code) {
+		return false
+	}
+
+	check, _ := strconv.Atoi(code[len(code)-1:])
+
+	return GTINCheckDigit(code[:len(code)-1]) == check
+}
+
+// GTINCheckDigit calculates the check digit of the given GTIN without its check digit
+func GTINCheckDigit(code string) int {
+	sum := 0
+	weight := 3
+
+	for i := len(code) - 1; i >= 0; i-- {
+		sum += int(code[i]-'0') * weight
+
+		if weight == 3 {
+			weight = 1
+		} else {
+			weight = 3
+		}
+	}
+
+	return (10 - sum%10) % 10
+}
+
+// Encode returns the modules of the barcode, true means a black bar.
+// 12 and 13 digits codes are encoded as EAN-13 and the others as Code 128. A 12 digits code is a UPC-A which has its
+// check digit, it is the EAN-13 of the same number with a leading zero.
+func Encode(code string) ([]bool, error) {
+	if code == "" {
+		return nil, errors.New("empty barcode")
+	}
+
+	if digitsRegex.MatchString(code) {
+		switch len(code) {
+		case 12:
+			return EncodeEAN13("0" + code)
+		case 13:
+			return EncodeEAN13(code)
+		}
+	}
+
+	return EncodeCode128(code)
+}
+
+// PNG renders the barcode as a png image
+func PNG(code string, moduleWidth, height int) ([]byte, error) {
+	modules, err := Encode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if moduleWidth <= 0 {
+		moduleWidth = 2
+	}
+
+	if height <= 0 {
+		height = 80
+	}
+
+	width := (len(modules) + 2*quietZone) * moduleWidth
+	img := image.NewGray(image.Rect(0, 0, width, height))
+
+	for x := 0; x < width; x++ {
+		module := x/moduleWidth - quietZone
+
+		c := color.Gray{Y: 255}
+		if module >= 0 && module < len(modules) && modules[module] {
+			c = color.Gray{Y: 0}
+		}
+
+		for y := 0; y < height; y++ {
+			img.SetGray(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
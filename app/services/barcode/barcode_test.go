@@ -0,0 +1,138 @@
+package barcode
+
+import (
+	"bytes"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"github.com/esmailemami/eshop/models"
+)
+
+func TestValidGTIN(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code: "4006381333931", want: true},
+		{code: "4006381333932", want: false},
+		{code: "036000291452", want: true},
+		{code: "96385074", want: true},
+		{code: "400638133393", want: false},
+		{code: "40063813339a1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := ValidGTIN(tt.code); got != tt.want {
+				t.Errorf("ValidGTIN() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeEAN13(t *testing.T) {
+	modules, err := EncodeEAN13("4006381333931")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(modules) != 95 {
+		t.Errorf("EncodeEAN13() wants 95 modules got: %d", len(modules))
+	}
+
+	if _, err := EncodeEAN13("4006381333932"); err == nil {
+		t.Error("EncodeEAN13() wants error for invalid check digit got: nil")
+	}
+}
+
+func TestEncodeUPCA(t *testing.T) {
+	modules, err := Encode("036000291452")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := EncodeEAN13("0036000291452")
+	if !reflect.DeepEqual(modules, want) {
+		t.Error("Encode() of the UPC-A wants the EAN-13 with a leading zero")
+	}
+
+	if _, err := Encode("036000291453"); err == nil {
+		t.Error("Encode() wants error for invalid UPC-A check digit got: nil")
+	}
+}
+
+func TestCode128Patterns(t *testing.T) {
+	for i, pattern := range code128Patterns {
+		sum := 0
+		for _, w := range pattern {
+			sum += int(w - '0')
+		}
+
+		want := 11
+		if i == code128Stop {
+			want = 13
+		}
+
+		if sum != want {
+			t.Errorf("pattern #%d width wants: %d got: %d", i, want, sum)
+		}
+	}
+}
+
+func TestEncodeCode128(t *testing.T) {
+	modules, err := EncodeCode128("SKU-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// start + 5 chars + checksum = 7 symbols of 11 modules and the stop symbol
+	if len(modules) != 7*11+13 {
+		t.Errorf("EncodeCode128() wants %d modules got: %d", 7*11+13, len(modules))
+	}
+
+	if _, err := EncodeCode128("کد"); err == nil {
+		t.Error("EncodeCode128() wants error for non ascii text got: nil")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	bts, err := PNG("4006381333931", 2, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(bts))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if img.Bounds().Dx() != (95+2*quietZone)*2 || img.Bounds().Dy() != 50 {
+		t.Errorf("PNG() invalid image size: %v", img.Bounds())
+	}
+}
+
+func TestItemCode(t *testing.T) {
+	var (
+		barcode = "4006381333931"
+		sku     = "SKU-1"
+		empty   = ""
+	)
+
+	tests := []struct {
+		name string
+		item models.ProductItem
+		want string
+		ok   bool
+	}{
+		{name: "barcode has priority", item: models.ProductItem{Barcode: &barcode, SKU: &sku}, want: barcode, ok: true},
+		{name: "sku", item: models.ProductItem{Barcode: &empty, SKU: &sku}, want: sku, ok: true},
+		{name: "no code", item: models.ProductItem{SKU: &empty}, want: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := ItemCode(&tt.item); got != tt.want || ok != tt.ok {
+				t.Errorf("ItemCode() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
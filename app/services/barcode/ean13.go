@@ -0,0 +1,55 @@
+package barcode
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ean13LCodes = []string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	ean13GCodes = []string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	ean13RCodes = []string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+
+	// the first digit is encoded by the parity of the left group
+	ean13Parities = []string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+// EncodeEAN13 encodes a 13 digits GTIN to 95 modules
+func EncodeEAN13(code string) ([]bool, error) {
+	if len(code) != 13 || !ValidGTIN(code) {
+		return nil, errors.New("invalid EAN-13 code")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("101")
+
+	parity := ean13Parities[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		digit := code[i] - '0'
+		if parity[i-1] == 'L' {
+			sb.WriteString(ean13LCodes[digit])
+		} else {
+			sb.WriteString(ean13GCodes[digit])
+		}
+	}
+
+	sb.WriteString("01010")
+
+	for i := 7; i <= 12; i++ {
+		sb.WriteString(ean13RCodes[code[i]-'0'])
+	}
+
+	sb.WriteString("101")
+
+	return toModules(sb.String()), nil
+}
+
+func toModules(pattern string) []bool {
+	modules := make([]bool, len(pattern))
+	for i, c := range pattern {
+		modules[i] = c == '1'
+	}
+
+	return modules
+}
@@ -0,0 +1,112 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	fileService "github.com/esmailemami/eshop/app/services/file"
+	"github.com/esmailemami/eshop/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoCode      = errors.New("the item has no barcode or SKU")
+	ErrInvalidCode = errors.New("the code cannot be encoded as a barcode")
+)
+
+// ItemCode returns the code of the barcode image of the item, the barcode has priority and the SKU is encoded as
+// Code 128 when there is no barcode
+func ItemCode(item *models.ProductItem) (string, bool) {
+	if item.Barcode != nil && *item.Barcode != "" {
+		return *item.Barcode, true
+	}
+
+	if item.SKU != nil && *item.SKU != "" {
+		return *item.SKU, true
+	}
+
+	return "", false
+}
+
+// SaveItemImage renders the code of the item and replaces its barcode image, the previous image is deleted by the
+// file service. It should be called in a transaction, the written file is removed when it fails.
+func SaveItemImage(db, tx *gorm.DB, item *models.ProductItem) (*models.File, string, error) {
+	code, ok := ItemCode(item)
+	if !ok {
+		return nil, "", ErrNoCode
+	}
+
+	bts, err := PNG(code, 0, 0)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidCode, err)
+	}
+
+	var (
+		fileType = models.FileTypeProductItemBarcode
+		dirPath  = fileService.GetPath(fileType.GetDirectory())
+		fileName = fileService.GenerateRandomFileName(code + ".png")
+		filePath = dirPath + "/" + fileName
+	)
+
+	if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
+		return nil, "", err
+	}
+
+	if err := fileService.WriteFile(filePath, bts); err != nil {
+		return nil, "", err
+	}
+
+	file := &models.File{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		MimeType:       "image/png",
+		Extension:      "png",
+		OriginalName:   code + ".png",
+		UniqueFileName: fileName,
+		FileType:       fileType,
+		ItemID:         item.ID,
+	}
+
+	if err := tx.Create(file).Error; err != nil {
+		fileService.DeleteFileByPath(filePath)
+		return nil, "", err
+	}
+
+	if err := fileService.InsertItemFile(db, tx, *item.ID, fileType, file); err != nil {
+		fileService.DeleteFileByPath(filePath)
+		return nil, "", err
+	}
+
+	item.BarcodeFileID = file.ID
+
+	return file, code, nil
+}
+
+// RefreshItemImage updates the barcode image of the item after its codes are changed, the image is regenerated
+// when the item had one and it is deleted when the new codes cannot be encoded. It should be called in a transaction.
+func RefreshItemImage(db, tx *gorm.DB, item *models.ProductItem) error {
+	if item.BarcodeFileID == nil {
+		return nil
+	}
+
+	_, _, err := SaveItemImage(db, tx, item)
+	if err == nil || !(errors.Is(err, ErrNoCode) || errors.Is(err, ErrInvalidCode)) {
+		return err
+	}
+
+	var file models.File
+
+	if err := tx.First(&file, "id = ?", *item.BarcodeFileID).Error; err != nil {
+		return err
+	}
+
+	if err := fileService.DeleteFile(db, tx, item.ID, &file); err != nil {
+		return err
+	}
+
+	item.BarcodeFileID = nil
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package bruteforce
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/cachedirver"
+	"github.com/esmailemami/eshop/app/services/logger"
+)
+
+// Policy is the limits of the failed attempts of a key, the failures are delayed progressively after DelayAfter and
+// the key is locked after LockAfter
+type Policy struct {
+	Name string
+	// DelayAfter is the number of the failures which are allowed without any delay
+	DelayAfter int
+	// BaseDelay is the delay of the first delayed failure, it is doubled by every next failure up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockAfter is the number of the failures which locks the key
+	LockAfter    int
+	LockDuration time.Duration
+	// Window is the time the failures are counted in, it starts by the first failure
+	Window time.Duration
+}
+
+var (
+	// LoginAccount limits the wrong passwords and codes of a username
+	LoginAccount = Policy{
+		Name:         "login:account",
+		DelayAfter:   3,
+		BaseDelay:    2 * time.Second,
+		MaxDelay:     time.Minute,
+		LockAfter:    10,
+		LockDuration: 15 * time.Minute,
+		Window:       time.Hour,
+	}
+	// LoginIP limits the failed logins of an ip address on all of the usernames
+	LoginIP = Policy{
+		Name:         "login:ip",
+		DelayAfter:   10,
+		BaseDelay:    time.Second,
+		MaxDelay:     30 * time.Second,
+		LockAfter:    50,
+		LockDuration: 30 * time.Minute,
+		Window:       time.Hour,
+	}
+	// RecoveryAccount limits the recovery requests of a mobile number or an email address, every request is counted
+	RecoveryAccount = Policy{
+		Name:         "recovery:account",
+		DelayAfter:   2,
+		BaseDelay:    30 * time.Second,
+		MaxDelay:     10 * time.Minute,
+		LockAfter:    5,
+		LockDuration: time.Hour,
+		Window:       time.Hour,
+	}
+	// RecoveryIP limits the recovery requests of an ip address, every request is counted
+	RecoveryIP = Policy{
+		Name:         "recovery:ip",
+		DelayAfter:   5,
+		BaseDelay:    10 * time.Second,
+		MaxDelay:     5 * time.Minute,
+		LockAfter:    20,
+		LockDuration: time.Hour,
+		Window:       time.Hour,
+	}
+)
+
+// driver returns the cache which keeps the counters
+var driver = cachedirver.GetConnection
+
+// Guard is the counter of a key in a policy
+type Guard struct {
+	Policy Policy
+	Key    string
+}
+
+// Guard returns the guard of the key, the key is case insensitive
+func (p Policy) Guard(key string) Guard {
+	return Guard{
+		Policy: p,
+		Key:    strings.ToLower(strings.TrimSpace(key)),
+	}
+}
+
+func (g Guard) cacheKey(suffix string) string {
+	return "bruteforce:" + g.Policy.Name + ":" + g.Key + ":" + suffix
+}
+
+// BlockedError is returned when the attempt is not allowed, RetryAfter is the time to the next allowed attempt
+type BlockedError struct {
+	Guard      Guard
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *BlockedError) Error() string {
+	if e.Locked {
+		return consts.AttemptsLocked
+	}
+	return consts.TooManyFailedAttempts
+}
+
+// Check returns a *BlockedError when any of the guards is delayed or locked. The cache errors do not block the
+// attempts, the passwords and codes are still checked.
+func Check(guards ...Guard) error {
+	now := time.Now()
+
+	for _, g := range guards {
+		if until, ok := blockedUntil(g.cacheKey("locked")); ok && until.After(now) {
+			return &BlockedError{Guard: g, Locked: true, RetryAfter: until.Sub(now)}
+		}
+
+		if until, ok := blockedUntil(g.cacheKey("delayed")); ok && until.After(now) {
+			return &BlockedError{Guard: g, RetryAfter: until.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// Fail counts a failed attempt on the guards and returns the guards which are locked by it
+func Fail(guards ...Guard) []Guard {
+	var locked []Guard
+
+	now := time.Now()
+
+	for _, g := range guards {
+		failures, err := driver().Incr(g.cacheKey("failures"), g.Policy.Window)
+		if err != nil {
+			logger.Default().WithField("key", g.Key).WithField("policy", g.Policy.Name).Error(err.Error())
+			continue
+		}
+
+		switch {
+		case failures >= int64(g.Policy.LockAfter):
+			until := now.Add(g.Policy.LockDuration)
+			block(g.cacheKey("locked"), until, g.Policy.LockDuration)
+			remove(g.cacheKey("failures"), g.cacheKey("delayed"))
+			locked = append(locked, g)
+
+		case failures > int64(g.Policy.DelayAfter):
+			d := g.Policy.delay(int(failures))
+			block(g.cacheKey("delayed"), now.Add(d), d)
+		}
+	}
+
+	return locked
+}
+
+// Reset forgets the failures of the guards, it is called by the successful attempts. The lock is not removed.
+func Reset(guards ...Guard) {
+	for _, g := range guards {
+		remove(g.cacheKey("failures"), g.cacheKey("delayed"))
+	}
+}
+
+// Unlock removes the lock and the failures of the guards, the admins use it to let the user in again
+func Unlock(guards ...Guard) {
+	for _, g := range guards {
+		remove(g.cacheKey("failures"), g.cacheKey("delayed"), g.cacheKey("locked"))
+	}
+}
+
+// delay returns the delay after the failure, it is doubled by every failure after DelayAfter
+func (p Policy) delay(failures int) time.Duration {
+	d := p.BaseDelay
+
+	for i := p.DelayAfter + 1; i < failures; i++ {
+		d *= 2
+		if d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	return d
+}
+
+func blockedUntil(key string) (time.Time, bool) {
+	value, err := driver().Get(key)
+	if err != nil || value == "" {
+		return time.Time{}, false
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, unix), true
+}
+
+func block(key string, until time.Time, expiration time.Duration) {
+	if err := driver().Set(key, strconv.FormatInt(until.UnixNano(), 10), expiration); err != nil {
+		logger.Default().WithField("key", key).Error(err.Error())
+	}
+}
+
+// remove deletes the keys which exist, the driver reports the missing keys as an error
+func remove(keys ...string) {
+	for _, key := range keys {
+		if value, err := driver().Get(key); err != nil || value == "" {
+			continue
+		}
+
+		_ = driver().Delete(key)
+	}
+}
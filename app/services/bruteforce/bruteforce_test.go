@@ -0,0 +1,206 @@
+package bruteforce
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/cachedirver"
+)
+
+// memoryDriver keeps the counters in memory, the expirations are ignored
+type memoryDriver struct {
+	values map[string]string
+}
+
+func (m *memoryDriver) SetConnection(conn interface{}) {}
+
+func (m *memoryDriver) Set(key string, value interface{}, expiration time.Duration) error {
+	m.values[key] = fmt.Sprint(value)
+	return nil
+}
+
+func (m *memoryDriver) Get(key string) (string, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return "", errors.New("ErrCacheRecordNotFound")
+	}
+	return value, nil
+}
+
+func (m *memoryDriver) Incr(key string, expiration time.Duration) (int64, error) {
+	var value int64
+	fmt.Sscan(m.values[key], &value)
+	value++
+	m.values[key] = fmt.Sprint(value)
+	return value, nil
+}
+
+func (m *memoryDriver) UnmarshalToObject(key string, object interface{}) error {
+	return errors.New("not supported")
+}
+
+func (m *memoryDriver) Delete(key string) error {
+	if _, ok := m.values[key]; !ok {
+		return errors.New("ErrCacheRecordNotFound")
+	}
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memoryDriver) DeleteByPattern(key string) (int64, error) {
+	var count int64
+	for k := range m.values {
+		if strings.HasPrefix(k, key) {
+			delete(m.values, k)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryDriver) Lock() error    { return nil }
+func (m *memoryDriver) Unlock() error  { return nil }
+func (m *memoryDriver) ResetDB() error { m.values = map[string]string{}; return nil }
+
+func useMemoryDriver(t *testing.T) *memoryDriver {
+	m := &memoryDriver{values: map[string]string{}}
+
+	previous := driver
+	driver = func() cachedirver.CacheDriver { return m }
+	t.Cleanup(func() { driver = previous })
+
+	return m
+}
+
+var testPolicy = Policy{
+	Name:         "test",
+	DelayAfter:   2,
+	BaseDelay:    time.Minute,
+	MaxDelay:     5 * time.Minute,
+	LockAfter:    5,
+	LockDuration: time.Hour,
+	Window:       time.Hour,
+}
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 3, want: time.Minute},
+		{failures: 4, want: 2 * time.Minute},
+		{failures: 5, want: 4 * time.Minute},
+		{failures: 6, want: 5 * time.Minute},
+		{failures: 20, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := testPolicy.delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%v) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestFailDelaysAndLocks(t *testing.T) {
+	useMemoryDriver(t)
+
+	guard := testPolicy.Guard(" Admin ")
+
+	// the first failures are not delayed
+	for i := 0; i < testPolicy.DelayAfter; i++ {
+		Fail(guard)
+		if err := Check(guard); err != nil {
+			t.Fatalf("Check() after %v failures = %v, want nil", i+1, err)
+		}
+	}
+
+	Fail(guard)
+
+	var blocked *BlockedError
+	if err := Check(guard); !errors.As(err, &blocked) || blocked.Locked {
+		t.Fatalf("Check() = %v, want a delay", err)
+	}
+	if blocked.RetryAfter <= 0 || blocked.RetryAfter > testPolicy.BaseDelay {
+		t.Errorf("RetryAfter = %v, want up to %v", blocked.RetryAfter, testPolicy.BaseDelay)
+	}
+
+	for i := testPolicy.DelayAfter + 1; i < testPolicy.LockAfter-1; i++ {
+		if locked := Fail(guard); len(locked) != 0 {
+			t.Fatalf("Fail() locked after %v failures", i+1)
+		}
+	}
+
+	if locked := Fail(guard); len(locked) != 1 || locked[0].Key != "admin" {
+		t.Fatalf("Fail() = %v, want the guard locked", locked)
+	}
+
+	if err := Check(guard); !errors.As(err, &blocked) || !blocked.Locked {
+		t.Fatalf("Check() = %v, want locked", err)
+	}
+
+	// the same key with another case is the same account
+	if err := Check(testPolicy.Guard("ADMIN")); err == nil {
+		t.Errorf("Check() of the same key with another case = nil, want locked")
+	}
+}
+
+func TestResetKeepsLock(t *testing.T) {
+	useMemoryDriver(t)
+
+	guard := testPolicy.Guard("user")
+
+	for i := 0; i < testPolicy.DelayAfter+1; i++ {
+		Fail(guard)
+	}
+
+	Reset(guard)
+	if err := Check(guard); err != nil {
+		t.Fatalf("Check() after Reset() = %v, want nil", err)
+	}
+
+	for i := 0; i < testPolicy.LockAfter; i++ {
+		Fail(guard)
+	}
+
+	Reset(guard)
+	if err := Check(guard); err == nil {
+		t.Fatalf("Check() after Reset() of the locked guard = nil, want locked")
+	}
+
+	Unlock(guard)
+	if err := Check(guard); err != nil {
+		t.Fatalf("Check() after Unlock() = %v, want nil", err)
+	}
+}
+
+func TestGuardsAreIndependent(t *testing.T) {
+	useMemoryDriver(t)
+
+	ip := LoginIP.Guard("10.0.0.1")
+
+	// an attacker tries many usernames from one ip address
+	for i := 0; i < LoginIP.LockAfter; i++ {
+		Fail(LoginAccount.Guard(fmt.Sprintf("user%d", i)), ip)
+	}
+
+	if err := Check(LoginAccount.Guard("another"), ip); err == nil {
+		t.Errorf("Check() of the locked ip address = nil, want locked")
+	}
+
+	if err := Check(LoginAccount.Guard("another"), LoginIP.Guard("10.0.0.2")); err != nil {
+		t.Errorf("Check() of another ip address = %v, want nil", err)
+	}
+}
+
+func TestCheckIgnoresCacheErrors(t *testing.T) {
+	m := useMemoryDriver(t)
+
+	guard := testPolicy.Guard("user")
+	m.values[guard.cacheKey("locked")] = "invalid"
+
+	if err := Check(guard); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+}
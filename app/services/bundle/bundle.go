@@ -0,0 +1,150 @@
+package bundle
+
+import (
+	"errors"
+
+	"github.com/esmailemami/eshop/app/services/inventory"
+	"github.com/esmailemami/eshop/app/services/product_schedule"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock of the bundle components")
+
+// StockQuery is the available quantity of the bundle, it is the number of complete bundles which can be
+// made from the stock of the components. alias is the table alias of the bundle.
+func StockQuery(alias string) string {
+	return "(SELECT COALESCE(MIN(CASE WHEN pi2.deleted_at IS NULL AND " + product_schedule.VisibleCond("pi2") +
+		" THEN FLOOR(pi2.quantity / bi.quantity)::int ELSE 0 END), 0)" +
+		" FROM bundle_item bi INNER JOIN product_item pi2 ON pi2.id = bi.product_item_id" +
+		" WHERE bi.bundle_id = " + alias + ".id AND bi.deleted_at IS NULL)"
+}
+
+// Component is a component of a bundle with its list price
+type Component struct {
+	ProductItemID uuid.UUID `gorm:"column:product_item_id"`
+	Quantity      int       `gorm:"column:quantity"`
+	ListPrice     float64   `gorm:"column:list_price"`
+	Stock         int       `gorm:"column:stock"`
+}
+
+// Allocate splits the bundle price between the components proportional to their list prices and returns the
+// price of each unit of the components, so the sum of the unit prices multiplied by the quantities is the price.
+func Allocate(price float64, components []Component) []float64 {
+	result := make([]float64, len(components))
+
+	var listTotal float64
+	var units int
+
+	for _, component := range components {
+		listTotal += component.ListPrice * float64(component.Quantity)
+		units += component.Quantity
+	}
+
+	if units == 0 {
+		return result
+	}
+
+	for i, component := range components {
+		if listTotal > 0 {
+			result[i] = component.ListPrice * price / listTotal
+		} else {
+			// without list prices the bundle price is divided equally between the units
+			result[i] = price / float64(units)
+		}
+	}
+
+	return result
+}
+
+// Checkout deducts the stock of the components of the bundle items of the order and keeps the breakdown
+// of each item, it should be called in a transaction.
+func Checkout(tx *gorm.DB, orderID uuid.UUID) error {
+	var orderItems []models.OrderItem
+
+	if err := tx.Preload("Bundle").
+		Where("order_id = ? AND bundle_id IS NOT NULL", orderID).
+		Find(&orderItems).Error; err != nil {
+		return err
+	}
+
+	for _, orderItem := range orderItems {
+		if orderItem.Bundle == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		var components []Component
+
+		// the component rows are locked until the end of the checkout
+		if err := tx.Table("bundle_item bi").
+			Joins("INNER JOIN product_item pi2 ON pi2.id = bi.product_item_id").
+			Where("bi.bundle_id = ? AND bi.deleted_at IS NULL", *orderItem.BundleID).
+			Where("pi2.deleted_at IS NULL").
+			Where(product_schedule.VisibleCond("pi2")).
+			Select("bi.product_item_id, bi.quantity, pi2.price AS list_price, pi2.quantity AS stock").
+			Order("bi.created_at, bi.id").
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "pi2"}}).
+			Find(&components).Error; err != nil {
+			return err
+		}
+
+		var itemsCount int64
+
+		if err := tx.Model(&models.BundleItem{}).
+			Where("bundle_id = ?", *orderItem.BundleID).
+			Count(&itemsCount).Error; err != nil {
+			return err
+		}
+
+		// a deleted or hidden component makes the bundle unavailable, it is not sold without the component
+		if len(components) == 0 || int64(len(components)) != itemsCount {
+			return ErrInsufficientStock
+		}
+
+		prices := Allocate(orderItem.Bundle.Price, components)
+
+		// the total price of a bundle is the list price of its components
+		var listPrice float64
+
+		for i, component := range components {
+			quantity := component.Quantity * orderItem.Quantity
+
+			listPrice += component.ListPrice * float64(component.Quantity)
+
+			if component.Stock < quantity {
+				return ErrInsufficientStock
+			}
+
+			if err := inventory.ChangeItemQuantity(tx, component.ProductItemID, -quantity,
+				models.StockMovementReasonSale, &orderID, nil); err != nil {
+				return err
+			}
+
+			if err := tx.Create(&models.OrderItemComponent{
+				Model: models.Model{
+					ID: models.NewID(),
+				},
+				OrderItemID:   *orderItem.ID,
+				ProductItemID: component.ProductItemID,
+				Quantity:      quantity,
+				ListPrice:     component.ListPrice,
+				Price:         prices[i],
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.OrderItem{}).
+			Where("id = ?", *orderItem.ID).
+			UpdateColumns(map[string]interface{}{
+				"price":       orderItem.Bundle.Price,
+				"total_price": listPrice,
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
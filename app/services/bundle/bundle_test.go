@@ -0,0 +1,61 @@
+package bundle
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name       string
+		price      float64
+		components []Component
+		want       []float64
+	}{
+		{
+			name:  "proportional",
+			price: 900,
+			components: []Component{
+				{Quantity: 1, ListPrice: 800},
+				{Quantity: 2, ListPrice: 100},
+			},
+			want: []float64{720, 90},
+		},
+		{
+			name:  "without list prices",
+			price: 300,
+			components: []Component{
+				{Quantity: 1, ListPrice: 0},
+				{Quantity: 2, ListPrice: 0},
+			},
+			want: []float64{100, 100},
+		},
+		{
+			name:       "empty",
+			price:      300,
+			components: []Component{},
+			want:       []float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Allocate(tt.price, tt.components)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Allocate() wants %d prices got: %d", len(tt.want), len(got))
+			}
+
+			var total float64
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("Allocate()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+				total += got[i] * float64(tt.components[i].Quantity)
+			}
+
+			if len(got) > 0 && math.Abs(total-tt.price) > 1e-9 {
+				t.Errorf("Allocate() total = %v, want %v", total, tt.price)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ type CacheDriver interface {
 	SetConnection(conn interface{})
 	Set(key string, value interface{}, expiration time.Duration) error
 	Get(key string) (string, error)
+	// Incr increments the counter of the key, the expiration is set by the first increment
+	Incr(key string, expiration time.Duration) (int64, error)
 	UnmarshalToObject(key string, object interface{}) error
 	Delete(key string) error
 	DeleteByPattern(key string) (deletedCount int64, err error)
@@ -28,6 +28,11 @@ func (m *CacheDriverMock) Get(key string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *CacheDriverMock) Incr(key string, expiration time.Duration) (int64, error) {
+	args := m.Called(key, expiration)
+	return int64(args.Int(0)), args.Error(1)
+}
+
 func (m *CacheDriverMock) GetByPattern(pattern string) (map[string]string, error) {
 	args := m.Called(pattern)
 	return args.Get(0).(map[string]string), args.Error(1)
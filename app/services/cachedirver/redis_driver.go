@@ -69,6 +69,30 @@ func (driver *RedisDriver) Get(key string) (str string, err error) {
 	return value.Val(), nil
 }
 
+func (driver *RedisDriver) Incr(key string, expiration time.Duration) (int64, error) {
+	value, err := driver.client.Incr(context.Background(), key).Result()
+	if err != nil {
+		log.Err(err).
+			Str("func", "RedisDriver.Incr").
+			Str("@", "driver.client.Incr").
+			Send()
+		return 0, err
+	}
+
+	// the next increments do not extend the expiration
+	if value == 1 && expiration > 0 {
+		if err := driver.client.Expire(context.Background(), key, expiration).Err(); err != nil {
+			log.Err(err).
+				Str("func", "RedisDriver.Incr").
+				Str("@", "driver.client.Expire").
+				Send()
+			return value, err
+		}
+	}
+
+	return value, nil
+}
+
 func (driver *RedisDriver) UnmarshalToObject(key string, object interface{}) error {
 	value, err := driver.Get(key)
 	if err != nil {
@@ -107,18 +131,29 @@ func (driver *RedisDriver) Delete(key string) error {
 }
 
 func (driver *RedisDriver) DeleteByPattern(key string) (deletedCount int64, err error) {
-	scan := driver.client.Scan(context.Background(), 0, key+"*", 0)
-	res, _, err := scan.Result()
-	if err != nil {
-		log.Err(err).
-			Str("func", "RedisDriver.DeleteByPattern").
-			Str("@", "scan.Result").
-			Send()
-		return 0, err
-	}
-	driver.client.Del(context.Background(), res...)
+	// a scan returns a page of the keys, it is repeated until the cursor is back to zero
+	var cursor uint64
+	for {
+		scan := driver.client.Scan(context.Background(), cursor, key+"*", 0)
+		res, next, err := scan.Result()
+		if err != nil {
+			log.Err(err).
+				Str("func", "RedisDriver.DeleteByPattern").
+				Str("@", "scan.Result").
+				Send()
+			return deletedCount, err
+		}
+
+		if len(res) > 0 {
+			driver.client.Del(context.Background(), res...)
+			deletedCount += int64(len(res))
+		}
 
-	return int64(len(res)), nil
+		if next == 0 {
+			return deletedCount, nil
+		}
+		cursor = next
+	}
 }
 
 func (driver *RedisDriver) ResetDB() error {
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/logger"
+)
+
+type Event struct {
+	Name       string      `json:"name"`
+	Payload    interface{} `json:"payload"`
+	OccurredAt time.Time   `json:"occurredAt"`
+}
+
+type Handler func(event Event)
+
+var (
+	mu       sync.RWMutex
+	handlers = map[string][]Handler{}
+)
+
+// Subscribe registers the handler for the event name, use "*" to receive all the events
+func Subscribe(name string, handler Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	handlers[name] = append(handlers[name], handler)
+}
+
+// Publish calls the handlers of the events synchronously, a panic in a handler does not stop the others
+func Publish(events ...Event) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, event := range events {
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now()
+		}
+
+		for _, handler := range handlers[event.Name] {
+			call(handler, event)
+		}
+
+		for _, handler := range handlers["*"] {
+			call(handler, event)
+		}
+	}
+}
+
+func call(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Default().WithField("Event", event.Name).Errorf("event handler panic: %v", r)
+		}
+	}()
+
+	handler(event)
+}
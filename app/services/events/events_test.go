@@ -0,0 +1,32 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	var (
+		received []string
+		all      int
+	)
+
+	Subscribe("test.first", func(event Event) {
+		received = append(received, event.Name)
+	})
+	Subscribe("test.panic", func(event Event) {
+		panic("handler panic")
+	})
+	Subscribe("*", func(event Event) {
+		all++
+	})
+
+	Publish(Event{Name: "test.first"}, Event{Name: "test.panic"}, Event{Name: "test.other"})
+
+	if len(received) != 1 || received[0] != "test.first" {
+		t.Errorf("Publish() wants [test.first] got: %v", received)
+	}
+
+	if all != 3 {
+		t.Errorf("Publish() wants 3 events for the wildcard handler got: %d", all)
+	}
+}
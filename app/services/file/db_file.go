@@ -66,7 +66,7 @@ func InsertItemFile(db, tx *gorm.DB, itemID uuid.UUID, fileType models.FileType,
 	}
 
 	var (
-		fileID string
+		fileID sql.NullString
 		file   models.File
 	)
 
@@ -76,8 +76,12 @@ func InsertItemFile(db, tx *gorm.DB, itemID uuid.UUID, fileType models.FileType,
 		if err := tx.Table(table).Where("id = ?", itemID).Select(fileColumn).Limit(1).Find(&fileID).Error; err != nil {
 			return err
 		}
-		if err := tx.Model(&models.File{}).Where("id = ?", fileID).First(&file).Error; err != nil {
-			return err
+
+		// nullable file columns may not have any file yet
+		if fileID.Valid {
+			if err := tx.Model(&models.File{}).Where("id = ?", fileID.String).First(&file).Error; err != nil {
+				return err
+			}
 		}
 	}
 
@@ -85,7 +89,7 @@ func InsertItemFile(db, tx *gorm.DB, itemID uuid.UUID, fileType models.FileType,
 		return err
 	}
 
-	if !multiple && fileType.CanForceDelete() {
+	if !multiple && fileType.CanForceDelete() && fileID.Valid {
 		if err := DeleteFile(db, tx, &itemID, &file); err != nil {
 			return err
 		}
@@ -95,7 +99,7 @@ func InsertItemFile(db, tx *gorm.DB, itemID uuid.UUID, fileType models.FileType,
 }
 
 func DeleteFileWithNoItemID(db, tx *gorm.DB, file *models.File) error {
-	itemID, err := getItemID(db, file)
+	itemID, err := GetItemID(db, file)
 
 	if err != nil {
 		return err
@@ -188,7 +192,8 @@ func ChangeFilePriority(db, tx *gorm.DB, itemID, fileID uuid.UUID, fileType mode
 	return nil
 }
 
-func getItemID(db *gorm.DB, file *models.File) (*uuid.UUID, error) {
+// GetItemID returns the item which the file belongs to, it is nil when the file is not attached to any item
+func GetItemID(db *gorm.DB, file *models.File) (*uuid.UUID, error) {
 	multiple, _, table, mapTable, foreignColumn, fileColumn, _, _ := file.FileType.GetInfo()
 
 	var itemID sql.NullString
@@ -0,0 +1,237 @@
+package inventory
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock in the locations")
+	ErrManagedStock      = errors.New("the stock of the item is managed by the locations")
+)
+
+// Strategy is how the locations which an order is shipped from are chosen
+type Strategy int
+
+const (
+	// StrategyNearest prefers the locations which their postal code is the nearest to the order's postal code
+	StrategyNearest Strategy = iota
+	// StrategyHighestStock prefers the locations which have the highest stock of the item
+	StrategyHighestStock
+)
+
+// Candidate is the stock of a product item in a location
+type Candidate struct {
+	WarehouseID uuid.UUID `gorm:"column:warehouse_id"`
+	Code        string    `gorm:"column:code"`
+	PostalCode  string    `gorm:"column:postal_code"`
+	Stock       int       `gorm:"column:stock"`
+}
+
+// Allocation is the quantity which is shipped from a location
+type Allocation struct {
+	WarehouseID uuid.UUID
+	Quantity    int
+}
+
+// Allocate chooses the locations of the quantity. A single location which has the whole quantity is preferred
+// and otherwise the quantity is split between the locations in the order of the strategy.
+func Allocate(candidates []Candidate, postalCode string, quantity int, strategy Strategy) ([]Allocation, error) {
+	sorted := make([]Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Stock > 0 {
+			sorted = append(sorted, candidate)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if strategy == StrategyNearest {
+			pi, pj := commonPrefix(sorted[i].PostalCode, postalCode), commonPrefix(sorted[j].PostalCode, postalCode)
+			if pi != pj {
+				return pi > pj
+			}
+		}
+
+		if sorted[i].Stock != sorted[j].Stock {
+			return sorted[i].Stock > sorted[j].Stock
+		}
+
+		return sorted[i].Code < sorted[j].Code
+	})
+
+	for _, candidate := range sorted {
+		if candidate.Stock >= quantity {
+			return []Allocation{{WarehouseID: candidate.WarehouseID, Quantity: quantity}}, nil
+		}
+	}
+
+	result := []Allocation{}
+	remaining := quantity
+
+	for _, candidate := range sorted {
+		if remaining == 0 {
+			break
+		}
+
+		taken := candidate.Stock
+		if taken > remaining {
+			taken = remaining
+		}
+
+		result = append(result, Allocation{WarehouseID: candidate.WarehouseID, Quantity: taken})
+		remaining -= taken
+	}
+
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+
+	return result, nil
+}
+
+// commonPrefix is the length of the common prefix of the postal codes, the postal codes are assigned by
+// region so the longer prefix means the nearer location
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	return n
+}
+
+// Move changes the stock of the product item in the location and logs the movement, quantity is negative
+// for the outgoing stock. It should be called in a transaction.
+func Move(tx *gorm.DB, warehouseID, productItemID uuid.UUID, quantity int, reason models.StockMovementReason, sourceID *uuid.UUID, note *string) error {
+	var stock models.WarehouseStock
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Limit(1).
+		Find(&stock, "warehouse_id = ? AND product_item_id = ?", warehouseID, productItemID).Error; err != nil {
+		return err
+	}
+
+	if stock.Quantity+quantity < 0 {
+		return ErrInsufficientStock
+	}
+
+	if stock.ID == nil {
+		stock = models.WarehouseStock{
+			Model: models.Model{
+				ID: models.NewID(),
+			},
+			WarehouseID:   warehouseID,
+			ProductItemID: productItemID,
+			Quantity:      quantity,
+		}
+
+		// the row of a new item is not locked, the unique index rejects the concurrent creations
+		if err := tx.Create(&stock).Error; err != nil {
+			return err
+		}
+	} else {
+		stock.Quantity += quantity
+
+		if err := tx.Model(&models.WarehouseStock{}).
+			Where("id = ?", stock.ID).
+			UpdateColumn("quantity", stock.Quantity).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Create(&models.StockMovement{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		WarehouseID:   &warehouseID,
+		ProductItemID: productItemID,
+		Quantity:      quantity,
+		Balance:       stock.Quantity,
+		Reason:        reason,
+		SourceID:      sourceID,
+		Note:          note,
+	}).Error
+}
+
+// Transfer moves the items of the transfer document from its source to its destination, it should be called
+// in a transaction.
+func Transfer(tx *gorm.DB, transfer *models.StockTransfer) error {
+	for _, item := range transfer.Items {
+		if err := Move(tx, transfer.FromWarehouseID, item.ProductItemID, -item.Quantity,
+			models.StockMovementReasonTransferOut, transfer.ID, transfer.Note); err != nil {
+			return err
+		}
+
+		if err := Move(tx, transfer.ToWarehouseID, item.ProductItemID, item.Quantity,
+			models.StockMovementReasonTransferIn, transfer.ID, transfer.Note); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Checkout ships the items of the order from the locations, the bundles should be checked out before so their
+// components are allocated too. The items which have no stock row in the active locations are not managed by
+// the locations and are skipped. It should be called in a transaction.
+func Checkout(tx *gorm.DB, orderID uuid.UUID, postalCode string, strategy Strategy) error {
+	var demands []struct {
+		ProductItemID uuid.UUID `gorm:"column:product_item_id"`
+		Quantity      int       `gorm:"column:quantity"`
+	}
+
+	if err := tx.Raw(`
+		SELECT product_item_id, SUM(quantity) AS quantity FROM (
+			SELECT oi.product_item_id, oi.quantity FROM order_item oi
+				WHERE oi.order_id = ? AND oi.product_item_id IS NOT NULL AND oi.deleted_at IS NULL
+			UNION ALL
+			SELECT oic.product_item_id, oic.quantity FROM order_item_component oic
+				INNER JOIN order_item oi ON oi.id = oic.order_item_id
+				WHERE oi.order_id = ? AND oi.deleted_at IS NULL AND oic.deleted_at IS NULL
+		) t
+		GROUP BY product_item_id
+		ORDER BY product_item_id`,
+		orderID, orderID,
+	).Scan(&demands).Error; err != nil {
+		return err
+	}
+
+	for _, demand := range demands {
+		var candidates []Candidate
+
+		// the stocks are locked in the order of the items, so the concurrent checkouts do not deadlock
+		if err := tx.Table("warehouse_stock ws").
+			Joins("INNER JOIN warehouse w ON w.id = ws.warehouse_id").
+			Where("ws.product_item_id = ? AND ws.deleted_at IS NULL", demand.ProductItemID).
+			Where("w.deleted_at IS NULL AND w.is_active").
+			Select("ws.warehouse_id, w.code, w.postal_code, ws.quantity AS stock").
+			Order("ws.warehouse_id").
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "ws"}}).
+			Find(&candidates).Error; err != nil {
+			return err
+		}
+
+		if len(candidates) == 0 {
+			continue
+		}
+
+		allocations, err := Allocate(candidates, postalCode, demand.Quantity, strategy)
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			if err := Move(tx, allocation.WarehouseID, demand.ProductItemID, -allocation.Quantity,
+				models.StockMovementReasonSale, &orderID, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAllocate(t *testing.T) {
+	tehran := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	karaj := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	store := uuid.MustParse("00000000-0000-0000-0000-000000000003")
+
+	candidates := []Candidate{
+		{WarehouseID: tehran, Code: "THR", PostalCode: "1134567890", Stock: 10},
+		{WarehouseID: karaj, Code: "KRJ", PostalCode: "3134567890", Stock: 50},
+		{WarehouseID: store, Code: "STR", PostalCode: "1198765432", Stock: 2},
+	}
+
+	tests := []struct {
+		name       string
+		postalCode string
+		quantity   int
+		strategy   Strategy
+		want       []Allocation
+		wantErr    error
+	}{
+		{
+			name:       "nearest",
+			postalCode: "1198700000",
+			quantity:   2,
+			strategy:   StrategyNearest,
+			want:       []Allocation{{WarehouseID: store, Quantity: 2}},
+		},
+		{
+			name:       "nearest which has the whole quantity",
+			postalCode: "1198700000",
+			quantity:   5,
+			strategy:   StrategyNearest,
+			want:       []Allocation{{WarehouseID: tehran, Quantity: 5}},
+		},
+		{
+			name:       "nearest split",
+			postalCode: "1198700000",
+			quantity:   55,
+			strategy:   StrategyNearest,
+			want: []Allocation{
+				{WarehouseID: store, Quantity: 2},
+				{WarehouseID: tehran, Quantity: 10},
+				{WarehouseID: karaj, Quantity: 43},
+			},
+		},
+		{
+			name:       "highest stock",
+			postalCode: "1198700000",
+			quantity:   2,
+			strategy:   StrategyHighestStock,
+			want:       []Allocation{{WarehouseID: karaj, Quantity: 2}},
+		},
+		{
+			name:       "insufficient stock",
+			postalCode: "1198700000",
+			quantity:   63,
+			strategy:   StrategyHighestStock,
+			wantErr:    ErrInsufficientStock,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Allocate(candidates, tt.postalCode, tt.quantity, tt.strategy)
+			if err != tt.wantErr {
+				t.Fatalf("Allocate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
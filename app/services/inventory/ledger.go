@@ -0,0 +1,146 @@
+package inventory
+
+import (
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReconciliationNote is the note of the movements which correct the ledger
+const ReconciliationNote = "Reconciliation"
+
+// ChangeItemQuantity changes the sellable quantity of the product item and appends the change to the ledger,
+// quantity is negative for the decrease. It should be called in a transaction.
+func ChangeItemQuantity(tx *gorm.DB, productItemID uuid.UUID, quantity int, reason models.StockMovementReason, sourceID *uuid.UUID, note *string) error {
+	if quantity == 0 {
+		return nil
+	}
+
+	var balances []int
+
+	if err := tx.Raw(
+		"UPDATE product_item SET quantity = quantity + ? WHERE id = ? AND quantity + ? >= 0 RETURNING quantity",
+		quantity, productItemID, quantity,
+	).Scan(&balances).Error; err != nil {
+		return err
+	}
+
+	if len(balances) == 0 {
+		return ErrInsufficientStock
+	}
+
+	return appendItemMovement(tx, productItemID, quantity, balances[0], reason, sourceID, note)
+}
+
+// AdjustItemQuantity changes the quantity of the product item which is not stocked in the locations, ErrManagedStock
+// is returned for the items which have a stock row since their quantity follows the location adjustments. It should
+// be called in a transaction.
+func AdjustItemQuantity(tx *gorm.DB, productItemID uuid.UUID, quantity int, reason models.StockMovementReason, sourceID *uuid.UUID, note *string) error {
+	if quantity == 0 {
+		return nil
+	}
+
+	var stocks int64
+
+	if err := tx.Model(&models.WarehouseStock{}).
+		Where("product_item_id = ?", productItemID).
+		Count(&stocks).Error; err != nil {
+		return err
+	}
+
+	if stocks > 0 {
+		return ErrManagedStock
+	}
+
+	return ChangeItemQuantity(tx, productItemID, quantity, reason, sourceID, note)
+}
+
+// SellReservations turns the reservations of the cart items of the order into sales, the quantity of the items is
+// kept because it is deducted by the reservations. It should be called in a transaction.
+func SellReservations(tx *gorm.DB, orderID uuid.UUID) error {
+	var items []struct {
+		ProductItemID uuid.UUID `gorm:"column:product_item_id"`
+		Quantity      int       `gorm:"column:quantity"`
+	}
+
+	// the items are locked in their order, so the concurrent checkouts do not deadlock
+	if err := tx.Model(&models.OrderItem{}).
+		Where("order_id = ? AND product_item_id IS NOT NULL", orderID).
+		Select("product_item_id, SUM(quantity) AS quantity").
+		Group("product_item_id").
+		Order("product_item_id").
+		Scan(&items).Error; err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var balances []int
+
+		if err := tx.Raw("UPDATE product_item SET quantity = quantity WHERE id = ? RETURNING quantity",
+			item.ProductItemID).Scan(&balances).Error; err != nil {
+			return err
+		}
+
+		if len(balances) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := appendItemMovement(tx, item.ProductItemID, item.Quantity, balances[0]+item.Quantity,
+			models.StockMovementReasonRelease, &orderID, nil); err != nil {
+			return err
+		}
+
+		if err := appendItemMovement(tx, item.ProductItemID, -item.Quantity, balances[0],
+			models.StockMovementReasonSale, &orderID, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reconcile appends a correction to the ledger of the product item when its sum differs from the quantity of
+// the item, the quantity of the item is kept. It returns the correction.
+func Reconcile(tx *gorm.DB, productItemID uuid.UUID) (int, error) {
+	var item models.ProductItem
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "quantity").
+		First(&item, "id = ?", productItemID).Error; err != nil {
+		return 0, err
+	}
+
+	var ledger int
+
+	if err := tx.Model(&models.StockMovement{}).
+		Where("product_item_id = ? AND warehouse_id IS NULL", productItemID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&ledger).Error; err != nil {
+		return 0, err
+	}
+
+	difference := item.Quantity - ledger
+	if difference == 0 {
+		return 0, nil
+	}
+
+	note := ReconciliationNote
+
+	return difference, appendItemMovement(tx, productItemID, difference, item.Quantity,
+		models.StockMovementReasonAdjustment, nil, &note)
+}
+
+func appendItemMovement(tx *gorm.DB, productItemID uuid.UUID, quantity, balance int, reason models.StockMovementReason, sourceID *uuid.UUID, note *string) error {
+	return tx.Create(&models.StockMovement{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		ProductItemID: productItemID,
+		Quantity:      quantity,
+		Balance:       balance,
+		Reason:        reason,
+		SourceID:      sourceID,
+		Note:          note,
+	}).Error
+}
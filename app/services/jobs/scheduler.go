@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/events"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/app/services/notifier/email"
+	"github.com/esmailemami/eshop/app/services/notifier/sms"
+	"github.com/esmailemami/eshop/app/services/price_history"
+	"github.com/esmailemami/eshop/app/services/product_import"
+	"github.com/esmailemami/eshop/app/services/product_relation"
+	"github.com/esmailemami/eshop/app/services/product_schedule"
+	"github.com/esmailemami/eshop/app/services/restock"
+	"github.com/esmailemami/eshop/app/services/settings"
+	"github.com/esmailemami/eshop/app/services/token"
+	dbpkg "github.com/esmailemami/eshop/db"
+	"github.com/robfig/cron/v3"
+)
+
+var scheduler *cron.Cron
+
+// Start runs the periodic jobs of the application
+func Start() {
+	if scheduler != nil {
+		return
+	}
+
+	go RecoverProductImports()
+
+	scheduler = cron.New(cron.WithSeconds())
+
+	scheduler.AddFunc("0 * * * * *" /*every minute*/, ApplyProductSchedules)
+	scheduler.AddFunc("0 0 3 * * *" /*every day at 3 AM*/, ComputeBoughtTogether)
+	scheduler.AddFunc("0 * * * * *" /*every minute*/, ApplyPriceSchedules)
+	scheduler.AddFunc("0 0 * * * *" /*every hour*/, SendRestockAlerts)
+	scheduler.AddFunc("0 */5 * * * *" /*every 5 minutes*/, RotateJWTKeys)
+
+	scheduler.Start()
+}
+
+// Stop stops the scheduler and waits for the running jobs
+func Stop() {
+	if scheduler == nil {
+		return
+	}
+
+	<-scheduler.Stop().Done()
+	scheduler = nil
+}
+
+// ApplyProductSchedules publishes and unpublishes the scheduled products and items
+func ApplyProductSchedules() {
+	db := dbpkg.MustGormDBConn(context.Background())
+
+	changes, err := product_schedule.Apply(db, time.Now())
+	if err != nil {
+		logger.Default().WithField("Job", "ApplyProductSchedules").Error(err.Error())
+	}
+
+	events.Publish(changes...)
+}
+
+// the running imports of the other instances are not failed before this time
+const staleProductImportAfter = time.Hour
+
+// RecoverProductImports resumes the imports which are stopped by the previous process
+func RecoverProductImports() {
+	db := dbpkg.MustGormDBConn(context.Background())
+
+	if err := product_import.Recover(db, time.Now().Add(-staleProductImportAfter)); err != nil {
+		logger.Default().WithField("Job", "RecoverProductImports").Error(err.Error())
+	}
+}
+
+// ComputeBoughtTogether refreshes the frequently bought together products from the paid orders
+func ComputeBoughtTogether() {
+	db := dbpkg.MustGormDBConn(context.Background())
+
+	if _, err := product_relation.ComputeBoughtTogether(db); err != nil {
+		logger.Default().WithField("Job", "ComputeBoughtTogether").Error(err.Error())
+	}
+}
+
+// ApplyPriceSchedules changes the prices of the items which their scheduled price is reached
+func ApplyPriceSchedules() {
+	db := dbpkg.MustGormDBConn(context.Background())
+
+	changes, err := price_history.ApplySchedules(db, time.Now())
+	if err != nil {
+		logger.Default().WithField("Job", "ApplyPriceSchedules").Error(err.Error())
+	}
+
+	events.Publish(changes...)
+}
+
+// RotateJWTKeys loads the signing keys of the other instances, creates the next key and removes the retired ones.
+// It runs more often than the publish delay of the keys so every instance verifies a new key before it signs.
+func RotateJWTKeys() {
+	if err := token.RotateKeys(time.Now()); err != nil {
+		logger.Default().WithField("Job", "RotateJWTKeys").Error(err.Error())
+	}
+}
+
+// SendRestockAlerts emails and sms the admins the items which are fallen below their reorder threshold
+func SendRestockAlerts() {
+	db := dbpkg.MustGormDBConn(context.Background())
+	setting := settings.GetSystemSettings()
+
+	emails := restock.Recipients(setting.RestockAlertEmails)
+	mobiles := restock.Recipients(setting.RestockAlertMobiles)
+
+	if len(emails) == 0 && len(mobiles) == 0 {
+		return
+	}
+
+	log := logger.Default().WithField("Job", "SendRestockAlerts")
+
+	threshold := restock.DefaultThreshold
+	if setting.ReorderThreshold != nil {
+		threshold = *setting.ReorderThreshold
+	}
+
+	// the items are alerted when at least one of the recipients receives the alert
+	_, err := restock.Alert(db, threshold, func(items []restock.Item) error {
+		var (
+			sent    bool
+			lastErr error
+		)
+
+		if len(emails) > 0 {
+			data := email.LowStock{Items: make([]email.LowStockItem, len(items))}
+			for i, item := range items {
+				data.Items[i] = email.LowStockItem{
+					Name:      item.ProductName + " - " + item.ColorName,
+					Quantity:  item.Quantity,
+					Threshold: item.Threshold,
+				}
+				if item.SKU != nil {
+					data.Items[i].SKU = *item.SKU
+				}
+			}
+
+			if err := email.NewNotifier("gmail").Send(emails, email.KeyLowStock, data); err != nil {
+				log.Error(err.Error())
+				lastErr = err
+			} else {
+				sent = true
+			}
+		}
+
+		message := restock.Message(items)
+		notifier := sms.NewSmsNotifier("niksms")
+
+		for _, mobile := range mobiles {
+			if _, err := notifier.Send(mobile, message); err != nil {
+				log.Error(err.Error())
+				lastErr = err
+			} else {
+				sent = true
+			}
+		}
+
+		if !sent {
+			return lastErr
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
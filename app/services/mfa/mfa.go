@@ -0,0 +1,424 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/token"
+	"github.com/esmailemami/eshop/app/services/totp"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// ChallengeTTL is the time the user has to enter the code after the password is checked
+	ChallengeTTL = 5 * time.Minute
+	// MaxChallengeAttempts is the number of the codes which are checked by a challenge
+	MaxChallengeAttempts = 5
+	// RecoveryCodeCount is the number of the recovery codes which are generated at once
+	RecoveryCodeCount = 10
+
+	DefaultIssuer = "Eshop"
+
+	// the width of the QR code image in pixels
+	qrSize = 256
+)
+
+// Issuer is the name which is shown in the authenticator apps, it is configured by mfa.issuer
+func Issuer() string {
+	if issuer := viper.GetString("mfa.issuer"); issuer != "" {
+		return issuer
+	}
+
+	return DefaultIssuer
+}
+
+// Status is the 2FA state of the user
+type Status struct {
+	Enabled           bool
+	Required          bool
+	RecoveryCodesLeft int
+}
+
+// GetStatus returns the 2FA state of the user, the role of the user must be loaded
+func GetStatus(db *gorm.DB, user models.User) (Status, error) {
+	status := Status{
+		Required: user.Role != nil && user.Role.RequiresMfa(),
+	}
+
+	var mfa models.UserMfa
+
+	err := db.Where("user_id = ? AND enabled = true", user.ID).First(&mfa).Error
+	if err == gorm.ErrRecordNotFound {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+
+	status.Enabled = true
+
+	var left int64
+	if err := db.Model(&models.MfaRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Count(&left).Error; err != nil {
+		return status, err
+	}
+	status.RecoveryCodesLeft = int(left)
+
+	return status, nil
+}
+
+// Enrollment is the secret which is added to the authenticator app
+type Enrollment struct {
+	Secret string
+	URI    string
+	QRCode string
+}
+
+// Enroll creates a new secret for the user, the 2FA is enabled when a code of the secret is confirmed
+func Enroll(db *gorm.DB, user models.User) (*Enrollment, error) {
+	var mfa models.UserMfa
+
+	err := db.Where("user_id = ?", user.ID).First(&mfa).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if err == nil && mfa.Enabled {
+		return nil, errors.New(consts.MfaAlreadyEnabled)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	if mfa.ID == nil {
+		mfa = models.UserMfa{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			UserID: *user.ID,
+			Secret: secret,
+		}
+		err = db.Create(&mfa).Error
+	} else {
+		err = db.Model(&mfa).UpdateColumns(map[string]any{
+			"secret":         secret,
+			"last_used_step": 0,
+		}).Error
+	}
+
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	uri := totp.URI(Issuer(), user.Username, secret)
+
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrSize)
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	// the data url can be used as the src of an img tag
+	return &Enrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Confirm enables the 2FA of the user by the first code of the enrolled secret and returns the recovery codes
+func Confirm(db *gorm.DB, userID uuid.UUID, code string) ([]string, error) {
+	var recoveryCodes []string
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var mfa models.UserMfa
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&mfa).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.New(consts.MfaNotEnrolled)
+			}
+			return errors.New(consts.InternalServerError)
+		}
+
+		if mfa.Enabled {
+			return errors.New(consts.MfaAlreadyEnabled)
+		}
+
+		step, ok := totp.Validate(mfa.Secret, code, time.Now())
+		if !ok {
+			return errors.New(consts.InvalidMfaCode)
+		}
+
+		now := time.Now()
+		if err := tx.Model(&mfa).UpdateColumns(map[string]any{
+			"enabled":        true,
+			"enabled_at":     now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		var err error
+		recoveryCodes, err = generateRecoveryCodes(tx, userID)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// VerifyCode checks the TOTP code or a recovery code of the user, the used TOTP steps and recovery codes are not
+// accepted again
+func VerifyCode(tx *gorm.DB, userID uuid.UUID, code string) error {
+	var mfa models.UserMfa
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND enabled = true", userID).
+		First(&mfa).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New(consts.MfaNotEnrolled)
+		}
+		return errors.New(consts.InternalServerError)
+	}
+
+	if recovery := normalizeRecoveryCode(code); len(recovery) == recoveryCodeLength {
+		return useRecoveryCode(tx, userID, recovery)
+	}
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now())
+	if !ok || step <= mfa.LastUsedStep {
+		return errors.New(consts.InvalidMfaCode)
+	}
+
+	if err := tx.Model(&mfa).UpdateColumn("last_used_step", step).Error; err != nil {
+		return errors.New(consts.InternalServerError)
+	}
+
+	return nil
+}
+
+// Disable removes the 2FA of the user after checking a code, it is not allowed when the role requires the 2FA
+func Disable(db *gorm.DB, user models.User, code string) error {
+	if user.Role != nil && user.Role.RequiresMfa() {
+		return errors.New(consts.MfaRequiredByRole)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := VerifyCode(tx, *user.ID, code); err != nil {
+			return err
+		}
+
+		return Reset(tx, *user.ID)
+	})
+}
+
+// Reset removes the 2FA and the recovery codes of the user, the admins use it when the user loses the device
+func Reset(db *gorm.DB, userID uuid.UUID) error {
+	if err := db.Unscoped().Where("user_id = ?", userID).Delete(&models.MfaRecoveryCode{}).Error; err != nil {
+		return errors.New(consts.InternalServerError)
+	}
+
+	if err := db.Unscoped().Where("user_id = ?", userID).Delete(&models.UserMfa{}).Error; err != nil {
+		return errors.New(consts.InternalServerError)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user after checking a code
+func RegenerateRecoveryCodes(db *gorm.DB, userID uuid.UUID, code string) ([]string, error) {
+	var recoveryCodes []string
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := VerifyCode(tx, userID, code); err != nil {
+			return err
+		}
+
+		var err error
+		recoveryCodes, err = generateRecoveryCodes(tx, userID)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// NewChallenge starts the "mfa pending" state of the login, the returned token is exchanged with the access token
+// of the route tree when the code is verified
+func NewChallenge(db *gorm.DB, userID uuid.UUID, actAs string) (string, time.Time, error) {
+	tokenString, hash, err := token.NewOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, errors.New(consts.InternalServerError)
+	}
+
+	challenge := models.MfaChallenge{
+		BasicModel: models.BasicModel{
+			ID: models.NewID(),
+		},
+		UserID:    userID,
+		TokenHash: hash,
+		ActAs:     actAs,
+		ExpiresAt: time.Now().Add(ChallengeTTL),
+	}
+
+	if err := db.Create(&challenge).Error; err != nil {
+		return "", time.Time{}, errors.New(consts.InternalServerError)
+	}
+
+	return tokenString, challenge.ExpiresAt, nil
+}
+
+// ChallengeUser returns the user of the pending challenge without using it
+func ChallengeUser(db *gorm.DB, tokenString string) (uuid.UUID, error) {
+	var challenge models.MfaChallenge
+
+	if err := db.Where("token_hash = ?", token.HashOpaqueToken(tokenString)).First(&challenge).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return uuid.Nil, errors.New(consts.InvalidMfaToken)
+		}
+		return uuid.Nil, errors.New(consts.InternalServerError)
+	}
+
+	if !pending(challenge) {
+		return uuid.Nil, errors.New(consts.InvalidMfaToken)
+	}
+
+	return challenge.UserID, nil
+}
+
+// PassChallenge runs the check of the second factor on the pending challenge, every check counts as an attempt and
+// the challenge is used when the check passes. The changes of a failed check are rolled back but the attempt is kept.
+func PassChallenge(db *gorm.DB, tokenString string, check func(tx *gorm.DB, userID uuid.UUID) error) (*models.MfaChallenge, error) {
+	var (
+		challenge models.MfaChallenge
+		checkErr  error
+	)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// the concurrent checks of the same challenge wait here, so the attempts are counted correctly
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", token.HashOpaqueToken(tokenString)).
+			First(&challenge).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.New(consts.InvalidMfaToken)
+			}
+			return errors.New(consts.InternalServerError)
+		}
+
+		if !pending(challenge) {
+			return errors.New(consts.InvalidMfaToken)
+		}
+
+		// the nested transaction is a savepoint, so only the changes of the check are rolled back
+		checkErr = tx.Transaction(func(inner *gorm.DB) error {
+			return check(inner, challenge.UserID)
+		})
+
+		updates := map[string]any{
+			"attempts": gorm.Expr("attempts + 1"),
+		}
+		if checkErr == nil {
+			updates["used_at"] = time.Now()
+		}
+
+		if err := tx.Model(&challenge).UpdateColumns(updates).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if checkErr != nil {
+		return nil, checkErr
+	}
+
+	return &challenge, nil
+}
+
+// pending reports whether the challenge can still be used
+func pending(c models.MfaChallenge) bool {
+	return c.UsedAt == nil && c.ExpiresAt.After(time.Now()) && c.Attempts < MaxChallengeAttempts
+}
+
+const recoveryCodeLength = 8
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes replaces the recovery codes of the user and returns them, they are shown to the user once
+func generateRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.MfaRecoveryCode{}).Error; err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	codes := make([]string, RecoveryCodeCount)
+	rows := make([]models.MfaRecoveryCode, RecoveryCodeCount)
+
+	for i := range codes {
+		bts := make([]byte, 5)
+		if _, err := rand.Read(bts); err != nil {
+			return nil, errors.New(consts.InternalServerError)
+		}
+
+		code := strings.ToLower(recoveryEncoding.EncodeToString(bts))
+		codes[i] = code[:4] + "-" + code[4:]
+
+		rows[i] = models.MfaRecoveryCode{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			UserID:   userID,
+			CodeHash: token.HashOpaqueToken(code),
+		}
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	return codes, nil
+}
+
+func useRecoveryCode(tx *gorm.DB, userID uuid.UUID, code string) error {
+	result := tx.Model(&models.MfaRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, token.HashOpaqueToken(code)).
+		UpdateColumn("used_at", time.Now())
+
+	if result.Error != nil {
+		return errors.New(consts.InternalServerError)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New(consts.InvalidMfaCode)
+	}
+
+	return nil
+}
+
+// normalizeRecoveryCode removes the separators of the recovery code which the user may type differently
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
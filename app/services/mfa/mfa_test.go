@@ -0,0 +1,44 @@
+package mfa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/esmailemami/eshop/models"
+)
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "abcd-efgh", want: "abcdefgh"},
+		{code: " ABCD EFGH ", want: "abcdefgh"},
+		{code: "123456", want: "123456"},
+	}
+	for _, tt := range tests {
+		if got := normalizeRecoveryCode(tt.code); got != tt.want {
+			t.Errorf("normalizeRecoveryCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestPending(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		challenge models.MfaChallenge
+		want      bool
+	}{
+		{name: "pending", challenge: models.MfaChallenge{ExpiresAt: now.Add(time.Minute)}, want: true},
+		{name: "expired", challenge: models.MfaChallenge{ExpiresAt: now.Add(-time.Minute)}, want: false},
+		{name: "used", challenge: models.MfaChallenge{ExpiresAt: now.Add(time.Minute), UsedAt: &now}, want: false},
+		{name: "too many attempts", challenge: models.MfaChallenge{ExpiresAt: now.Add(time.Minute), Attempts: MaxChallengeAttempts}, want: false},
+	}
+	for _, tt := range tests {
+		if got := pending(tt.challenge); got != tt.want {
+			t.Errorf("%v: pending() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package moderation
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// DuplicateMinLength is the length which shorter reviews, like "great", are not checked for the duplicates
+	DuplicateMinLength = 20
+	// DuplicateRejectCount is the number of the identical reviews which rejects a review
+	DuplicateRejectCount = 3
+)
+
+// Duplicate checks the review against the existing reviews with the same text
+type Duplicate struct {
+	DB *gorm.DB
+}
+
+func (Duplicate) Name() string {
+	return "duplicate"
+}
+
+func (d Duplicate) Check(review Review) (Result, error) {
+	text := strings.Join(strings.Fields(strings.ToLower(review.Text)), " ")
+
+	if len([]rune(text)) < DuplicateMinLength {
+		return Result{Verdict: VerdictAccept}, nil
+	}
+
+	var rows []struct {
+		SameUser    bool `gorm:"column:same_user"`
+		SameProduct bool `gorm:"column:same_product"`
+	}
+
+	if err := d.DB.Model(&models.Comment{}).
+		Where("id <> ? AND lower(regexp_replace(trim(text), '\\s+', ' ', 'g')) = ?", review.ID, text).
+		Select("created_by_id = ? AS same_user, product_id = ? AS same_product", review.UserID, review.ProductID).
+		Limit(DuplicateRejectCount).
+		Scan(&rows).Error; err != nil {
+		return Result{}, err
+	}
+
+	if len(rows) >= DuplicateRejectCount {
+		return Result{Verdict: VerdictReject, Reason: "the same text is posted " + strconv.Itoa(len(rows)) + " times"}, nil
+	}
+
+	for _, row := range rows {
+		if row.SameUser {
+			return Result{Verdict: VerdictQueue, Reason: "the user has posted the same text before"}, nil
+		}
+
+		if row.SameProduct {
+			return Result{Verdict: VerdictQueue, Reason: "the same text is posted for the product"}, nil
+		}
+	}
+
+	return Result{Verdict: VerdictAccept}, nil
+}
+
+// RateLimit queues the reviews of the users who post more than the limit in the window, zero limit disables it
+type RateLimit struct {
+	DB     *gorm.DB
+	Limit  int
+	Window time.Duration
+}
+
+func (RateLimit) Name() string {
+	return "rate limit"
+}
+
+func (r RateLimit) Check(review Review) (Result, error) {
+	if r.Limit <= 0 {
+		return Result{Verdict: VerdictAccept}, nil
+	}
+
+	var count int64
+
+	if err := r.DB.Model(&models.Comment{}).
+		Where("id <> ? AND created_by_id = ? AND created_at > ?", review.ID, review.UserID, time.Now().Add(-r.Window)).
+		Count(&count).Error; err != nil {
+		return Result{}, err
+	}
+
+	if count >= int64(r.Limit) {
+		return Result{Verdict: VerdictQueue, Reason: strconv.FormatInt(count, 10) + " reviews in the last " + strconv.Itoa(int(r.Window.Minutes())) + " minutes"}, nil
+	}
+
+	return Result{Verdict: VerdictAccept}, nil
+}
@@ -0,0 +1,172 @@
+package moderation
+
+import (
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/settings"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Verdict is the decision of the moderation about a review, the verdicts are ordered by their severity
+type Verdict int
+
+const (
+	VerdictAccept Verdict = iota
+	VerdictQueue
+	VerdictReject
+)
+
+// Mode is how the reviews are moderated
+type Mode int
+
+const (
+	// ModeManual keeps all the reviews pending for the admins
+	ModeManual Mode = iota
+	// ModeAutomatic runs the pipeline and queues only the borderline reviews
+	ModeAutomatic
+)
+
+const (
+	// DefaultRateLimit is the number of the reviews a user can post in RateLimitWindow
+	DefaultRateLimit = 5
+	RateLimitWindow  = time.Hour
+)
+
+// Status returns the comment status of the verdict
+func (v Verdict) Status() models.CommentStatus {
+	switch v {
+	case VerdictAccept:
+		return models.CommntStatusAccept
+	case VerdictReject:
+		return models.CommentStatusReject
+	default:
+		return models.CommentStatusPending
+	}
+}
+
+// Review is the content of a comment which is moderated
+type Review struct {
+	// ID is the id of the comment which is excluded from the checks of the existing comments
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	ProductID      uuid.UUID
+	Text           string
+	StrengthPoints []string
+	WeakPoints     []string
+}
+
+// Content returns all the texts of the review
+func (r Review) Content() string {
+	parts := make([]string, 0, 1+len(r.StrengthPoints)+len(r.WeakPoints))
+	parts = append(parts, r.Text)
+	parts = append(parts, r.StrengthPoints...)
+	parts = append(parts, r.WeakPoints...)
+
+	return strings.Join(parts, "\n")
+}
+
+// Result is the verdict of a stage with its reason
+type Result struct {
+	Verdict Verdict
+	Reason  string
+}
+
+// Note is the text which is recorded in the admin note of the comment
+func (r Result) Note() string {
+	switch r.Verdict {
+	case VerdictAccept:
+		return "Auto accepted"
+	case VerdictReject:
+		return "Auto rejected: " + r.Reason
+	default:
+		return "Queued for review: " + r.Reason
+	}
+}
+
+// Stage is a check of the pipeline, it returns VerdictAccept with no reason when the review is clean
+type Stage interface {
+	Name() string
+	Check(review Review) (Result, error)
+}
+
+// Pipeline runs its stages in order
+type Pipeline struct {
+	stages []Stage
+}
+
+func New(stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Use appends the stages to the pipeline
+func (p *Pipeline) Use(stages ...Stage) *Pipeline {
+	p.stages = append(p.stages, stages...)
+	return p
+}
+
+// Run returns the most severe verdict of the stages, it stops at the first rejection and the reasons of the
+// queued verdicts are joined together.
+func (p *Pipeline) Run(review Review) (Result, error) {
+	reasons := []string{}
+
+	for _, stage := range p.stages {
+		result, err := stage.Check(review)
+		if err != nil {
+			return Result{}, err
+		}
+
+		switch result.Verdict {
+		case VerdictReject:
+			return Result{Verdict: VerdictReject, Reason: stage.Name() + ": " + result.Reason}, nil
+		case VerdictQueue:
+			reasons = append(reasons, stage.Name()+": "+result.Reason)
+		}
+	}
+
+	if len(reasons) > 0 {
+		return Result{Verdict: VerdictQueue, Reason: strings.Join(reasons, "; ")}, nil
+	}
+
+	return Result{Verdict: VerdictAccept}, nil
+}
+
+// CurrentMode returns the review moderation mode of the system settings
+func CurrentMode() Mode {
+	if v := settings.GetSystemSettings().ReviewModeration; v != nil {
+		return Mode(*v)
+	}
+
+	return ModeManual
+}
+
+// Default returns the pipeline of the reviews which is configured by the system settings
+func Default(db *gorm.DB) *Pipeline {
+	setting := settings.GetSystemSettings()
+
+	limit := DefaultRateLimit
+	if setting.ReviewRateLimit != nil {
+		limit = *setting.ReviewRateLimit
+	}
+
+	return New(
+		NewProfanity(Words(setting.ProfanityWords)...),
+		Spam{},
+		Duplicate{DB: db},
+		RateLimit{DB: db, Limit: limit, Window: RateLimitWindow},
+	)
+}
+
+// Moderate runs the default pipeline, the reviews are queued when the pipeline fails, so a failure never
+// publishes a review.
+func Moderate(db *gorm.DB, review Review) (models.CommentStatus, *string, error) {
+	result, err := Default(db).Run(review)
+	if err != nil {
+		result = Result{Verdict: VerdictQueue, Reason: "automatic moderation failed"}
+	}
+
+	note := result.Note()
+	return result.Verdict.Status(), &note, err
+}
@@ -0,0 +1,129 @@
+package moderation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fixedStage struct {
+	name   string
+	result Result
+	err    error
+}
+
+func (s fixedStage) Name() string {
+	return s.name
+}
+
+func (s fixedStage) Check(Review) (Result, error) {
+	return s.result, s.err
+}
+
+func TestPipelineRun(t *testing.T) {
+	accept := fixedStage{name: "a", result: Result{Verdict: VerdictAccept}}
+	queue1 := fixedStage{name: "q1", result: Result{Verdict: VerdictQueue, Reason: "first"}}
+	queue2 := fixedStage{name: "q2", result: Result{Verdict: VerdictQueue, Reason: "second"}}
+	reject := fixedStage{name: "r", result: Result{Verdict: VerdictReject, Reason: "spam"}}
+	failing := fixedStage{name: "f", err: errors.New("failed")}
+
+	tests := []struct {
+		name    string
+		stages  []Stage
+		want    Result
+		wantErr bool
+	}{
+		{name: "no stage", stages: nil, want: Result{Verdict: VerdictAccept}},
+		{name: "clean", stages: []Stage{accept, accept}, want: Result{Verdict: VerdictAccept}},
+		{name: "queued reasons are joined", stages: []Stage{queue1, accept, queue2}, want: Result{Verdict: VerdictQueue, Reason: "q1: first; q2: second"}},
+		{name: "reject wins", stages: []Stage{queue1, reject}, want: Result{Verdict: VerdictReject, Reason: "r: spam"}},
+		{name: "reject stops the pipeline", stages: []Stage{reject, failing}, want: Result{Verdict: VerdictReject, Reason: "r: spam"}},
+		{name: "error", stages: []Stage{accept, failing}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.stages...).Run(Review{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Run() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfanityCheck(t *testing.T) {
+	stage := NewProfanity("lemon")
+
+	tests := []struct {
+		name string
+		text string
+		want Verdict
+	}{
+		{name: "clean english", text: "Great phone, the battery lasts two days.", want: VerdictAccept},
+		{name: "clean persian", text: "گوشی خوبیه، باتریش عالیه", want: VerdictAccept},
+		{name: "one word", text: "This is SHIT", want: VerdictQueue},
+		{name: "hidden by repeats and leet", text: "sh1iiiit product", want: VerdictQueue},
+		{name: "persian word", text: "فروشنده احمق", want: VerdictQueue},
+		{name: "arabic letters", text: "كثافت", want: VerdictQueue},
+		{name: "extra word", text: "what a Lemon", want: VerdictQueue},
+		{name: "two words", text: "fuck this shit", want: VerdictReject},
+		{name: "part of a word", text: "Dickens would like it", want: VerdictAccept},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stage.Check(Review{Text: tt.text})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Verdict != tt.want {
+				t.Errorf("Check() = %+v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpamCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		review Review
+		want   Verdict
+	}{
+		{name: "clean", review: Review{Text: "The size is smaller than the photos."}, want: VerdictAccept},
+		{name: "one link", review: Review{Text: "Cheaper at www.example.com"}, want: VerdictQueue},
+		{name: "two links", review: Review{Text: "visit https://a.example and shop.ir"}, want: VerdictReject},
+		{name: "link and phone", review: Review{Text: "buy at example.com or call 09121234567"}, want: VerdictReject},
+		{name: "persian phone", review: Review{Text: "تماس بگیرید ۰۹۱۲۱۲۳۴۵۶۷"}, want: VerdictQueue},
+		{name: "link in the strength points", review: Review{Text: "good", StrengthPoints: []string{"cheap.shop"}}, want: VerdictQueue},
+		{name: "repeated characters", review: Review{Text: "wowwwwwwwww"}, want: VerdictQueue},
+		{name: "shouting", review: Review{Text: "THIS IS THE BEST PHONE EVER MADE"}, want: VerdictQueue},
+		{name: "short upper case", review: Review{Text: "OK USB C"}, want: VerdictAccept},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Spam{}.Check(tt.review)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Verdict != tt.want {
+				t.Errorf("Check() = %+v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	list := " foo, bar;baz\nqux "
+
+	if got, want := Words(&list), []string{"foo", "bar", "baz", "qux"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Words() = %v, want %v", got, want)
+	}
+
+	if got := Words(nil); len(got) != 0 {
+		t.Errorf("Words(nil) = %v, want empty", got)
+	}
+}
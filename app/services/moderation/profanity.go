@@ -0,0 +1,137 @@
+package moderation
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/esmailemami/eshop/app/services/str"
+)
+
+// ProfanityRejectCount is the number of the profane words which rejects a review, fewer words queue it
+const ProfanityRejectCount = 2
+
+// the built-in English and Persian word lists, the admins can extend them by the profanity words setting
+var (
+	englishWords = []string{
+		"fuck", "fucking", "fucker", "motherfucker", "shit", "bullshit", "bitch", "bastard", "asshole",
+		"dick", "cunt", "whore", "slut", "wanker", "retard",
+	}
+
+	persianWords = []string{
+		"کیر", "کسکش", "جنده", "مادرجنده", "حرومزاده", "حرامزاده", "کثافت", "بیشعور", "احمق", "لعنتی",
+		"گوه", "کونی", "پفیوز", "عوضی", "الاغ",
+	}
+)
+
+// leet maps the characters which are used instead of the latin letters to hide the words
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// Profanity checks the review against a word list
+type Profanity struct {
+	words map[string]bool
+}
+
+// NewProfanity returns the profanity stage of the built-in words and the extra words
+func NewProfanity(extra ...string) Profanity {
+	p := Profanity{words: map[string]bool{}}
+
+	for _, list := range [][]string{englishWords, persianWords, extra} {
+		for _, word := range list {
+			for _, token := range tokenize(word) {
+				p.words[token] = true
+			}
+		}
+	}
+
+	return p
+}
+
+func (Profanity) Name() string {
+	return "profanity"
+}
+
+func (p Profanity) Check(review Review) (Result, error) {
+	found := map[string]bool{}
+
+	for _, token := range tokenize(review.Content()) {
+		if p.words[token] {
+			found[token] = true
+		}
+	}
+
+	switch {
+	case len(found) >= ProfanityRejectCount:
+		return Result{Verdict: VerdictReject, Reason: strconv.Itoa(len(found)) + " profane words"}, nil
+	case len(found) > 0:
+		return Result{Verdict: VerdictQueue, Reason: "1 profane word"}, nil
+	}
+
+	return Result{Verdict: VerdictAccept}, nil
+}
+
+// Words splits the list of the words which are separated by comma, semicolon or white space
+func Words(list *string) []string {
+	if list == nil {
+		return nil
+	}
+
+	return strings.FieldsFunc(*list, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+}
+
+// tokenize splits the normalized text into its words, the repeated letters are collapsed
+// so "fuuuck" and "fuck" are the same
+func tokenize(text string) []string {
+	text = normalize(text)
+
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '$'
+	})
+
+	tokens := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		if isLatin(field) {
+			field = leet.Replace(field)
+		}
+
+		tokens = append(tokens, collapse(field))
+	}
+
+	return tokens
+}
+
+// normalize lower cases the text, converts the Arabic letters and digits to the Persian and English ones
+// and removes the zero width non-joiners
+func normalize(text string) string {
+	text = strings.ToLower(str.ArToFa(text))
+	return strings.ReplaceAll(text, "\u200c", "")
+}
+
+func isLatin(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+
+	return true
+}
+
+func collapse(word string) string {
+	var (
+		sb   strings.Builder
+		last rune = -1
+	)
+
+	for _, r := range word {
+		if r != last {
+			sb.WriteRune(r)
+		}
+		last = r
+	}
+
+	return sb.String()
+}
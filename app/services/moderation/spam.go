@@ -0,0 +1,112 @@
+package moderation
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/esmailemami/eshop/app/services/numeric"
+)
+
+const (
+	// SpamRejectLinks is the number of the links which rejects a review
+	SpamRejectLinks = 2
+	// SpamRepeatedChars is the length of a run of the same character which queues a review
+	SpamRepeatedChars = 8
+	// SpamUpperRatio is the ratio of the upper case letters which queues a long enough review
+	SpamUpperRatio = 0.7
+	spamUpperMin   = 20
+)
+
+var (
+	linkRegex  = regexp.MustCompile(`(?i)(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|net|org|ir|info|biz|xyz|io|me|co|shop|site|online)\b`)
+	phoneRegex = regexp.MustCompile(`(?:\+98|0098|\b0)?9\d{9}\b`)
+)
+
+// Spam checks the review for the links, the phone numbers and the shouting
+type Spam struct{}
+
+func (Spam) Name() string {
+	return "spam"
+}
+
+func (Spam) Check(review Review) (Result, error) {
+	content := numeric.TransformFa2En(review.Content())
+
+	links := len(linkRegex.FindAllString(content, -1))
+	hasPhone := phoneRegex.MatchString(content)
+
+	// advertising a site or a contact is an obvious spam
+	if links >= SpamRejectLinks || (links > 0 && hasPhone) {
+		return Result{Verdict: VerdictReject, Reason: strconv.Itoa(links) + " links"}, nil
+	}
+
+	reasons := []string{}
+
+	if links > 0 {
+		reasons = append(reasons, "contains a link")
+	}
+
+	if hasPhone {
+		reasons = append(reasons, "contains a phone number")
+	}
+
+	if longestRun(content) >= SpamRepeatedChars {
+		reasons = append(reasons, "repeated characters")
+	}
+
+	if upperRatio(content) > SpamUpperRatio {
+		reasons = append(reasons, "mostly upper case")
+	}
+
+	if len(reasons) > 0 {
+		return Result{Verdict: VerdictQueue, Reason: strings.Join(reasons, ", ")}, nil
+	}
+
+	return Result{Verdict: VerdictAccept}, nil
+}
+
+func longestRun(text string) int {
+	var (
+		longest, run int
+		last         rune = -1
+	)
+
+	for _, r := range text {
+		if r == last && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		last = r
+
+		if run > longest {
+			longest = run
+		}
+	}
+
+	return longest
+}
+
+// upperRatio is zero when the text has fewer latin letters than spamUpperMin
+func upperRatio(text string) float64 {
+	var letters, upper int
+
+	for _, r := range text {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	if letters < spamUpperMin {
+		return 0
+	}
+
+	return float64(upper) / float64(letters)
+}
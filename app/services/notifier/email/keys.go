@@ -6,6 +6,9 @@ type Key int
 
 const (
 	KeyForgotPassword Key = iota
+	KeyLowStock
+	KeyQuestionAnswered
+	KeyVerifyEmail
 )
 
 func (k Key) getTemplatePath() string {
@@ -13,6 +16,12 @@ func (k Key) getTemplatePath() string {
 	switch k {
 	case KeyForgotPassword:
 		return path + "/forgot-password.html"
+	case KeyLowStock:
+		return path + "/low-stock.html"
+	case KeyQuestionAnswered:
+		return path + "/question-answered.html"
+	case KeyVerifyEmail:
+		return path + "/verify-email.html"
 	}
 
 	panic("invalid template key!")
@@ -23,6 +32,15 @@ func (k Key) validateData(data any) (ok bool) {
 	case KeyForgotPassword:
 		_, ok = data.(ForgotPassword)
 		return
+	case KeyLowStock:
+		_, ok = data.(LowStock)
+		return
+	case KeyQuestionAnswered:
+		_, ok = data.(QuestionAnswered)
+		return
+	case KeyVerifyEmail:
+		_, ok = data.(VerifyEmail)
+		return
 	}
 
 	panic("invalid template key!")
@@ -32,6 +50,12 @@ func (k Key) GetSubject() string {
 	switch k {
 	case KeyForgotPassword:
 		return "Recovery Password"
+	case KeyLowStock:
+		return "Low Stock Items"
+	case KeyQuestionAnswered:
+		return "Your Question Is Answered"
+	case KeyVerifyEmail:
+		return "Verify Your Email"
 	}
 
 	panic("invalid template key!")
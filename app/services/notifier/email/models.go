@@ -4,3 +4,26 @@ type ForgotPassword struct {
 	Username    string
 	RecoveryUrl string
 }
+
+type LowStock struct {
+	Items []LowStockItem
+}
+
+type LowStockItem struct {
+	Name      string
+	SKU       string
+	Quantity  int
+	Threshold int
+}
+
+type QuestionAnswered struct {
+	Username    string
+	ProductName string
+	Question    string
+	Answer      string
+}
+
+type VerifyEmail struct {
+	Username string
+	Code     string
+}
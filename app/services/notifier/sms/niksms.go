@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 
 	"github.com/spf13/viper"
@@ -71,8 +70,10 @@ func (d NikSmsDriver) Send(to, message string) (result interface{}, err error) {
 		return nil, errors.New("NikSmsDriver@Send, response:" + string(bts))
 	}
 
-	b, _ := io.ReadAll(res.Body)
-	log.Println(string(b))
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
 
 	respModel := struct {
 		Status         int64   `json:"Status"`
@@ -82,19 +83,24 @@ func (d NikSmsDriver) Send(to, message string) (result interface{}, err error) {
 		Data           *string `json:"Data"`
 	}{}
 
-	err = json.NewDecoder(res.Body).Decode(&respModel)
+	// the body is already read, so it is unmarshaled from the read bytes
+	err = json.Unmarshal(b, &respModel)
 	if err != nil {
 		return nil, err
 	}
 
 	if respModel.Status != 0 {
-		return nil, fmt.Errorf("NikSmsDriver@Send response status: %v", res.Status)
+		return nil, fmt.Errorf("NikSmsDriver@Send response status: %v, body: %s", respModel.Status, string(b))
 	}
-	return SmsResult{
+
+	smsResult := SmsResult{
 		To:         to,
-		TrackID:    *respModel.Data,
 		DriverName: d.GetDriverName(),
-	}, nil
+	}
+	if respModel.Data != nil {
+		smsResult.TrackID = *respModel.Data
+	}
+	return smsResult, nil
 }
 
 type nikSmsSendModel struct {
@@ -0,0 +1,297 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrExchangeFailed = errors.New("authorization code exchange failed")
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// discovery is the part of the openid configuration of the provider which is used
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider is an OpenID Connect provider, the endpoints are read from the discovery document of the issuer
+type OIDCProvider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      jwk.Set
+}
+
+func NewOIDCProvider(config Config) *OIDCProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = defaultScopes
+	}
+
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, request AuthRequest) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", p.config.ClientID)
+	values.Set("redirect_uri", p.config.RedirectURL)
+	values.Set("scope", strings.Join(p.config.Scopes, " "))
+	values.Set("state", request.State)
+	values.Set("nonce", request.Nonce)
+	values.Set("code_challenge", codeChallenge(request.CodeVerifier))
+	values.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return d.AuthorizationEndpoint + separator + values.Encode(), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, request AuthRequest) (*Identity, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", p.config.RedirectURL)
+	values.Set("client_id", p.config.ClientID)
+	values.Set("client_secret", p.config.ClientSecret)
+	values.Set("code_verifier", request.CodeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrExchangeFailed, body)
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.Unmarshal(body, &tokenResponse); err != nil {
+		return nil, err
+	}
+
+	if tokenResponse.IDToken == "" {
+		return nil, fmt.Errorf("%w: the response has not any id token", ErrExchangeFailed)
+	}
+
+	return p.verifyIDToken(ctx, d, tokenResponse.IDToken, request.Nonce)
+}
+
+// verifyIDToken checks the signature and the claims of the id token, the keys are fetched again once when the key
+// of the token is not found, the providers rotate their keys
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, d *discovery, idToken, nonce string) (*Identity, error) {
+	keys, err := p.getKeys(ctx, d, false)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.parse(idToken, keys)
+	if err != nil {
+		if keys, err = p.getKeys(ctx, d, true); err != nil {
+			return nil, err
+		}
+
+		if token, err = p.parse(idToken, keys); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+		}
+	}
+
+	if err := jwt.Validate(token,
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithAcceptableSkew(time.Minute),
+		jwt.WithClaimValue("nonce", nonce),
+	); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// the authorized party must be the client when the token has other audiences
+	if azp, ok := token.Get("azp"); ok && azp != p.config.ClientID {
+		return nil, fmt.Errorf("%w: azp mismatch", ErrInvalidIDToken)
+	}
+
+	if token.Subject() == "" {
+		return nil, fmt.Errorf("%w: the token has not any subject", ErrInvalidIDToken)
+	}
+
+	identity := &Identity{
+		Provider:      p.config.Name,
+		Subject:       token.Subject(),
+		Email:         stringClaim(token, "email"),
+		EmailVerified: boolClaim(token, "email_verified"),
+		Name:          stringClaim(token, "name"),
+		GivenName:     stringClaim(token, "given_name"),
+		FamilyName:    stringClaim(token, "family_name"),
+	}
+
+	return identity, nil
+}
+
+func (p *OIDCProvider) parse(idToken string, keys jwk.Set) (jwt.Token, error) {
+	return jwt.ParseString(idToken,
+		jwt.WithKeySet(keys),
+		jwt.UseDefaultKey(true),
+		jwt.InferAlgorithmFromKey(true),
+	)
+}
+
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openid configuration of %s: %s", p.config.Name, res.Status)
+	}
+
+	var d discovery
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&d); err != nil {
+		return nil, err
+	}
+
+	// the tokens are checked against the configured issuer, a different document is not trusted
+	if strings.TrimSuffix(d.Issuer, "/") != strings.TrimSuffix(p.config.Issuer, "/") {
+		return nil, fmt.Errorf("openid configuration of %s: issuer mismatch %s", p.config.Name, d.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksURI == "" {
+		return nil, fmt.Errorf("openid configuration of %s: missing endpoints", p.config.Name)
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) getKeys(ctx context.Context, d *discovery, refresh bool) (jwk.Set, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && !refresh {
+		return p.keys, nil
+	}
+
+	keys, err := jwk.Fetch(ctx, d.JwksURI, jwk.WithHTTPClient(p.client))
+	if err != nil {
+		return nil, err
+	}
+
+	p.keys = keys
+	return keys, nil
+}
+
+// NewAuthRequest returns the random state, nonce and PKCE verifier of a new authorization
+func NewAuthRequest() (AuthRequest, error) {
+	values := make([]string, 3)
+
+	for i := range values {
+		bts := make([]byte, 32)
+		if _, err := rand.Read(bts); err != nil {
+			return AuthRequest{}, err
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(bts)
+	}
+
+	return AuthRequest{
+		State:        values[0],
+		Nonce:        values[1],
+		CodeVerifier: values[2],
+	}, nil
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func stringClaim(token jwt.Token, name string) string {
+	value, _ := token.Get(name)
+	s, _ := value.(string)
+	return s
+}
+
+// boolClaim reads the boolean claim, some providers send it as a string
+func boolClaim(token jwt.Token, name string) bool {
+	value, _ := token.Get(name)
+
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+
+	return false
+}
@@ -0,0 +1,320 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jws"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// mockOIDC is a local OpenID Connect provider, it issues the id token of the code which is authorized by authorize
+type mockOIDC struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	// codes are the authorized codes and their PKCE challenges and nonces
+	codes map[string]AuthRequest
+	// claims changes the claims of the issued id tokens
+	claims func(token jwt.Token)
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockOIDC{
+		key:   key,
+		kid:   "key-1",
+		codes: map[string]AuthRequest{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		public, err := jwk.New(&m.key.PublicKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = public.Set(jwk.KeyIDKey, m.kid)
+		_ = public.Set(jwk.AlgorithmKey, jwa.RS256)
+
+		set := jwk.NewSet()
+		set.Add(public)
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/token", m.token)
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// authorize approves the consent of the user and returns the code which is sent to the redirect uri
+func (m *mockOIDC) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %v, want S256", query.Get("code_challenge_method"))
+	}
+
+	code := "code-" + query.Get("state")
+	m.codes[code] = AuthRequest{
+		State:        query.Get("state"),
+		Nonce:        query.Get("nonce"),
+		CodeVerifier: query.Get("code_challenge"),
+	}
+
+	return code
+}
+
+func (m *mockOIDC) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	authorized, ok := m.codes[r.PostForm.Get("code")]
+	if !ok || r.PostForm.Get("client_secret") != "secret" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	delete(m.codes, r.PostForm.Get("code"))
+
+	if codeChallenge(r.PostForm.Get("code_verifier")) != authorized.CodeVerifier {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.New()
+	_ = token.Set(jwt.IssuerKey, m.server.URL)
+	_ = token.Set(jwt.SubjectKey, "10769150350006150715113082367")
+	_ = token.Set(jwt.AudienceKey, "client")
+	_ = token.Set(jwt.IssuedAtKey, time.Now())
+	_ = token.Set(jwt.ExpirationKey, time.Now().Add(time.Hour))
+	_ = token.Set("nonce", authorized.Nonce)
+	_ = token.Set("email", "Jane.Doe@example.com")
+	_ = token.Set("email_verified", true)
+	_ = token.Set("given_name", "Jane")
+	_ = token.Set("family_name", "Doe")
+
+	if m.claims != nil {
+		m.claims(token)
+	}
+
+	headers := jws.NewHeaders()
+	_ = headers.Set(jws.KeyIDKey, m.kid)
+
+	signed, err := jwt.Sign(token, jwa.RS256, m.key, jwt.WithHeaders(headers))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"id_token":     string(signed),
+	})
+}
+
+func (m *mockOIDC) provider() *OIDCProvider {
+	return NewOIDCProvider(Config{
+		Name:         "mock",
+		Issuer:       m.server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://127.0.0.1:3000/oauth/mock/callback",
+	})
+}
+
+// login runs the authorization code flow against the mock provider
+func (m *mockOIDC) login(t *testing.T, provider *OIDCProvider) (*Identity, error) {
+	t.Helper()
+
+	request, err := NewAuthRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return provider.Exchange(context.Background(), m.authorize(t, authURL), request)
+}
+
+func TestOIDCLogin(t *testing.T) {
+	m := newMockOIDC(t)
+
+	identity, err := m.login(t, m.provider())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Identity{
+		Provider:      "mock",
+		Subject:       "10769150350006150715113082367",
+		Email:         "Jane.Doe@example.com",
+		EmailVerified: true,
+		GivenName:     "Jane",
+		FamilyName:    "Doe",
+	}
+	if *identity != want {
+		t.Errorf("Exchange() = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	m := newMockOIDC(t)
+
+	request := AuthRequest{State: "state", Nonce: "nonce", CodeVerifier: "verifier"}
+
+	authURL, err := m.provider().AuthCodeURL(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(authURL)
+	query := u.Query()
+
+	if u.Path != "/authorize" {
+		t.Errorf("path = %v, want /authorize", u.Path)
+	}
+
+	for key, want := range map[string]string{
+		"client_id":      "client",
+		"response_type":  "code",
+		"scope":          "openid email profile",
+		"state":          "state",
+		"nonce":          "nonce",
+		"code_challenge": codeChallenge("verifier"),
+		"redirect_uri":   "http://127.0.0.1:3000/oauth/mock/callback",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%v = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestOIDCRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims func(token jwt.Token)
+	}{
+		{name: "nonce", claims: func(token jwt.Token) { _ = token.Set("nonce", "replayed") }},
+		{name: "audience", claims: func(token jwt.Token) { _ = token.Set(jwt.AudienceKey, "another-client") }},
+		{name: "issuer", claims: func(token jwt.Token) { _ = token.Set(jwt.IssuerKey, "https://evil.example.com") }},
+		{name: "expired", claims: func(token jwt.Token) { _ = token.Set(jwt.ExpirationKey, time.Now().Add(-time.Hour)) }},
+		{name: "authorized party", claims: func(token jwt.Token) { _ = token.Set("azp", "another-client") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDC(t)
+			m.claims = tt.claims
+
+			if _, err := m.login(t, m.provider()); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("Exchange() error = %v, want %v", err, ErrInvalidIDToken)
+			}
+		})
+	}
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	m := newMockOIDC(t)
+	provider := m.provider()
+
+	// the keys of the provider are cached by the first login
+	if _, err := m.login(t, provider); err != nil {
+		t.Fatal(err)
+	}
+
+	m.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+	m.kid = "key-2"
+
+	if _, err := m.login(t, provider); err != nil {
+		t.Fatalf("Exchange() after the key rotation = %v, want nil", err)
+	}
+}
+
+func TestOIDCRejectsForeignSignature(t *testing.T) {
+	m := newMockOIDC(t)
+
+	// the token endpoint signs by a key which is not published by the jwks
+	forged := *m
+	forged.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+	m.server.Config.Handler.(*http.ServeMux).HandleFunc("/forged/token", forged.token)
+
+	provider := m.provider()
+
+	request, _ := NewAuthRequest()
+	authURL, _ := provider.AuthCodeURL(context.Background(), request)
+	code := m.authorize(t, authURL)
+
+	provider.discovery.TokenEndpoint = m.server.URL + "/forged/token"
+
+	if _, err := provider.Exchange(context.Background(), code, request); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Exchange() error = %v, want %v", err, ErrInvalidIDToken)
+	}
+}
+
+func TestOIDCRejectsWrongVerifier(t *testing.T) {
+	m := newMockOIDC(t)
+	provider := m.provider()
+
+	request, _ := NewAuthRequest()
+	authURL, _ := provider.AuthCodeURL(context.Background(), request)
+	code := m.authorize(t, authURL)
+
+	request.CodeVerifier = "stolen-code-without-verifier"
+
+	if _, err := provider.Exchange(context.Background(), code, request); !errors.Is(err, ErrExchangeFailed) {
+		t.Errorf("Exchange() error = %v, want %v", err, ErrExchangeFailed)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	m := newMockOIDC(t)
+	Register(m.provider())
+
+	if _, err := Get("mock"); err != nil {
+		t.Errorf("Get() error = %v", err)
+	}
+
+	if _, err := Get("unknown"); err != ErrUnknownProvider {
+		t.Errorf("Get() error = %v, want %v", err, ErrUnknownProvider)
+	}
+}
@@ -0,0 +1,127 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// GoogleIssuer is the issuer of the google provider when it is not configured
+const GoogleIssuer = "https://accounts.google.com"
+
+var ErrUnknownProvider = errors.New("unknown oauth provider")
+
+// Identity is the user of the provider
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+}
+
+// AuthRequest is the state of an authorization which is kept until the callback
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// Provider is an OAuth2 provider which returns the identity of the user by the authorization code
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the url of the consent page of the provider
+	AuthCodeURL(ctx context.Context, request AuthRequest) (string, error)
+	// Exchange exchanges the authorization code and returns the verified identity of the user
+	Exchange(ctx context.Context, code string, request AuthRequest) (*Identity, error)
+}
+
+// Config is the configuration of a provider, the providers are configured by oauth.providers.<name>
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+)
+
+// Initialize registers the providers of the config, the providers without a client id are disabled
+func Initialize() error {
+	for name := range viper.GetStringMap("oauth.providers") {
+		sub := viper.Sub("oauth.providers." + name)
+		if sub == nil {
+			continue
+		}
+
+		config := Config{
+			Name:         name,
+			Issuer:       sub.GetString("issuer"),
+			ClientID:     sub.GetString("client-id"),
+			ClientSecret: sub.GetString("client-secret"),
+			RedirectURL:  sub.GetString("redirect-url"),
+			Scopes:       sub.GetStringSlice("scopes"),
+		}
+
+		if config.ClientID == "" {
+			continue
+		}
+
+		if config.Issuer == "" && name == "google" {
+			config.Issuer = GoogleIssuer
+		}
+
+		if config.Issuer == "" || config.RedirectURL == "" {
+			return errors.New("oauth provider " + name + " needs the issuer and the redirect-url")
+		}
+
+		Register(NewOIDCProvider(config))
+	}
+
+	return nil
+}
+
+// Register adds the provider, a provider with the same name is replaced
+func Register(provider Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	providers[provider.Name()] = provider
+}
+
+// Get returns the provider by its name
+func Get(name string) (Provider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	provider, ok := providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	return provider, nil
+}
+
+// Names returns the names of the registered providers
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,51 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestInitialize(t *testing.T) {
+	viper.Set("oauth.providers", map[string]any{
+		"google": map[string]any{
+			"client-id":    "google-client",
+			"redirect-url": "http://127.0.0.1:3000/oauth/google/callback",
+		},
+		"disabled": map[string]any{
+			"issuer": "https://sso.example.com",
+		},
+	})
+	t.Cleanup(func() { viper.Set("oauth.providers", nil) })
+
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := Get("google")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issuer := provider.(*OIDCProvider).config.Issuer; issuer != GoogleIssuer {
+		t.Errorf("issuer = %v, want %v", issuer, GoogleIssuer)
+	}
+
+	if _, err := Get("disabled"); err != ErrUnknownProvider {
+		t.Errorf("Get() of the provider without a client id = %v, want %v", err, ErrUnknownProvider)
+	}
+}
+
+func TestInitializeRequiresRedirectURL(t *testing.T) {
+	viper.Set("oauth.providers", map[string]any{
+		"oidc": map[string]any{
+			"issuer":    "https://sso.example.com",
+			"client-id": "client",
+		},
+	})
+	t.Cleanup(func() { viper.Set("oauth.providers", nil) })
+
+	if err := Initialize(); err == nil {
+		t.Errorf("Initialize() error = nil, want the missing redirect-url")
+	}
+}
@@ -41,6 +41,31 @@ func GetOpenOrder(db *gorm.DB, userID uuid.UUID) (*models.Order, error) {
 	return &order, nil
 }
 
+// HasBought reports whether the user has a paid order of the product, the items of the bundles are counted too
+func HasBought(db *gorm.DB, userID, productID uuid.UUID) bool {
+	return hasOrdered(db, userID, productID, "o.status <> ?", models.OrderStatusOpen)
+}
+
+// HasReceived reports whether the user has received an order of the product
+func HasReceived(db *gorm.DB, userID, productID uuid.UUID) bool {
+	return hasOrdered(db, userID, productID, "o.status = ?", models.OrderStatusReceived)
+}
+
+func hasOrdered(db *gorm.DB, userID, productID uuid.UUID, statusCond string, status models.OrderStatus) bool {
+	var exists bool
+
+	db.Raw(`SELECT EXISTS (
+		SELECT 1 FROM order_item oi
+		INNER JOIN "order" o ON o.id = oi.order_id
+		LEFT JOIN order_item_component oic ON oic.order_item_id = oi.id AND oic.deleted_at IS NULL
+		INNER JOIN product_item pi2 ON pi2.id = COALESCE(oi.product_item_id, oic.product_item_id)
+		WHERE o.created_by_id = ? AND `+statusCond+` AND o.deleted_at IS NULL AND oi.deleted_at IS NULL
+			AND pi2.product_id = ?
+	)`, userID, status, productID).Scan(&exists)
+
+	return exists
+}
+
 func generateOrderCode(length int) string {
 	charSet := "0123456789"
 
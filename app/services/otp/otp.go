@@ -0,0 +1,261 @@
+package otp
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/app/services/notifier/email"
+	"github.com/esmailemami/eshop/app/services/notifier/sms"
+	"github.com/esmailemami/eshop/app/services/numeric"
+	"github.com/esmailemami/eshop/app/services/phone_number"
+	"github.com/esmailemami/eshop/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// CodeLength is the number of the digits of the code
+	CodeLength = 6
+	// CodeTTL is the lifetime of the code which is sent by sms
+	CodeTTL = 2 * time.Minute
+	// EmailCodeTTL is the lifetime of the code which is sent by email, emails are usually delivered slower
+	EmailCodeTTL = 10 * time.Minute
+	// Cooldown is the time the user has to wait before requesting a new code
+	Cooldown = time.Minute
+	// MaxAttempts is the number of the wrong codes which are accepted before the code is burned
+	MaxAttempts = 5
+	// HourlyLimit is the number of the codes which are sent to a mobile number or an email in an hour
+	HourlyLimit = 5
+
+	smsDriver = "niksms"
+)
+
+// NormalizeMobile transforms the persian digits and the international prefixes of the mobile number to the
+// 09xxxxxxxxx form, the second result is false when the number is not a valid mobile number
+func NormalizeMobile(mobile string) (string, bool) {
+	mobile = numeric.TransformFa2En(strings.TrimSpace(mobile))
+	mobile = strings.NewReplacer(" ", "", "-", "").Replace(mobile)
+
+	switch {
+	case strings.HasPrefix(mobile, "+98"):
+		mobile = "0" + mobile[3:]
+	case strings.HasPrefix(mobile, "0098"):
+		mobile = "0" + mobile[4:]
+	case strings.HasPrefix(mobile, "98") && len(mobile) == 12:
+		mobile = "0" + mobile[2:]
+	case strings.HasPrefix(mobile, "9") && len(mobile) == 10:
+		mobile = "0" + mobile
+	}
+
+	return mobile, phone_number.IsMobileNumber(mobile)
+}
+
+// Message is the text of the sms which contains the code
+func Message(code string) string {
+	return "Your verification code: " + code + "\nDo not share this code with anyone."
+}
+
+// Send creates a new code for the mobile number and sends it by sms. The previous codes of the mobile number are
+// expired, so only the last sent code is accepted.
+func Send(db *gorm.DB, mobile string) (*models.VerificationCode, error) {
+	verificationCode, err := issue(db, models.VerificationCodeScopeMobile, mobile, CodeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	result, sendErr := sms.NewSmsNotifier(smsDriver).Send(mobile, Message(verificationCode.Value))
+	record(db, mobile, result, sendErr)
+
+	if sendErr != nil {
+		// the code is never received, so it must not block the user from requesting another one
+		db.Unscoped().Delete(verificationCode)
+		return nil, errors.New(consts.UnableToSendVerificationCode)
+	}
+
+	return verificationCode, nil
+}
+
+// SendEmail creates a new code for the email address and sends it by email, the codes are separated from the
+// password recovery links of the address
+func SendEmail(db *gorm.DB, address, username string) (*models.VerificationCode, error) {
+	verificationCode, err := issue(db, models.VerificationCodeScopeEmailVerification, address, EmailCodeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := email.NewNotifier("gmail").Send([]string{address}, email.KeyVerifyEmail, email.VerifyEmail{
+		Username: username,
+		Code:     verificationCode.Value,
+	}); err != nil {
+		logger.Default().WithField("Email", address).Error(err.Error())
+		db.Unscoped().Delete(verificationCode)
+		return nil, errors.New(consts.UnableToSendVerificationCode)
+	}
+
+	return verificationCode, nil
+}
+
+// issue creates a new code for the key after checking the cooldown and the hourly limit of the key. The checks and
+// the insert are serialized by a lock of the key, so the concurrent requests cannot pass the limits together.
+func issue(db *gorm.DB, scope models.VerificationCodeScope, key string, ttl time.Duration) (*models.VerificationCode, error) {
+	code, err := generateCode(CodeLength)
+	if err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+
+	var verificationCode models.VerificationCode
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", fmt.Sprintf("otp.issue:%d:%s", scope, key)).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		now := time.Now()
+
+		var last models.VerificationCode
+
+		err := tx.Where("scope = ? AND key = ?", scope, key).
+			Order("created_at DESC").
+			First(&last).Error
+
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return errors.New(consts.InternalServerError)
+		}
+
+		if err == nil && last.CreatedAt.Add(Cooldown).After(now) {
+			return errors.New(consts.HasActiveVerificationCodeRequest)
+		}
+
+		var sent int64
+
+		if err := tx.Model(&models.VerificationCode{}).
+			Where("scope = ? AND key = ? AND created_at > ?", scope, key, now.Add(-time.Hour)).
+			Count(&sent).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		if sent >= HourlyLimit {
+			return errors.New(consts.TooManyVerificationCodeRequests)
+		}
+
+		if err := tx.Model(&models.VerificationCode{}).
+			Where("scope = ? AND key = ? AND verified = false AND expire_at > ?", scope, key, now).
+			UpdateColumn("expire_at", now).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		verificationCode = models.VerificationCode{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			ExpireAt:   now.Add(ttl),
+			MaxRetires: MaxAttempts,
+			Scope:      scope,
+			Key:        key,
+			Value:      code,
+		}
+
+		if err := tx.Create(&verificationCode).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &verificationCode, nil
+}
+
+// Verify checks the code against the last sent code of the key. Every check counts as an attempt and the code is
+// burned after MaxRetires wrong attempts.
+func Verify(db *gorm.DB, scope models.VerificationCodeScope, key, code string) error {
+	var matched bool
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var verificationCode models.VerificationCode
+
+		// the concurrent checks of the same code wait here, so the attempts are counted correctly
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND key = ? AND verified = false AND expire_at > ?", scope, key, time.Now()).
+			Order("created_at DESC").
+			First(&verificationCode).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.New(consts.InvalidVerificationCode)
+			}
+			return errors.New(consts.InternalServerError)
+		}
+
+		if verificationCode.Attempts >= verificationCode.MaxRetires {
+			return errors.New(consts.VerificationCodeAttemptsExceeded)
+		}
+
+		matched = subtle.ConstantTimeCompare([]byte(numeric.TransformFa2En(strings.TrimSpace(code))), []byte(verificationCode.Value)) == 1
+
+		if err := tx.Model(&verificationCode).UpdateColumns(map[string]any{
+			"attempts": gorm.Expr("attempts + 1"),
+			"verified": matched,
+		}).Error; err != nil {
+			return errors.New(consts.InternalServerError)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !matched {
+		return errors.New(consts.InvalidVerificationCode)
+	}
+
+	return nil
+}
+
+// record saves the delivery result of the sms
+func record(db *gorm.DB, mobile string, result interface{}, sendErr error) {
+	smsResult := models.SmsResult{
+		ID:     models.NewID(),
+		Status: models.SmsStatusSent,
+	}
+
+	if r, ok := result.(sms.SmsResult); ok {
+		smsResult.SmsResult = r
+	} else {
+		smsResult.To = mobile
+		smsResult.DriverName = smsDriver
+	}
+
+	if sendErr != nil {
+		msg := sendErr.Error()
+		smsResult.Status = models.SmsStatusFailed
+		smsResult.Error = &msg
+	}
+
+	if err := db.Create(&smsResult).Error; err != nil {
+		logger.Default().WithField("Mobile", mobile).Error(err.Error())
+	}
+}
+
+func generateCode(length int) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(byte('0' + n.Int64()))
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,47 @@
+package otp
+
+import "testing"
+
+func TestNormalizeMobile(t *testing.T) {
+	tests := []struct {
+		name   string
+		mobile string
+		want   string
+		valid  bool
+	}{
+		{name: "local", mobile: "09121234567", want: "09121234567", valid: true},
+		{name: "persian digits", mobile: "۰۹۱۲۱۲۳۴۵۶۷", want: "09121234567", valid: true},
+		{name: "plus prefix", mobile: "+989121234567", want: "09121234567", valid: true},
+		{name: "double zero prefix", mobile: "00989121234567", want: "09121234567", valid: true},
+		{name: "country code", mobile: "989121234567", want: "09121234567", valid: true},
+		{name: "without zero", mobile: "9121234567", want: "09121234567", valid: true},
+		{name: "spaces and dashes", mobile: " 0912-123 4567 ", want: "09121234567", valid: true},
+		{name: "short", mobile: "0912123456", want: "0912123456", valid: false},
+		{name: "landline", mobile: "02112345678", want: "02112345678", valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, valid := NormalizeMobile(tt.mobile)
+			if got != tt.want || valid != tt.valid {
+				t.Errorf("NormalizeMobile() = %v, %v, want %v, %v", got, valid, tt.want, tt.valid)
+			}
+		})
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	code, err := generateCode(CodeLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(code) != CodeLength {
+		t.Errorf("generateCode() length = %v, want %v", len(code), CodeLength)
+	}
+
+	for _, ch := range code {
+		if ch < '0' || ch > '9' {
+			t.Errorf("generateCode() = %v, want digits only", code)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package price_history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/events"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const EventProductItemPriceChanged = "product_item.price_changed"
+
+// LowestPriceDays is the period of the lowest price which is shown with the discounted prices
+const LowestPriceDays = 30
+
+type PriceChanged struct {
+	ProductItemID uuid.UUID `json:"productItemId"`
+	OldPrice      float64   `json:"oldPrice"`
+	NewPrice      float64   `json:"newPrice"`
+}
+
+// Record writes the price change of the product item to the history, previousPrice is nil for the new items.
+// Nothing is written when the price is not changed.
+func Record(tx *gorm.DB, productItemID uuid.UUID, previousPrice *float64, price float64, source models.PriceChangeSource, scheduleID *uuid.UUID) error {
+	if previousPrice != nil && *previousPrice == price {
+		return nil
+	}
+
+	return tx.Create(&models.ProductItemPrice{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		ProductItemID: productItemID,
+		Price:         price,
+		PreviousPrice: previousPrice,
+		Source:        source,
+		ScheduleID:    scheduleID,
+	}).Error
+}
+
+// Lowest returns the lowest price of the product item from since until now, the price which was in effect at
+// since is counted too.
+func Lowest(db *gorm.DB, productItemID uuid.UUID, since time.Time, currentPrice float64) (float64, error) {
+	var lowest *float64
+
+	err := db.Raw(`
+		SELECT MIN(price) FROM (
+			(SELECT price FROM product_item_price_history WHERE product_item_id = ? AND deleted_at IS NULL AND created_at >= ?)
+			UNION ALL
+			(SELECT price FROM product_item_price_history WHERE product_item_id = ? AND deleted_at IS NULL AND created_at < ?
+				ORDER BY created_at DESC LIMIT 1)
+		) t`,
+		productItemID, since, productItemID, since,
+	).Scan(&lowest).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	if lowest == nil || currentPrice < *lowest {
+		return currentPrice, nil
+	}
+
+	return *lowest, nil
+}
+
+// ApplySchedules applies the price schedules which their time is reached, the schedules are claimed by the update,
+// so running it on multiple instances does not apply a schedule twice.
+func ApplySchedules(db *gorm.DB, now time.Time) ([]events.Event, error) {
+	result := []events.Event{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var schedules []models.ProductItemPriceSchedule
+
+		if err := tx.Raw(
+			"UPDATE product_item_price_schedule SET status = ?, applied_at = ?, updated_at = ? "+
+				"WHERE deleted_at IS NULL AND status = ? AND apply_at <= ? "+
+				"RETURNING id, product_item_id, price, apply_at",
+			models.ProductItemPriceScheduleApplied, now, now, models.ProductItemPriceSchedulePending, now,
+		).Scan(&schedules).Error; err != nil {
+			return err
+		}
+
+		// the last schedule of an item wins
+		sort.SliceStable(schedules, func(i, j int) bool {
+			return schedules[i].ApplyAt.Before(schedules[j].ApplyAt)
+		})
+
+		for _, schedule := range schedules {
+			var item models.ProductItem
+
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id", "price").
+				Limit(1).
+				Find(&item, "id = ?", schedule.ProductItemID).Error; err != nil {
+				return err
+			}
+
+			// the item is deleted meanwhile
+			if item.ID == nil {
+				if err := tx.Model(&models.ProductItemPriceSchedule{}).
+					Where("id = ?", schedule.ID).
+					UpdateColumns(map[string]interface{}{
+						"status":     models.ProductItemPriceScheduleCanceled,
+						"applied_at": nil,
+					}).Error; err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if err := tx.Model(&models.ProductItem{}).
+				Where("id = ?", schedule.ProductItemID).
+				UpdateColumns(map[string]interface{}{
+					"price":      schedule.Price,
+					"updated_at": now,
+				}).Error; err != nil {
+				return err
+			}
+
+			previousPrice := item.Price
+
+			if err := Record(tx, schedule.ProductItemID, &previousPrice, schedule.Price, models.PriceChangeSourceSchedule, schedule.ID); err != nil {
+				return err
+			}
+
+			if previousPrice != schedule.Price {
+				result = append(result, events.Event{
+					Name: EventProductItemPriceChanged,
+					Payload: PriceChanged{
+						ProductItemID: schedule.ProductItemID,
+						OldPrice:      previousPrice,
+						NewPrice:      schedule.Price,
+					},
+					OccurredAt: now,
+				})
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return []events.Event{}, err
+	}
+
+	return result, nil
+}
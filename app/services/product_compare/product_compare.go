@@ -0,0 +1,111 @@
+package product_compare
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MinProducts = 2
+	MaxProducts = 4
+)
+
+// Value is a feature value of a product which is loaded from the database
+type Value struct {
+	CategoryID   uuid.UUID `gorm:"column:category_id"`
+	CategoryName string    `gorm:"column:category_name"`
+	KeyID        uuid.UUID `gorm:"column:key_id"`
+	KeyName      string    `gorm:"column:key_name"`
+	ProductID    uuid.UUID `gorm:"column:product_id"`
+	Value        string    `gorm:"column:value"`
+}
+
+type Category struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Keys []Key     `json:"keys"`
+}
+
+type Key struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+
+	// values are in the order of the compared products, nil means the product does not have the key
+	Values    []*string `json:"values"`
+	Different bool      `json:"different"`
+}
+
+// Matrix groups the feature values of the products by the feature categories and keys, the categories
+// and keys are in the order of their first appearance in values.
+func Matrix(productIDs []uuid.UUID, values []Value) []Category {
+	columns := make(map[uuid.UUID]int, len(productIDs))
+	for i, id := range productIDs {
+		columns[id] = i
+	}
+
+	categories := []Category{}
+	categoryIndex := map[uuid.UUID]int{}
+	keyIndex := map[uuid.UUID]int{}
+
+	for _, value := range values {
+		column, ok := columns[value.ProductID]
+		if !ok {
+			continue
+		}
+
+		ci, ok := categoryIndex[value.CategoryID]
+		if !ok {
+			ci = len(categories)
+			categoryIndex[value.CategoryID] = ci
+			categories = append(categories, Category{
+				ID:   value.CategoryID,
+				Name: value.CategoryName,
+				Keys: []Key{},
+			})
+		}
+
+		category := &categories[ci]
+
+		ki, ok := keyIndex[value.KeyID]
+		if !ok {
+			ki = len(category.Keys)
+			keyIndex[value.KeyID] = ki
+			category.Keys = append(category.Keys, Key{
+				ID:     value.KeyID,
+				Name:   value.KeyName,
+				Values: make([]*string, len(productIDs)),
+			})
+		}
+
+		v := value.Value
+		category.Keys[ki].Values[column] = &v
+	}
+
+	for i := range categories {
+		for j := range categories[i].Keys {
+			categories[i].Keys[j].Different = different(categories[i].Keys[j].Values)
+		}
+	}
+
+	return categories
+}
+
+// different reports whether the values are not the same, a missing value is different from any value
+func different(values []*string) bool {
+	for i := 1; i < len(values); i++ {
+		if (values[i] == nil) != (values[0] == nil) {
+			return true
+		}
+
+		if values[i] != nil && normalize(*values[i]) != normalize(*values[0]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalize(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
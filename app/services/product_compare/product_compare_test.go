@@ -0,0 +1,64 @@
+package product_compare
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMatrix(t *testing.T) {
+	var (
+		p1, p2, p3 = uuid.New(), uuid.New(), uuid.New()
+		display    = uuid.New()
+		battery    = uuid.New()
+		size       = uuid.New()
+		resolution = uuid.New()
+		capacity   = uuid.New()
+	)
+
+	values := []Value{
+		{CategoryID: display, CategoryName: "Display", KeyID: size, KeyName: "Size", ProductID: p1, Value: "6.1 inch"},
+		{CategoryID: display, CategoryName: "Display", KeyID: size, KeyName: "Size", ProductID: p2, Value: "6.1  Inch"},
+		{CategoryID: display, CategoryName: "Display", KeyID: size, KeyName: "Size", ProductID: p3, Value: "6.1 inch"},
+		{CategoryID: display, CategoryName: "Display", KeyID: resolution, KeyName: "Resolution", ProductID: p1, Value: "FHD"},
+		{CategoryID: display, CategoryName: "Display", KeyID: resolution, KeyName: "Resolution", ProductID: p3, Value: "FHD"},
+		{CategoryID: battery, CategoryName: "Battery", KeyID: capacity, KeyName: "Capacity", ProductID: p1, Value: "4000"},
+		{CategoryID: battery, CategoryName: "Battery", KeyID: capacity, KeyName: "Capacity", ProductID: p2, Value: "4500"},
+		{CategoryID: battery, CategoryName: "Battery", KeyID: capacity, KeyName: "Capacity", ProductID: p3, Value: "4000"},
+		{CategoryID: battery, CategoryName: "Battery", KeyID: capacity, KeyName: "Capacity", ProductID: uuid.New(), Value: "1"},
+	}
+
+	categories := Matrix([]uuid.UUID{p1, p2, p3}, values)
+
+	if len(categories) != 2 || categories[0].ID != display || categories[1].ID != battery {
+		t.Fatalf("Matrix() invalid categories: %+v", categories)
+	}
+
+	tests := []struct {
+		key       Key
+		missing   int
+		different bool
+	}{
+		{key: categories[0].Keys[0], missing: -1, different: false},
+		{key: categories[0].Keys[1], missing: 1, different: true},
+		{key: categories[1].Keys[0], missing: -1, different: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key.Name, func(t *testing.T) {
+			if len(tt.key.Values) != 3 {
+				t.Fatalf("wants 3 values got: %d", len(tt.key.Values))
+			}
+
+			for i, v := range tt.key.Values {
+				if (i == tt.missing) != (v == nil) {
+					t.Errorf("value #%d wants missing: %v got: %v", i, i == tt.missing, v)
+				}
+			}
+
+			if tt.key.Different != tt.different {
+				t.Errorf("Different wants: %v got: %v", tt.different, tt.key.Different)
+			}
+		})
+	}
+}
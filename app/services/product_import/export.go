@@ -0,0 +1,122 @@
+package product_import
+
+import (
+	"github.com/esmailemami/eshop/app/services/authorization"
+	"github.com/esmailemami/eshop/models"
+	datatypes "github.com/esmailemami/eshop/models/data_types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type exportRow struct {
+	ProductID            uuid.UUID             `gorm:"column:product_id"`
+	ProductCode          string                `gorm:"column:product_code"`
+	ProductName          string                `gorm:"column:product_name"`
+	BrandCode            string                `gorm:"column:brand_code"`
+	CategoryCode         string                `gorm:"column:category_code"`
+	ShortDescription     string                `gorm:"column:short_description"`
+	Description          string                `gorm:"column:description"`
+	TopFeatures          datatypes.StringArray `gorm:"column:top_features"`
+	DefaultProductItemID *uuid.UUID            `gorm:"column:default_product_item_id"`
+	ItemID               *uuid.UUID            `gorm:"column:item_id"`
+	ColorCode            *string               `gorm:"column:color_code"`
+	Price                *float64              `gorm:"column:price"`
+	Quantity             *int                  `gorm:"column:quantity"`
+	Status               *models.ProductStatus `gorm:"column:status"`
+	SKU                  *string               `gorm:"column:sku"`
+	Barcode              *string               `gorm:"column:barcode"`
+	Weight               *float64              `gorm:"column:weight"`
+	Length               *float64              `gorm:"column:length"`
+	Width                *float64              `gorm:"column:width"`
+	Height               *float64              `gorm:"column:height"`
+}
+
+// Export dumps the catalog with the import columns, so the exported file can be edited and imported again.
+// The products without any item are exported as a single row without the item columns. Only the products in
+// the scope are exported.
+func Export(db *gorm.DB, scope authorization.Scope) ([][]string, error) {
+	var items []exportRow
+
+	if err := scope.Where(db.Table("product p"), "p.category_id", "p.brand_id").
+		Joins("INNER JOIN brand b ON b.id = p.brand_id").
+		Joins("INNER JOIN category c ON c.id = p.category_id").
+		Joins("LEFT JOIN product_item pi2 ON pi2.product_id = p.id AND pi2.deleted_at IS NULL").
+		Joins("LEFT JOIN color co ON co.id = pi2.color_id").
+		Where("p.deleted_at IS NULL").
+		Order("p.code, pi2.created_at").
+		Select(`p.id AS product_id, p.code AS product_code, p.name AS product_name, b.code AS brand_code,
+		c.code AS category_code, p.short_description, p.description, p.top_features, p.default_product_item_id,
+		pi2.id AS item_id, co.code AS color_code, pi2.price, pi2.quantity, pi2.status, pi2.sku, pi2.barcode,
+		pi2.weight, pi2.length, pi2.width, pi2.height`).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	var featureValues []struct {
+		ProductID uuid.UUID `gorm:"column:product_id"`
+		Key       string    `gorm:"column:key"`
+		Value     string    `gorm:"column:value"`
+	}
+
+	if err := scope.Where(db.Table("product_feature_value pfv"), "p.category_id", "p.brand_id").
+		Joins("INNER JOIN product_feature_key pfk ON pfk.id = pfv.product_feature_key_id").
+		Joins("INNER JOIN product p ON p.id = pfv.product_id").
+		Where("pfv.deleted_at IS NULL").
+		Order("pfk.name").
+		Select("pfv.product_id, pfk.name AS key, pfv.value").
+		Find(&featureValues).Error; err != nil {
+		return nil, err
+	}
+
+	features := map[uuid.UUID][]Feature{}
+	for _, fv := range featureValues {
+		features[fv.ProductID] = append(features[fv.ProductID], Feature{Key: fv.Key, Value: fv.Value})
+	}
+
+	records := [][]string{Columns}
+
+	var lastProductID uuid.UUID
+
+	for _, item := range items {
+		row := Row{
+			ProductCode:      item.ProductCode,
+			ProductName:      item.ProductName,
+			BrandCode:        item.BrandCode,
+			CategoryCode:     item.CategoryCode,
+			ShortDescription: item.ShortDescription,
+			Description:      item.Description,
+			TopFeatures:      item.TopFeatures,
+			SKU:              item.SKU,
+			Barcode:          item.Barcode,
+			Weight:           item.Weight,
+			Length:           item.Length,
+			Width:            item.Width,
+			Height:           item.Height,
+		}
+
+		// the product features are written on the first row of the product
+		if item.ProductID != lastProductID {
+			row.Features = features[item.ProductID]
+			lastProductID = item.ProductID
+		}
+
+		if item.ItemID != nil && item.ColorCode != nil {
+			row.ColorCode = *item.ColorCode
+			row.IsMainItem = item.DefaultProductItemID != nil && *item.DefaultProductItemID == *item.ItemID
+
+			if item.Price != nil {
+				row.Price = *item.Price
+			}
+			if item.Quantity != nil {
+				row.Quantity = *item.Quantity
+			}
+			if item.Status != nil {
+				row.Status = *item.Status
+			}
+		}
+
+		records = append(records, row.Record())
+	}
+
+	return records, nil
+}
@@ -0,0 +1,516 @@
+package product_import
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/esmailemami/eshop/app/consts"
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/authorization"
+	"github.com/esmailemami/eshop/app/services/barcode"
+	"github.com/esmailemami/eshop/app/services/inventory"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/app/services/price_history"
+	"github.com/esmailemami/eshop/app/services/product_revision"
+	"github.com/esmailemami/eshop/models"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Result struct {
+	TotalRows     int        `json:"totalRows"`
+	SucceededRows int        `json:"succeededRows"`
+	FailedRows    int        `json:"failedRows"`
+	Errors        []RowError `json:"errors"`
+}
+
+// maps the request models fields to the sheet columns
+var fieldColumns = map[string]string{
+	"name":             ColumnProductName,
+	"code":             ColumnProductCode,
+	"brandId":          ColumnBrandCode,
+	"categoryId":       ColumnCategoryCode,
+	"description":      ColumnDescription,
+	"shortDescription": ColumnShortDescription,
+	"topFeatures":      ColumnTopFeatures,
+	"price":            ColumnPrice,
+	"status":           ColumnStatus,
+	"colorId":          ColumnColorCode,
+	"productId":        ColumnProductCode,
+	"quantity":         ColumnQuantity,
+	"sku":              ColumnSKU,
+	"barcode":          ColumnBarcode,
+	"weight":           ColumnWeight,
+	"length":           ColumnLength,
+	"width":            ColumnWidth,
+	"height":           ColumnHeight,
+}
+
+// Import upserts the products of the sheet by their code. Every row is applied in a savepoint
+// so the invalid rows are reported and skipped. On dry run nothing is persisted. The quantity changes
+// refer to the import in the ledger. The products out of the scope are reported as the row errors.
+func Import(db *gorm.DB, importID uuid.UUID, scope authorization.Scope, records [][]string, dryRun bool) (*Result, error) {
+	if len(records) == 0 {
+		return nil, errors.New("the sheet is empty")
+	}
+
+	header, err := ParseHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Errors: []RowError{},
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	// the job recovers the panic of a row, the transaction is rolled back before
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	imp := newImporter(tx, importID, scope)
+
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+
+		// the header is the first line
+		number := i + 2
+		result.TotalRows++
+
+		row, rowErrors := header.ParseRow(number, record)
+
+		if len(rowErrors) == 0 {
+			savepoint := fmt.Sprintf("import_row_%d", number)
+
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+
+			rowErrors = imp.importRow(row)
+
+			if len(rowErrors) > 0 {
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			result.FailedRows++
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+
+		result.SucceededRows++
+	}
+
+	if dryRun {
+		return result, tx.Rollback().Error
+	}
+
+	return result, tx.Commit().Error
+}
+
+type importer struct {
+	tx       *gorm.DB
+	importID uuid.UUID
+	scope    authorization.Scope
+
+	// caches the ids of the codes and names
+	brands      map[string]*uuid.UUID
+	categories  map[string]*uuid.UUID
+	colors      map[string]*uuid.UUID
+	featureKeys map[string]*uuid.UUID
+}
+
+func newImporter(tx *gorm.DB, importID uuid.UUID, scope authorization.Scope) *importer {
+	return &importer{
+		tx:          tx,
+		importID:    importID,
+		scope:       scope,
+		brands:      map[string]*uuid.UUID{},
+		categories:  map[string]*uuid.UUID{},
+		colors:      map[string]*uuid.UUID{},
+		featureKeys: map[string]*uuid.UUID{},
+	}
+}
+
+func (imp *importer) importRow(row Row) []RowError {
+	features, rowErrors := imp.resolveFeatures(row)
+	if len(rowErrors) > 0 {
+		return rowErrors
+	}
+
+	product, rowErrors := imp.upsertProduct(row, features)
+	if len(rowErrors) > 0 {
+		return rowErrors
+	}
+
+	if row.HasItem() {
+		return imp.upsertItem(row, product)
+	}
+
+	return nil
+}
+
+// upsertProduct creates the new products with their features, the content and the features of the existing products
+// are drafted to be published after the review like the edits of the admin panel
+func (imp *importer) upsertProduct(row Row, features []models.ProductRevisionFeature) (*models.Product, []RowError) {
+	var product models.Product
+
+	if err := imp.tx.Where("code = ?", row.ProductCode).Limit(1).Find(&product).Error; err != nil {
+		return nil, internalError(row, err)
+	}
+
+	isNew := product.ID == nil
+
+	if !isNew && !imp.scope.Allows(product.PermissionResource()) {
+		return nil, []RowError{{Row: row.Number, Column: ColumnProductCode, Message: consts.OutOfPermissionScope}}
+	}
+
+	// the empty columns keep the current values of the existing products
+	reqModel := appmodels.ProductReqModel{
+		Name:             product.Name,
+		Code:             row.ProductCode,
+		BrandID:          product.BrandID,
+		CategoryID:       product.CategoryID,
+		Description:      product.Description,
+		ShortDescription: product.ShortDescription,
+		TopFeatures:      product.TopFeatures,
+		Status:           product.Status,
+		PublishAt:        product.PublishAt,
+		UnpublishAt:      product.UnpublishAt,
+	}
+
+	if row.ProductName != "" {
+		reqModel.Name = row.ProductName
+	}
+	if row.Description != "" {
+		reqModel.Description = row.Description
+	}
+	if row.ShortDescription != "" {
+		reqModel.ShortDescription = row.ShortDescription
+	}
+	if row.TopFeatures != nil {
+		reqModel.TopFeatures = row.TopFeatures
+	}
+
+	rowErrors := []RowError{}
+
+	if row.BrandCode != "" {
+		if id := imp.lookup(imp.brands, "brand", "code", row.BrandCode); id != nil {
+			reqModel.BrandID = *id
+		} else {
+			rowErrors = append(rowErrors, RowError{Row: row.Number, Column: ColumnBrandCode, Message: consts.ModelBrandNotFound})
+		}
+	}
+
+	if row.CategoryCode != "" {
+		if id := imp.lookup(imp.categories, "category", "code", row.CategoryCode); id != nil {
+			reqModel.CategoryID = *id
+		} else {
+			rowErrors = append(rowErrors, RowError{Row: row.Number, Column: ColumnCategoryCode, Message: consts.ModelCategoryNotFound})
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors
+	}
+
+	// the product can not be moved out of the scope either
+	if !imp.scope.Allows(models.PermissionResource{CategoryID: &reqModel.CategoryID, BrandID: &reqModel.BrandID}) {
+		return nil, []RowError{{Row: row.Number, Message: consts.OutOfPermissionScope}}
+	}
+
+	if isNew {
+		if err := reqModel.ValidateCreate(imp.tx); err != nil {
+			return nil, validationErrors(row, err)
+		}
+
+		dbModel := reqModel.ToDBModel()
+
+		if err := imp.tx.Create(dbModel).Error; err != nil {
+			return nil, internalError(row, err)
+		}
+
+		if rowErrors := imp.createFeatures(row, *dbModel.ID, features); len(rowErrors) > 0 {
+			return nil, rowErrors
+		}
+
+		// the imported product is not visible until its content is approved
+		if _, err := product_revision.NewProductDraft(imp.tx, *dbModel.ID); err != nil {
+			return nil, internalError(row, err)
+		}
+
+		return dbModel, nil
+	}
+
+	if err := reqModel.ValidateUpdate(imp.tx, *product.ID); err != nil {
+		return nil, validationErrors(row, err)
+	}
+
+	reqModel.MergeWithDBData(&product)
+
+	if err := imp.tx.Save(&product).Error; err != nil {
+		return nil, internalError(row, err)
+	}
+
+	_, err := product_revision.DraftChanges(imp.tx, *product.ID, func(content *product_revision.Content) {
+		reqModel.MergeWithContent(content)
+
+		for _, feature := range features {
+			if i := indexOfFeature(content.Features, feature.ProductFeatureKeyID); i >= 0 {
+				content.Features[i].Value = feature.Value
+			} else {
+				content.Features = append(content.Features, feature)
+			}
+		}
+	})
+	if err != nil {
+		return nil, internalError(row, err)
+	}
+
+	return &product, nil
+}
+
+// resolveFeatures finds the keys of the features of the row
+func (imp *importer) resolveFeatures(row Row) ([]models.ProductRevisionFeature, []RowError) {
+	features := make([]models.ProductRevisionFeature, 0, len(row.Features))
+
+	for _, feature := range row.Features {
+		keyID := imp.lookup(imp.featureKeys, "product_feature_key", "name", feature.Key)
+		if keyID == nil {
+			return nil, []RowError{{
+				Row:     row.Number,
+				Column:  ColumnFeatures,
+				Message: fmt.Sprintf("%s: %s", consts.ModelProductFeatureKeyNotFound, feature.Key),
+			}}
+		}
+
+		features = append(features, models.ProductRevisionFeature{
+			ProductFeatureKeyID: *keyID,
+			Key:                 feature.Key,
+			Value:               feature.Value,
+		})
+	}
+
+	return features, nil
+}
+
+func (imp *importer) createFeatures(row Row, productID uuid.UUID, features []models.ProductRevisionFeature) []RowError {
+	for _, feature := range features {
+		value := models.ProductFeatureValue{
+			Model: models.Model{
+				ID: models.NewID(),
+			},
+			ProductFeatureKeyID: feature.ProductFeatureKeyID,
+			ProductID:           productID,
+			Value:               feature.Value,
+		}
+
+		if err := imp.tx.Create(&value).Error; err != nil {
+			return internalError(row, err)
+		}
+	}
+
+	return nil
+}
+
+func indexOfFeature(features []models.ProductRevisionFeature, keyID uuid.UUID) int {
+	for i, feature := range features {
+		if feature.ProductFeatureKeyID == keyID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (imp *importer) upsertItem(row Row, product *models.Product) []RowError {
+	colorID := imp.lookup(imp.colors, "color", "code", row.ColorCode)
+	if colorID == nil {
+		return []RowError{{Row: row.Number, Column: ColumnColorCode, Message: consts.ModelColorNotFound}}
+	}
+
+	var item models.ProductItem
+
+	// the items are matched by their SKU and then by their color
+	qry := imp.tx.Where("product_id = ? AND color_id = ?", product.ID, colorID)
+	if row.SKU != nil {
+		qry = imp.tx.Where("sku = ?", *row.SKU)
+	}
+
+	if err := qry.Limit(1).Find(&item).Error; err != nil {
+		return internalError(row, err)
+	}
+
+	if item.ID != nil && item.ProductID != *product.ID {
+		return []RowError{{Row: row.Number, Column: ColumnSKU, Message: consts.ExistedSKU}}
+	}
+
+	reqModel := appmodels.ProductItemReqModel{
+		Price:      row.Price,
+		Status:     row.Status,
+		ColorID:    *colorID,
+		ProductID:  *product.ID,
+		Quantity:   row.Quantity,
+		IsMainItem: row.IsMainItem,
+		SKU:        row.SKU,
+		Barcode:    row.Barcode,
+		Weight:     row.Weight,
+		Length:     row.Length,
+		Width:      row.Width,
+		Height:     row.Height,
+		// the schedules and the reorder threshold are not in the sheet
+		PublishAt:        item.PublishAt,
+		UnpublishAt:      item.UnpublishAt,
+		ReorderThreshold: item.ReorderThreshold,
+	}
+
+	var previousQuantity int
+
+	if item.ID == nil {
+		if err := reqModel.ValidateCreate(imp.tx); err != nil {
+			return validationErrors(row, err)
+		}
+
+		item = *reqModel.ToDBModel()
+
+		// the quantity is set along with the ledger
+		item.Quantity = 0
+
+		if err := imp.tx.Create(&item).Error; err != nil {
+			return internalError(row, err)
+		}
+
+		if err := price_history.Record(imp.tx, *item.ID, nil, item.Price, models.PriceChangeSourceImport, nil); err != nil {
+			return internalError(row, err)
+		}
+	} else {
+		if err := reqModel.ValidateUpdate(imp.tx, *item.ID); err != nil {
+			return validationErrors(row, err)
+		}
+
+		previousPrice := item.Price
+		previousQuantity = item.Quantity
+		codeChanged := reqModel.CodeChanged(&item)
+
+		reqModel.MergeWithDBData(&item)
+
+		if err := imp.tx.Omit("quantity").Save(&item).Error; err != nil {
+			return internalError(row, err)
+		}
+
+		if err := price_history.Record(imp.tx, *item.ID, &previousPrice, item.Price, models.PriceChangeSourceImport, nil); err != nil {
+			return internalError(row, err)
+		}
+
+		if codeChanged {
+			if err := barcode.RefreshItemImage(imp.tx, imp.tx, &item); err != nil {
+				return internalError(row, err)
+			}
+		}
+	}
+
+	if err := inventory.AdjustItemQuantity(imp.tx, *item.ID, row.Quantity-previousQuantity,
+		models.StockMovementReasonImport, &imp.importID, nil); err != nil {
+		if err == inventory.ErrInsufficientStock {
+			return []RowError{{Row: row.Number, Column: ColumnQuantity, Message: consts.InvalidQuantity}}
+		}
+
+		if err == inventory.ErrManagedStock {
+			return []RowError{{Row: row.Number, Column: ColumnQuantity, Message: consts.QuantityManagedByWarehouses}}
+		}
+
+		return internalError(row, err)
+	}
+
+	if row.IsMainItem || product.DefaultProductItemID == nil {
+		if err := imp.tx.Model(&models.Product{}).
+			Where("id = ?", product.ID).
+			UpdateColumn("default_product_item_id", item.ID).Error; err != nil {
+			return internalError(row, err)
+		}
+
+		product.DefaultProductItemID = item.ID
+	}
+
+	return nil
+}
+
+func (imp *importer) lookup(cache map[string]*uuid.UUID, table, column, value string) *uuid.UUID {
+	if id, ok := cache[value]; ok {
+		return id
+	}
+
+	var ids []uuid.UUID
+
+	imp.tx.Table(table).
+		Where(column+" = ? AND deleted_at IS NULL", value).
+		Limit(1).
+		Pluck("id", &ids)
+
+	var id *uuid.UUID
+	if len(ids) > 0 {
+		id = &ids[0]
+	}
+
+	cache[value] = id
+
+	return id
+}
+
+func validationErrors(row Row, err error) []RowError {
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		return []RowError{{Row: row.Number, Message: err.Error()}}
+	}
+
+	rowErrors := []RowError{}
+
+	for field, fieldErr := range errs {
+		column, ok := fieldColumns[field]
+		if !ok {
+			column = field
+		}
+
+		rowErrors = append(rowErrors, RowError{Row: row.Number, Column: column, Message: fieldErr.Error()})
+	}
+
+	sort.Slice(rowErrors, func(i, j int) bool {
+		return rowErrors[i].Column < rowErrors[j].Column
+	})
+
+	return rowErrors
+}
+
+func internalError(row Row, err error) []RowError {
+	logger.Default().Errorf("product import row %d: %s", row.Number, err.Error())
+
+	return []RowError{{Row: row.Number, Message: consts.InternalServerError}}
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,200 @@
+package product_import
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/authorization"
+	"github.com/esmailemami/eshop/app/services/file"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/app/services/roles"
+	dbpkg "github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// the imports are running one by one to avoid conflicts between their upserts
+var runMu sync.Mutex
+
+// Enqueue runs the import in the background, the context should carry the user for the audit columns and the
+// permission scope of the import
+func Enqueue(ctx context.Context, importID uuid.UUID) {
+	go func() {
+		runMu.Lock()
+		defer runMu.Unlock()
+
+		if err := Run(ctx, importID); err != nil {
+			logger.Default().WithField("ProductImportID", importID.String()).Error(err.Error())
+		}
+	}()
+}
+
+// Recover enqueues the imports which are left pending by a restart and fails the running ones which are started
+// before the stale time, their job is stopped with the previous process
+func Recover(db *gorm.DB, staleBefore time.Time) error {
+	msg := "the import is interrupted by a restart of the server"
+
+	if err := db.Model(&models.ProductImport{}).
+		Where("status = ? AND started_at < ?", models.ProductImportStatusRunning, staleBefore).
+		Updates(map[string]interface{}{
+			"status":      models.ProductImportStatusFailed,
+			"message":     msg,
+			"finished_at": time.Now(),
+		}).Error; err != nil {
+		return err
+	}
+
+	var imports []models.ProductImport
+
+	if err := db.Preload("CreatedBy").
+		Where("status = ?", models.ProductImportStatusPending).
+		Order("created_at").
+		Find(&imports).Error; err != nil {
+		return err
+	}
+
+	for _, productImport := range imports {
+		ctx := context.Background()
+
+		// the job keeps the user for the audit columns and the current scope of their permission
+		if productImport.CreatedBy != nil {
+			scope, err := importScope(db, productImport.CreatedBy)
+			if err != nil {
+				if err := db.Model(&productImport).Updates(map[string]interface{}{
+					"status":      models.ProductImportStatusFailed,
+					"message":     err.Error(),
+					"finished_at": time.Now(),
+				}).Error; err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			ctx = context.WithValue(ctx, consts.UserContext, *productImport.CreatedBy)
+			if scope != nil {
+				ctx = context.WithValue(ctx, consts.PermissionScope, scope)
+			}
+		}
+
+		Enqueue(ctx, *productImport.ID)
+	}
+
+	return nil
+}
+
+// importScope resolves the scope of the import permission of the user, it fails when the user is not permitted
+// to import the products anymore
+func importScope(db *gorm.DB, user *models.User) (authorization.Scope, error) {
+	role, err := roles.Effective(db, *user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, ok := role.PermissionScope(models.ACTION_PRODUCT_ADMIN_IMPORT)
+	if !ok {
+		return nil, errors.New("the user is not permitted to import the products")
+	}
+
+	user.Role = role
+
+	if scopes == nil {
+		return nil, nil
+	}
+
+	return authorization.Scope(scopes), nil
+}
+
+// Run executes the import and stores its result and row errors
+func Run(ctx context.Context, importID uuid.UUID) (err error) {
+	db := dbpkg.MustGormDBConn(ctx)
+
+	var productImport models.ProductImport
+
+	if err := db.First(&productImport, "id = ?", importID).Error; err != nil {
+		return err
+	}
+
+	// the pending imports are enqueued again on the startup, only one instance runs them
+	now := time.Now()
+	claim := db.Model(&productImport).
+		Where("status = ?", models.ProductImportStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.ProductImportStatusRunning,
+			"started_at": now,
+		})
+	if claim.Error != nil {
+		return claim.Error
+	}
+
+	if claim.RowsAffected == 0 {
+		return nil
+	}
+
+	productImport.Status = models.ProductImportStatusRunning
+	productImport.StartedAt = &now
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("product import panic: %v", r)
+		}
+
+		finishedAt := time.Now()
+		productImport.FinishedAt = &finishedAt
+
+		if err != nil {
+			msg := err.Error()
+			productImport.Status = models.ProductImportStatusFailed
+			productImport.Message = &msg
+		} else {
+			productImport.Status = models.ProductImportStatusDone
+		}
+
+		if saveErr := db.Save(&productImport).Error; saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}()
+
+	bts, err := file.ReadFile(productImport.FilePath)
+	if err != nil {
+		return err
+	}
+
+	records, err := ReadSheet(productImport.Format, bts)
+	if err != nil {
+		return err
+	}
+
+	result, err := Import(db, importID, authorization.GetScope(ctx), records, productImport.DryRun)
+	if err != nil {
+		return err
+	}
+
+	productImport.TotalRows = result.TotalRows
+	productImport.SucceededRows = result.SucceededRows
+	productImport.FailedRows = result.FailedRows
+
+	if len(result.Errors) == 0 {
+		return nil
+	}
+
+	importErrors := make([]models.ProductImportError, len(result.Errors))
+	for i, rowError := range result.Errors {
+		importErrors[i] = models.ProductImportError{
+			BasicModel: models.BasicModel{
+				ID: models.NewID(),
+			},
+			ProductImportID: importID,
+			Row:             rowError.Row,
+			Column:          rowError.Column,
+			Message:         rowError.Message,
+		}
+	}
+
+	return db.CreateInBatches(importErrors, 500).Error
+}
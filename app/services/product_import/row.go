@@ -0,0 +1,321 @@
+package product_import
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/esmailemami/eshop/models"
+)
+
+const (
+	ColumnProductCode      = "product_code"
+	ColumnProductName      = "product_name"
+	ColumnBrandCode        = "brand_code"
+	ColumnCategoryCode     = "category_code"
+	ColumnShortDescription = "short_description"
+	ColumnDescription      = "description"
+	ColumnTopFeatures      = "top_features"
+	ColumnColorCode        = "color_code"
+	ColumnPrice            = "price"
+	ColumnQuantity         = "quantity"
+	ColumnStatus           = "status"
+	ColumnIsMainItem       = "is_main_item"
+	ColumnSKU              = "sku"
+	ColumnBarcode          = "barcode"
+	ColumnWeight           = "weight"
+	ColumnLength           = "length"
+	ColumnWidth            = "width"
+	ColumnHeight           = "height"
+	ColumnFeatures         = "features"
+)
+
+// Columns are the header of the import and export sheets
+var Columns = []string{
+	ColumnProductCode,
+	ColumnProductName,
+	ColumnBrandCode,
+	ColumnCategoryCode,
+	ColumnShortDescription,
+	ColumnDescription,
+	ColumnTopFeatures,
+	ColumnColorCode,
+	ColumnPrice,
+	ColumnQuantity,
+	ColumnStatus,
+	ColumnIsMainItem,
+	ColumnSKU,
+	ColumnBarcode,
+	ColumnWeight,
+	ColumnLength,
+	ColumnWidth,
+	ColumnHeight,
+	ColumnFeatures,
+}
+
+const (
+	// separates the top features
+	listSeparator = "|"
+	// separates the features and their key and value, e.g. "RAM=8GB;CPU=M1"
+	featureSeparator      = ";"
+	featureValueSeparator = "="
+)
+
+// Row is a parsed line of the sheet. Each row is a product item and the rows with
+// the same product code belong to the same product. A row without color code only
+// upserts the product itself.
+type Row struct {
+	Number int
+
+	ProductCode      string
+	ProductName      string
+	BrandCode        string
+	CategoryCode     string
+	ShortDescription string
+	Description      string
+	TopFeatures      []string
+	Features         []Feature
+
+	ColorCode  string
+	Price      float64
+	Quantity   int
+	Status     models.ProductStatus
+	IsMainItem bool
+	SKU        *string
+	Barcode    *string
+	Weight     *float64
+	Length     *float64
+	Width      *float64
+	Height     *float64
+}
+
+type Feature struct {
+	Key   string
+	Value string
+}
+
+func (r Row) HasItem() bool {
+	return r.ColorCode != ""
+}
+
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d, %s: %s", e.Row, e.Column, e.Message)
+}
+
+// Header maps the column names to their index
+type Header map[string]int
+
+func ParseHeader(record []string) (Header, error) {
+	header := Header{}
+
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		header[name] = i
+	}
+
+	for _, required := range []string{ColumnProductCode} {
+		if _, ok := header[required]; !ok {
+			return nil, fmt.Errorf("the %q column is required", required)
+		}
+	}
+
+	return header, nil
+}
+
+func (h Header) value(record []string, column string) string {
+	idx, ok := h[column]
+	if !ok || idx >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[idx])
+}
+
+// ParseRow converts the record to a row, number is the line number in the sheet
+func (h Header) ParseRow(number int, record []string) (Row, []RowError) {
+	var (
+		rowErrors = []RowError{}
+		row       = Row{
+			Number:           number,
+			ProductCode:      h.value(record, ColumnProductCode),
+			ProductName:      h.value(record, ColumnProductName),
+			BrandCode:        h.value(record, ColumnBrandCode),
+			CategoryCode:     h.value(record, ColumnCategoryCode),
+			ShortDescription: h.value(record, ColumnShortDescription),
+			Description:      h.value(record, ColumnDescription),
+			ColorCode:        h.value(record, ColumnColorCode),
+			SKU:              optionalString(h.value(record, ColumnSKU)),
+			Barcode:          optionalString(h.value(record, ColumnBarcode)),
+		}
+		addError = func(column string, err error) {
+			rowErrors = append(rowErrors, RowError{Row: number, Column: column, Message: err.Error()})
+		}
+	)
+
+	if v := h.value(record, ColumnTopFeatures); v != "" {
+		for _, feature := range strings.Split(v, listSeparator) {
+			if feature = strings.TrimSpace(feature); feature != "" {
+				row.TopFeatures = append(row.TopFeatures, feature)
+			}
+		}
+	}
+
+	if v := h.value(record, ColumnFeatures); v != "" {
+		for _, pair := range strings.Split(v, featureSeparator) {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+
+			key, value, ok := strings.Cut(pair, featureValueSeparator)
+			if !ok || strings.TrimSpace(key) == "" {
+				addError(ColumnFeatures, fmt.Errorf("invalid feature %q, the format is key=value", pair))
+				continue
+			}
+
+			row.Features = append(row.Features, Feature{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+		}
+	}
+
+	if v := h.value(record, ColumnPrice); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			addError(ColumnPrice, fmt.Errorf("invalid number %q", v))
+		}
+		row.Price = price
+	}
+
+	if v := h.value(record, ColumnQuantity); v != "" {
+		quantity, err := strconv.Atoi(v)
+		if err != nil {
+			addError(ColumnQuantity, fmt.Errorf("invalid integer %q", v))
+		}
+		row.Quantity = quantity
+	}
+
+	if v := h.value(record, ColumnStatus); v != "" {
+		status, err := parseStatus(v)
+		if err != nil {
+			addError(ColumnStatus, err)
+		}
+		row.Status = status
+	}
+
+	if v := h.value(record, ColumnIsMainItem); v != "" {
+		isMainItem, err := strconv.ParseBool(v)
+		if err != nil {
+			addError(ColumnIsMainItem, fmt.Errorf("invalid boolean %q", v))
+		}
+		row.IsMainItem = isMainItem
+	}
+
+	for column, target := range map[string]**float64{
+		ColumnWeight: &row.Weight,
+		ColumnLength: &row.Length,
+		ColumnWidth:  &row.Width,
+		ColumnHeight: &row.Height,
+	} {
+		v := h.value(record, column)
+		if v == "" {
+			continue
+		}
+
+		number, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			addError(column, fmt.Errorf("invalid number %q", v))
+			continue
+		}
+		*target = &number
+	}
+
+	if row.ProductCode == "" {
+		addError(ColumnProductCode, fmt.Errorf("product code is required"))
+	}
+
+	return row, rowErrors
+}
+
+// Record converts the row to a sheet record with the Columns order
+func (r Row) Record() []string {
+	var (
+		features = make([]string, len(r.Features))
+		record   = make([]string, len(Columns))
+	)
+
+	for i, f := range r.Features {
+		features[i] = f.Key + featureValueSeparator + f.Value
+	}
+
+	values := map[string]string{
+		ColumnProductCode:      r.ProductCode,
+		ColumnProductName:      r.ProductName,
+		ColumnBrandCode:        r.BrandCode,
+		ColumnCategoryCode:     r.CategoryCode,
+		ColumnShortDescription: r.ShortDescription,
+		ColumnDescription:      r.Description,
+		ColumnTopFeatures:      strings.Join(r.TopFeatures, listSeparator),
+		ColumnFeatures:         strings.Join(features, featureSeparator),
+	}
+
+	if r.HasItem() {
+		values[ColumnColorCode] = r.ColorCode
+		values[ColumnPrice] = strconv.FormatFloat(r.Price, 'f', -1, 64)
+		values[ColumnQuantity] = strconv.Itoa(r.Quantity)
+		values[ColumnStatus] = r.Status.String()
+		values[ColumnIsMainItem] = strconv.FormatBool(r.IsMainItem)
+		values[ColumnSKU] = stringValue(r.SKU)
+		values[ColumnBarcode] = stringValue(r.Barcode)
+		values[ColumnWeight] = floatValue(r.Weight)
+		values[ColumnLength] = floatValue(r.Length)
+		values[ColumnWidth] = floatValue(r.Width)
+		values[ColumnHeight] = floatValue(r.Height)
+	}
+
+	for i, column := range Columns {
+		record[i] = values[column]
+	}
+
+	return record
+}
+
+func parseStatus(v string) (models.ProductStatus, error) {
+	for _, status := range []models.ProductStatus{models.ProductStatusPublish, models.ProductStatusInActive} {
+		if strings.EqualFold(v, status.String()) || v == strconv.Itoa(int(status)) {
+			return status, nil
+		}
+	}
+
+	return models.ProductStatusPublish, fmt.Errorf("invalid status %q", v)
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func floatValue(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
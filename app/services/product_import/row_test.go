@@ -0,0 +1,73 @@
+package product_import
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/esmailemami/eshop/models"
+)
+
+func TestParseRow(t *testing.T) {
+	header, err := ParseHeader(Columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sku := "SKU-1"
+	weight := 120.5
+
+	want := Row{
+		Number:       2,
+		ProductCode:  "P-1",
+		ProductName:  "Phone",
+		BrandCode:    "apple",
+		CategoryCode: "mobile",
+		TopFeatures:  []string{"5G", "OLED"},
+		Features:     []Feature{{Key: "RAM", Value: "8GB"}, {Key: "CPU", Value: "A16"}},
+		ColorCode:    "black",
+		Price:        1000,
+		Quantity:     5,
+		Status:       models.ProductStatusInActive,
+		IsMainItem:   true,
+		SKU:          &sku,
+		Weight:       &weight,
+	}
+
+	row, rowErrors := header.ParseRow(2, want.Record())
+	if len(rowErrors) > 0 {
+		t.Fatalf("ParseRow() unexpected errors: %v", rowErrors)
+	}
+
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("ParseRow() = %+v, want %+v", row, want)
+	}
+}
+
+func TestParseRowErrors(t *testing.T) {
+	header, err := ParseHeader([]string{"Product_Code", "price", "quantity", "status", "features"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, rowErrors := header.ParseRow(3, []string{"", "abc", "1.5", "deleted", "RAM"})
+
+	got := map[string]bool{}
+	for _, e := range rowErrors {
+		if e.Row != 3 {
+			t.Errorf("ParseRow() wants row 3 got: %d", e.Row)
+		}
+		got[e.Column] = true
+	}
+
+	for _, column := range []string{ColumnProductCode, ColumnPrice, ColumnQuantity, ColumnStatus, ColumnFeatures} {
+		if !got[column] {
+			t.Errorf("ParseRow() wants error for %s column", column)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	if _, err := ParseHeader([]string{"name", "price"}); err == nil {
+		t.Error("ParseHeader() wants error for missing product_code column got: nil")
+	}
+}
@@ -0,0 +1,316 @@
+package product_import
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ReadSheet reads all the records of the csv file or the first worksheet of the xlsx file
+func ReadSheet(format string, bts []byte) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return ReadCSV(bytes.NewReader(bts))
+	case FormatXLSX:
+		return ReadXLSX(bts)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// WriteSheet writes the records as a csv file or a single worksheet xlsx file
+func WriteSheet(format string, w io.Writer, records [][]string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, records)
+	case FormatXLSX:
+		return WriteXLSX(w, records)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func ReadCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// excel adds the utf-8 BOM to the csv files
+	if len(records) > 0 && len(records[0]) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+
+	return records, nil
+}
+
+func WriteCSV(w io.Writer, records [][]string) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.Text
+	}
+
+	var sb strings.Builder
+	for _, r := range rt.Runs {
+		sb.WriteString(r.Text)
+	}
+
+	return sb.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func ReadXLSX(bts []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(bts), int64(len(bts)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodeZipXML(files[sheetPath], &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+
+	for _, row := range sheet.Rows {
+		record := []string{}
+
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				col = columnIndex(cell.Ref)
+			}
+
+			var value string
+
+			switch cell.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscan(cell.Value, &idx); err != nil || idx < 0 || idx >= len(sharedStrings.Items) {
+					return nil, errors.New("invalid shared string index")
+				}
+				value = sharedStrings.Items[idx].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			default:
+				value = cell.Value
+			}
+
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			record[col] = value
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func WriteXLSX(w io.Writer, records [][]string) error {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Products" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+		{"xl/worksheets/sheet1.xml", worksheetXML(records)},
+	}
+
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func worksheetXML(records [][]string) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	for i, record := range records {
+		fmt.Fprintf(&sb, `<row r="%d">`, i+1)
+
+		for j, value := range record {
+			fmt.Fprintf(&sb, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(j), i+1)
+			xml.EscapeText(&sb, []byte(value))
+			sb.WriteString(`</t></is></c>`)
+		}
+
+		sb.WriteString(`</row>`)
+	}
+
+	sb.WriteString(`</sheetData></worksheet>`)
+
+	return sb.String()
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const defaultPath = "xl/worksheets/sheet1.xml"
+
+	var (
+		workbook xlsxWorkbook
+		rels     xlsxRelationships
+	)
+
+	wf, wok := files["xl/workbook.xml"]
+	rf, rok := files["xl/_rels/workbook.xml.rels"]
+
+	if wok && rok {
+		if err := decodeZipXML(wf, &workbook); err != nil {
+			return "", err
+		}
+		if err := decodeZipXML(rf, &rels); err != nil {
+			return "", err
+		}
+
+		if len(workbook.Sheets) > 0 {
+			for _, rel := range rels.Relationships {
+				if rel.ID != workbook.Sheets[0].ID {
+					continue
+				}
+
+				target := strings.TrimPrefix(rel.Target, "/")
+				if !strings.HasPrefix(target, "xl/") {
+					target = path.Join("xl", target)
+				}
+
+				if _, ok := files[target]; ok {
+					return target, nil
+				}
+			}
+		}
+	}
+
+	if _, ok := files[defaultPath]; ok {
+		return defaultPath, nil
+	}
+
+	return "", errors.New("no worksheet found in the xlsx file")
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// columnIndex converts the column letters of the cell reference to a zero based index, "B3" => 1
+func columnIndex(ref string) int {
+	idx := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		idx = idx*26 + int(c-'A'+1)
+	}
+
+	return idx - 1
+}
+
+// columnName converts the zero based index to the column letters, 27 => "AB"
+func columnName(idx int) string {
+	name := ""
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		name = string(rune('A'+(idx-1)%26)) + name
+	}
+
+	return name
+}
@@ -0,0 +1,92 @@
+package product_import
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		idx  int
+		name string
+	}{
+		{idx: 0, name: "A"},
+		{idx: 25, name: "Z"},
+		{idx: 26, name: "AA"},
+		{idx: 27, name: "AB"},
+		{idx: 701, name: "ZZ"},
+		{idx: 702, name: "AAA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnName(tt.idx); got != tt.name {
+				t.Errorf("columnName() = %v, want %v", got, tt.name)
+			}
+			if got := columnIndex(tt.name + "12"); got != tt.idx {
+				t.Errorf("columnIndex() = %v, want %v", got, tt.idx)
+			}
+		})
+	}
+}
+
+func TestSheetRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"product_code", "product_name", "features"},
+		{"P-1", "گوشی <موبایل> & \"تست\"", "RAM=8GB;CPU=M1"},
+		{"P-2", "", "  spaces  "},
+	}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			if err := WriteSheet(format, &buf, records); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ReadSheet(format, buf.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, records) {
+				t.Errorf("ReadSheet() = %q, want %q", got, records)
+			}
+		})
+	}
+}
+
+func TestReadXLSXSharedStrings(t *testing.T) {
+	// the sparse cells and shared strings are written by the spreadsheet applications
+	sheet := `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2"><v>12.5</v></c><c r="B2" t="str"><v>text</v></c></row>
+</sheetData></worksheet>`
+	sharedStrings := `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>product_code</t></si><si><r><t>pri</t></r><r><t>ce</t></r></si></sst>`
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	bts := replaceZipParts(t, buf.Bytes(), map[string]string{
+		"xl/worksheets/sheet1.xml": sheet,
+		"xl/sharedStrings.xml":     sharedStrings,
+	})
+
+	got, err := ReadXLSX(bts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"product_code", "", "price"},
+		{"12.5", "text"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadXLSX() = %q, want %q", got, want)
+	}
+}
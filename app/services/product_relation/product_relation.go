@@ -0,0 +1,69 @@
+package product_relation
+
+import (
+	"github.com/esmailemami/eshop/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// the maximum number of frequently bought together products of each product
+	BoughtTogetherLimit = 10
+
+	// the minimum number of orders which contain both products to link them
+	BoughtTogetherMinOrders = 2
+)
+
+// ComputeBoughtTogether replaces the frequently bought together relations with the co-occurrence of
+// the products in the paid orders and returns the number of the created relations.
+func ComputeBoughtTogether(db *gorm.DB) (int64, error) {
+	var created int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// running the job on multiple instances at the same time must not duplicate the relations
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('product_relation.bought_together'))").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().
+			Where("type = ?", models.ProductRelationTypeBoughtTogether).
+			Delete(&models.ProductRelation{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			INSERT INTO product_relation (product_id, related_product_id, type, priority, score)
+			SELECT product_id, related_product_id, ?, rn, score
+			FROM (
+				SELECT pairs.product_id, pairs.related_product_id, pairs.score,
+					ROW_NUMBER() OVER (PARTITION BY pairs.product_id ORDER BY pairs.score DESC, pairs.related_product_id) AS rn
+				FROM (
+					SELECT pi1.product_id, pi2.product_id AS related_product_id, COUNT(DISTINCT o.id) AS score
+					FROM "order" o
+					INNER JOIN order_item oi1 ON oi1.order_id = o.id AND oi1.deleted_at IS NULL
+					INNER JOIN order_item oi2 ON oi2.order_id = o.id AND oi2.deleted_at IS NULL
+					INNER JOIN product_item pi1 ON pi1.id = oi1.product_item_id
+					INNER JOIN product_item pi2 ON pi2.id = oi2.product_item_id
+					INNER JOIN product p ON p.id = pi2.product_id AND p.deleted_at IS NULL
+					WHERE o.deleted_at IS NULL AND o.status <> ? AND pi1.product_id <> pi2.product_id
+					GROUP BY pi1.product_id, pi2.product_id
+					HAVING COUNT(DISTINCT o.id) >= ?
+				) pairs
+			) ranked
+			WHERE rn <= ?`,
+			models.ProductRelationTypeBoughtTogether,
+			models.OrderStatusOpen,
+			BoughtTogetherMinOrders,
+			BoughtTogetherLimit,
+		)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		created = result.RowsAffected
+
+		return nil
+	})
+
+	return created, err
+}
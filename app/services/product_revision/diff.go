@@ -0,0 +1,81 @@
+package product_revision
+
+import (
+	"reflect"
+)
+
+const (
+	FieldName             = "name"
+	FieldShortDescription = "shortDescription"
+	FieldDescription      = "description"
+	FieldTopFeatures      = "topFeatures"
+	FieldFeature          = "feature"
+)
+
+// Change is a difference between two contents, Old is nil for the added and New is nil for the removed values
+type Change struct {
+	Field string      `json:"field"`
+	Key   string      `json:"key,omitempty"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Diff returns the changes from old to new content. The features are matched by their key
+// and reported in the order of the new content followed by the removed ones.
+func Diff(old, new Content) []Change {
+	changes := []Change{}
+
+	for _, field := range []struct {
+		name     string
+		old, new string
+	}{
+		{FieldName, old.Name, new.Name},
+		{FieldShortDescription, old.ShortDescription, new.ShortDescription},
+		{FieldDescription, old.Description, new.Description},
+	} {
+		if field.old != field.new {
+			changes = append(changes, Change{Field: field.name, Old: field.old, New: field.new})
+		}
+	}
+
+	if !equalStrings(old.TopFeatures, new.TopFeatures) {
+		changes = append(changes, Change{Field: FieldTopFeatures, Old: old.TopFeatures, New: new.TopFeatures})
+	}
+
+	oldFeatures := map[string]string{}
+	for _, feature := range old.Features {
+		oldFeatures[feature.ProductFeatureKeyID.String()] = feature.Value
+	}
+
+	seen := map[string]bool{}
+
+	for _, feature := range new.Features {
+		id := feature.ProductFeatureKeyID.String()
+		seen[id] = true
+
+		oldValue, ok := oldFeatures[id]
+
+		switch {
+		case !ok:
+			changes = append(changes, Change{Field: FieldFeature, Key: feature.Key, Old: nil, New: feature.Value})
+		case oldValue != feature.Value:
+			changes = append(changes, Change{Field: FieldFeature, Key: feature.Key, Old: oldValue, New: feature.Value})
+		}
+	}
+
+	for _, feature := range old.Features {
+		if !seen[feature.ProductFeatureKeyID.String()] {
+			changes = append(changes, Change{Field: FieldFeature, Key: feature.Key, Old: feature.Value, New: nil})
+		}
+	}
+
+	return changes
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
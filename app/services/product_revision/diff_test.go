@@ -0,0 +1,77 @@
+package product_revision
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+func TestDiff(t *testing.T) {
+	var (
+		ram     = uuid.New()
+		cpu     = uuid.New()
+		battery = uuid.New()
+	)
+
+	old := Content{
+		Name:             "Phone",
+		ShortDescription: "short",
+		Description:      "desc",
+		TopFeatures:      []string{"5G"},
+		Features: []models.ProductRevisionFeature{
+			{ProductFeatureKeyID: ram, Key: "RAM", Value: "4GB"},
+			{ProductFeatureKeyID: cpu, Key: "CPU", Value: "A15"},
+		},
+	}
+
+	new := Content{
+		Name:             "Phone Pro",
+		ShortDescription: "short",
+		Description:      "desc",
+		TopFeatures:      []string{"5G", "OLED"},
+		Features: []models.ProductRevisionFeature{
+			{ProductFeatureKeyID: ram, Key: "RAM", Value: "8GB"},
+			{ProductFeatureKeyID: battery, Key: "Battery", Value: "5000mAh"},
+		},
+	}
+
+	want := []Change{
+		{Field: FieldName, Old: "Phone", New: "Phone Pro"},
+		{Field: FieldTopFeatures, Old: []string{"5G"}, New: []string{"5G", "OLED"}},
+		{Field: FieldFeature, Key: "RAM", Old: "4GB", New: "8GB"},
+		{Field: FieldFeature, Key: "Battery", Old: nil, New: "5000mAh"},
+		{Field: FieldFeature, Key: "CPU", Old: "A15", New: nil},
+	}
+
+	if got := Diff(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	if got := Diff(old, old); len(got) != 0 {
+		t.Errorf("Diff() wants no changes for the same content got: %+v", got)
+	}
+
+	if got := Diff(Content{TopFeatures: nil}, Content{TopFeatures: []string{}}); len(got) != 0 {
+		t.Errorf("Diff() wants no changes for the empty top features got: %+v", got)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	content := Content{
+		Name:     "Phone",
+		Features: []models.ProductRevisionFeature{{ProductFeatureKeyID: uuid.New(), Key: "RAM", Value: "8GB"}},
+	}
+
+	if content.Hash() != (Content{Name: "Phone", TopFeatures: []string{}, Features: content.Features}).Hash() {
+		t.Error("Hash() wants the same hash for nil and empty top features")
+	}
+
+	changed := content
+	changed.Features = []models.ProductRevisionFeature{{ProductFeatureKeyID: content.Features[0].ProductFeatureKeyID, Key: "RAM", Value: "12GB"}}
+
+	if content.Hash() == changed.Hash() {
+		t.Error("Hash() wants a different hash for changed features")
+	}
+}
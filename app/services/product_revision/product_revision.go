@@ -0,0 +1,279 @@
+package product_revision
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOutdated is returned when the live content is changed after the revision is drafted
+var ErrOutdated = errors.New("the product content is changed after the revision is drafted")
+
+// Content is the reviewable part of a product
+type Content struct {
+	Name             string                          `json:"name"`
+	ShortDescription string                          `json:"shortDescription"`
+	Description      string                          `json:"description"`
+	TopFeatures      []string                        `json:"topFeatures"`
+	Features         []models.ProductRevisionFeature `json:"features"`
+}
+
+func ContentOf(revision *models.ProductRevision) Content {
+	return Content{
+		Name:             revision.Name,
+		ShortDescription: revision.ShortDescription,
+		Description:      revision.Description,
+		TopFeatures:      revision.TopFeatures,
+		Features:         revision.Features,
+	}
+}
+
+// Hash identifies the content, the drafts keep the hash of the live content which they are based on
+func (c Content) Hash() string {
+	if c.TopFeatures == nil {
+		c.TopFeatures = []string{}
+	}
+
+	if c.Features == nil {
+		c.Features = []models.ProductRevisionFeature{}
+	}
+
+	bts, _ := json.Marshal(c)
+	sum := sha256.Sum256(bts)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// LiveContent loads the current published content of the product
+func LiveContent(db *gorm.DB, productID uuid.UUID) (*Content, error) {
+	var product models.Product
+
+	if err := db.First(&product, "id = ?", productID).Error; err != nil {
+		return nil, err
+	}
+
+	features := []models.ProductRevisionFeature{}
+
+	if err := db.Table("product_feature_value pfv").
+		Joins("INNER JOIN product_feature_key pfk ON pfk.id = pfv.product_feature_key_id").
+		Where("pfv.product_id = ? AND pfv.deleted_at IS NULL", productID).
+		Order("pfk.name").
+		Select("pfv.product_feature_key_id, pfk.name AS key, pfv.value").
+		Scan(&features).Error; err != nil {
+		return nil, err
+	}
+
+	return &Content{
+		Name:             product.Name,
+		ShortDescription: product.ShortDescription,
+		Description:      product.Description,
+		TopFeatures:      product.TopFeatures,
+		Features:         features,
+	}, nil
+}
+
+// LockProduct locks the product row until the end of the transaction, so the revision numbers
+// and the publishes of a product are serialized
+func LockProduct(tx *gorm.DB, productID uuid.UUID) error {
+	var product models.Product
+
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&product, "id = ?", productID).Error
+}
+
+// NewDraft creates a draft revision of the product. The live content is kept as the first approved
+// revision when the product has no revision yet, so it can be restored later.
+func NewDraft(tx *gorm.DB, productID uuid.UUID, content Content, note *string, restoredFromID *uuid.UUID) (*models.ProductRevision, error) {
+	if err := LockProduct(tx, productID); err != nil {
+		return nil, err
+	}
+
+	var lastNumber int
+
+	if err := tx.Model(&models.ProductRevision{}).Unscoped().
+		Where("product_id = ?", productID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&lastNumber).Error; err != nil {
+		return nil, err
+	}
+
+	live, err := LiveContent(tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastNumber == 0 {
+		now := time.Now()
+		lastNumber++
+
+		initial := newRevision(productID, lastNumber, *live)
+		initial.Status = models.ProductRevisionStatusApproved
+		initial.ReviewedAt = &now
+
+		if err := tx.Create(initial).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	baseHash := live.Hash()
+	draft := newRevision(productID, lastNumber+1, content)
+	draft.Note = note
+	draft.RestoredFromID = restoredFromID
+	draft.BaseHash = &baseHash
+
+	if err := tx.Create(draft).Error; err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// NewProductDraft creates the first revision of a new product as a draft, the product is not visible in the store
+// until the draft is approved. It should be called in a transaction after the content of the product is created.
+func NewProductDraft(tx *gorm.DB, productID uuid.UUID) (*models.ProductRevision, error) {
+	if err := LockProduct(tx, productID); err != nil {
+		return nil, err
+	}
+
+	content, err := LiveContent(tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseHash := content.Hash()
+	draft := newRevision(productID, 1, *content)
+	draft.BaseHash = &baseHash
+
+	if err := tx.Create(draft).Error; err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// DraftChanges creates a draft revision of the live content which is changed by the edit, nil is returned when the
+// edit does not change the content. The content of the product is only changed by publishing the draft.
+func DraftChanges(tx *gorm.DB, productID uuid.UUID, edit func(content *Content)) (*models.ProductRevision, error) {
+	if err := LockProduct(tx, productID); err != nil {
+		return nil, err
+	}
+
+	live, err := LiveContent(tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	content := *live
+	content.Features = append([]models.ProductRevisionFeature(nil), live.Features...)
+	edit(&content)
+
+	if len(Diff(*live, content)) == 0 {
+		return nil, nil
+	}
+
+	return NewDraft(tx, productID, content, nil, nil)
+}
+
+// Publish applies the revision content to the product and its feature values and approves the revision, a new
+// product becomes visible by its status after its first revision is approved. It should be called in a transaction.
+// ErrOutdated is returned when the live content is not the content which the revision is drafted on, the changes
+// should be drafted again so they do not overwrite the published ones.
+func Publish(tx *gorm.DB, revision *models.ProductRevision, reviewerID uuid.UUID, reviewNote *string) error {
+	if err := LockProduct(tx, revision.ProductID); err != nil {
+		return err
+	}
+
+	live, err := LiveContent(tx, revision.ProductID)
+	if err != nil {
+		return err
+	}
+
+	if revision.BaseHash == nil || *revision.BaseHash != live.Hash() {
+		return ErrOutdated
+	}
+
+	if err := tx.Model(&models.Product{}).
+		Where("id = ?", revision.ProductID).
+		Updates(map[string]interface{}{
+			"name":              revision.Name,
+			"short_description": revision.ShortDescription,
+			"description":       revision.Description,
+			"top_features":      revision.TopFeatures,
+			"reviewed_at":       gorm.Expr("COALESCE(reviewed_at, ?)", time.Now()),
+			"updated_at":        time.Now(),
+		}).Error; err != nil {
+		return err
+	}
+
+	// the same as the feature values endpoint, the last values are replaced
+	if err := tx.Unscoped().Where("product_id = ?", revision.ProductID).Delete(&models.ProductFeatureValue{}).Error; err != nil {
+		return err
+	}
+
+	if len(revision.Features) > 0 {
+		values := make([]models.ProductFeatureValue, len(revision.Features))
+
+		for i, feature := range revision.Features {
+			values[i] = models.ProductFeatureValue{
+				Model: models.Model{
+					ID: models.NewID(),
+				},
+				ProductFeatureKeyID: feature.ProductFeatureKeyID,
+				ProductID:           revision.ProductID,
+				Value:               feature.Value,
+			}
+		}
+
+		if err := tx.CreateInBatches(values, len(values)).Error; err != nil {
+			return err
+		}
+	}
+
+	return review(tx, revision, models.ProductRevisionStatusApproved, reviewerID, reviewNote)
+}
+
+func Reject(tx *gorm.DB, revision *models.ProductRevision, reviewerID uuid.UUID, reviewNote *string) error {
+	return review(tx, revision, models.ProductRevisionStatusRejected, reviewerID, reviewNote)
+}
+
+func review(tx *gorm.DB, revision *models.ProductRevision, status models.ProductRevisionStatus, reviewerID uuid.UUID, reviewNote *string) error {
+	now := time.Now()
+
+	revision.Status = status
+	revision.ReviewedByID = &reviewerID
+	revision.ReviewedAt = &now
+	revision.ReviewNote = reviewNote
+
+	return tx.Model(revision).
+		Where("id = ?", revision.ID).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"reviewed_by_id": reviewerID,
+			"reviewed_at":    now,
+			"review_note":    reviewNote,
+		}).Error
+}
+
+func newRevision(productID uuid.UUID, number int, content Content) *models.ProductRevision {
+	return &models.ProductRevision{
+		Model: models.Model{
+			ID: models.NewID(),
+		},
+		ProductID:        productID,
+		Number:           number,
+		Status:           models.ProductRevisionStatusDraft,
+		Name:             content.Name,
+		ShortDescription: content.ShortDescription,
+		Description:      content.Description,
+		TopFeatures:      content.TopFeatures,
+		Features:         content.Features,
+	}
+}
@@ -0,0 +1,95 @@
+package product_schedule
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/events"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	EventProductPublished       = "product.published"
+	EventProductUnpublished     = "product.unpublished"
+	EventProductItemPublished   = "product_item.published"
+	EventProductItemUnpublished = "product_item.unpublished"
+)
+
+type StatusChanged struct {
+	ProductID     uuid.UUID            `json:"productId"`
+	ProductItemID *uuid.UUID           `json:"productItemId,omitempty"`
+	Status        models.ProductStatus `json:"status"`
+}
+
+// VisibleCond is the storefront condition of the visible products and items, alias is the table alias
+func VisibleCond(alias string) string {
+	return "(" + alias + ".status = " + strconv.Itoa(int(models.ProductStatusPublish)) +
+		" AND (" + alias + ".publish_at IS NULL OR " + alias + ".publish_at <= NOW())" +
+		" AND (" + alias + ".unpublish_at IS NULL OR " + alias + ".unpublish_at > NOW()))"
+}
+
+// VisibleProductCond is the storefront condition of the visible products, the new products are not visible until
+// their first revision is approved. alias is the table alias of the product.
+func VisibleProductCond(alias string) string {
+	return "(" + VisibleCond(alias) + " AND " + alias + ".reviewed_at IS NOT NULL)"
+}
+
+// Apply changes the status of the products and items that their publish or unpublish time is reached
+// and clears the applied time. The rows are claimed by the update, so running it on multiple instances
+// does not emit duplicated events.
+func Apply(db *gorm.DB, now time.Time) ([]events.Event, error) {
+	result := []events.Event{}
+
+	steps := []struct {
+		table, column, event string
+		status               models.ProductStatus
+	}{
+		{"product", "publish_at", EventProductPublished, models.ProductStatusPublish},
+		{"product", "unpublish_at", EventProductUnpublished, models.ProductStatusInActive},
+		{"product_item", "publish_at", EventProductItemPublished, models.ProductStatusPublish},
+		{"product_item", "unpublish_at", EventProductItemUnpublished, models.ProductStatusInActive},
+	}
+
+	for _, step := range steps {
+		var changed []struct {
+			ID        uuid.UUID `gorm:"column:id"`
+			ProductID uuid.UUID `gorm:"column:product_id"`
+		}
+
+		productIDColumn := "id"
+		if step.table == "product_item" {
+			productIDColumn = "product_id"
+		}
+
+		if err := db.Raw(
+			"UPDATE "+step.table+" SET status = ?, "+step.column+" = NULL, updated_at = ? "+
+				"WHERE deleted_at IS NULL AND "+step.column+" IS NOT NULL AND "+step.column+" <= ? "+
+				"RETURNING id, "+productIDColumn+" AS product_id",
+			step.status, now, now,
+		).Scan(&changed).Error; err != nil {
+			return result, err
+		}
+
+		for _, row := range changed {
+			payload := StatusChanged{
+				ProductID: row.ProductID,
+				Status:    step.status,
+			}
+
+			if step.table == "product_item" {
+				id := row.ID
+				payload.ProductItemID = &id
+			}
+
+			result = append(result, events.Event{
+				Name:       step.event,
+				Payload:    payload,
+				OccurredAt: now,
+			})
+		}
+	}
+
+	return result, nil
+}
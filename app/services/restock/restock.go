@@ -0,0 +1,149 @@
+package restock
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultThreshold is the threshold when the reorder threshold setting is not set
+const DefaultThreshold = 5
+
+// Item is an item which its quantity has fallen below its reorder threshold
+type Item struct {
+	ProductItemID uuid.UUID `gorm:"column:product_item_id"`
+	ProductName   string    `gorm:"column:product_name"`
+	ColorName     string    `gorm:"column:color_name"`
+	SKU           *string   `gorm:"column:sku"`
+	Quantity      int       `gorm:"column:quantity"`
+	Threshold     int       `gorm:"column:threshold"`
+}
+
+// Scope joins the product, the color and the effective reorder threshold of the items to the product item table
+// which is aliased as pi2, the threshold of the item overrides the threshold of the category and the default one.
+func Scope(db *gorm.DB, defaultThreshold int) *gorm.DB {
+	return db.Table("product_item pi2").
+		Joins("INNER JOIN product p ON p.id = pi2.product_id").
+		Joins("INNER JOIN category cat ON cat.id = p.category_id").
+		Joins("INNER JOIN color c ON c.id = pi2.color_id").
+		Joins("CROSS JOIN LATERAL (SELECT COALESCE(pi2.reorder_threshold, cat.reorder_threshold, ?) AS threshold) t", defaultThreshold).
+		Where("pi2.deleted_at IS NULL AND p.deleted_at IS NULL")
+}
+
+// Alert sends the items which are fallen below their threshold and are not alerted yet, the items are marked as
+// alerted when the send succeeds, so they are alerted again only after they are restocked.
+// The items are claimed in a transaction and sent after the commit, so the lock is not held while sending, and
+// the claim is released when the send fails so the next run retries them. It returns the number of the alerted items.
+func Alert(db *gorm.DB, defaultThreshold int, send func(items []Item) error) (int, error) {
+	var items []Item
+
+	// the time is the claim of this run, it is truncated to the precision of the column to be compared
+	claimedAt := time.Now().Truncate(time.Microsecond)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// running the job on multiple instances at the same time must not send the alerts twice
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('restock.alert'))").Error; err != nil {
+			return err
+		}
+
+		// the restocked items are alerted again when they fall below the threshold
+		if err := tx.Exec(`UPDATE product_item pi2 SET low_stock_alerted_at = NULL
+			FROM product p, category cat
+			WHERE p.id = pi2.product_id AND cat.id = p.category_id AND pi2.low_stock_alerted_at IS NOT NULL
+				AND pi2.quantity >= COALESCE(pi2.reorder_threshold, cat.reorder_threshold, ?)`, defaultThreshold).Error; err != nil {
+			return err
+		}
+
+		if err := Scope(tx, defaultThreshold).
+			Where("pi2.quantity < t.threshold AND pi2.low_stock_alerted_at IS NULL").
+			Order("pi2.quantity, p.name").
+			Select(`pi2.id AS product_item_id, p."name" AS product_name, c."name" AS color_name, pi2.sku,
+				pi2.quantity, t.threshold`).
+			Scan(&items).Error; err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.ProductItem{}).
+			Where("id IN ?", itemIDs(items)).
+			UpdateColumn("low_stock_alerted_at", claimedAt).Error
+	})
+
+	if err != nil || len(items) == 0 {
+		return 0, err
+	}
+
+	if err := send(items); err != nil {
+		// the items which are restocked and claimed again meanwhile are kept
+		if releaseErr := db.Model(&models.ProductItem{}).
+			Where("id IN ? AND low_stock_alerted_at = ?", itemIDs(items), claimedAt).
+			UpdateColumn("low_stock_alerted_at", nil).Error; releaseErr != nil {
+			return 0, releaseErr
+		}
+
+		return 0, err
+	}
+
+	return len(items), nil
+}
+
+func itemIDs(items []Item) []uuid.UUID {
+	ids := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductItemID
+	}
+
+	return ids
+}
+
+// Recipients splits the list of the recipients which are separated by comma, semicolon or white space,
+// the list is nil when the setting is not set.
+func Recipients(list *string) []string {
+	recipients := []string{}
+
+	if list == nil {
+		return recipients
+	}
+
+	fields := strings.FieldsFunc(*list, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\t'
+	})
+
+	seen := map[string]bool{}
+
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		recipients = append(recipients, field)
+	}
+
+	return recipients
+}
+
+// Message is the text of the sms alert
+func Message(items []Item) string {
+	var sb strings.Builder
+
+	sb.WriteString("Low stock items:")
+
+	for _, item := range items {
+		sb.WriteString("\n")
+		sb.WriteString(item.ProductName + " " + item.ColorName)
+		if item.SKU != nil {
+			sb.WriteString(" (" + *item.SKU + ")")
+		}
+		sb.WriteString(": ")
+		sb.WriteString(strconv.Itoa(item.Quantity))
+	}
+
+	return sb.String()
+}
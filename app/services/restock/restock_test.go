@@ -0,0 +1,45 @@
+package restock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecipients(t *testing.T) {
+	tests := []struct {
+		name string
+		list *string
+		want []string
+	}{
+		{name: "not set", list: nil, want: []string{}},
+		{name: "empty", list: ptr(""), want: []string{}},
+		{name: "comma", list: ptr("a@eshop.com,b@eshop.com"), want: []string{"a@eshop.com", "b@eshop.com"}},
+		{name: "spaces and semicolon", list: ptr(" a@eshop.com ; b@eshop.com ,\n"), want: []string{"a@eshop.com", "b@eshop.com"}},
+		{name: "duplicated", list: ptr("09120000000,09120000000"), want: []string{"09120000000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Recipients(tt.list); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Recipients() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	sku := "SKU-1"
+	items := []Item{
+		{ProductName: "Phone", ColorName: "Black", SKU: &sku, Quantity: 2},
+		{ProductName: "Case", ColorName: "Red", Quantity: 0},
+	}
+
+	want := "Low stock items:\nPhone Black (SKU-1): 2\nCase Red: 0"
+	if got := Message(items); got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
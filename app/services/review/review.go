@@ -0,0 +1,119 @@
+package review
+
+import (
+	"math"
+
+	appmodels "github.com/esmailemami/eshop/app/models"
+	"github.com/esmailemami/eshop/app/services/settings"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Policy is who can review the products
+type Policy int
+
+const (
+	PolicyEveryone Policy = iota
+	PolicyBuyersOnly
+)
+
+const (
+	// MinRate and MaxRate are the bounds of the histogram buckets
+	MinRate = 1
+	MaxRate = 5
+
+	// MaxPhotos is the number of the photos which can be attached to a review
+	MaxPhotos = 5
+)
+
+// UpdateProductRate sets the rate of the product to the weighted average of its approved reviews, the verified
+// purchase reviews are counted verified weight times and the rate is zero when the product has no approved review.
+// It is called whenever a review is added, edited, deleted or its status is changed.
+func UpdateProductRate(tx *gorm.DB, productID uuid.UUID, verifiedWeight int) error {
+	if verifiedWeight < 1 {
+		verifiedWeight = 1
+	}
+
+	return tx.Model(&models.Product{}).Where("id = ?", productID).
+		UpdateColumn("rate", tx.Model(&models.Comment{}).
+			Where("product_id = ? AND status = ?", productID, models.CommntStatusAccept).
+			Select(`COALESCE(SUM(rate * CASE WHEN is_verified_purchase THEN ? ELSE 1 END)::numeric /
+				NULLIF(SUM(CASE WHEN is_verified_purchase THEN ? ELSE 1 END), 0), 0)`, verifiedWeight, verifiedWeight),
+		).Error
+}
+
+// CurrentPolicy returns the review policy of the system settings
+func CurrentPolicy() Policy {
+	if v := settings.GetSystemSettings().ReviewPolicy; v != nil {
+		return Policy(*v)
+	}
+
+	return PolicyEveryone
+}
+
+// VerifiedWeight returns the weight of the verified purchase reviews of the system settings
+func VerifiedWeight() int {
+	if v := settings.GetSystemSettings().VerifiedReviewWeight; v != nil {
+		return *v
+	}
+
+	return 1
+}
+
+// Summary returns the rate of the product and the distribution of the rates of its approved reviews
+func Summary(db *gorm.DB, productID uuid.UUID) (*appmodels.RatingSummaryOutPutModel, error) {
+	var rows []struct {
+		Rate  int `gorm:"column:rate"`
+		Count int `gorm:"column:count"`
+	}
+
+	if err := db.Model(&models.Comment{}).
+		Where("product_id = ? AND status = ?", productID, models.CommntStatusAccept).
+		Group("rate").
+		Select("rate, COUNT(*) AS count").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.Rate] = row.Count
+	}
+
+	summary := appmodels.RatingSummaryOutPutModel{}
+
+	if err := db.Model(&models.Product{}).Where("id = ?", productID).Pluck("rate", &summary.Rate).Error; err != nil {
+		return nil, err
+	}
+
+	summary.Count, summary.Histogram = Histogram(counts)
+
+	return &summary, nil
+}
+
+// Histogram returns a bucket for each rate from MinRate to MaxRate, highest rate first, with the percent of
+// the reviews in it. The rates out of the bounds are ignored.
+func Histogram(counts map[int]int) (int, []appmodels.RatingBucketOutPutModel) {
+	total := 0
+	for rate := MinRate; rate <= MaxRate; rate++ {
+		total += counts[rate]
+	}
+
+	buckets := make([]appmodels.RatingBucketOutPutModel, 0, MaxRate-MinRate+1)
+
+	for rate := MaxRate; rate >= MinRate; rate-- {
+		bucket := appmodels.RatingBucketOutPutModel{
+			Rate:  rate,
+			Count: counts[rate],
+		}
+
+		if total > 0 {
+			bucket.Percent = math.Round(float64(bucket.Count)*10000/float64(total)) / 100
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return total, buckets
+}
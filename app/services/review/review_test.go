@@ -0,0 +1,58 @@
+package review
+
+import (
+	"reflect"
+	"testing"
+
+	appmodels "github.com/esmailemami/eshop/app/models"
+)
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name      string
+		counts    map[int]int
+		wantTotal int
+		want      []appmodels.RatingBucketOutPutModel
+	}{
+		{
+			name:      "no review",
+			counts:    map[int]int{},
+			wantTotal: 0,
+			want: []appmodels.RatingBucketOutPutModel{
+				{Rate: 5}, {Rate: 4}, {Rate: 3}, {Rate: 2}, {Rate: 1},
+			},
+		},
+		{
+			name:      "distributed",
+			counts:    map[int]int{5: 2, 4: 1, 1: 3},
+			wantTotal: 6,
+			want: []appmodels.RatingBucketOutPutModel{
+				{Rate: 5, Count: 2, Percent: 33.33},
+				{Rate: 4, Count: 1, Percent: 16.67},
+				{Rate: 3},
+				{Rate: 2},
+				{Rate: 1, Count: 3, Percent: 50},
+			},
+		},
+		{
+			name:      "out of bounds rates",
+			counts:    map[int]int{0: 4, 3: 1, 6: 2},
+			wantTotal: 1,
+			want: []appmodels.RatingBucketOutPutModel{
+				{Rate: 5}, {Rate: 4}, {Rate: 3, Count: 1, Percent: 100}, {Rate: 2}, {Rate: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, got := Histogram(tt.counts)
+			if total != tt.wantTotal {
+				t.Errorf("Histogram() total = %d, want %d", total, tt.wantTotal)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Histogram() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package roles
+
+import (
+	"errors"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/cachedirver"
+	"github.com/esmailemami/eshop/app/services/logger"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultCacheTTL is the time the effective role of a user is cached for, the edits of the roles and the
+	// assignments invalidate it earlier
+	DefaultCacheTTL = time.Hour
+
+	cacheKeyPrefix = "roles:user:"
+)
+
+var ErrInheritanceCycle = errors.New(consts.RoleInheritanceCycle)
+
+// driver returns the cache which keeps the effective roles
+var driver = cachedirver.GetConnection
+
+// CacheTTL is the time the effective role of a user is cached for, it is configured by roles.cache-ttl
+func CacheTTL() time.Duration {
+	if d := viper.GetDuration("roles.cache-ttl"); d > 0 {
+		return d
+	}
+
+	return DefaultCacheTTL
+}
+
+func cacheKey(userID uuid.UUID) string {
+	return cacheKeyPrefix + userID.String()
+}
+
+// Load sets the effective role of the user
+func Load(db *gorm.DB, user *models.User) error {
+	if user.ID == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	role, err := Effective(db, *user.ID)
+	if err != nil {
+		return err
+	}
+
+	user.Role = role
+
+	return nil
+}
+
+// Effective returns the effective role of the user from the cache, it is resolved by the database when it is not
+// cached
+func Effective(db *gorm.DB, userID uuid.UUID) (*models.EffectiveRole, error) {
+	var role models.EffectiveRole
+	if err := driver().UnmarshalToObject(cacheKey(userID), &role); err == nil {
+		return &role, nil
+	}
+
+	resolved, err := Resolve(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := driver().Set(cacheKey(userID), resolved, CacheTTL()); err != nil {
+		logger.Default().WithField("userID", userID.String()).Error("roles: caching the effective role failed: " + err.Error())
+	}
+
+	return resolved, nil
+}
+
+// Resolve merges the roles of the user with the roles they inherit
+func Resolve(db *gorm.DB, userID uuid.UUID) (*models.EffectiveRole, error) {
+	var assignedIDs []uuid.UUID
+	if err := db.Model(&models.UserRole{}).Where("user_id = ?", userID).Pluck("role_id", &assignedIDs).Error; err != nil {
+		return nil, err
+	}
+
+	if len(assignedIDs) == 0 {
+		return models.NewEffectiveRole(nil, nil), nil
+	}
+
+	var edges []models.RoleInheritance
+	if err := db.Find(&edges).Error; err != nil {
+		return nil, err
+	}
+
+	inheritedIDs := ancestors(edges, assignedIDs)
+
+	var roles []models.Role
+	if err := db.Where("id IN ?", append(assignedIDs, inheritedIDs...)).Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	assignedSet := make(map[uuid.UUID]bool, len(assignedIDs))
+	for _, id := range assignedIDs {
+		assignedSet[id] = true
+	}
+
+	var assigned, inherited []models.Role
+	for _, role := range roles {
+		if assignedSet[*role.ID] {
+			assigned = append(assigned, role)
+		} else {
+			inherited = append(inherited, role)
+		}
+	}
+
+	return models.NewEffectiveRole(assigned, inherited), nil
+}
+
+// ancestors returns the roles which are inherited by the roles directly or by their parents, the roles themselves
+// are not returned even when they are inherited by each other
+func ancestors(edges []models.RoleInheritance, roleIDs []uuid.UUID) []uuid.UUID {
+	parents := make(map[uuid.UUID][]uuid.UUID)
+	for _, edge := range edges {
+		parents[edge.RoleID] = append(parents[edge.RoleID], edge.ParentID)
+	}
+
+	visited := make(map[uuid.UUID]bool, len(roleIDs))
+	for _, id := range roleIDs {
+		visited[id] = true
+	}
+
+	var result []uuid.UUID
+	queue := append([]uuid.UUID(nil), roleIDs...)
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, parent := range parents[id] {
+			if visited[parent] {
+				continue
+			}
+
+			visited[parent] = true
+			result = append(result, parent)
+			queue = append(queue, parent)
+		}
+	}
+
+	return result
+}
+
+// createsCycle reports whether the role reaches itself when it inherits the parents instead of its current parents
+func createsCycle(edges []models.RoleInheritance, roleID uuid.UUID, parentIDs []uuid.UUID) bool {
+	for _, id := range parentIDs {
+		if id == roleID {
+			return true
+		}
+	}
+
+	others := make([]models.RoleInheritance, 0, len(edges))
+	for _, edge := range edges {
+		if edge.RoleID != roleID {
+			others = append(others, edge)
+		}
+	}
+
+	for _, id := range ancestors(others, parentIDs) {
+		if id == roleID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parents returns the roles which the role inherits
+func Parents(db *gorm.DB, roleID uuid.UUID) ([]uuid.UUID, error) {
+	parentIDs := []uuid.UUID{}
+	err := db.Model(&models.RoleInheritance{}).Where("role_id = ?", roleID).Order("created_at").Pluck("parent_id", &parentIDs).Error
+
+	return parentIDs, err
+}
+
+// CheckInheritance returns ErrInheritanceCycle when the role inherits itself by inheriting the parents
+func CheckInheritance(db *gorm.DB, roleID uuid.UUID, parentIDs []uuid.UUID) error {
+	var edges []models.RoleInheritance
+	if err := db.Find(&edges).Error; err != nil {
+		return err
+	}
+
+	if createsCycle(edges, roleID, parentIDs) {
+		return ErrInheritanceCycle
+	}
+
+	return nil
+}
+
+// SetParents replaces the parents of the role, it must be called in a transaction. The inheritance is locked until
+// the transaction is finished, so two concurrent edits cannot make a cycle together.
+func SetParents(tx *gorm.DB, roleID uuid.UUID, parentIDs []uuid.UUID) error {
+	if err := tx.Exec(`LOCK TABLE public.role_inheritance IN SHARE ROW EXCLUSIVE MODE`).Error; err != nil {
+		return err
+	}
+
+	if err := CheckInheritance(tx, roleID, parentIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Where("role_id = ?", roleID).Delete(&models.RoleInheritance{}).Error; err != nil {
+		return err
+	}
+
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	edges := make([]models.RoleInheritance, 0, len(parentIDs))
+	for _, id := range unique(parentIDs) {
+		edges = append(edges, models.RoleInheritance{RoleID: roleID, ParentID: id, CreatedAt: now})
+	}
+
+	return tx.Create(&edges).Error
+}
+
+// SetUserRoles replaces the roles of the user, the cached role of the user must be invalidated after the commit
+func SetUserRoles(tx *gorm.DB, userID uuid.UUID, roleIDs []uuid.UUID) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRole{}).Error; err != nil {
+		return err
+	}
+
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	userRoles := make([]models.UserRole, 0, len(roleIDs))
+	for _, id := range unique(roleIDs) {
+		userRoles = append(userRoles, models.UserRole{UserID: userID, RoleID: id, CreatedAt: now})
+	}
+
+	return tx.Create(&userRoles).Error
+}
+
+// AssignDefault assigns the role of the customers to a registered user
+func AssignDefault(tx *gorm.DB, userID uuid.UUID) error {
+	return SetUserRoles(tx, userID, []uuid.UUID{uuid.MustParse(consts.ROLE_USER_ID)})
+}
+
+// RemoveRole removes the assignments and the inheritance of a deleted role
+func RemoveRole(tx *gorm.DB, roleID uuid.UUID) error {
+	if err := tx.Where("role_id = ?", roleID).Delete(&models.UserRole{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("role_id = ? OR parent_id = ?", roleID, roleID).Delete(&models.RoleInheritance{}).Error
+}
+
+// Invalidate removes the cached role of the user
+func Invalidate(userID uuid.UUID) {
+	// the driver reports a missing key as an error
+	if value, err := driver().Get(cacheKey(userID)); err != nil || value == "" {
+		return
+	}
+
+	if err := driver().Delete(cacheKey(userID)); err != nil {
+		logger.Default().WithField("userID", userID.String()).Error("roles: invalidating the effective role failed: " + err.Error())
+	}
+}
+
+// InvalidateAll removes the cached roles of every user, an edit of a role changes the users of the roles which
+// inherit it too
+func InvalidateAll() {
+	if _, err := driver().DeleteByPattern(cacheKeyPrefix); err != nil {
+		logger.Default().Error("roles: invalidating the effective roles failed: " + err.Error())
+	}
+}
+
+func unique(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,246 @@
+package roles
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/esmailemami/eshop/app/services/cachedirver"
+	"github.com/esmailemami/eshop/models"
+	"github.com/google/uuid"
+)
+
+// memoryDriver keeps the values in memory as json like the redis driver, the expirations are ignored
+type memoryDriver struct {
+	values map[string]string
+}
+
+func (m *memoryDriver) SetConnection(conn interface{}) {}
+
+func (m *memoryDriver) Set(key string, value interface{}, expiration time.Duration) error {
+	bts, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.values[key] = string(bts)
+	return nil
+}
+
+func (m *memoryDriver) Get(key string) (string, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return "", errors.New("ErrCacheRecordNotFound")
+	}
+	return value, nil
+}
+
+func (m *memoryDriver) Incr(key string, expiration time.Duration) (int64, error) {
+	return 0, errors.New("not supported")
+}
+
+func (m *memoryDriver) UnmarshalToObject(key string, object interface{}) error {
+	value, err := m.Get(key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(value), object)
+}
+
+func (m *memoryDriver) Delete(key string) error {
+	if _, ok := m.values[key]; !ok {
+		return errors.New("ErrCacheRecordNotFound")
+	}
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memoryDriver) DeleteByPattern(key string) (int64, error) {
+	var count int64
+	for k := range m.values {
+		if strings.HasPrefix(k, key) {
+			delete(m.values, k)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryDriver) Lock() error    { return nil }
+func (m *memoryDriver) Unlock() error  { return nil }
+func (m *memoryDriver) ResetDB() error { m.values = map[string]string{}; return nil }
+
+func useMemoryDriver(t *testing.T) *memoryDriver {
+	m := &memoryDriver{values: map[string]string{}}
+
+	previous := driver
+	driver = func() cachedirver.CacheDriver { return m }
+	t.Cleanup(func() { driver = previous })
+
+	return m
+}
+
+func edge(role, parent uuid.UUID) models.RoleInheritance {
+	return models.RoleInheritance{RoleID: role, ParentID: parent}
+}
+
+func TestAncestors(t *testing.T) {
+	admin, editor, viewer, support := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	edges := []models.RoleInheritance{
+		edge(admin, editor),
+		edge(editor, viewer),
+		edge(support, viewer),
+		// a cycle of the legacy data does not loop
+		edge(viewer, admin),
+	}
+
+	got := ancestors(edges, []uuid.UUID{editor})
+	if len(got) != 2 || got[0] != viewer || got[1] != admin {
+		t.Errorf("ancestors(editor) = %v, want [viewer admin]", got)
+	}
+
+	// the assigned roles are not returned as the inherited ones
+	got = ancestors(edges, []uuid.UUID{admin, viewer})
+	if len(got) != 1 || got[0] != editor {
+		t.Errorf("ancestors(admin, viewer) = %v, want [editor]", got)
+	}
+
+	if got := ancestors(edges, []uuid.UUID{uuid.New()}); len(got) != 0 {
+		t.Errorf("ancestors() of a role without parents = %v, want empty", got)
+	}
+}
+
+func TestCreatesCycle(t *testing.T) {
+	admin, editor, viewer, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	edges := []models.RoleInheritance{
+		edge(admin, editor),
+		edge(editor, viewer),
+	}
+
+	tests := []struct {
+		name    string
+		role    uuid.UUID
+		parents []uuid.UUID
+		want    bool
+	}{
+		{name: "itself", role: other, parents: []uuid.UUID{other}, want: true},
+		{name: "direct", role: editor, parents: []uuid.UUID{admin}, want: true},
+		{name: "indirect", role: viewer, parents: []uuid.UUID{admin}, want: true},
+		{name: "another role", role: viewer, parents: []uuid.UUID{other}, want: false},
+		{name: "shared parent", role: other, parents: []uuid.UUID{editor, viewer}, want: false},
+		// the current parents of the role are replaced
+		{name: "replaced parents", role: admin, parents: []uuid.UUID{viewer}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := createsCycle(edges, tt.role, tt.parents); got != tt.want {
+				t.Errorf("createsCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEffectiveRole(t *testing.T) {
+	brand, category := uuid.New(), uuid.New()
+
+	editor := models.Role{
+		Name:        "editor",
+		Permissions: models.RolePermissions{models.ACTION_PRODUCT_ADMIN_UPDATE, models.ACTION_PRODUCT_ADMIN_LIST},
+		PermissionScopes: models.RolePermissionScopes{
+			models.ACTION_PRODUCT_ADMIN_UPDATE: {BrandIDs: []uuid.UUID{brand}},
+			models.ACTION_PRODUCT_ADMIN_LIST:   {BrandIDs: []uuid.UUID{brand}},
+		},
+	}
+	categoryEditor := models.Role{
+		Name:        "category editor",
+		Permissions: models.RolePermissions{models.ACTION_PRODUCT_ADMIN_UPDATE},
+		PermissionScopes: models.RolePermissionScopes{
+			models.ACTION_PRODUCT_ADMIN_UPDATE: {CategoryIDs: []uuid.UUID{category}},
+		},
+	}
+	viewer := models.Role{
+		Name:        "viewer",
+		Permissions: models.RolePermissions{models.ACTION_PRODUCT_ADMIN_LIST, models.ACTION_CAN_LOGIN_ADMIN},
+		MfaRequired: true,
+	}
+
+	role := models.NewEffectiveRole([]models.Role{editor, categoryEditor}, []models.Role{viewer})
+
+	if len(role.Permissions) != 3 {
+		t.Errorf("Permissions = %v, want 3", role.Permissions)
+	}
+
+	// every role limits the update, so the scopes are merged
+	if scopes, ok := role.PermissionScope(models.ACTION_PRODUCT_ADMIN_UPDATE); !ok || len(scopes) != 2 {
+		t.Errorf("PermissionScope(update) = %v, %v, want 2 scopes", scopes, ok)
+	}
+
+	// the inherited role does not limit the list
+	if scopes, ok := role.PermissionScope(models.ACTION_PRODUCT_ADMIN_LIST); !ok || scopes != nil {
+		t.Errorf("PermissionScope(list) = %v, %v, want unlimited", scopes, ok)
+	}
+
+	if _, ok := role.PermissionScope(models.ACTION_PRODUCT_ADMIN_DELETE); ok {
+		t.Errorf("PermissionScope(delete) is permitted")
+	}
+
+	if !role.RequiresMfa() {
+		t.Errorf("RequiresMfa() = false, want the inherited requirement")
+	}
+
+	if names := role.Names(); len(names) != 2 {
+		t.Errorf("Names() = %v, want the assigned roles", names)
+	}
+
+	var empty *models.EffectiveRole
+	if empty.Permitted(models.ACTION_CAN_LOGIN_ADMIN) || empty.RequiresMfa() || len(empty.Names()) != 0 {
+		t.Errorf("the nil role grants something")
+	}
+}
+
+func TestEffectiveFromCache(t *testing.T) {
+	m := useMemoryDriver(t)
+
+	userID, otherID := uuid.New(), uuid.New()
+	viewer := models.Role{
+		Model:       models.Model{ID: models.NewID()},
+		Name:        "viewer",
+		Permissions: models.RolePermissions{models.ACTION_CAN_LOGIN_ADMIN},
+	}
+
+	for _, id := range []uuid.UUID{userID, otherID} {
+		if err := m.Set(cacheKey(id), models.NewEffectiveRole([]models.Role{viewer}, nil), CacheTTL()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the cached role is used without the database
+	user := models.User{Model: models.Model{ID: &userID}}
+	if err := Load(nil, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	if !user.Can(models.ACTION_CAN_LOGIN_ADMIN) || !user.Role.HasRole(*viewer.ID) {
+		t.Errorf("Load() = %+v, want the cached role", user.Role)
+	}
+
+	Invalidate(userID)
+	if _, err := m.Get(cacheKey(userID)); err == nil {
+		t.Errorf("the role is cached after Invalidate()")
+	}
+	if _, err := m.Get(cacheKey(otherID)); err != nil {
+		t.Errorf("Invalidate() removed the role of another user")
+	}
+
+	// a missing key is not an error
+	Invalidate(userID)
+
+	m.values["other"] = "value"
+	InvalidateAll()
+	if len(m.values) != 1 {
+		t.Errorf("values after InvalidateAll() = %v, want the other keys", m.values)
+	}
+}
@@ -10,7 +10,16 @@ import (
 var systemSetting = new(SystemSetting)
 
 type SystemSetting struct {
-	FileExpireTimeStampts *int `column:"file_expire_time_stampts" title:"File Expire TimeStampts" description:"The value is calculating by month"`
+	FileExpireTimeStampts   *int    `column:"file_expire_time_stampts" title:"File Expire TimeStampts" description:"The value is calculating by month"`
+	StockAllocationStrategy *int    `column:"stock_allocation_strategy" title:"Stock Allocation Strategy" description:"The locations which the orders are shipped from, 0: nearest to the postal code, 1: highest stock"`
+	ReorderThreshold        *int    `column:"reorder_threshold" title:"Reorder Threshold" description:"The default threshold of the items which neither they nor their category have a threshold"`
+	RestockAlertEmails      *string `column:"restock_alert_emails" title:"Restock Alert Emails" description:"The emails which the low stock alerts are sent to, separated by comma"`
+	RestockAlertMobiles     *string `column:"restock_alert_mobiles" title:"Restock Alert Mobiles" description:"The mobile numbers which the low stock alerts are sent to, separated by comma"`
+	ReviewPolicy            *int    `column:"review_policy" title:"Review Policy" description:"Who can review the products, 0: everyone, 1: only the buyers who received the product"`
+	VerifiedReviewWeight    *int    `column:"verified_review_weight" title:"Verified Review Weight" description:"The weight of the verified purchase reviews in the rate of the products, 1 means the same as the other reviews"`
+	ReviewModeration        *int    `column:"review_moderation" title:"Review Moderation" description:"How the reviews are moderated, 0: all the reviews wait for the admins, 1: automatic, only the borderline reviews wait for the admins"`
+	ReviewRateLimit         *int    `column:"review_rate_limit" title:"Review Rate Limit" description:"The number of the reviews a user can post in an hour before the next ones wait for the admins, 0 disables the limit"`
+	ProfanityWords          *string `column:"profanity_words" title:"Profanity Words" description:"The words which are added to the built-in profanity list of the moderation, separated by comma"`
 }
 
 func (SystemSetting) TableName() string {
@@ -26,6 +35,42 @@ func (s *SystemSetting) LoadDefaultValues() {
 		value := 4
 		return &value
 	}()
+	s.StockAllocationStrategy = func() *int {
+		value := 0
+		return &value
+	}()
+	s.ReorderThreshold = func() *int {
+		value := 5
+		return &value
+	}()
+	s.RestockAlertEmails = func() *string {
+		value := ""
+		return &value
+	}()
+	s.RestockAlertMobiles = func() *string {
+		value := ""
+		return &value
+	}()
+	s.ReviewPolicy = func() *int {
+		value := 0
+		return &value
+	}()
+	s.VerifiedReviewWeight = func() *int {
+		value := 1
+		return &value
+	}()
+	s.ReviewModeration = func() *int {
+		value := 1
+		return &value
+	}()
+	s.ReviewRateLimit = func() *int {
+		value := 5
+		return &value
+	}()
+	s.ProfanityWords = func() *string {
+		value := ""
+		return &value
+	}()
 }
 
 func GetSystemSettings() *SystemSetting {
@@ -0,0 +1,425 @@
+package token
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultKeyRotation is the age of the signing key which a new key is created after
+	DefaultKeyRotation = 30 * 24 * time.Hour
+	// DefaultKeyPublishDelay is the time a new key is only published by the jwks, so the other instances and
+	// services load it before they receive its tokens
+	DefaultKeyPublishDelay = 10 * time.Minute
+	// DefaultKeysPath is the directory of the signing keys
+	DefaultKeysPath = "./oauth-keys"
+
+	keyBits         = 2048
+	keyFileExt      = ".pem"
+	createdAtHeader = "Created-At"
+	// retireLeeway covers the clock drift of the verifiers
+	retireLeeway = time.Minute
+)
+
+var ErrNoSigningKey = errors.New("the signing keys are not loaded")
+
+// KeyRotation is the age of the signing key which a new key is created after, it is configured by jwt.key-rotation
+func KeyRotation() time.Duration {
+	if d := viper.GetDuration("jwt.key-rotation"); d > 0 {
+		return d
+	}
+
+	return DefaultKeyRotation
+}
+
+// KeyPublishDelay is the time a new key is published before it signs, it is configured by jwt.key-publish-delay
+func KeyPublishDelay() time.Duration {
+	if d := viper.GetDuration("jwt.key-publish-delay"); d > 0 {
+		return d
+	}
+
+	return DefaultKeyPublishDelay
+}
+
+// KeysPath is the directory of the signing keys, it is configured by the KEYS_PATH env or keys.path
+func KeysPath() string {
+	if path := os.Getenv("KEYS_PATH"); path != "" {
+		return path
+	}
+
+	if path := viper.GetString("keys.path"); path != "" {
+		return path
+	}
+
+	return DefaultKeysPath
+}
+
+// signingKey is a RSA key of the tokens, the kid is the thumbprint of the public key
+type signingKey struct {
+	ID        string
+	Private   *rsa.PrivateKey
+	CreatedAt time.Time
+
+	file string
+}
+
+// keyring is the signing keys sorted by their creation, the newest active key signs and every key which is not
+// retired verifies. The key of keys.private only verifies the tokens which are signed before the rotation.
+type keyring struct {
+	mu     sync.RWMutex
+	keys   []*signingKey
+	legacy *signingKey
+}
+
+var ring keyring
+
+func (r *keyring) set(keys []*signingKey, legacy *signingKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keys = keys
+	r.legacy = legacy
+}
+
+func (r *keyring) signing(now time.Time) (*signingKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	return signingOf(r.keys, now), nil
+}
+
+func (r *keyring) verifying(now time.Time) []*signingKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := verifyingOf(r.keys, now)
+
+	if r.legacy != nil && len(r.keys) > 0 && now.Before(legacyRetireAt(r.keys)) {
+		keys = append([]*signingKey{r.legacy}, keys...)
+	}
+
+	return keys
+}
+
+// lookup returns the key of the kid which is not retired, the tokens without any kid are signed before the rotation
+// by the key of keys.private
+func (r *keyring) lookup(kid string, now time.Time) (*signingKey, bool) {
+	r.mu.RLock()
+	legacy := r.legacy
+	r.mu.RUnlock()
+
+	for _, key := range r.verifying(now) {
+		if (kid != "" && kid == key.ID) || (kid == "" && key == legacy) {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+// activeAt is the time the key starts to sign, the first key signs from its creation because there is not any other
+// key to sign by
+func activeAt(keys []*signingKey, i int) time.Time {
+	if i == 0 {
+		return keys[i].CreatedAt
+	}
+
+	return keys[i].CreatedAt.Add(KeyPublishDelay())
+}
+
+func signingOf(keys []*signingKey, now time.Time) *signingKey {
+	for i := len(keys) - 1; i > 0; i-- {
+		if !activeAt(keys, i).After(now) {
+			return keys[i]
+		}
+	}
+
+	return keys[0]
+}
+
+// verifyingOf returns the keys which are not retired, a key is retired when a newer key signs for longer than the
+// lifetime of the access tokens
+func verifyingOf(keys []*signingKey, now time.Time) []*signingKey {
+	retireAfter := AccessTokenTTL() + retireLeeway
+
+	for i := len(keys) - 1; i > 0; i-- {
+		if !activeAt(keys, i).Add(retireAfter).After(now) {
+			return keys[i:]
+		}
+	}
+
+	return keys
+}
+
+// legacyRetireAt is the time the key of keys.private is retired, its tokens expire after the first rotated key signs
+// for the lifetime of the access tokens. The time does not depend on the key file, so a redeploy does not extend it.
+func legacyRetireAt(keys []*signingKey) time.Time {
+	return activeAt(keys, 0).Add(AccessTokenTTL() + retireLeeway)
+}
+
+// InitJWT loads the signing keys of the keys path, the first key is created when there is not any
+func InitJWT() error {
+	dir := KeysPath()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("keys path %s is not a directory", dir)
+	}
+
+	keys, err := loadKeys(dir)
+	if err != nil {
+		return err
+	}
+
+	legacy, err := loadLegacyKey()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		key, err := generateKey(dir, time.Now())
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	// the retired keys are removed by the next rotation, they do not verify until then
+	ring.set(verifyingOf(keys, time.Now()), legacy)
+
+	return nil
+}
+
+// RotateKeys reloads the keys of the other instances, creates a new key when the newest one is older than the
+// rotation and removes the retired keys
+func RotateKeys(now time.Time) error {
+	dir := KeysPath()
+
+	keys, err := loadKeys(dir)
+	if err != nil {
+		return err
+	}
+
+	legacy, err := loadLegacyKey()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 || now.Sub(keys[len(keys)-1].CreatedAt) >= KeyRotation() {
+		key, err := generateKey(dir, now)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	verifying := verifyingOf(keys, now)
+
+	for _, key := range keys[:len(keys)-len(verifying)] {
+		if err := os.Remove(key.file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	ring.set(verifying, legacy)
+
+	return nil
+}
+
+// PublicKeys returns the jwks of the keys which are not retired
+func PublicKeys() (jwk.Set, error) {
+	set := jwk.NewSet()
+
+	for _, key := range ring.verifying(time.Now()) {
+		public, err := jwk.New(&key.Private.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		_ = public.Set(jwk.KeyIDKey, key.ID)
+		_ = public.Set(jwk.AlgorithmKey, jwa.RS256)
+		_ = public.Set(jwk.KeyUsageKey, jwk.ForSignature)
+
+		set.Add(public)
+	}
+
+	return set, nil
+}
+
+// loadKeys reads the keys of the directory, the keys are sorted by their creation
+func loadKeys(dir string) ([]*signingKey, error) {
+	var keys []*signingKey
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExt))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		key, err := readKey(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		key.file = file
+		keys = append(keys, key)
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// loadLegacyKey reads the key of keys.private which signed the tokens before the rotation, nil is returned when it
+// is not configured. The key never signs and it is not sorted with the rotated keys.
+func loadLegacyKey() (*signingKey, error) {
+	file := viper.GetString("keys.private")
+	if file == "" {
+		return nil, nil
+	}
+
+	key, err := readKey(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return key, nil
+}
+
+// readKey reads the PEM of the key, the creation time is kept in the Created-At header of the PEM and the
+// modification time of the file is used for the keys which are not created by the rotation
+func readKey(file string) (*signingKey, error) {
+	bts, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(bts)
+	if block == nil {
+		return nil, errors.New("invalid private key")
+	}
+
+	var private *rsa.PrivateKey
+
+	if pk, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := pk.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("invalid private key")
+		}
+		private = rsaKey
+	} else if private, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return nil, errors.New("invalid private key")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, block.Headers[createdAtHeader])
+	if err != nil {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		createdAt = info.ModTime()
+	}
+
+	kid, err := keyID(&private.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{
+		ID:        kid,
+		Private:   private,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// generateKey creates a new key in the directory, the file is renamed after it is written so the other instances
+// do not read a partial key
+func generateKey(dir string, now time.Time) (*signingKey, error) {
+	private, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := keyID(&private.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := now.UTC().Truncate(time.Second)
+
+	bts := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{createdAtHeader: createdAt.Format(time.RFC3339)},
+		Bytes:   der,
+	})
+
+	tmp, err := os.CreateTemp(dir, ".key-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(bts); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	file := filepath.Join(dir, kid+keyFileExt)
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return nil, err
+	}
+
+	return &signingKey{
+		ID:        kid,
+		Private:   private,
+		CreatedAt: createdAt,
+		file:      file,
+	}, nil
+}
+
+// keyID returns the RFC 7638 thumbprint of the public key
+func keyID(public *rsa.PublicKey) (string, error) {
+	key, err := jwk.New(public)
+	if err != nil {
+		return "", err
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
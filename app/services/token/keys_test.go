@@ -0,0 +1,256 @@
+package token
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/spf13/viper"
+)
+
+// useKeysPath loads the keys of a temporary directory, the keys of the previous tests are restored after the test
+func useKeysPath(t testing.TB) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	previous := os.Getenv("KEYS_PATH")
+	os.Setenv("KEYS_PATH", dir)
+
+	t.Cleanup(func() {
+		os.Setenv("KEYS_PATH", previous)
+		ring.set(nil, nil)
+	})
+
+	if err := InitJWT(); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func signedToken(t *testing.T) string {
+	t.Helper()
+
+	tokenString, err := NewTokenString(map[string]interface{}{jwt.SubjectKey: "mgh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tokenString
+}
+
+func keyFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return files
+}
+
+func TestInitJWTCreatesKey(t *testing.T) {
+	dir := useKeysPath(t)
+
+	if files := keyFiles(t, dir); len(files) != 1 {
+		t.Fatalf("key files = %v, want 1", files)
+	}
+
+	// the key is loaded again by the next start
+	first, _ := ring.signing(time.Now())
+	if err := InitJWT(); err != nil {
+		t.Fatal(err)
+	}
+
+	second, _ := ring.signing(time.Now())
+	if first.ID != second.ID || !first.CreatedAt.Equal(second.CreatedAt) {
+		t.Errorf("signing key after InitJWT() = %v, want %v", second.ID, first.ID)
+	}
+}
+
+func TestRotateKeys(t *testing.T) {
+	dir := useKeysPath(t)
+
+	old, _ := ring.signing(time.Now())
+	oldToken := signedToken(t)
+
+	// the key is not rotated before its rotation
+	if err := RotateKeys(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if files := keyFiles(t, dir); len(files) != 1 {
+		t.Fatalf("key files = %v, want 1", files)
+	}
+
+	rotatedAt := time.Now().Add(KeyRotation())
+	if err := RotateKeys(rotatedAt); err != nil {
+		t.Fatal(err)
+	}
+	if files := keyFiles(t, dir); len(files) != 2 {
+		t.Fatalf("key files = %v, want 2", files)
+	}
+
+	// the new key is published but the old key signs until the publish delay is passed
+	if key, _ := ring.signing(rotatedAt); key.ID != old.ID {
+		t.Errorf("signing key = %v, want the old key %v", key.ID, old.ID)
+	}
+	if keys := verifyingOf(ring.keys, rotatedAt); len(keys) != 2 {
+		t.Errorf("verifying keys = %v, want 2", len(keys))
+	}
+
+	activeAt := rotatedAt.Add(KeyPublishDelay())
+	key, _ := ring.signing(activeAt)
+	if key.ID == old.ID {
+		t.Errorf("signing key after the publish delay = the old key, want the new key")
+	}
+
+	// the tokens of the old key are valid until the old key is retired
+	if _, ok := ring.lookup(old.ID, activeAt); !ok {
+		t.Errorf("lookup() of the old key = false, want true")
+	}
+
+	retiredAt := activeAt.Add(AccessTokenTTL() + retireLeeway)
+	if err := RotateKeys(retiredAt); err != nil {
+		t.Fatal(err)
+	}
+
+	files := keyFiles(t, dir)
+	if len(files) != 1 || filepath.Base(files[0]) != key.ID+keyFileExt {
+		t.Fatalf("key files = %v, want the new key", files)
+	}
+
+	if _, ok := ring.lookup(old.ID, retiredAt); ok {
+		t.Errorf("lookup() of the retired key = true, want false")
+	}
+
+	if _, err := ParseToken(oldToken, false); err == nil {
+		t.Errorf("ParseToken() of the retired key = nil, want error")
+	}
+}
+
+func TestInitJWTSkipsRetiredKeys(t *testing.T) {
+	dir := useKeysPath(t)
+
+	retired, err := generateKey(dir, time.Now().Add(-2*KeyRotation()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := generateKey(dir, time.Now().Add(-KeyRotation())); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InitJWT(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range ring.keys {
+		if key.ID == retired.ID {
+			t.Errorf("InitJWT() loaded the retired key %v", retired.ID)
+		}
+	}
+}
+
+func TestParseTokenByKeyID(t *testing.T) {
+	useKeysPath(t)
+
+	oldToken := signedToken(t)
+
+	if err := RotateKeys(time.Now().Add(KeyRotation())); err != nil {
+		t.Fatal(err)
+	}
+
+	// the old key still signs and both keys verify
+	if _, err := ParseToken(oldToken, true); err != nil {
+		t.Errorf("ParseToken() of the old key = %v, want nil", err)
+	}
+
+	other := t.TempDir()
+	key, err := generateKey(other, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a token of a foreign key is rejected even when it claims a known kid
+	keys := ring.keys
+	ring.set([]*signingKey{{ID: keys[0].ID, Private: key.Private, CreatedAt: keys[0].CreatedAt, file: keys[0].file}}, nil)
+	forged := signedToken(t)
+	ring.set(keys, nil)
+
+	if _, err := ParseToken(forged, true); err == nil {
+		t.Errorf("ParseToken() of the forged token = nil, want error")
+	}
+}
+
+func TestLegacyPrivateKey(t *testing.T) {
+	dir := t.TempDir()
+	legacy, err := generateKey(dir, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("keys.private", legacy.file)
+	t.Cleanup(func() { viper.Set("keys.private", "") })
+
+	useKeysPath(t)
+
+	// the key of keys.private only verifies, even when its file is newer than the rotated keys
+	if key, _ := ring.signing(time.Now()); key.ID == legacy.ID {
+		t.Fatalf("signing key = %v, want a rotated key", key.ID)
+	}
+
+	// the tokens which are signed before the rotation have not any kid
+	token := NewToken(map[string]interface{}{jwt.SubjectKey: "mgh"})
+	bts, err := jwt.Sign(token, "RS256", legacy.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ParseToken(string(bts), true); err != nil {
+		t.Errorf("ParseToken() of the token without kid = %v, want nil", err)
+	}
+
+	retiredAt := legacyRetireAt(ring.keys)
+
+	if _, ok := ring.lookup("", retiredAt.Add(-time.Second)); !ok {
+		t.Errorf("lookup() of the legacy key before its retirement = false, want true")
+	}
+
+	if _, ok := ring.lookup("", retiredAt); ok {
+		t.Errorf("lookup() of the retired legacy key = true, want false")
+	}
+}
+
+func TestPublicKeys(t *testing.T) {
+	useKeysPath(t)
+
+	if err := RotateKeys(time.Now().Add(KeyRotation())); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := PublicKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if set.Len() != 2 {
+		t.Fatalf("PublicKeys() = %v keys, want 2", set.Len())
+	}
+
+	for i := 0; i < set.Len(); i++ {
+		key, _ := set.Get(i)
+
+		if _, ok := ring.lookup(key.KeyID(), time.Now()); !ok {
+			t.Errorf("PublicKeys() has the unknown kid %v", key.KeyID())
+		}
+		if key.Algorithm() != "RS256" || key.KeyUsage() != "sig" {
+			t.Errorf("PublicKeys() alg = %v, use = %v", key.Algorithm(), key.KeyUsage())
+		}
+		if _, ok := key.Get("d"); ok {
+			t.Errorf("PublicKeys() exposes the private key")
+		}
+	}
+}
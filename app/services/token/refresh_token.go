@@ -0,0 +1,63 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 29 * 24 * time.Hour
+)
+
+// AccessTokenTTL is the lifetime of the access tokens, it is configured by jwt.access-token-ttl
+func AccessTokenTTL() time.Duration {
+	if ttl := viper.GetDuration("jwt.access-token-ttl"); ttl > 0 {
+		return ttl
+	}
+
+	return DefaultAccessTokenTTL
+}
+
+// RefreshTokenTTL is the lifetime of the refresh tokens, it is configured by jwt.refresh-token-ttl
+func RefreshTokenTTL() time.Duration {
+	if ttl := viper.GetDuration("jwt.refresh-token-ttl"); ttl > 0 {
+		return ttl
+	}
+
+	return DefaultRefreshTokenTTL
+}
+
+// NewRefreshToken returns a random opaque token and its hash which is stored instead of the token
+func NewRefreshToken() (tokenString, hash string, err error) {
+	return NewOpaqueToken()
+}
+
+// HashRefreshToken returns the hex encoded sha256 of the token, the tokens are random enough to not need a salt
+func HashRefreshToken(tokenString string) string {
+	return HashOpaqueToken(tokenString)
+}
+
+// NewOpaqueToken returns a random url safe token and its hash
+func NewOpaqueToken() (tokenString, hash string, err error) {
+	bts := make([]byte, 32)
+
+	if _, err = rand.Read(bts); err != nil {
+		return "", "", err
+	}
+
+	tokenString = base64.RawURLEncoding.EncodeToString(bts)
+
+	return tokenString, HashOpaqueToken(tokenString), nil
+}
+
+// HashOpaqueToken returns the hex encoded sha256 of the token
+func HashOpaqueToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
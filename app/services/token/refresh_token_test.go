@@ -0,0 +1,33 @@
+package token
+
+import (
+	"testing"
+)
+
+func TestNewRefreshToken(t *testing.T) {
+	first, firstHash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, secondHash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second || firstHash == secondHash {
+		t.Error("NewRefreshToken() returned the same token twice")
+	}
+
+	if len(first) != 43 {
+		t.Errorf("NewRefreshToken() token length = %d, want 43", len(first))
+	}
+
+	if HashRefreshToken(first) != firstHash {
+		t.Error("HashRefreshToken() does not match the hash of NewRefreshToken()")
+	}
+
+	if len(firstHash) != 64 {
+		t.Errorf("HashRefreshToken() length = %d, want 64", len(firstHash))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/esmailemami/eshop/app/services/roles"
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/jwt"
@@ -59,7 +60,11 @@ func GetValidAuthTokenByID(db *gorm.DB, tokenID uuid.UUID) (*models.AuthToken, *
 
 	var user models.User
 
-	if err := db.Where(`"id"=?`, authToken.UserID).Preload("Role").First(&user).Error; err != nil {
+	if err := db.Where(`"id"=?`, authToken.UserID).First(&user).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if err := roles.Load(db, &user); err != nil {
 		return nil, nil, err
 	}
 
@@ -3,7 +3,6 @@ package token
 import (
 	"errors"
 	"net/http"
-	"os"
 	"testing"
 
 	"github.com/lestrrat-go/jwx/jwt"
@@ -21,11 +20,7 @@ func TestLoadTokenFromHttpRequest(t *testing.T) {
 		}
 	})
 	t.Run("valid token exists in header", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 
 		payload := make(map[string]interface{})
 		payload[jwt.SubjectKey] = 1
@@ -38,17 +33,13 @@ func TestLoadTokenFromHttpRequest(t *testing.T) {
 		req.Header.Add("Accept", "application/json")
 		req.Header.Add("Authorization", tokenString)
 
-		_, _, err = LoadTokenFromHttpRequest(req)
+		_, _, err := LoadTokenFromHttpRequest(req)
 		if err != nil {
 			t.Errorf("LoadTokenFromHttpRequest() wants: no error, got: %v", err)
 		}
 	})
 	t.Run("valid token exists in query params: Authorization", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 
 		payload := make(map[string]interface{})
 		payload[jwt.SubjectKey] = 1
@@ -64,17 +55,13 @@ func TestLoadTokenFromHttpRequest(t *testing.T) {
 		params.Set("Authorization", tokenString)
 		req.URL.RawQuery = params.Encode()
 
-		_, _, err = LoadTokenFromHttpRequest(req)
+		_, _, err := LoadTokenFromHttpRequest(req)
 		if err != nil {
 			t.Errorf("LoadTokenFromHttpRequest() wants: no error, got: %v", err)
 		}
 	})
 	t.Run("valid token exists in query params: authorization", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 
 		payload := make(map[string]interface{})
 		payload[jwt.SubjectKey] = 1
@@ -90,17 +77,13 @@ func TestLoadTokenFromHttpRequest(t *testing.T) {
 		params.Set("authorization", tokenString)
 		req.URL.RawQuery = params.Encode()
 
-		_, _, err = LoadTokenFromHttpRequest(req)
+		_, _, err := LoadTokenFromHttpRequest(req)
 		if err != nil {
 			t.Errorf("LoadTokenFromHttpRequest() wants: no error, got: %v", err)
 		}
 	})
 	t.Run("valid token exists in cookie: Authorization", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 
 		payload := make(map[string]interface{})
 		payload[jwt.SubjectKey] = 1
@@ -115,17 +98,13 @@ func TestLoadTokenFromHttpRequest(t *testing.T) {
 		c := http.Cookie{Name: "Authorization", Value: tokenString, Path: "/"}
 		req.AddCookie(&c)
 
-		_, _, err = LoadTokenFromHttpRequest(req)
+		_, _, err := LoadTokenFromHttpRequest(req)
 		if err != nil {
 			t.Errorf("LoadTokenFromHttpRequest() wants: no error, got: %v", err)
 		}
 	})
 	t.Run("valid token exists in cookie: authorization", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 
 		payload := make(map[string]interface{})
 		payload[jwt.SubjectKey] = 1
@@ -140,17 +119,13 @@ func TestLoadTokenFromHttpRequest(t *testing.T) {
 		c := http.Cookie{Name: "authorization", Value: tokenString, Path: "/"}
 		req.AddCookie(&c)
 
-		_, _, err = LoadTokenFromHttpRequest(req)
+		_, _, err := LoadTokenFromHttpRequest(req)
 		if err != nil {
 			t.Errorf("LoadTokenFromHttpRequest() wants: no error, got: %v", err)
 		}
 	})
 	t.Run("invalid token", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 
 		req, _ := http.NewRequest(http.MethodGet, "somewhere", nil)
 		req.Header.Add("Content-Type", "application/json")
@@ -159,7 +134,7 @@ func TestLoadTokenFromHttpRequest(t *testing.T) {
 		c := http.Cookie{Name: "authorization", Value: "invalid", Path: "/"}
 		req.AddCookie(&c)
 
-		_, _, err = LoadTokenFromHttpRequest(req)
+		_, _, err := LoadTokenFromHttpRequest(req)
 		if err == nil {
 			t.Errorf("LoadTokenFromHttpRequest() wants: %v, got: no error", errors.New("invalid auth token"))
 		}
@@ -1,48 +1,16 @@
 package token
 
 import (
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
-	"os"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jws"
 	"github.com/lestrrat-go/jwx/jwt"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 )
 
-// and load the signKey on every signing request? depends on  your usage i guess
-var (
-	privateKey *rsa.PrivateKey
-)
-
-func InitJWT() (err error) {
-	keyPath := viper.GetString("keys.private")
-	bts, err := os.ReadFile(keyPath)
-	if err != nil {
-		panic(err)
-	}
-	block, _ := pem.Decode(bts)
-
-	pk, err := x509.ParsePKCS8PrivateKey(block.Bytes)
-	if err != nil {
-		panic(err)
-	}
-
-	prvKey, ok := pk.(*rsa.PrivateKey)
-	if !ok {
-		return errors.New("Invalid private key")
-	}
-
-	privateKey = prvKey
-
-	return nil
-}
-
 func NewToken(payload map[string]interface{}) jwt.Token {
 	token := jwt.New()
 
@@ -61,18 +29,18 @@ func NewToken(payload map[string]interface{}) jwt.Token {
 	return token
 }
 
+// String signs the token by the current signing key, the kid of the key is set in the header
 func String(token jwt.Token) (tokenString string, err error) {
-	bts, err := jwt.Sign(token, jwa.RS256, privateKey)
+	key, err := ring.signing(time.Now())
 	if err != nil {
 		logrus.Errorf("failed to generate signed payload: %s\n", err)
 		return
 	}
-	return string(bts), nil
-}
 
-func NewTokenString(payload map[string]interface{}) (tokenString string, err error) {
-	token := NewToken(payload)
-	bts, err := jwt.Sign(token, jwa.RS256, privateKey)
+	headers := jws.NewHeaders()
+	_ = headers.Set(jws.KeyIDKey, key.ID)
+
+	bts, err := jwt.Sign(token, jwa.RS256, key.Private, jwt.WithHeaders(headers))
 	if err != nil {
 		logrus.Errorf("failed to generate signed payload: %s\n", err)
 		return
@@ -80,10 +48,29 @@ func NewTokenString(payload map[string]interface{}) (tokenString string, err err
 	return string(bts), nil
 }
 
+func NewTokenString(payload map[string]interface{}) (tokenString string, err error) {
+	return String(NewToken(payload))
+}
+
+// ParseToken verifies the token by the key of its kid, the tokens of the retired keys are rejected
 func ParseToken(tokenString string, validateToken bool) (*jwt.Token, error) {
+	message, err := jws.ParseString(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(message.Signatures()) != 1 {
+		return nil, errors.New("invalid token signature")
+	}
+
+	key, ok := ring.lookup(message.Signatures()[0].ProtectedHeaders().KeyID(), time.Now())
+	if !ok {
+		return nil, errors.New("unknown token signing key")
+	}
+
 	token, err := jwt.ParseString(
 		tokenString,
-		jwt.WithVerify(jwa.RS256, &privateKey.PublicKey),
+		jwt.WithVerify(jwa.RS256, &key.Private.PublicKey),
 		jwt.WithValidate(validateToken),
 	)
 	if err != nil {
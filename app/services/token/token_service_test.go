@@ -1,7 +1,6 @@
 package token
 
 import (
-	"os"
 	"testing"
 	"time"
 
@@ -10,14 +9,10 @@ import (
 
 func TestInitJWT(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 	})
 	t.Run("no key found", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "invalidpath")
+		t.Setenv("KEYS_PATH", "invalidpath")
 		err := InitJWT()
 		if err == nil {
 			t.Error("error wanted but got nil")
@@ -26,11 +21,7 @@ func TestInitJWT(t *testing.T) {
 }
 
 func TestNewToken(t *testing.T) {
-	os.Setenv("KEYS_PATH", "../../oauth-keys")
-	err := InitJWT()
-	if err != nil {
-		t.Error(err)
-	}
+	useKeysPath(t)
 	payload := make(map[string]interface{})
 	payload[jwt.SubjectKey] = "mgh"
 	token := NewToken(payload)
@@ -45,15 +36,11 @@ func TestNewToken(t *testing.T) {
 }
 
 func TestString(t *testing.T) {
-	os.Setenv("KEYS_PATH", "../../oauth-keys")
-	err := InitJWT()
-	if err != nil {
-		t.Error(err)
-	}
+	useKeysPath(t)
 	payload := make(map[string]interface{})
 	payload[jwt.SubjectKey] = "mgh"
 	token := NewToken(payload)
-	_, err = String(token)
+	_, err := String(token)
 	if err != nil {
 		t.Errorf("String() wants error: nil, got: %v", err)
 	}
@@ -61,11 +48,7 @@ func TestString(t *testing.T) {
 
 func TestNewTokenString(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
-		os.Setenv("KEYS_PATH", "../../oauth-keys")
-		err := InitJWT()
-		if err != nil {
-			t.Error(err)
-		}
+		useKeysPath(t)
 		payload := make(map[string]interface{})
 		payload[jwt.SubjectKey] = "mgh"
 		token, err := NewTokenString(payload)
@@ -77,11 +60,7 @@ func TestNewTokenString(t *testing.T) {
 }
 
 func TestParseToken(t *testing.T) {
-	os.Setenv("KEYS_PATH", "../../oauth-keys")
-	err := InitJWT()
-	if err != nil {
-		t.Error(err)
-	}
+	useKeysPath(t)
 
 	t.Run("invalid token", func(t *testing.T) {
 		_, err := ParseToken("invalid", true)
@@ -114,8 +93,7 @@ func TestParseToken(t *testing.T) {
 }
 
 func BenchmarkParseToken(b *testing.B) {
-	os.Setenv("KEYS_PATH", "../../oauth-keys")
-	_ = InitJWT()
+	useKeysPath(b)
 	payload := make(map[string]interface{})
 	payload[jwt.SubjectKey] = "MGH"
 	tokenString, _ := NewTokenString(payload)
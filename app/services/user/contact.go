@@ -0,0 +1,152 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/otp"
+	dbpkg "github.com/esmailemami/eshop/db"
+	"github.com/esmailemami/eshop/models"
+	"gorm.io/gorm"
+)
+
+// Contact is a contact detail of the user which is verified by a code
+type Contact struct {
+	scope            models.VerificationCodeScope
+	column           string
+	verifiedColumn   string
+	verifiedAtColumn string
+	pendingColumn    string
+	takenMessage     string
+
+	current    func(user *models.User) (*string, bool)
+	pending    func(user *models.User) *string
+	setPending func(user *models.User, value *string)
+	isTaken    func(db *gorm.DB, user *models.User, value string) bool
+	sendCode   func(db *gorm.DB, user *models.User, value string) (*models.VerificationCode, error)
+}
+
+var (
+	Email = Contact{
+		scope:            models.VerificationCodeScopeEmailVerification,
+		column:           "email",
+		verifiedColumn:   "email_verified",
+		verifiedAtColumn: "email_verified_at",
+		pendingColumn:    "pending_email",
+		takenMessage:     consts.EmailAlreadyExists,
+		current: func(user *models.User) (*string, bool) {
+			return user.Email, user.EmailVerified
+		},
+		pending: func(user *models.User) *string {
+			return user.PendingEmail
+		},
+		setPending: func(user *models.User, value *string) {
+			user.PendingEmail = value
+		},
+		// the email column is unique, so an unverified email of another user blocks it too
+		isTaken: func(db *gorm.DB, user *models.User, value string) bool {
+			return dbpkg.Exists(db, &models.User{}, "email = ? AND id <> ?", value, user.ID)
+		},
+		sendCode: func(db *gorm.DB, user *models.User, value string) (*models.VerificationCode, error) {
+			return otp.SendEmail(db, value, user.Username)
+		},
+	}
+
+	Mobile = Contact{
+		scope:            models.VerificationCodeScopeMobile,
+		column:           "mobile",
+		verifiedColumn:   "mobile_verified",
+		verifiedAtColumn: "mobile_verified_at",
+		pendingColumn:    "pending_mobile",
+		takenMessage:     consts.MobileAlreadyExists,
+		current: func(user *models.User) (*string, bool) {
+			return user.Mobile, user.MobileVerified
+		},
+		pending: func(user *models.User) *string {
+			return user.PendingMobile
+		},
+		setPending: func(user *models.User, value *string) {
+			user.PendingMobile = value
+		},
+		isTaken: func(db *gorm.DB, user *models.User, value string) bool {
+			return dbpkg.Exists(db, &models.User{}, "mobile = ? AND mobile_verified = true AND id <> ?", value, user.ID)
+		},
+		sendCode: func(db *gorm.DB, user *models.User, value string) (*models.VerificationCode, error) {
+			return otp.Send(db, value)
+		},
+	}
+)
+
+// RequestChange keeps the value as the pending contact of the user and sends a verification code to it, the
+// contact is changed when the code is verified. Nothing is sent when the value is the current contact of the user.
+func (c Contact) RequestChange(db *gorm.DB, user *models.User, value string) (*models.VerificationCode, error) {
+	if current, _ := c.current(user); current != nil && *current == value {
+		if err := db.Model(user).UpdateColumn(c.pendingColumn, nil).Error; err != nil {
+			return nil, errors.New(consts.InternalServerError)
+		}
+		c.setPending(user, nil)
+		return nil, nil
+	}
+
+	if c.isTaken(db, user, value) {
+		return nil, errors.New(c.takenMessage)
+	}
+
+	if err := db.Model(user).UpdateColumn(c.pendingColumn, value).Error; err != nil {
+		return nil, errors.New(consts.InternalServerError)
+	}
+	c.setPending(user, &value)
+
+	return c.sendCode(db, user, value)
+}
+
+// SendCode sends a new verification code to the contact which is waiting for the verification
+func (c Contact) SendCode(db *gorm.DB, user *models.User) (*models.VerificationCode, error) {
+	value, ok := c.unverified(user)
+	if !ok {
+		return nil, errors.New(consts.NoContactToVerify)
+	}
+
+	return c.sendCode(db, user, value)
+}
+
+// Verify checks the code which is sent to the unverified contact and replaces the contact of the user with it
+func (c Contact) Verify(db *gorm.DB, user *models.User, code string) error {
+	value, ok := c.unverified(user)
+	if !ok {
+		return errors.New(consts.NoContactToVerify)
+	}
+
+	if err := otp.Verify(db, c.scope, value, code); err != nil {
+		return err
+	}
+
+	if c.isTaken(db, user, value) {
+		return errors.New(c.takenMessage)
+	}
+
+	if err := db.Model(user).UpdateColumns(map[string]any{
+		c.column:           value,
+		c.verifiedColumn:   true,
+		c.verifiedAtColumn: time.Now(),
+		c.pendingColumn:    nil,
+	}).Error; err != nil {
+		return errors.New(consts.InternalServerError)
+	}
+
+	return nil
+}
+
+// unverified returns the pending contact of the user, or the current one when it is not verified yet
+func (c Contact) unverified(user *models.User) (string, bool) {
+	if pending := c.pending(user); pending != nil && *pending != "" {
+		return *pending, true
+	}
+
+	if current, verified := c.current(user); !verified && current != nil && *current != "" {
+		return *current, true
+	}
+
+	return "", false
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 
+	"github.com/esmailemami/eshop/app/services/roles"
 	dbpkg "github.com/esmailemami/eshop/db"
 	"github.com/esmailemami/eshop/models"
 )
@@ -26,11 +27,15 @@ func GetUserByUsername(value string) (*models.User, error) {
 	db := dbpkg.MustGormDBConn(context.Background())
 
 	var user models.User
-	err := db.Model(&models.User{}).Preload("Role").Where("username = ?", value).First(&user).Error
+	err := db.Model(&models.User{}).Where("username = ?", value).First(&user).Error
 
 	if err != nil {
 		return nil, err
 	}
 
+	if err := roles.Load(db, &user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
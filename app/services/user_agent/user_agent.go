@@ -0,0 +1,90 @@
+package user_agent
+
+import "strings"
+
+// Device is the information of the device which is detected from a user agent
+type Device struct {
+	Browser string
+	OS      string
+	Type    string
+}
+
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeBot     = "bot"
+
+	unknown = "Unknown"
+)
+
+// the order matters, the browsers which contain the name of the others in their user agent come first
+var browsers = []struct {
+	token, name string
+}{
+	{"edg/", "Edge"},
+	{"edge/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser", "Samsung Internet"},
+	{"yabrowser", "Yandex"},
+	{"firefox/", "Firefox"},
+	{"fxios", "Firefox"},
+	{"crios", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"okhttp", "Android App"},
+	{"dart", "Mobile App"},
+	{"postmanruntime", "Postman"},
+	{"curl/", "curl"},
+}
+
+var systems = []struct {
+	token, name string
+}{
+	{"windows", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"android", "Android"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"cros", "Chrome OS"},
+	{"linux", "Linux"},
+}
+
+// Parse detects the browser, the operating system and the type of the device of the user agent
+func Parse(userAgent string) Device {
+	ua := strings.ToLower(userAgent)
+
+	device := Device{
+		Browser: unknown,
+		OS:      unknown,
+		Type:    DeviceTypeDesktop,
+	}
+
+	for _, b := range browsers {
+		if strings.Contains(ua, b.token) {
+			device.Browser = b.name
+			break
+		}
+	}
+
+	for _, s := range systems {
+		if strings.Contains(ua, s.token) {
+			device.OS = s.name
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(ua, "bot") || strings.Contains(ua, "spider") || strings.Contains(ua, "crawl"):
+		device.Type = DeviceTypeBot
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		device.Type = DeviceTypeTablet
+	case strings.Contains(ua, "mobile") || strings.Contains(ua, "iphone") || strings.Contains(ua, "okhttp"):
+		device.Type = DeviceTypeMobile
+	}
+
+	return device
+}
@@ -0,0 +1,28 @@
+package validations
+
+import (
+	"errors"
+
+	"github.com/esmailemami/eshop/app/consts"
+	"github.com/esmailemami/eshop/app/services/barcode"
+)
+
+// GTIN validates the GTIN-8, GTIN-12, GTIN-13 and GTIN-14 barcodes with their check digit
+func GTIN() func(value interface{}) error {
+	return func(value interface{}) error {
+		if IsNil(value) {
+			return nil
+		}
+
+		code, ok := Value(value).(string)
+		if !ok {
+			return errors.New(consts.InvalidBarcode)
+		}
+
+		if !barcode.ValidGTIN(code) {
+			return errors.New(consts.InvalidBarcode)
+		}
+
+		return nil
+	}
+}
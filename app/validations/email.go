@@ -0,0 +1,26 @@
+package validations
+
+import (
+	"errors"
+	"net/mail"
+
+	"github.com/esmailemami/eshop/app/consts"
+)
+
+func IsValidEmail() func(value interface{}) error {
+	return func(value interface{}) error {
+		if IsNil(value) {
+			return nil
+		}
+
+		email, ok := Value(value).(string)
+		if !ok {
+			return errors.New(consts.InvalidEmail)
+		}
+
+		if address, err := mail.ParseAddress(email); err == nil && address.Address == email {
+			return nil
+		}
+		return errors.New(consts.InvalidEmail)
+	}
+}
@@ -0,0 +1,29 @@
+package validations
+
+import "testing"
+
+func TestIsValidEmail(t *testing.T) {
+	valid := "user@example.com"
+	invalid := "user@"
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr bool
+	}{
+		{name: "valid", value: "user@example.com", wantErr: false},
+		{name: "valid pointer", value: &valid, wantErr: false},
+		{name: "nil pointer", value: (*string)(nil), wantErr: false},
+		{name: "missing domain", value: "user@", wantErr: true},
+		{name: "invalid pointer", value: &invalid, wantErr: true},
+		{name: "display name", value: "User <user@example.com>", wantErr: true},
+		{name: "not a string", value: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := IsValidEmail()(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("IsValidEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -6,18 +6,23 @@ import (
 
 	dbpkg "github.com/esmailemami/eshop/db"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func ExistsInDB(model interface{}, column string, errorMsg string) func(value interface{}) error {
+	return ExistsInTx(nil, model, column, errorMsg)
+}
+
+// ExistsInTx checks the value by the connection, so the records of an open transaction are visible to it. A nil
+// connection checks the default connection.
+func ExistsInTx(db *gorm.DB, model interface{}, column string, errorMsg string) func(value interface{}) error {
 	return func(value interface{}) error {
 		if IsNil(value) {
 			return nil
 		}
 
-		db := dbpkg.MustGormDBConn(context.Background())
-
 		var count int64
-		db.Model(model).
+		conn(db).Model(model).
 			Where(column+"=?", value).
 			Count(&count)
 
@@ -30,15 +35,18 @@ func ExistsInDB(model interface{}, column string, errorMsg string) func(value in
 }
 
 func NotExistsInDB(model interface{}, column string, errorMsg string) func(value interface{}) error {
+	return NotExistsInTx(nil, model, column, errorMsg)
+}
+
+// NotExistsInTx is NotExistsInDB by the connection of an open transaction
+func NotExistsInTx(db *gorm.DB, model interface{}, column string, errorMsg string) func(value interface{}) error {
 	return func(value interface{}) error {
 		if IsNil(value) {
 			return nil
 		}
 
-		db := dbpkg.MustGormDBConn(context.Background())
-
 		var count int64
-		db.Model(model).
+		conn(db).Model(model).
 			Where(column+"=?", value).
 			Count(&count)
 
@@ -51,17 +59,20 @@ func NotExistsInDB(model interface{}, column string, errorMsg string) func(value
 }
 
 func NotExistsInDBWithID(model interface{}, column string, id uuid.UUID, errorMsg string) func(value interface{}) error {
+	return NotExistsInTxWithID(nil, model, column, id, errorMsg)
+}
+
+// NotExistsInTxWithID is NotExistsInDBWithID by the connection of an open transaction
+func NotExistsInTxWithID(db *gorm.DB, model interface{}, column string, id uuid.UUID, errorMsg string) func(value interface{}) error {
 	return func(value interface{}) error {
 		if IsNil(value) {
 			return nil
 		}
 
-		db := dbpkg.MustGormDBConn(context.Background())
-
 		var count int64
-		db.Model(model).
+		conn(db).Model(model).
 			Where(column+"=?", value).
-			Where("id != ", id).
+			Where("id != ?", id).
 			Count(&count)
 
 		if count > 0 {
@@ -72,6 +83,16 @@ func NotExistsInDBWithID(model interface{}, column string, id uuid.UUID, errorMs
 	}
 }
 
+// conn returns the connection of the validation, the default connection is used when it is nil
+func conn(db *gorm.DB) *gorm.DB {
+	if db == nil {
+		return dbpkg.MustGormDBConn(context.Background())
+	}
+
+	// a new session, so the conditions of a query do not leak to the next one
+	return db.Session(&gorm.Session{NewDB: true})
+}
+
 func NotExistsInDBWithCond(model interface{}, column string, errorMsg string, condition interface{}, args ...interface{}) func(value interface{}) error {
 	return func(value interface{}) error {
 		if IsNil(value) {
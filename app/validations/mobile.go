@@ -9,7 +9,11 @@ import (
 
 func IsValidMobileNumber() func(value interface{}) error {
 	return func(value interface{}) error {
-		mobile, ok := value.(string)
+		if IsNil(value) {
+			return nil
+		}
+
+		mobile, ok := Value(value).(string)
 		if !ok {
 			return errors.New(consts.InvalidMobileNumber)
 		}
@@ -43,3 +43,19 @@ func TimeGreaterThanNow() func(value interface{}) error {
 		return nil
 	}
 }
+
+// TimeAfter validates the time is after the other time when both of them are set
+func TimeAfter(other *time.Time, errorMsg string) func(value interface{}) error {
+	return func(value interface{}) error {
+		if IsNil(value) || other == nil {
+			return nil
+		}
+		valTime := Value(value).(time.Time)
+
+		if !valTime.After(*other) {
+			return errors.New(errorMsg)
+		}
+
+		return nil
+	}
+}
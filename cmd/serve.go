@@ -4,6 +4,8 @@ import (
 	"log"
 
 	"github.com/esmailemami/eshop/api/server"
+	"github.com/esmailemami/eshop/app/services/jobs"
+	"github.com/esmailemami/eshop/app/services/oauth"
 	"github.com/esmailemami/eshop/app/services/settings"
 	"github.com/esmailemami/eshop/app/services/token"
 	"github.com/spf13/cobra"
@@ -17,7 +19,12 @@ var serveCmd = &cobra.Command{
 			log.Fatalln(err)
 		}
 
+		if err := oauth.Initialize(); err != nil {
+			log.Fatalln(err)
+		}
+
 		go settings.Initialize()
+		jobs.Start()
 		server.RunServer()
 
 		return nil
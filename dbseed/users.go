@@ -7,6 +7,7 @@ import (
 	"github.com/esmailemami/eshop/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func seedUser(dbConn *gorm.DB) error {
@@ -38,10 +39,6 @@ func seedUser(dbConn *gorm.DB) error {
 				value := "esmailemami84@gmail.com"
 				return &value
 			}(),
-			RoleID: func() *uuid.UUID {
-				id := uuid.MustParse(consts.ROLE_ROOT_ID)
-				return &id
-			}(),
 		},
 		{
 			Model: models.Model{
@@ -70,10 +67,6 @@ func seedUser(dbConn *gorm.DB) error {
 				return &value
 			}(),
 			Enabled: true,
-			RoleID: func() *uuid.UUID {
-				id := uuid.MustParse(consts.ROLE_ROOT_ID)
-				return &id
-			}(),
 		},
 	}
 
@@ -100,7 +93,6 @@ func seedUser(dbConn *gorm.DB) error {
 				"is_system":  item.IsSystem,
 				"mobile":     item.Mobile,
 				"enabled":    item.Enabled,
-				"role_id":    item.RoleID.String(),
 				"updated_at": time.Now(),
 				"email":      item.Email,
 			}).Error
@@ -108,6 +100,15 @@ func seedUser(dbConn *gorm.DB) error {
 				return err
 			}
 		}
+
+		err = dbConn.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserRole{
+			UserID:    *item.ID,
+			RoleID:    uuid.MustParse(consts.ROLE_ROOT_ID),
+			CreatedAt: time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
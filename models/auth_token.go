@@ -12,6 +12,7 @@ type AuthToken struct {
 	User           *User          `gorm:"foreignKey:user_id;references:id"  json:"user"`
 	Revoked        bool           `gorm:"column:revoked"                    json:"revoked"`
 	ExpiresAt      time.Time      `gorm:"column:expires_at"                 json:"expiresAt"`
+	FamilyID       *uuid.UUID     `gorm:"column:family_id"                  json:"familyId"`
 	LoginHistories []LoginHistory `gorm:"foreignKey:token_id;references:id" json:"loginHistories"`
 }
 
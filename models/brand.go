@@ -15,3 +15,8 @@ type Brand struct {
 func (Brand) TableName() string {
 	return "brand"
 }
+
+// PermissionResource returns the attributes which the permission scopes are checked against
+func (model Brand) PermissionResource() PermissionResource {
+	return PermissionResource{BrandID: model.ID}
+}
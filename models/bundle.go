@@ -0,0 +1,33 @@
+package models
+
+import "github.com/google/uuid"
+
+// Bundle is sold as a single line item at its own price, the stock is derived from the components
+type Bundle struct {
+	Model
+
+	Name        string        `gorm:"column:name"                              json:"name"`
+	Code        string        `gorm:"column:code"                              json:"code"`
+	Description string        `gorm:"column:description"                       json:"description"`
+	Price       float64       `gorm:"column:price"                             json:"price"`
+	Status      ProductStatus `gorm:"column:status"                            json:"status"`
+	Items       []BundleItem  `gorm:"foreignKey:bundle_id;references:id"       json:"items"`
+}
+
+func (Bundle) TableName() string {
+	return "bundle"
+}
+
+type BundleItem struct {
+	Model
+
+	BundleID      uuid.UUID    `gorm:"column:bundle_id"                         json:"bundleId"`
+	Bundle        *Bundle      `gorm:"foreignKey:bundle_id;references:id"       json:"bundle"`
+	ProductItemID uuid.UUID    `gorm:"column:product_item_id"                   json:"productItemId"`
+	ProductItem   *ProductItem `gorm:"foreignKey:product_item_id;references:id" json:"productItem"`
+	Quantity      int          `gorm:"column:quantity"                          json:"quantity"`
+}
+
+func (BundleItem) TableName() string {
+	return "bundle_item"
+}
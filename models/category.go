@@ -6,9 +6,17 @@ type Category struct {
 	Name string `gorm:"column:name" json:"name"`
 	Code string `gorm:"column:code" json:"code"`
 
+	// the reorder threshold of the items which do not have their own threshold
+	ReorderThreshold *int `gorm:"column:reorder_threshold" json:"reorderThreshold"`
+
 	Products []Product `gorm:"foreignKey:category_id;references:id" json:"products"`
 }
 
 func (Category) TableName() string {
 	return "category"
 }
+
+// PermissionResource returns the attributes which the permission scopes are checked against
+func (model Category) PermissionResource() PermissionResource {
+	return PermissionResource{CategoryID: model.ID}
+}
@@ -16,6 +16,13 @@ type Comment struct {
 	Product        *Product              `gorm:"foreignKey:product_id; references:id;" json:"product"`
 	Status         CommentStatus         `gorm:"status"                                json:"status"`
 	AdminNote      *string               `gorm:"admin_note"                            json:"adminNote"`
+
+	// the author has a received order which contains the product
+	IsVerifiedPurchase bool `gorm:"column:is_verified_purchase"           json:"isVerifiedPurchase"`
+
+	// the votes of the other users on the helpfulness of the review
+	HelpfulVotes   int `gorm:"column:helpful_votes"                  json:"helpfulVotes"`
+	UnhelpfulVotes int `gorm:"column:unhelpful_votes"                json:"unhelpfulVotes"`
 }
 
 func (Comment) TableName() string {
@@ -29,3 +36,15 @@ const (
 	CommntStatusAccept
 	CommentStatusReject
 )
+
+// CommentVote is the vote of a user on the helpfulness of a review
+type CommentVote struct {
+	Model
+
+	CommentID uuid.UUID `gorm:"column:comment_id"     json:"commentId"`
+	Helpful   bool      `gorm:"column:helpful"        json:"helpful"`
+}
+
+func (CommentVote) TableName() string {
+	return "comment_vote"
+}
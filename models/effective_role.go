@@ -0,0 +1,133 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// RoleRef is the identity of a role which the effective role is made of
+type RoleRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Code string    `json:"code"`
+}
+
+// EffectiveRole is the merge of the roles of a user and the roles they inherit, a permission is granted when one of
+// the roles grants it and it is only scoped when every role which grants it limits it
+type EffectiveRole struct {
+	// Roles is the roles which are assigned to the user
+	Roles []RoleRef `json:"roles"`
+	// Inherited is the roles which are included by the assigned roles
+	Inherited []RoleRef `json:"inherited"`
+
+	Permissions      RolePermissions              `json:"permissions"`
+	PermissionScopes map[string][]PermissionScope `json:"permissionScopes"`
+	MfaRequired      bool                         `json:"mfaRequired"`
+}
+
+// NewEffectiveRole merges the permissions of the assigned and the inherited roles
+func NewEffectiveRole(assigned, inherited []Role) *EffectiveRole {
+	role := &EffectiveRole{
+		Roles:            make([]RoleRef, 0, len(assigned)),
+		Inherited:        make([]RoleRef, 0, len(inherited)),
+		Permissions:      RolePermissions{},
+		PermissionScopes: map[string][]PermissionScope{},
+	}
+
+	granted := map[string]bool{}
+	unlimited := map[string]bool{}
+
+	merge := func(r Role) {
+		role.MfaRequired = role.MfaRequired || r.MfaRequired
+
+		for _, p := range r.Permissions {
+			if !granted[p] {
+				granted[p] = true
+				role.Permissions = append(role.Permissions, p)
+			}
+
+			if unlimited[p] {
+				continue
+			}
+
+			scope, ok := r.PermissionScopes[p]
+			if !ok {
+				unlimited[p] = true
+				delete(role.PermissionScopes, p)
+				continue
+			}
+
+			role.PermissionScopes[p] = append(role.PermissionScopes[p], scope)
+		}
+	}
+
+	for _, r := range assigned {
+		role.Roles = append(role.Roles, r.Ref())
+		merge(r)
+	}
+
+	for _, r := range inherited {
+		role.Inherited = append(role.Inherited, r.Ref())
+		merge(r)
+	}
+
+	return role
+}
+
+// Permitted reports whether one of the roles grants the action
+func (r *EffectiveRole) Permitted(action string) bool {
+	if r == nil {
+		return false
+	}
+
+	for _, p := range r.Permissions {
+		if p == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PermissionScope returns the scopes of the permitted action, ok is false when the action is not permitted and nil
+// scopes mean the action is permitted on every resource
+func (r *EffectiveRole) PermissionScope(action string) (scopes []PermissionScope, ok bool) {
+	if !r.Permitted(action) {
+		return nil, false
+	}
+
+	return r.PermissionScopes[action], true
+}
+
+// RequiresMfa reports whether one of the roles requires the 2FA and the user can login as admin
+func (r *EffectiveRole) RequiresMfa() bool {
+	return r != nil && r.MfaRequired && r.Permitted(ACTION_CAN_LOGIN_ADMIN)
+}
+
+// HasRole reports whether the role is assigned to the user, the inherited roles are not counted
+func (r *EffectiveRole) HasRole(id uuid.UUID) bool {
+	if r == nil {
+		return false
+	}
+
+	for _, role := range r.Roles {
+		if role.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Names returns the names of the assigned roles
+func (r *EffectiveRole) Names() []string {
+	if r == nil {
+		return []string{}
+	}
+
+	names := make([]string, len(r.Roles))
+	for i, role := range r.Roles {
+		names[i] = role.Name
+	}
+
+	return names
+}
@@ -35,6 +35,8 @@ const (
 	FileTypeProduct
 	FileTypeBrand
 	FileTypeAppPic
+	FileTypeProductItemBarcode
+	FileTypeReview
 )
 
 func FileTypeFromInt(value int) (FileType, error) {
@@ -47,6 +49,10 @@ func FileTypeFromInt(value int) (FileType, error) {
 		return FileTypeBrand, nil
 	case int(FileTypeAppPic):
 		return FileTypeAppPic, nil
+	case int(FileTypeProductItemBarcode):
+		return FileTypeProductItemBarcode, nil
+	case int(FileTypeReview):
+		return FileTypeReview, nil
 	default:
 		return 0, errors.New("invalid FileType value")
 	}
@@ -104,6 +110,33 @@ func (ft FileType) GetFullInfo() (multiple, hasPriority, canForceDelete, isFileC
 		uploadDir = "app-pic"
 		hasPriority = true
 
+	case FileTypeProductItemBarcode:
+		downloadPermission = ACTION_FILE_BARCODE_DOWNLOAD
+		uploadPermission = ACTION_FILE_BARCODE_UPLOAD
+		listPermission = ACTION_FILE_BARCODE_LIST
+		deletePermission = ACTION_FILE_BARCODE_DELETE
+
+		table = "product_item"
+		fileColumn = "barcode_file_id"
+		uploadDir = "barcode"
+		isFileColumnNullable = true
+		// the images are generated from the codes, the replaced ones are not kept
+		canForceDelete = true
+
+	case FileTypeReview:
+		downloadPermission = ACTION_FILE_REVIEW_DOWNLOAD
+		uploadPermission = ACTION_FILE_REVIEW_UPLOAD
+		listPermission = ACTION_FILE_REVIEW_LIST
+		deletePermission = ACTION_FILE_REVIEW_DELETE
+		changePriorirtyPermission = ACTION_FILE_REVIEW_CHANGE_PRIORITY
+
+		multiple = true
+		hasPriority = true
+		table = "comment"
+		mapTable = "comment_file_map"
+		foreignColumn = "comment_id"
+		uploadDir = "review"
+
 	default:
 		panic("invalid file type")
 	}
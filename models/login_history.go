@@ -4,9 +4,18 @@ import (
 	"github.com/google/uuid"
 )
 
+type LoginEvent string
+
+const (
+	LoginEventLogin LoginEvent = "login"
+	// LoginEventLockout is recorded when the account is locked by the failed attempts
+	LoginEventLockout LoginEvent = "lockout"
+)
+
 // LoginHistory
 type LoginHistory struct {
 	BasicModel
+	Event     LoginEvent `gorm:"column:event;default:login"        json:"event"`
 	IP        *string    `gorm:"column:ip"                         json:"ip"`
 	UserAgent *string    `gorm:"column:user_agent"                 json:"userAgent"`
 	UserID    uuid.UUID  `gorm:"column:user_id"                    json:"userId"`
@@ -7,11 +7,16 @@ type OrderItem struct {
 
 	OrderID       uuid.UUID    `gorm:"column:order_id"                          json:"orderId"`
 	Order         *Order       `gorm:"foreignKey:order_id;references:id;"       json:"order"`
-	ProductItemID uuid.UUID    `gorm:"column:product_item_id"                   json:"productItemId"`
+	ProductItemID *uuid.UUID   `gorm:"column:product_item_id"                   json:"productItemId"`
 	ProductItem   *ProductItem `gorm:"foreignKey:product_item_id;references:id" json:"productItem"`
 	Quantity      int          `gorm:"column:quantity"                          json:"quantity"`
 	Price         float64      `gorm:"column:price"                             json:"price"`
 	TotalPrice    float64      `gorm:"column:total_price"                       json:"totalPrice"`
+
+	// an order item is either a product item or a bundle
+	BundleID   *uuid.UUID           `gorm:"column:bundle_id"                         json:"bundleId"`
+	Bundle     *Bundle              `gorm:"foreignKey:bundle_id;references:id"       json:"bundle"`
+	Components []OrderItemComponent `gorm:"foreignKey:order_item_id;references:id"   json:"components"`
 }
 
 func (OrderItem) TableName() string {
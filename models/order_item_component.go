@@ -0,0 +1,22 @@
+package models
+
+import "github.com/google/uuid"
+
+// OrderItemComponent keeps the breakdown of a bundle order item at the checkout time
+type OrderItemComponent struct {
+	Model
+
+	OrderItemID   uuid.UUID    `gorm:"column:order_item_id"                     json:"orderItemId"`
+	OrderItem     *OrderItem   `gorm:"foreignKey:order_item_id;references:id"   json:"orderItem"`
+	ProductItemID uuid.UUID    `gorm:"column:product_item_id"                   json:"productItemId"`
+	ProductItem   *ProductItem `gorm:"foreignKey:product_item_id;references:id" json:"productItem"`
+
+	// quantity is the total deducted quantity and price is the share of the bundle price for each unit
+	Quantity  int     `gorm:"column:quantity"                          json:"quantity"`
+	ListPrice float64 `gorm:"column:list_price"                        json:"listPrice"`
+	Price     float64 `gorm:"column:price"                             json:"price"`
+}
+
+func (OrderItemComponent) TableName() string {
+	return "order_item_component"
+}
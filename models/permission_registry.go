@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PermissionScopeType is an attribute of the resources which a permission can be limited by
+type PermissionScopeType string
+
+const (
+	PermissionScopeCategory PermissionScopeType = "category"
+	PermissionScopeBrand    PermissionScopeType = "brand"
+)
+
+// Permission is an action which can be granted to the roles, the scopes are the attributes which the roles can limit
+// the permission by
+type Permission struct {
+	Code        string                `json:"code"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Group       string                `json:"group"`
+	Scopes      []PermissionScopeType `json:"scopes,omitempty"`
+}
+
+// Scopable reports whether the permission can be limited by the scope type
+func (p Permission) Scopable(scopeType PermissionScopeType) bool {
+	for _, s := range p.Scopes {
+		if s == scopeType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PermissionGroup is the permissions of a resource
+type PermissionGroup struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+}
+
+var permissionRegistry = struct {
+	mu     sync.RWMutex
+	groups []PermissionGroup
+	codes  map[string]Permission
+}{
+	codes: map[string]Permission{},
+}
+
+// RegisterPermissions adds the permissions of the group to the registry, the permissions of a registered group are
+// appended to it. A code is registered once, registering it again is a programming error and panics.
+func RegisterPermissions(group PermissionGroup) {
+	permissionRegistry.mu.Lock()
+	defer permissionRegistry.mu.Unlock()
+
+	index := -1
+	for i, g := range permissionRegistry.groups {
+		if g.Name == group.Name {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		permissionRegistry.groups = append(permissionRegistry.groups, PermissionGroup{
+			Name:        group.Name,
+			Description: group.Description,
+		})
+		index = len(permissionRegistry.groups) - 1
+	}
+
+	for _, permission := range group.Permissions {
+		if _, ok := permissionRegistry.codes[permission.Code]; ok {
+			panic(fmt.Sprintf("permission %s is registered twice", permission.Code))
+		}
+
+		permission.Group = group.Name
+		permissionRegistry.codes[permission.Code] = permission
+		permissionRegistry.groups[index].Permissions = append(permissionRegistry.groups[index].Permissions, permission)
+	}
+}
+
+// PermissionGroups returns the registered groups in the order of their registration
+func PermissionGroups() []PermissionGroup {
+	permissionRegistry.mu.RLock()
+	defer permissionRegistry.mu.RUnlock()
+
+	groups := make([]PermissionGroup, len(permissionRegistry.groups))
+	for i, group := range permissionRegistry.groups {
+		groups[i] = group
+		groups[i].Permissions = append([]Permission(nil), group.Permissions...)
+	}
+
+	return groups
+}
+
+// LookupPermission returns the registered permission of the code
+func LookupPermission(code string) (Permission, bool) {
+	permissionRegistry.mu.RLock()
+	defer permissionRegistry.mu.RUnlock()
+
+	permission, ok := permissionRegistry.codes[code]
+	return permission, ok
+}
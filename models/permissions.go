@@ -11,9 +11,67 @@ const (
 	ACTION_PRODUCT_ADMIN_CREATE = "action_product_admin_create"
 	ACTION_PRODUCT_ADMIN_UPDATE = "action_product_admin_update"
 	ACTION_PRODUCT_ADMIN_DELETE = "action_product_admin_delete"
+	ACTION_PRODUCT_ADMIN_IMPORT = "action_product_admin_import"
+	ACTION_PRODUCT_ADMIN_EXPORT = "action_product_admin_export"
+	ACTION_PRODUCT_ADMIN_REVIEW = "action_product_admin_review"
 
 	// ###### Product ######
 
+	// ###### ProductRelation ######
+
+	ACTION_PRODUCT_RELATION_ADMIN_LIST   = "action_product_relation_admin_list"
+	ACTION_PRODUCT_RELATION_ADMIN_CREATE = "action_product_relation_admin_create"
+	ACTION_PRODUCT_RELATION_ADMIN_DELETE = "action_product_relation_admin_delete"
+
+	// ###### ProductRelation ######
+
+	// ###### Bundle ######
+
+	ACTION_BUNDLE_ADMIN_INFO   = "action_bundle_admin_info"
+	ACTION_BUNDLE_ADMIN_LIST   = "action_bundle_admin_list"
+	ACTION_BUNDLE_ADMIN_CREATE = "action_bundle_admin_create"
+	ACTION_BUNDLE_ADMIN_UPDATE = "action_bundle_admin_update"
+	ACTION_BUNDLE_ADMIN_DELETE = "action_bundle_admin_delete"
+
+	// ###### Bundle ######
+
+	// ###### Warehouse ######
+
+	ACTION_WAREHOUSE_ADMIN_INFO   = "action_warehouse_admin_info"
+	ACTION_WAREHOUSE_ADMIN_LIST   = "action_warehouse_admin_list"
+	ACTION_WAREHOUSE_ADMIN_CREATE = "action_warehouse_admin_create"
+	ACTION_WAREHOUSE_ADMIN_UPDATE = "action_warehouse_admin_update"
+	ACTION_WAREHOUSE_ADMIN_DELETE = "action_warehouse_admin_delete"
+	ACTION_WAREHOUSE_ADMIN_STOCK  = "action_warehouse_admin_stock"
+	ACTION_WAREHOUSE_ADMIN_ADJUST = "action_warehouse_admin_adjust"
+
+	// ###### Warehouse ######
+
+	// ###### StockTransfer ######
+
+	ACTION_STOCK_TRANSFER_ADMIN_INFO     = "action_stock_transfer_admin_info"
+	ACTION_STOCK_TRANSFER_ADMIN_LIST     = "action_stock_transfer_admin_list"
+	ACTION_STOCK_TRANSFER_ADMIN_CREATE   = "action_stock_transfer_admin_create"
+	ACTION_STOCK_TRANSFER_ADMIN_COMPLETE = "action_stock_transfer_admin_complete"
+	ACTION_STOCK_TRANSFER_ADMIN_CANCEL   = "action_stock_transfer_admin_cancel"
+
+	// ###### StockTransfer ######
+
+	// ###### StockMovement ######
+
+	ACTION_STOCK_MOVEMENT_ADMIN_LIST      = "action_stock_movement_admin_list"
+	ACTION_STOCK_MOVEMENT_ADMIN_RECONCILE = "action_stock_movement_admin_reconcile"
+
+	// ###### StockMovement ######
+
+	// ###### ProductItem ######
+
+	ACTION_PRODUCT_ITEM_ADMIN_LOOKUP  = "action_product_item_admin_lookup"
+	ACTION_PRODUCT_ITEM_ADMIN_BARCODE = "action_product_item_admin_barcode"
+	ACTION_PRODUCT_ITEM_ADMIN_PRICE   = "action_product_item_admin_price"
+
+	// ###### ProductItem ######
+
 	// ###### Brand ######
 
 	ACTION_BRAND_ADMIN_INFO   = "action_brand_admin_info"
@@ -139,6 +197,25 @@ const (
 
 	// ###### File - AppPic ######
 
+	// ###### File - Barcode ######
+
+	ACTION_FILE_BARCODE_DOWNLOAD = "action_file_barcode_download"
+	ACTION_FILE_BARCODE_UPLOAD   = "action_file_barcode_upload"
+	ACTION_FILE_BARCODE_LIST     = "action_file_barcode_list"
+	ACTION_FILE_BARCODE_DELETE   = "action_file_barcode_delete"
+
+	// ###### File - Barcode ######
+
+	// ###### File - Review ######
+
+	ACTION_FILE_REVIEW_DOWNLOAD        = "action_file_review_download"
+	ACTION_FILE_REVIEW_UPLOAD          = "action_file_review_upload"
+	ACTION_FILE_REVIEW_LIST            = "action_file_review_list"
+	ACTION_FILE_REVIEW_DELETE          = "action_file_review_delete"
+	ACTION_FILE_REVIEW_CHANGE_PRIORITY = "action_file_review_change_priority"
+
+	// ###### File - Review ######
+
 	// ###### File ######
 
 	// ###### VerificationCode ######
@@ -160,10 +237,20 @@ const (
 
 	// ###### Comment ######
 
+	// ###### ProductQuestion ######
+
+	ACTION_QUESTION_ADMIN_LIST          = "action_question_admin_list"
+	ACTION_QUESTION_ADMIN_CHANGE_STATUS = "action_question_change_status"
+	ACTION_QUESTION_ADMIN_ANSWER        = "action_question_admin_answer"
+
+	// ###### ProductQuestion ######
+
 	// ###### Report ######
 
 	ACTION_REPORT_ADMIN_REVENUE_BY_CATEGORY = "action_report_revenue_by_category"
 	ACTION_REPORT_ADMIN_SELLS_CHART         = "action_report_sells_chart"
+	ACTION_REPORT_ADMIN_LOW_STOCK           = "action_report_low_stock"
+	ACTION_REPORT_ADMIN_AT_RISK             = "action_report_at_risk"
 
 	// ###### Report ######
 
@@ -177,12 +264,18 @@ const (
 	ACTION_USER_ADMIN_RECOVERY_PASSWORD     = "action_user_admin_recovery_password"
 	ACTION_USER_ADMIN_ORDER_LIST            = "action_user_admin_order_list"
 	ACTION_USER_ADMIN_FAVORITE_PRODUCT_LIST = "action_user_admin_favorite_product_list"
+	ACTION_USER_ADMIN_SESSION_LIST          = "action_user_admin_session_list"
+	ACTION_USER_ADMIN_FORCE_LOGOUT          = "action_user_admin_force_logout"
+	ACTION_USER_ADMIN_MFA_RESET             = "action_user_admin_mfa_reset"
+	ACTION_USER_ADMIN_UNLOCK                = "action_user_admin_unlock"
+	ACTION_USER_ADMIN_PERMISSIONS           = "action_user_admin_permissions"
 
 	// ###### User ######
 
 	// ###### Order ######
 
 	ACTION_ORDER_ADMIN_LIST = "action_user_admin_orders_list"
+	ACTION_ORDER_ADMIN_INFO = "action_user_admin_orders_info"
 
 	// ###### Order ######
 
@@ -197,500 +290,972 @@ const (
 	// ###### Color ######
 )
 
+// Action is a node of the permissions tree, the groups have children and the permissions have codes
 type Action struct {
-	Name     string   `json:"name,omitempty"`
-	Code     string   `json:"code,omitempty"`
-	Children []Action `json:"children,omitempty"`
+	Name        string                `json:"name,omitempty"`
+	Code        string                `json:"code,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Scopes      []PermissionScopeType `json:"scopes,omitempty"`
+	Children    []Action              `json:"children,omitempty"`
 }
 
+// GetPermissionsTree returns the registered permissions grouped in the order of their registration
 func GetPermissionsTree() []Action {
-	actions := []Action{
-		{
-			Name: "Login Permissions",
-			Children: []Action{
-				{
-					Name: "Ability to log in to admin panel with this role",
-					Code: ACTION_CAN_LOGIN_ADMIN,
-				},
-				{
-					Name: "Ability to log in to user panel with this role",
-					Code: ACTION_CAN_LOGIN_USER,
-				},
+	groups := PermissionGroups()
+	actions := make([]Action, len(groups))
+
+	for i, group := range groups {
+		actions[i] = Action{
+			Name:        group.Name,
+			Description: group.Description,
+			Children:    make([]Action, len(group.Permissions)),
+		}
+
+		for j, permission := range group.Permissions {
+			actions[i].Children[j] = Action{
+				Name:        permission.Name,
+				Code:        permission.Code,
+				Description: permission.Description,
+				Scopes:      permission.Scopes,
+			}
+		}
+	}
+
+	return actions
+}
+
+func init() {
+	RegisterPermissions(PermissionGroup{
+		Name:        "Login Permissions",
+		Description: "The panels which the users of the role can log in to",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_CAN_LOGIN_ADMIN,
+				Name:        "Ability to log in to admin panel with this role",
+				Description: "The users of the role can log in to the admin panel",
+			},
+			{
+				Code:        ACTION_CAN_LOGIN_USER,
+				Name:        "Ability to log in to user panel with this role",
+				Description: "The users of the role can log in to the user panel",
 			},
 		},
-		{
-			Name: "Categories",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Categories",
-					Code: ACTION_CATEGORY_ADMIN_LIST,
-				},
-				{
-					Name: "Create category",
-					Code: ACTION_CATEGORY_ADMIN_CREATE,
-				},
-				{
-					Name: "Update category",
-					Code: ACTION_CATEGORY_ADMIN_UPDATE,
-				},
-				{
-					Name: "Category information",
-					Code: ACTION_CATEGORY_ADMIN_INFO,
-				},
-				{
-					Name: "Delete category",
-					Code: ACTION_CATEGORY_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Categories",
+		Description: "The product categories, the permissions can be limited to some categories",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_CATEGORY_ADMIN_LIST,
+				Name:        "Categories",
+				Description: "List the categories",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory},
+			},
+			{
+				Code:        ACTION_CATEGORY_ADMIN_CREATE,
+				Name:        "Create category",
+				Description: "Create categories",
+			},
+			{
+				Code:        ACTION_CATEGORY_ADMIN_UPDATE,
+				Name:        "Update category",
+				Description: "Edit the categories",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory},
+			},
+			{
+				Code:        ACTION_CATEGORY_ADMIN_INFO,
+				Name:        "Category information",
+				Description: "View the details of the categories",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory},
+			},
+			{
+				Code:        ACTION_CATEGORY_ADMIN_DELETE,
+				Name:        "Delete category",
+				Description: "Delete the categories",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory},
 			},
 		},
-		{
-			Name: "Brands",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Brands",
-					Code: ACTION_BRAND_ADMIN_LIST,
-				},
-				{
-					Name: "Create brand",
-					Code: ACTION_BRAND_ADMIN_CREATE,
-				},
-				{
-					Name: "Update brand",
-					Code: ACTION_BRAND_ADMIN_UPDATE,
-				},
-				{
-					Name: "Brand information",
-					Code: ACTION_BRAND_ADMIN_INFO,
-				},
-				{
-					Name: "Delete brand",
-					Code: ACTION_BRAND_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Brands",
+		Description: "The product brands, the permissions can be limited to some brands",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_BRAND_ADMIN_LIST,
+				Name:        "Brands",
+				Description: "List the brands",
+				Scopes:      []PermissionScopeType{PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_BRAND_ADMIN_CREATE,
+				Name:        "Create brand",
+				Description: "Create brands",
+			},
+			{
+				Code:        ACTION_BRAND_ADMIN_UPDATE,
+				Name:        "Update brand",
+				Description: "Edit the brands",
+				Scopes:      []PermissionScopeType{PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_BRAND_ADMIN_INFO,
+				Name:        "Brand information",
+				Description: "View the details of the brands",
+				Scopes:      []PermissionScopeType{PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_BRAND_ADMIN_DELETE,
+				Name:        "Delete brand",
+				Description: "Delete the brands",
+				Scopes:      []PermissionScopeType{PermissionScopeBrand},
 			},
 		},
-		{
-			Name: "Roles",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Roles",
-					Code: ACTION_ROLE_ADMIN_LIST,
-				},
-				{
-					Name: "Create role",
-					Code: ACTION_ROLE_ADMIN_CREATE,
-				},
-				{
-					Name: "Update role",
-					Code: ACTION_ROLE_ADMIN_UPDATE,
-				},
-				{
-					Name: "Role information",
-					Code: ACTION_ROLE_ADMIN_INFO,
-				},
-				{
-					Name: "Delete role",
-					Code: ACTION_ROLE_ADMIN_DELETE,
-				},
-				{
-					Name: "Permissions",
-					Code: ACTION_ROLE_ADMIN_PERMISSIONS,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Roles",
+		Description: "The roles and their permissions",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_ROLE_ADMIN_LIST,
+				Name:        "Roles",
+				Description: "List the roles",
+			},
+			{
+				Code:        ACTION_ROLE_ADMIN_CREATE,
+				Name:        "Create role",
+				Description: "Create roles",
+			},
+			{
+				Code:        ACTION_ROLE_ADMIN_UPDATE,
+				Name:        "Update role",
+				Description: "Edit the roles",
+			},
+			{
+				Code:        ACTION_ROLE_ADMIN_INFO,
+				Name:        "Role information",
+				Description: "View the details of the roles",
+			},
+			{
+				Code:        ACTION_ROLE_ADMIN_DELETE,
+				Name:        "Delete role",
+				Description: "Delete the roles",
+			},
+			{
+				Code:        ACTION_ROLE_ADMIN_PERMISSIONS,
+				Name:        "Permissions",
+				Description: "View the permissions which can be granted to the roles",
 			},
 		},
-		{
-			Name: "Products",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Products",
-					Code: ACTION_PRODUCT_ADMIN_LIST,
-				},
-				{
-					Name: "Create product",
-					Code: ACTION_PRODUCT_ADMIN_CREATE,
-				},
-				{
-					Name: "Update product",
-					Code: ACTION_PRODUCT_ADMIN_UPDATE,
-				},
-				{
-					Name: "product information",
-					Code: ACTION_PRODUCT_ADMIN_INFO,
-				},
-				{
-					Name: "Delete product",
-					Code: ACTION_PRODUCT_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Products",
+		Description: "The products, the permissions can be limited to the products of some categories and brands",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_PRODUCT_ADMIN_LIST,
+				Name:        "Products",
+				Description: "List the products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ADMIN_CREATE,
+				Name:        "Create product",
+				Description: "Create products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ADMIN_UPDATE,
+				Name:        "Update product",
+				Description: "Edit the products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ADMIN_INFO,
+				Name:        "product information",
+				Description: "View the details of the products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ADMIN_DELETE,
+				Name:        "Delete product",
+				Description: "Delete the products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ADMIN_IMPORT,
+				Name:        "Import products",
+				Description: "Import the products from a spreadsheet and view the imports",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ADMIN_EXPORT,
+				Name:        "Export products",
+				Description: "Export the products to a spreadsheet",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ADMIN_REVIEW,
+				Name:        "Approve or reject product revisions",
+				Description: "Approve or reject the drafted revisions of the products",
 			},
 		},
-		{
-			Name: "Colors",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Colors",
-					Code: ACTION_COLOR_ADMIN_LIST,
-				},
-				{
-					Name: "Create color",
-					Code: ACTION_COLOR_ADMIN_CREATE,
-				},
-				{
-					Name: "Update color",
-					Code: ACTION_COLOR_ADMIN_UPDATE,
-				},
-				{
-					Name: "Color information",
-					Code: ACTION_COLOR_ADMIN_INFO,
-				},
-				{
-					Name: "Delete color",
-					Code: ACTION_COLOR_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Product Relations",
+		Description: "The related, accessory and alternative products",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_PRODUCT_RELATION_ADMIN_LIST,
+				Name:        "List related products",
+				Description: "List the related products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_RELATION_ADMIN_CREATE,
+				Name:        "Link related products",
+				Description: "Link the related products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_RELATION_ADMIN_DELETE,
+				Name:        "Unlink related products",
+				Description: "Unlink the related products",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
 			},
 		},
-		{
-			Name: "App Pics",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "App Pics",
-					Code: ACTION_APP_PIC_ADMIN_LIST,
-				},
-				{
-					Name: "Create app pic",
-					Code: ACTION_APP_PIC_ADMIN_CREATE,
-				},
-				{
-					Name: "Update app pic",
-					Code: ACTION_APP_PIC_ADMIN_UPDATE,
-				},
-				{
-					Name: "App pic information",
-					Code: ACTION_APP_PIC_ADMIN_INFO,
-				},
-				{
-					Name: "Delete app pic",
-					Code: ACTION_APP_PIC_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Bundles",
+		Description: "The product bundles which are sold together",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_BUNDLE_ADMIN_LIST,
+				Name:        "Bundles",
+				Description: "List the bundles",
+			},
+			{
+				Code:        ACTION_BUNDLE_ADMIN_CREATE,
+				Name:        "Create bundle",
+				Description: "Create bundles",
+			},
+			{
+				Code:        ACTION_BUNDLE_ADMIN_UPDATE,
+				Name:        "Update bundle",
+				Description: "Edit the bundles",
+			},
+			{
+				Code:        ACTION_BUNDLE_ADMIN_INFO,
+				Name:        "Bundle info",
+				Description: "View the details of the bundles",
+			},
+			{
+				Code:        ACTION_BUNDLE_ADMIN_DELETE,
+				Name:        "Delete bundle",
+				Description: "Delete the bundles",
 			},
 		},
-		{
-			Name: "Product Feature Categories",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Product Feature Categories",
-					Code: ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_LIST,
-				},
-				{
-					Name: "Create product feature category",
-					Code: ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_CREATE,
-				},
-				{
-					Name: "Update product feature category",
-					Code: ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_UPDATE,
-				},
-				{
-					Name: "Product feature category information",
-					Code: ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_INFO,
-				},
-				{
-					Name: "Delete product feature category",
-					Code: ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Warehouses",
+		Description: "The warehouses and their stocks",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_WAREHOUSE_ADMIN_LIST,
+				Name:        "Warehouses",
+				Description: "List the warehouses",
+			},
+			{
+				Code:        ACTION_WAREHOUSE_ADMIN_CREATE,
+				Name:        "Create warehouse",
+				Description: "Create warehouses",
+			},
+			{
+				Code:        ACTION_WAREHOUSE_ADMIN_UPDATE,
+				Name:        "Update warehouse",
+				Description: "Edit the warehouses",
+			},
+			{
+				Code:        ACTION_WAREHOUSE_ADMIN_INFO,
+				Name:        "Warehouse info",
+				Description: "View the details of the warehouses",
+			},
+			{
+				Code:        ACTION_WAREHOUSE_ADMIN_DELETE,
+				Name:        "Delete warehouse",
+				Description: "Delete the warehouses",
+			},
+			{
+				Code:        ACTION_WAREHOUSE_ADMIN_STOCK,
+				Name:        "Warehouse stocks",
+				Description: "View the stocks of the items in the warehouses",
+			},
+			{
+				Code:        ACTION_WAREHOUSE_ADMIN_ADJUST,
+				Name:        "Adjust warehouse stock",
+				Description: "Change the stock of an item in a warehouse",
 			},
 		},
-		{
-			Name: "Product Feature Keys",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Product Feature Keys",
-					Code: ACTION_PRODUCT_FEATURE_KEY_ADMIN_LIST,
-				},
-				{
-					Name: "Create product feature key",
-					Code: ACTION_PRODUCT_FEATURE_KEY_ADMIN_CREATE,
-				},
-				{
-					Name: "Update product feature key",
-					Code: ACTION_PRODUCT_FEATURE_KEY_ADMIN_UPDATE,
-				},
-				{
-					Name: "Product feature key information",
-					Code: ACTION_PRODUCT_FEATURE_KEY_ADMIN_INFO,
-				},
-				{
-					Name: "Delete product feature key",
-					Code: ACTION_PRODUCT_FEATURE_KEY_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Stock Transfers",
+		Description: "The transfers of the stocks between the warehouses",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_STOCK_TRANSFER_ADMIN_LIST,
+				Name:        "Stock transfers",
+				Description: "List the stock transfers",
+			},
+			{
+				Code:        ACTION_STOCK_TRANSFER_ADMIN_CREATE,
+				Name:        "Create stock transfer",
+				Description: "Create stock transfers",
+			},
+			{
+				Code:        ACTION_STOCK_TRANSFER_ADMIN_INFO,
+				Name:        "Stock transfer info",
+				Description: "View the details of the stock transfers",
+			},
+			{
+				Code:        ACTION_STOCK_TRANSFER_ADMIN_COMPLETE,
+				Name:        "Complete stock transfer",
+				Description: "Complete a stock transfer and move its stocks",
+			},
+			{
+				Code:        ACTION_STOCK_TRANSFER_ADMIN_CANCEL,
+				Name:        "Cancel stock transfer",
+				Description: "Cancel a pending stock transfer",
 			},
 		},
-		{
-			Name: "Product Feature Values",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Product Feature Values",
-					Code: ACTION_PRODUCT_FEATURE_VALUE_ADMIN_LIST,
-				},
-				{
-					Name: "Create product feature value",
-					Code: ACTION_PRODUCT_FEATURE_VALUE_ADMIN_CREATE,
-				},
-				{
-					Name: "Product feature value information",
-					Code: ACTION_PRODUCT_FEATURE_VALUE_ADMIN_INFO,
-				},
-				{
-					Name: "Delete product feature value",
-					Code: ACTION_PRODUCT_FEATURE_VALUE_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Stock Movements",
+		Description: "The ledger of the stock changes",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_STOCK_MOVEMENT_ADMIN_LIST,
+				Name:        "Stock movements",
+				Description: "List the stock movements",
+			},
+			{
+				Code:        ACTION_STOCK_MOVEMENT_ADMIN_RECONCILE,
+				Name:        "Reconcile stock ledger",
+				Description: "Compare the stock ledger with the stocks of the items",
 			},
 		},
-		{
-			Name: "Files",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Files",
-					Code: ACTION_FILE_LIST,
-				},
-				{
-					Name: "Upload file",
-					Code: ACTION_FILE_UPLOAD,
-				},
-				{
-					Name: "Download File",
-					Code: ACTION_FILE_DOWNLOAD,
-				},
-				{
-					Name: "Delete file",
-					Code: ACTION_FILE_DELETE,
-				},
-				{
-					Name: "Change file priority",
-					Code: ACTION_FILE_CHANGE_PRIORITY,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Product Items",
+		Description: "The sellable items of the products",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_PRODUCT_ITEM_ADMIN_LOOKUP,
+				Name:        "Lookup product item by SKU or barcode",
+				Description: "Find a product item by its SKU or barcode",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ITEM_ADMIN_BARCODE,
+				Name:        "Generate product item barcode",
+				Description: "Generate the barcode of a product item",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_ITEM_ADMIN_PRICE,
+				Name:        "Product item price history and schedules",
+				Description: "View the price history of the items and schedule their prices",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
 			},
 		},
-		{
-			Name: "Systematic Files",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Systematic Files",
-					Code: ACTION_FILE_SYSTEMATIC_LIST,
-				},
-				{
-					Name: "Upload systematic file",
-					Code: ACTION_FILE_SYSTEMATIC_UPLOAD,
-				},
-				{
-					Name: "Systematic file information",
-					Code: ACTION_FILE_SYSTEMATIC_DOWNLOAD,
-				},
-				{
-					Name: "Delete systematic file",
-					Code: ACTION_FILE_SYSTEMATIC_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Colors",
+		Description: "The colors of the product items",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_COLOR_ADMIN_LIST,
+				Name:        "Colors",
+				Description: "List the colors",
+			},
+			{
+				Code:        ACTION_COLOR_ADMIN_CREATE,
+				Name:        "Create color",
+				Description: "Create colors",
+			},
+			{
+				Code:        ACTION_COLOR_ADMIN_UPDATE,
+				Name:        "Update color",
+				Description: "Edit the colors",
+			},
+			{
+				Code:        ACTION_COLOR_ADMIN_INFO,
+				Name:        "Color information",
+				Description: "View the details of the colors",
+			},
+			{
+				Code:        ACTION_COLOR_ADMIN_DELETE,
+				Name:        "Delete color",
+				Description: "Delete the colors",
 			},
 		},
-		{
-			Name: "Product File Map",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Product File Map",
-					Code: ACTION_FILE_PRODUCT_LIST,
-				},
-				{
-					Name: "Upload product file map",
-					Code: ACTION_FILE_PRODUCT_UPLOAD,
-				},
-				{
-					Name: "Product file map information",
-					Code: ACTION_FILE_PRODUCT_DOWNLOAD,
-				},
-				{
-					Name: "Delete product file map",
-					Code: ACTION_FILE_PRODUCT_DELETE,
-				},
-				{
-					Name: "Change product file map priority",
-					Code: ACTION_FILE_PRODUCT_CHANGE_PRIORITY,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "App Pics",
+		Description: "The pictures of the application pages",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_APP_PIC_ADMIN_LIST,
+				Name:        "App Pics",
+				Description: "List the app pics",
+			},
+			{
+				Code:        ACTION_APP_PIC_ADMIN_CREATE,
+				Name:        "Create app pic",
+				Description: "Create app pics",
+			},
+			{
+				Code:        ACTION_APP_PIC_ADMIN_UPDATE,
+				Name:        "Update app pic",
+				Description: "Edit the app pics",
+			},
+			{
+				Code:        ACTION_APP_PIC_ADMIN_INFO,
+				Name:        "App pic information",
+				Description: "View the details of the app pics",
+			},
+			{
+				Code:        ACTION_APP_PIC_ADMIN_DELETE,
+				Name:        "Delete app pic",
+				Description: "Delete the app pics",
 			},
 		},
-		{
-			Name: "Brand Files",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Brand Files",
-					Code: ACTION_FILE_BRAND_LIST,
-				},
-				{
-					Name: "Upload brand file",
-					Code: ACTION_FILE_BRAND_UPLOAD,
-				},
-				{
-					Name: "Brand file information",
-					Code: ACTION_FILE_BRAND_DOWNLOAD,
-				},
-				{
-					Name: "Delete brand file",
-					Code: ACTION_FILE_BRAND_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Product Feature Categories",
+		Description: "The categories of the product features",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_LIST,
+				Name:        "Product Feature Categories",
+				Description: "List the product feature categories",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_CREATE,
+				Name:        "Create product feature category",
+				Description: "Create product feature categories",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_UPDATE,
+				Name:        "Update product feature category",
+				Description: "Edit the product feature categories",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_INFO,
+				Name:        "Product feature category information",
+				Description: "View the details of the product feature categories",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_CATEGORY_ADMIN_DELETE,
+				Name:        "Delete product feature category",
+				Description: "Delete the product feature categories",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Product Feature Keys",
+		Description: "The keys of the product features",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_PRODUCT_FEATURE_KEY_ADMIN_LIST,
+				Name:        "Product Feature Keys",
+				Description: "List the product feature keys",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_KEY_ADMIN_CREATE,
+				Name:        "Create product feature key",
+				Description: "Create product feature keys",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_KEY_ADMIN_UPDATE,
+				Name:        "Update product feature key",
+				Description: "Edit the product feature keys",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_KEY_ADMIN_INFO,
+				Name:        "Product feature key information",
+				Description: "View the details of the product feature keys",
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_KEY_ADMIN_DELETE,
+				Name:        "Delete product feature key",
+				Description: "Delete the product feature keys",
 			},
 		},
-		{
-			Name: "App Pic Files",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "App Pic Files",
-					Code: ACTION_FILE_APP_PIC_LIST,
-				},
-				{
-					Name: "Upload app pic file",
-					Code: ACTION_FILE_APP_PIC_UPLOAD,
-				},
-				{
-					Name: "App pic file information",
-					Code: ACTION_FILE_APP_PIC_DOWNLOAD,
-				},
-				{
-					Name: "Delete app pic file",
-					Code: ACTION_FILE_APP_PIC_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Product Feature Values",
+		Description: "The values of the product features",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_PRODUCT_FEATURE_VALUE_ADMIN_LIST,
+				Name:        "Product Feature Values",
+				Description: "List the product feature values",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_VALUE_ADMIN_CREATE,
+				Name:        "Create product feature value",
+				Description: "Create product feature values",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_VALUE_ADMIN_INFO,
+				Name:        "Product feature value information",
+				Description: "View the details of the product feature values",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_PRODUCT_FEATURE_VALUE_ADMIN_DELETE,
+				Name:        "Delete product feature value",
+				Description: "Delete the product feature values",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
 			},
 		},
-		{
-			Name: "Verification Codes",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Verification Codes",
-					Code: ACTION_VERIFICATION_CODE_ADMIN_LIST,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Files",
+		Description: "The uploaded files of every type",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_FILE_LIST,
+				Name:        "Files",
+				Description: "List the files",
+			},
+			{
+				Code:        ACTION_FILE_UPLOAD,
+				Name:        "Upload file",
+				Description: "Upload files",
+			},
+			{
+				Code:        ACTION_FILE_DOWNLOAD,
+				Name:        "Download File",
+				Description: "Download the files",
+			},
+			{
+				Code:        ACTION_FILE_DELETE,
+				Name:        "Delete file",
+				Description: "Delete the files",
+			},
+			{
+				Code:        ACTION_FILE_CHANGE_PRIORITY,
+				Name:        "Change file priority",
+				Description: "Change the order of the files",
 			},
 		},
-		{
-			Name: "Address",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Address",
-					Code: ACTION_ADDRESS_ADMIN_LIST,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Systematic Files",
+		Description: "The files which are used by the system",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_FILE_SYSTEMATIC_LIST,
+				Name:        "Systematic Files",
+				Description: "List the systematic files",
+			},
+			{
+				Code:        ACTION_FILE_SYSTEMATIC_UPLOAD,
+				Name:        "Upload systematic file",
+				Description: "Upload systematic files",
+			},
+			{
+				Code:        ACTION_FILE_SYSTEMATIC_DOWNLOAD,
+				Name:        "Systematic file information",
+				Description: "Download the systematic files",
+			},
+			{
+				Code:        ACTION_FILE_SYSTEMATIC_DELETE,
+				Name:        "Delete systematic file",
+				Description: "Delete the systematic files",
 			},
 		},
-		{
-			Name: "Comments",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Comments",
-					Code: ACTION_COMMENT_ADMIN_LIST,
-				},
-				{
-					Name: "Change comment status",
-					Code: ACTION_COMMENT_ADMIN_CHANGE_STATUS,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Product File Map",
+		Description: "The images of the products",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_FILE_PRODUCT_LIST,
+				Name:        "Product File Map",
+				Description: "List the product images",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_FILE_PRODUCT_UPLOAD,
+				Name:        "Upload product file map",
+				Description: "Upload product images",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_FILE_PRODUCT_DOWNLOAD,
+				Name:        "Product file map information",
+				Description: "Download the product images",
+			},
+			{
+				Code:        ACTION_FILE_PRODUCT_DELETE,
+				Name:        "Delete product file map",
+				Description: "Delete the product images",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_FILE_PRODUCT_CHANGE_PRIORITY,
+				Name:        "Change product file map priority",
+				Description: "Change the order of the product images",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
 			},
 		},
-		{
-			Name: "Reports",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Watch revenue per category",
-					Code: ACTION_REPORT_ADMIN_REVENUE_BY_CATEGORY,
-				},
-				{
-					Name: "Watch sells chart",
-					Code: ACTION_REPORT_ADMIN_SELLS_CHART,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Brand Files",
+		Description: "The logos of the brands",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_FILE_BRAND_LIST,
+				Name:        "Brand Files",
+				Description: "List the brand files",
+			},
+			{
+				Code:        ACTION_FILE_BRAND_UPLOAD,
+				Name:        "Upload brand file",
+				Description: "Upload brand files",
+			},
+			{
+				Code:        ACTION_FILE_BRAND_DOWNLOAD,
+				Name:        "Brand file information",
+				Description: "Download the brand files",
+			},
+			{
+				Code:        ACTION_FILE_BRAND_DELETE,
+				Name:        "Delete brand file",
+				Description: "Delete the brand files",
 			},
 		},
-		{
-			Name: "Users",
-			Code: "",
-			Children: []Action{
-				{
-					Name: "Users",
-					Code: ACTION_USER_ADMIN_LIST,
-				},
-				{
-					Name: "Create user",
-					Code: ACTION_USER_ADMIN_CREATE,
-				},
-				{
-					Name: "Update user",
-					Code: ACTION_USER_ADMIN_UPDATE,
-				},
-				{
-					Name: "User information",
-					Code: ACTION_USER_ADMIN_INFO,
-				},
-				{
-					Name: "Delete user",
-					Code: ACTION_USER_ADMIN_DELETE,
-				},
-				{
-					Name: "Recovery user password",
-					Code: ACTION_USER_ADMIN_RECOVERY_PASSWORD,
-				},
-				{
-					Name: "User orders",
-					Code: ACTION_USER_ADMIN_ORDER_LIST,
-				},
-				{
-					Name: "User favorite products",
-					Code: ACTION_USER_ADMIN_FAVORITE_PRODUCT_LIST,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "App Pic Files",
+		Description: "The images of the app pics",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_FILE_APP_PIC_LIST,
+				Name:        "App Pic Files",
+				Description: "List the app pic files",
+			},
+			{
+				Code:        ACTION_FILE_APP_PIC_UPLOAD,
+				Name:        "Upload app pic file",
+				Description: "Upload app pic files",
+			},
+			{
+				Code:        ACTION_FILE_APP_PIC_DOWNLOAD,
+				Name:        "App pic file information",
+				Description: "Download the app pic files",
+			},
+			{
+				Code:        ACTION_FILE_APP_PIC_DELETE,
+				Name:        "Delete app pic file",
+				Description: "Delete the app pic files",
 			},
 		},
-		{
-			Name: "Orders",
-			Children: []Action{
-				{
-					Name: "Orders",
-					Code: ACTION_ORDER_ADMIN_LIST,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Barcode Files",
+		Description: "The generated barcode images of the product items",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_FILE_BARCODE_LIST,
+				Name:        "Barcode Files",
+				Description: "List the barcode files",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_FILE_BARCODE_UPLOAD,
+				Name:        "Upload barcode file",
+				Description: "Upload barcode files",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
+			},
+			{
+				Code:        ACTION_FILE_BARCODE_DOWNLOAD,
+				Name:        "Barcode file information",
+				Description: "Download the barcode files",
+			},
+			{
+				Code:        ACTION_FILE_BARCODE_DELETE,
+				Name:        "Delete barcode file",
+				Description: "Delete the barcode files",
+				Scopes:      []PermissionScopeType{PermissionScopeCategory, PermissionScopeBrand},
 			},
 		},
-		{
-			Name: "Discounts",
-			Children: []Action{
-				{
-					Name: "Discounts",
-					Code: ACTION_DISCOUNT_ADMIN_LIST,
-				},
-				{
-					Name: "Create discount",
-					Code: ACTION_DISCOUNT_ADMIN_CREATE,
-				},
-				{
-					Name: "Update discount",
-					Code: ACTION_DISCOUNT_ADMIN_UPDATE,
-				},
-				{
-					Name: "Discount information",
-					Code: ACTION_DISCOUNT_ADMIN_INFO,
-				},
-				{
-					Name: "Delete discount",
-					Code: ACTION_DISCOUNT_ADMIN_DELETE,
-				},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Review Files",
+		Description: "The images which are attached to the reviews",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_FILE_REVIEW_LIST,
+				Name:        "Review Files",
+				Description: "List the review files",
+			},
+			{
+				Code:        ACTION_FILE_REVIEW_UPLOAD,
+				Name:        "Upload review file",
+				Description: "Upload review files",
+			},
+			{
+				Code:        ACTION_FILE_REVIEW_DOWNLOAD,
+				Name:        "Review file information",
+				Description: "Download the review files",
+			},
+			{
+				Code:        ACTION_FILE_REVIEW_DELETE,
+				Name:        "Delete review file",
+				Description: "Delete the review files",
+			},
+			{
+				Code:        ACTION_FILE_REVIEW_CHANGE_PRIORITY,
+				Name:        "Change review file priority",
+				Description: "Change the order of the review images",
 			},
 		},
-	}
-
-	return actions
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Verification Codes",
+		Description: "The sent verification codes",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_VERIFICATION_CODE_ADMIN_LIST,
+				Name:        "Verification Codes",
+				Description: "List the sent verification codes",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Address",
+		Description: "The addresses of the users",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_ADDRESS_ADMIN_LIST,
+				Name:        "Address",
+				Description: "List the addresses of the users",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Comments",
+		Description: "The reviews of the products",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_COMMENT_ADMIN_LIST,
+				Name:        "Comments",
+				Description: "List the comments",
+			},
+			{
+				Code:        ACTION_COMMENT_ADMIN_CHANGE_STATUS,
+				Name:        "Change comment status",
+				Description: "Approve or reject the reviews",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Product Questions",
+		Description: "The questions and answers of the products",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_QUESTION_ADMIN_LIST,
+				Name:        "Questions and answers",
+				Description: "List the product questions",
+			},
+			{
+				Code:        ACTION_QUESTION_ADMIN_CHANGE_STATUS,
+				Name:        "Change question and answer status",
+				Description: "Approve or reject the questions and answers",
+			},
+			{
+				Code:        ACTION_QUESTION_ADMIN_ANSWER,
+				Name:        "Answer questions",
+				Description: "Answer the questions of the users",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Reports",
+		Description: "The sales and stock reports",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_REPORT_ADMIN_REVENUE_BY_CATEGORY,
+				Name:        "Watch revenue per category",
+				Description: "View the revenue of every category",
+			},
+			{
+				Code:        ACTION_REPORT_ADMIN_SELLS_CHART,
+				Name:        "Watch sells chart",
+				Description: "View the chart of the sales",
+			},
+			{
+				Code:        ACTION_REPORT_ADMIN_LOW_STOCK,
+				Name:        "Watch low stock items",
+				Description: "View the items which are running out of stock",
+			},
+			{
+				Code:        ACTION_REPORT_ADMIN_AT_RISK,
+				Name:        "Watch at risk items",
+				Description: "View the items which will run out of stock soon by their sales",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Users",
+		Description: "The users, their sessions and their security",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_USER_ADMIN_LIST,
+				Name:        "Users",
+				Description: "List the users",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_CREATE,
+				Name:        "Create user",
+				Description: "Create users",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_UPDATE,
+				Name:        "Update user",
+				Description: "Edit the users",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_INFO,
+				Name:        "User information",
+				Description: "View the details of the users",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_DELETE,
+				Name:        "Delete user",
+				Description: "Delete the users",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_RECOVERY_PASSWORD,
+				Name:        "Recovery user password",
+				Description: "Send the password recovery of a user",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_ORDER_LIST,
+				Name:        "User orders",
+				Description: "View the orders of a user",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_FAVORITE_PRODUCT_LIST,
+				Name:        "User favorite products",
+				Description: "View the favorite products of a user",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_SESSION_LIST,
+				Name:        "User sessions and login history",
+				Description: "View the sessions and the login history of a user",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_FORCE_LOGOUT,
+				Name:        "Force logout user",
+				Description: "Revoke the sessions of a user",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_MFA_RESET,
+				Name:        "Reset user two-factor authentication",
+				Description: "Disable the two-factor authentication of a user who lost their authenticator",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_UNLOCK,
+				Name:        "Unlock user login",
+				Description: "Unlock a user who is locked out by failed logins",
+			},
+			{
+				Code:        ACTION_USER_ADMIN_PERMISSIONS,
+				Name:        "User effective permissions",
+				Description: "View the permissions which a user has by their roles and the roles they inherit",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Orders",
+		Description: "The orders of the users",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_ORDER_ADMIN_LIST,
+				Name:        "Orders",
+				Description: "List the orders",
+			},
+			{
+				Code:        ACTION_ORDER_ADMIN_INFO,
+				Name:        "Order items",
+				Description: "View the items of an order",
+			},
+		},
+	})
+
+	RegisterPermissions(PermissionGroup{
+		Name:        "Discounts",
+		Description: "The discount codes",
+		Permissions: []Permission{
+			{
+				Code:        ACTION_DISCOUNT_ADMIN_LIST,
+				Name:        "Discounts",
+				Description: "List the discounts",
+			},
+			{
+				Code:        ACTION_DISCOUNT_ADMIN_CREATE,
+				Name:        "Create discount",
+				Description: "Create discounts",
+			},
+			{
+				Code:        ACTION_DISCOUNT_ADMIN_UPDATE,
+				Name:        "Update discount",
+				Description: "Edit the discounts",
+			},
+			{
+				Code:        ACTION_DISCOUNT_ADMIN_INFO,
+				Name:        "Discount information",
+				Description: "View the details of the discounts",
+			},
+			{
+				Code:        ACTION_DISCOUNT_ADMIN_DELETE,
+				Name:        "Delete discount",
+				Description: "Delete the discounts",
+			},
+		},
+	})
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	datatypes "github.com/esmailemami/eshop/models/data_types"
 	"github.com/google/uuid"
 )
@@ -23,8 +25,17 @@ type Product struct {
 	Comments             []Comment             `gorm:"foreignKey:product_id;references:id"    json:"comments"`
 	TopFeatures          datatypes.StringArray `gorm:"column:top_features"                    json:"topFeatures"`
 	Rate                 float64               `gorm:"column:rate"                            json:"rate"`
+	Status               ProductStatus         `gorm:"column:status"                          json:"status"`
+	PublishAt            *time.Time            `gorm:"column:publish_at"                      json:"publishAt"`
+	UnpublishAt          *time.Time            `gorm:"column:unpublish_at"                    json:"unpublishAt"`
+	ReviewedAt           *time.Time            `gorm:"column:reviewed_at"                     json:"reviewedAt"`
 }
 
 func (Product) TableName() string {
 	return "product"
 }
+
+// PermissionResource returns the attributes which the permission scopes are checked against
+func (model Product) PermissionResource() PermissionResource {
+	return PermissionResource{CategoryID: &model.CategoryID, BrandID: &model.BrandID}
+}
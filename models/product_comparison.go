@@ -0,0 +1,15 @@
+package models
+
+import datatypes "github.com/esmailemami/eshop/models/data_types"
+
+// ProductComparison is a comparison list which is saved by a user, the owner is the creator
+type ProductComparison struct {
+	Model
+
+	Name       string                `gorm:"column:name"          json:"name"`
+	ProductIDs datatypes.StringArray `gorm:"column:product_ids"   json:"productIds"`
+}
+
+func (ProductComparison) TableName() string {
+	return "product_comparison"
+}
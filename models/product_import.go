@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProductImport struct {
+	Model
+
+	OriginalName  string               `gorm:"column:original_name"                        json:"originalName"`
+	FilePath      string               `gorm:"column:file_path"                            json:"-"`
+	Format        string               `gorm:"column:format"                               json:"format"`
+	DryRun        bool                 `gorm:"column:dry_run"                              json:"dryRun"`
+	Status        ProductImportStatus  `gorm:"column:status"                               json:"status"`
+	TotalRows     int                  `gorm:"column:total_rows"                           json:"totalRows"`
+	SucceededRows int                  `gorm:"column:succeeded_rows"                       json:"succeededRows"`
+	FailedRows    int                  `gorm:"column:failed_rows"                          json:"failedRows"`
+	Message       *string              `gorm:"column:message"                              json:"message"`
+	StartedAt     *time.Time           `gorm:"column:started_at"                           json:"startedAt"`
+	FinishedAt    *time.Time           `gorm:"column:finished_at"                          json:"finishedAt"`
+	Errors        []ProductImportError `gorm:"foreignKey:product_import_id;references:id" json:"errors"`
+}
+
+func (ProductImport) TableName() string {
+	return "product_import"
+}
+
+type ProductImportError struct {
+	BasicModel
+
+	ProductImportID uuid.UUID `gorm:"column:product_import_id" json:"productImportId"`
+	Row             int       `gorm:"column:row"               json:"row"`
+	Column          string    `gorm:"column:column"            json:"column"`
+	Message         string    `gorm:"column:message"           json:"message"`
+}
+
+func (ProductImportError) TableName() string {
+	return "product_import_error"
+}
+
+type ProductImportStatus int
+
+const (
+	ProductImportStatusPending ProductImportStatus = iota
+	ProductImportStatusRunning
+	ProductImportStatusDone
+	ProductImportStatusFailed
+)
+
+func (s ProductImportStatus) String() string {
+	switch s {
+	case ProductImportStatusPending:
+		return "Pending"
+	case ProductImportStatusRunning:
+		return "Running"
+	case ProductImportStatusDone:
+		return "Done"
+	case ProductImportStatusFailed:
+		return "Failed"
+	default:
+		return "unknown"
+	}
+}
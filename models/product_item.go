@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type ProductItem struct {
 	Model
@@ -16,6 +20,24 @@ type ProductItem struct {
 	OrderItems     []OrderItem           `gorm:"foreignKey:product_item_id;references:id"        json:"orderItems"`
 	Favorites      []FavoriteProductItem `gorm:"foreignKey:product_item_id;references:id"        json:"favorites"`
 	Discounts      []Discount            `gorm:"foreignKey:product_item_id;references:id"        json:"discounts"`
+
+	// weight is in grams and dimensions are in centimeters
+	SKU           *string    `gorm:"column:sku"                                      json:"sku"`
+	Barcode       *string    `gorm:"column:barcode"                                  json:"barcode"`
+	Weight        *float64   `gorm:"column:weight"                                   json:"weight"`
+	Length        *float64   `gorm:"column:length"                                   json:"length"`
+	Width         *float64   `gorm:"column:width"                                    json:"width"`
+	Height        *float64   `gorm:"column:height"                                   json:"height"`
+	BarcodeFileID *uuid.UUID `gorm:"column:barcode_file_id"                          json:"barcodeFileId"`
+	BarcodeFile   *File      `gorm:"foreignKey:barcode_file_id;references:id"        json:"barcodeFile"`
+
+	// the item is visible between publish at and unpublish at, the scheduler applies them to the status
+	PublishAt   *time.Time `gorm:"column:publish_at"                               json:"publishAt"`
+	UnpublishAt *time.Time `gorm:"column:unpublish_at"                             json:"unpublishAt"`
+
+	// the threshold of the item overrides the threshold of the category, the alerted at is cleared when the item is restocked
+	ReorderThreshold  *int       `gorm:"column:reorder_threshold"                        json:"reorderThreshold"`
+	LowStockAlertedAt *time.Time `gorm:"column:low_stock_alerted_at"                     json:"lowStockAlertedAt"`
 }
 
 func (ProductItem) TableName() string {
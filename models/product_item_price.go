@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductItemPrice is a record of the price history of a product item, it is written on every price change
+type ProductItemPrice struct {
+	Model
+
+	ProductItemID uuid.UUID         `gorm:"column:product_item_id"                   json:"productItemId"`
+	ProductItem   *ProductItem      `gorm:"foreignKey:product_item_id;references:id" json:"productItem"`
+	Price         float64           `gorm:"column:price"                             json:"price"`
+	PreviousPrice *float64          `gorm:"column:previous_price"                    json:"previousPrice"`
+	Source        PriceChangeSource `gorm:"column:source"                            json:"source"`
+	ScheduleID    *uuid.UUID        `gorm:"column:schedule_id"                       json:"scheduleId"`
+}
+
+func (ProductItemPrice) TableName() string {
+	return "product_item_price_history"
+}
+
+type PriceChangeSource int
+
+const (
+	PriceChangeSourceManual PriceChangeSource = iota
+	PriceChangeSourceImport
+	PriceChangeSourceSchedule
+)
+
+func (s PriceChangeSource) String() string {
+	switch s {
+	case PriceChangeSourceManual:
+		return "Manual"
+	case PriceChangeSourceImport:
+		return "Import"
+	case PriceChangeSourceSchedule:
+		return "Schedule"
+	default:
+		return "unknown"
+	}
+}
+
+// ProductItemPriceSchedule is a future price of a product item which is applied by the scheduler
+type ProductItemPriceSchedule struct {
+	Model
+
+	ProductItemID uuid.UUID                      `gorm:"column:product_item_id"                   json:"productItemId"`
+	ProductItem   *ProductItem                   `gorm:"foreignKey:product_item_id;references:id" json:"productItem"`
+	Price         float64                        `gorm:"column:price"                             json:"price"`
+	ApplyAt       time.Time                      `gorm:"column:apply_at"                          json:"applyAt"`
+	Status        ProductItemPriceScheduleStatus `gorm:"column:status"                            json:"status"`
+	AppliedAt     *time.Time                     `gorm:"column:applied_at"                        json:"appliedAt"`
+}
+
+func (ProductItemPriceSchedule) TableName() string {
+	return "product_item_price_schedule"
+}
+
+type ProductItemPriceScheduleStatus int
+
+const (
+	ProductItemPriceSchedulePending ProductItemPriceScheduleStatus = iota
+	ProductItemPriceScheduleApplied
+	ProductItemPriceScheduleCanceled
+)
+
+func (s ProductItemPriceScheduleStatus) String() string {
+	switch s {
+	case ProductItemPriceSchedulePending:
+		return "Pending"
+	case ProductItemPriceScheduleApplied:
+		return "Applied"
+	case ProductItemPriceScheduleCanceled:
+		return "Canceled"
+	default:
+		return "unknown"
+	}
+}
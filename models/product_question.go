@@ -0,0 +1,52 @@
+package models
+
+import "github.com/google/uuid"
+
+// ProductQuestion is a question which is asked about a product before buying it, the questions and
+// the answers are moderated like the comments.
+type ProductQuestion struct {
+	Model
+
+	ProductID uuid.UUID       `gorm:"column:product_id"                        json:"productId"`
+	Product   *Product        `gorm:"foreignKey:product_id; references:id;"    json:"product"`
+	Text      string          `gorm:"column:text"                              json:"text"`
+	Status    CommentStatus   `gorm:"column:status"                            json:"status"`
+	AdminNote *string         `gorm:"column:admin_note"                        json:"adminNote"`
+	Upvotes   int             `gorm:"column:upvotes"                           json:"upvotes"`
+	Answers   []ProductAnswer `gorm:"foreignKey:question_id;references:id"     json:"answers"`
+}
+
+func (ProductQuestion) TableName() string {
+	return "product_question"
+}
+
+// ProductAnswer is the answer of the staff or the other customers to a question, the answers of the staff
+// are accepted at once and the verified buyer flag is set when the customer has a paid order of the product.
+type ProductAnswer struct {
+	Model
+
+	QuestionID      uuid.UUID        `gorm:"column:question_id"                       json:"questionId"`
+	Question        *ProductQuestion `gorm:"foreignKey:question_id; references:id;"   json:"question"`
+	Text            string           `gorm:"column:text"                              json:"text"`
+	Status          CommentStatus    `gorm:"column:status"                            json:"status"`
+	AdminNote       *string          `gorm:"column:admin_note"                        json:"adminNote"`
+	Upvotes         int              `gorm:"column:upvotes"                           json:"upvotes"`
+	IsStaff         bool             `gorm:"column:is_staff"                          json:"isStaff"`
+	IsVerifiedBuyer bool             `gorm:"column:is_verified_buyer"                 json:"isVerifiedBuyer"`
+}
+
+func (ProductAnswer) TableName() string {
+	return "product_answer"
+}
+
+// ProductQAVote is the upvote of a user to either a question or an answer
+type ProductQAVote struct {
+	Model
+
+	QuestionID *uuid.UUID `gorm:"column:question_id"    json:"questionId"`
+	AnswerID   *uuid.UUID `gorm:"column:answer_id"      json:"answerId"`
+}
+
+func (ProductQAVote) TableName() string {
+	return "product_qa_vote"
+}
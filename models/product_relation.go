@@ -0,0 +1,47 @@
+package models
+
+import "github.com/google/uuid"
+
+type ProductRelation struct {
+	Model
+
+	ProductID        uuid.UUID           `gorm:"column:product_id"                              json:"productId"`
+	Product          *Product            `gorm:"foreignKey:product_id;references:id"            json:"product"`
+	RelatedProductID uuid.UUID           `gorm:"column:related_product_id"                      json:"relatedProductId"`
+	RelatedProduct   *Product            `gorm:"foreignKey:related_product_id;references:id"    json:"relatedProduct"`
+	Type             ProductRelationType `gorm:"column:type"                                    json:"type"`
+	Priority         int                 `gorm:"column:priority"                                json:"priority"`
+
+	// the number of orders which contain both products, it is only set for the frequently bought together relations
+	Score int `gorm:"column:score"                                   json:"score"`
+}
+
+func (ProductRelation) TableName() string {
+	return "product_relation"
+}
+
+type ProductRelationType int
+
+const (
+	ProductRelationTypeRelated ProductRelationType = iota
+	ProductRelationTypeCrossSell
+	ProductRelationTypeUpSell
+
+	// the frequently bought together relations are computed by the job and they are not editable
+	ProductRelationTypeBoughtTogether
+)
+
+func (t ProductRelationType) String() string {
+	switch t {
+	case ProductRelationTypeRelated:
+		return "Related"
+	case ProductRelationTypeCrossSell:
+		return "CrossSell"
+	case ProductRelationTypeUpSell:
+		return "UpSell"
+	case ProductRelationTypeBoughtTogether:
+		return "BoughtTogether"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	datatypes "github.com/esmailemami/eshop/models/data_types"
+	"github.com/google/uuid"
+)
+
+type ProductRevision struct {
+	Model
+
+	ProductID        uuid.UUID               `gorm:"column:product_id"                     json:"productId"`
+	Product          *Product                `gorm:"foreignKey:product_id;references:id"   json:"product"`
+	Number           int                     `gorm:"column:number"                         json:"number"`
+	Status           ProductRevisionStatus   `gorm:"column:status"                         json:"status"`
+	Name             string                  `gorm:"column:name"                           json:"name"`
+	ShortDescription string                  `gorm:"column:short_description"              json:"shortDescription"`
+	Description      string                  `gorm:"column:description"                    json:"description"`
+	TopFeatures      datatypes.StringArray   `gorm:"column:top_features"                   json:"topFeatures"`
+	Features         ProductRevisionFeatures `gorm:"column:features"                       json:"features"`
+	Note             *string                 `gorm:"column:note"                           json:"note"`
+	RestoredFromID   *uuid.UUID              `gorm:"column:restored_from_id"               json:"restoredFromId"`
+	BaseHash         *string                 `gorm:"column:base_hash"                      json:"-"`
+	ReviewedByID     *uuid.UUID              `gorm:"column:reviewed_by_id"                 json:"reviewedById"`
+	ReviewedBy       *User                   `gorm:"foreignKey:reviewed_by_id;references:id" json:"reviewedBy"`
+	ReviewedAt       *time.Time              `gorm:"column:reviewed_at"                    json:"reviewedAt"`
+	ReviewNote       *string                 `gorm:"column:review_note"                    json:"reviewNote"`
+}
+
+func (ProductRevision) TableName() string {
+	return "product_revision"
+}
+
+type ProductRevisionStatus int
+
+const (
+	ProductRevisionStatusDraft ProductRevisionStatus = iota
+	ProductRevisionStatusApproved
+	ProductRevisionStatusRejected
+)
+
+func (s ProductRevisionStatus) String() string {
+	switch s {
+	case ProductRevisionStatusDraft:
+		return "Draft"
+	case ProductRevisionStatusApproved:
+		return "Approved"
+	case ProductRevisionStatusRejected:
+		return "Rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// ProductRevisionFeature is the snapshot of a product feature value, the key name is kept for the history
+type ProductRevisionFeature struct {
+	ProductFeatureKeyID uuid.UUID `json:"productFeatureKeyId"`
+	Key                 string    `json:"key"`
+	Value               string    `json:"value"`
+}
+
+type ProductRevisionFeatures []ProductRevisionFeature
+
+func (p ProductRevisionFeatures) Value() (driver.Value, error) {
+	valueString, err := json.Marshal(p)
+	return string(valueString), err
+}
+
+func (p *ProductRevisionFeatures) Scan(value interface{}) error {
+	var bts []byte
+	switch v := value.(type) {
+	case []byte:
+		bts = v
+	case string:
+		bts = []byte(v)
+	default:
+		*p = nil
+		return nil
+	}
+	return json.Unmarshal(bts, p)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is an opaque token which is exchanged for a new access token and a new refresh token, only the
+// hash of the token is stored. The tokens which are rotated from the same login share the family.
+type RefreshToken struct {
+	BasicModel
+	FamilyID     uuid.UUID  `gorm:"column:family_id"      json:"familyId"`
+	UserID       uuid.UUID  `gorm:"column:user_id"        json:"userId"`
+	AuthTokenID  uuid.UUID  `gorm:"column:auth_token_id"  json:"authTokenId"`
+	TokenHash    string     `gorm:"column:token_hash"     json:"-"`
+	ActAs        string     `gorm:"column:act_as"         json:"actAs"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at"     json:"expiresAt"`
+	UsedAt       *time.Time `gorm:"column:used_at"        json:"usedAt"`
+	ReplacedByID *uuid.UUID `gorm:"column:replaced_by_id" json:"replacedById"`
+	Revoked      bool       `gorm:"column:revoked"        json:"revoked"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_token"
+}
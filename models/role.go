@@ -3,21 +3,32 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+
+	"github.com/google/uuid"
 )
 
 type Role struct {
 	Model
 
-	Name        string          `gorm:"column:name"        json:"name"`
-	Code        string          `gorm:"column:code"        json:"code"`
-	IsSystem    bool            `gorm:"column:is_system"   json:"isSystem"`
-	Permissions RolePermissions `gorm:"column:permissions" json:"permissions"`
+	Name        string          `gorm:"column:name"         json:"name"`
+	Code        string          `gorm:"column:code"         json:"code"`
+	IsSystem    bool            `gorm:"column:is_system"    json:"isSystem"`
+	Permissions RolePermissions `gorm:"column:permissions"  json:"permissions"`
+	MfaRequired bool            `gorm:"column:mfa_required" json:"mfaRequired"`
+
+	// PermissionScopes limits the permissions to some resources, the permissions without scope are not limited
+	PermissionScopes RolePermissionScopes `gorm:"column:permission_scopes" json:"permissionScopes"`
 }
 
 func (Role) TableName() string {
 	return "role"
 }
 
+// RequiresMfa reports whether the users of the role must pass the 2FA to login as admin
+func (model Role) RequiresMfa() bool {
+	return model.MfaRequired && model.Permitted(ACTION_CAN_LOGIN_ADMIN)
+}
+
 func (model Role) Permitted(action string) bool {
 	for _, p := range model.Permissions {
 		if p == action {
@@ -28,6 +39,30 @@ func (model Role) Permitted(action string) bool {
 	return false
 }
 
+// PermissionScope returns the scope of the permitted action, ok is false when the action is not permitted and a nil
+// scope means the action is permitted on every resource
+func (model Role) PermissionScope(action string) (scope *PermissionScope, ok bool) {
+	if !model.Permitted(action) {
+		return nil, false
+	}
+
+	if s, ok := model.PermissionScopes[action]; ok {
+		return &s, true
+	}
+
+	return nil, true
+}
+
+// Ref returns the identity of the role
+func (model Role) Ref() RoleRef {
+	ref := RoleRef{Name: model.Name, Code: model.Code}
+	if model.ID != nil {
+		ref.ID = *model.ID
+	}
+
+	return ref
+}
+
 type RolePermissions []string
 
 func (p RolePermissions) Value() (driver.Value, error) {
@@ -52,3 +87,69 @@ func (j *RolePermissions) Scan(value interface{}) error {
 	}
 	return json.Unmarshal(bts, &j)
 }
+
+// PermissionScope limits a permission to the resources of the categories and the brands, a resource must match
+// every list which is not empty
+type PermissionScope struct {
+	CategoryIDs []uuid.UUID `json:"categoryIds,omitempty"`
+	BrandIDs    []uuid.UUID `json:"brandIds,omitempty"`
+}
+
+// PermissionResource is the attributes of a resource which the scopes are checked against
+type PermissionResource struct {
+	CategoryID *uuid.UUID
+	BrandID    *uuid.UUID
+}
+
+// Allows reports whether the resource is in the scope
+func (s PermissionScope) Allows(resource PermissionResource) bool {
+	return scopeContains(s.CategoryIDs, resource.CategoryID) && scopeContains(s.BrandIDs, resource.BrandID)
+}
+
+// Empty reports whether the scope does not limit anything
+func (s PermissionScope) Empty() bool {
+	return len(s.CategoryIDs) == 0 && len(s.BrandIDs) == 0
+}
+
+func scopeContains(ids []uuid.UUID, id *uuid.UUID) bool {
+	if len(ids) == 0 {
+		return true
+	}
+
+	if id == nil {
+		return false
+	}
+
+	for _, i := range ids {
+		if i == *id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RolePermissionScopes is the scopes of the permissions by their codes
+type RolePermissionScopes map[string]PermissionScope
+
+func (p RolePermissionScopes) Value() (driver.Value, error) {
+	if p == nil {
+		p = RolePermissionScopes{}
+	}
+	valueString, err := json.Marshal(p)
+	return string(valueString), err
+}
+
+func (j *RolePermissionScopes) Scan(value interface{}) error {
+	var bts []byte
+	switch v := value.(type) {
+	case []byte:
+		bts = v
+	case string:
+		bts = []byte(v)
+	default:
+		*j = nil
+		return nil
+	}
+	return json.Unmarshal(bts, j)
+}
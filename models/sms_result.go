@@ -8,8 +8,10 @@ import (
 )
 
 type SmsResult struct {
-	ID        *uuid.UUID `gorm:"column:primaryKey"        json:"id"`
+	ID        *uuid.UUID `gorm:"primaryKey"        json:"id"`
 	CreatedAt time.Time  `gorm:"column:created_at" json:"createdAt"`
+	Status    SmsStatus  `gorm:"column:status"     json:"status"`
+	Error     *string    `gorm:"column:error"      json:"error"`
 
 	sms.SmsResult
 }
@@ -17,3 +19,10 @@ type SmsResult struct {
 func (SmsResult) TableName() string {
 	return "sms_result"
 }
+
+type SmsStatus int
+
+const (
+	SmsStatusSent SmsStatus = iota
+	SmsStatusFailed
+)
@@ -0,0 +1,62 @@
+package models
+
+import "github.com/google/uuid"
+
+// StockMovement is an append-only log of the stock changes, the rows are never updated or deleted.
+// The rows without a warehouse are the ledger of the sellable quantity of the product items and the others
+// are the stock of the locations. SourceID is the order of the sales, returns and reservations, the stock transfer
+// of the transfers and the product import of the imports. The items of the carts are reserved and the reservations
+// become sales at the checkout.
+type StockMovement struct {
+	Model
+
+	WarehouseID   *uuid.UUID          `gorm:"column:warehouse_id"                      json:"warehouseId"`
+	Warehouse     *Warehouse          `gorm:"foreignKey:warehouse_id;references:id"    json:"warehouse"`
+	ProductItemID uuid.UUID           `gorm:"column:product_item_id"                   json:"productItemId"`
+	ProductItem   *ProductItem        `gorm:"foreignKey:product_item_id;references:id" json:"productItem"`
+	Quantity      int                 `gorm:"column:quantity"                          json:"quantity"`
+	Balance       int                 `gorm:"column:balance"                           json:"balance"`
+	Reason        StockMovementReason `gorm:"column:reason"                            json:"reason"`
+	SourceID      *uuid.UUID          `gorm:"column:source_id"                         json:"sourceId"`
+	Note          *string             `gorm:"column:note"                              json:"note"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movement"
+}
+
+type StockMovementReason int
+
+const (
+	StockMovementReasonAdjustment StockMovementReason = iota
+	StockMovementReasonSale
+	StockMovementReasonTransferOut
+	StockMovementReasonTransferIn
+	StockMovementReasonReturn
+	StockMovementReasonImport
+	StockMovementReasonReserve
+	StockMovementReasonRelease
+)
+
+func (r StockMovementReason) String() string {
+	switch r {
+	case StockMovementReasonAdjustment:
+		return "Adjustment"
+	case StockMovementReasonSale:
+		return "Sale"
+	case StockMovementReasonTransferOut:
+		return "TransferOut"
+	case StockMovementReasonTransferIn:
+		return "TransferIn"
+	case StockMovementReasonReturn:
+		return "Return"
+	case StockMovementReasonImport:
+		return "Import"
+	case StockMovementReasonReserve:
+		return "Reserve"
+	case StockMovementReasonRelease:
+		return "Release"
+	default:
+		return "unknown"
+	}
+}
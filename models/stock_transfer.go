@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockTransfer is a document which moves the stock of product items between two locations
+type StockTransfer struct {
+	Model
+
+	Code            string              `gorm:"column:code"                                 json:"code"`
+	FromWarehouseID uuid.UUID           `gorm:"column:from_warehouse_id"                    json:"fromWarehouseId"`
+	FromWarehouse   *Warehouse          `gorm:"foreignKey:from_warehouse_id;references:id"  json:"fromWarehouse"`
+	ToWarehouseID   uuid.UUID           `gorm:"column:to_warehouse_id"                      json:"toWarehouseId"`
+	ToWarehouse     *Warehouse          `gorm:"foreignKey:to_warehouse_id;references:id"    json:"toWarehouse"`
+	Status          StockTransferStatus `gorm:"column:status"                               json:"status"`
+	Note            *string             `gorm:"column:note"                                 json:"note"`
+	CompletedAt     *time.Time          `gorm:"column:completed_at"                         json:"completedAt"`
+	Items           []StockTransferItem `gorm:"foreignKey:stock_transfer_id;references:id"  json:"items"`
+}
+
+func (StockTransfer) TableName() string {
+	return "stock_transfer"
+}
+
+type StockTransferStatus int
+
+const (
+	StockTransferStatusDraft StockTransferStatus = iota
+	StockTransferStatusCompleted
+	StockTransferStatusCanceled
+)
+
+func (s StockTransferStatus) String() string {
+	switch s {
+	case StockTransferStatusDraft:
+		return "Draft"
+	case StockTransferStatusCompleted:
+		return "Completed"
+	case StockTransferStatusCanceled:
+		return "Canceled"
+	default:
+		return "unknown"
+	}
+}
+
+type StockTransferItem struct {
+	Model
+
+	StockTransferID uuid.UUID      `gorm:"column:stock_transfer_id"                   json:"stockTransferId"`
+	StockTransfer   *StockTransfer `gorm:"foreignKey:stock_transfer_id;references:id" json:"stockTransfer"`
+	ProductItemID   uuid.UUID      `gorm:"column:product_item_id"                     json:"productItemId"`
+	ProductItem     *ProductItem   `gorm:"foreignKey:product_item_id;references:id"   json:"productItem"`
+	Quantity        int            `gorm:"column:quantity"                            json:"quantity"`
+}
+
+func (StockTransferItem) TableName() string {
+	return "stock_transfer_item"
+}
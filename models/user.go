@@ -1,26 +1,33 @@
 package models
 
 import (
+	"time"
+
 	"github.com/esmailemami/eshop/app/consts"
 	"github.com/google/uuid"
 )
 
 type User struct {
 	Model
-	Username   string      `gorm:"column:username"                                 json:"username"`
-	Password   string      `gorm:"column:password"                                 json:"-"`
-	FirstName  *string     `gorm:"column:first_name"                               json:"firstName"`
-	LastName   *string     `gorm:"column:last_name"                                json:"lastName"`
-	Mobile     *string     `gorm:"column:mobile"                                   json:"mobile"`
-	RoleID     *uuid.UUID  `gorm:"column:role_id"                                  json:"roleId"`
-	Email      *string     `gorm:"email"                                           json:"email"`
-	Role       *Role       `gorm:"foreignKey:role_id; references:id"               json:"role"`
-	IsSystem   bool        `gorm:"column:is_system"                                json:"isSystem"`
-	Enabled    bool        `gorm:"column:enabled"                                  json:"enabled"`
-	AuthTokens []AuthToken `gorm:"foreignKey:user_id;references:id"                json:"authTokens"`
-	Comments   []Comment   `gorm:"foreignKey:created_by_id;references:id"          json:"comments"`
-	Addresses  []Address   `gorm:"foreignKey:created_by_id;references:id"          json:"addresses"`
-	Discounts  []Discount  `gorm:"foreignKey:related_user_id;references:id"        json:"discounts"`
+	Username         string         `gorm:"column:username"                                 json:"username"`
+	Password         string         `gorm:"column:password"                                 json:"-"`
+	FirstName        *string        `gorm:"column:first_name"                               json:"firstName"`
+	LastName         *string        `gorm:"column:last_name"                                json:"lastName"`
+	Mobile           *string        `gorm:"column:mobile"                                   json:"mobile"`
+	Email            *string        `gorm:"email"                                           json:"email"`
+	EmailVerified    bool           `gorm:"column:email_verified"                           json:"emailVerified"`
+	EmailVerifiedAt  *time.Time     `gorm:"column:email_verified_at"                        json:"emailVerifiedAt"`
+	MobileVerified   bool           `gorm:"column:mobile_verified"                          json:"mobileVerified"`
+	MobileVerifiedAt *time.Time     `gorm:"column:mobile_verified_at"                       json:"mobileVerifiedAt"`
+	PendingEmail     *string        `gorm:"column:pending_email"                            json:"pendingEmail"`
+	PendingMobile    *string        `gorm:"column:pending_mobile"                           json:"pendingMobile"`
+	Role             *EffectiveRole `gorm:"-"                                              json:"role"`
+	IsSystem         bool           `gorm:"column:is_system"                                json:"isSystem"`
+	Enabled          bool           `gorm:"column:enabled"                                  json:"enabled"`
+	AuthTokens       []AuthToken    `gorm:"foreignKey:user_id;references:id"                json:"authTokens"`
+	Comments         []Comment      `gorm:"foreignKey:created_by_id;references:id"          json:"comments"`
+	Addresses        []Address      `gorm:"foreignKey:created_by_id;references:id"          json:"addresses"`
+	Discounts        []Discount     `gorm:"foreignKey:related_user_id;references:id"        json:"discounts"`
 }
 
 func (User) TableName() string {
@@ -40,9 +47,46 @@ func (user User) Can(action string) bool {
 }
 
 func (user User) IsRoot() bool {
-	if user.RoleID == nil {
-		return false
+	return user.Role.HasRole(uuid.MustParse(consts.ROLE_ROOT_ID))
+}
+
+// VerifiedEmail returns the email when its ownership is proved
+func (user User) VerifiedEmail() (string, bool) {
+	if !user.EmailVerified || user.Email == nil || *user.Email == "" {
+		return "", false
 	}
+	return *user.Email, true
+}
+
+// VerifiedMobile returns the mobile number when its ownership is proved
+func (user User) VerifiedMobile() (string, bool) {
+	if !user.MobileVerified || user.Mobile == nil || *user.Mobile == "" {
+		return "", false
+	}
+	return *user.Mobile, true
+}
 
-	return user.RoleID.String() == consts.ROLE_ROOT_ID
+// SetEmail changes the email, the verification is reset when the email is changed
+func (user *User) SetEmail(email *string) {
+	if !sameContact(user.Email, email) {
+		user.EmailVerified = false
+		user.EmailVerifiedAt = nil
+	}
+	user.Email = email
+}
+
+// SetMobile changes the mobile number, the verification is reset when the mobile number is changed
+func (user *User) SetMobile(mobile *string) {
+	if !sameContact(user.Mobile, mobile) {
+		user.MobileVerified = false
+		user.MobileVerifiedAt = nil
+	}
+	user.Mobile = mobile
+}
+
+func sameContact(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
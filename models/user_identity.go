@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// UserIdentity links the account of an OAuth provider to the user, the subject is the stable id of the account in
+// the provider
+type UserIdentity struct {
+	BasicModel
+	UserID   uuid.UUID `gorm:"column:user_id"                   json:"userId"`
+	User     *User     `gorm:"foreignKey:user_id;references:id" json:"user"`
+	Provider string    `gorm:"column:provider"                  json:"provider"`
+	Subject  string    `gorm:"column:subject"                   json:"-"`
+	Email    *string   `gorm:"column:email"                     json:"email"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identity"
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMfa is the TOTP secret of the user, the 2FA is enabled once the first code of the secret is confirmed
+type UserMfa struct {
+	BasicModel
+	UserID       uuid.UUID  `gorm:"column:user_id"        json:"userId"`
+	Secret       string     `gorm:"column:secret"         json:"-"`
+	Enabled      bool       `gorm:"column:enabled"        json:"enabled"`
+	EnabledAt    *time.Time `gorm:"column:enabled_at"     json:"enabledAt"`
+	LastUsedStep int64      `gorm:"column:last_used_step" json:"-"`
+}
+
+func (UserMfa) TableName() string {
+	return "user_mfa"
+}
+
+// MfaRecoveryCode is a one time code which is used instead of the TOTP code, only the hash of the code is stored
+type MfaRecoveryCode struct {
+	BasicModel
+	UserID   uuid.UUID  `gorm:"column:user_id"   json:"userId"`
+	CodeHash string     `gorm:"column:code_hash" json:"-"`
+	UsedAt   *time.Time `gorm:"column:used_at"   json:"usedAt"`
+}
+
+func (MfaRecoveryCode) TableName() string {
+	return "mfa_recovery_code"
+}
+
+// MfaChallenge is the "mfa pending" state of a login whose password is checked, the tokens are issued when the
+// second factor of the challenge is verified
+type MfaChallenge struct {
+	BasicModel
+	UserID    uuid.UUID  `gorm:"column:user_id"    json:"userId"`
+	TokenHash string     `gorm:"column:token_hash" json:"-"`
+	ActAs     string     `gorm:"column:act_as"     json:"actAs"`
+	ExpiresAt time.Time  `gorm:"column:expires_at" json:"expiresAt"`
+	Attempts  int        `gorm:"column:attempts"   json:"attempts"`
+	UsedAt    *time.Time `gorm:"column:used_at"    json:"usedAt"`
+}
+
+func (MfaChallenge) TableName() string {
+	return "mfa_challenge"
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRole assigns a role to the user, a user has every permission of its roles
+type UserRole struct {
+	UserID    uuid.UUID `gorm:"column:user_id;primaryKey" json:"userId"`
+	RoleID    uuid.UUID `gorm:"column:role_id;primaryKey" json:"roleId"`
+	CreatedAt time.Time `gorm:"column:created_at"         json:"createdAt"`
+}
+
+func (UserRole) TableName() string {
+	return "user_role"
+}
+
+// RoleInheritance makes the role include the permissions of the parent role
+type RoleInheritance struct {
+	RoleID    uuid.UUID `gorm:"column:role_id;primaryKey"   json:"roleId"`
+	ParentID  uuid.UUID `gorm:"column:parent_id;primaryKey" json:"parentId"`
+	CreatedAt time.Time `gorm:"column:created_at"           json:"createdAt"`
+}
+
+func (RoleInheritance) TableName() string {
+	return "role_inheritance"
+}
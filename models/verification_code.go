@@ -21,6 +21,8 @@ func (VerificationCode) TableName() string {
 type VerificationCodeScope int
 
 const (
+	// the password recovery links of the email
 	VerificationCodeScopeEmail VerificationCodeScope = iota
 	VerificationCodeScopeMobile
+	VerificationCodeScopeEmailVerification
 )
@@ -0,0 +1,54 @@
+package models
+
+import "github.com/google/uuid"
+
+// Warehouse is a stock location, the stores are locations which the items can be sold and shipped from too
+type Warehouse struct {
+	Model
+
+	Name       string           `gorm:"column:name"                                json:"name"`
+	Code       string           `gorm:"column:code"                                json:"code"`
+	Type       WarehouseType    `gorm:"column:type"                                json:"type"`
+	PostalCode string           `gorm:"column:postal_code"                         json:"postalCode"`
+	Address    string           `gorm:"column:address"                             json:"address"`
+	IsActive   bool             `gorm:"column:is_active"                           json:"isActive"`
+	Stocks     []WarehouseStock `gorm:"foreignKey:warehouse_id;references:id" json:"stocks"`
+}
+
+func (Warehouse) TableName() string {
+	return "warehouse"
+}
+
+type WarehouseType int
+
+const (
+	WarehouseTypeWarehouse WarehouseType = iota
+	WarehouseTypeStore
+)
+
+func (t WarehouseType) String() string {
+	switch t {
+	case WarehouseTypeWarehouse:
+		return "Warehouse"
+	case WarehouseTypeStore:
+		return "Store"
+	default:
+		return "unknown"
+	}
+}
+
+// WarehouseStock is the on hand quantity of a product item in a location, it is changed only along with
+// a stock movement
+type WarehouseStock struct {
+	Model
+
+	WarehouseID   uuid.UUID    `gorm:"column:warehouse_id"                      json:"warehouseId"`
+	Warehouse     *Warehouse   `gorm:"foreignKey:warehouse_id;references:id"    json:"warehouse"`
+	ProductItemID uuid.UUID    `gorm:"column:product_item_id"                   json:"productItemId"`
+	ProductItem   *ProductItem `gorm:"foreignKey:product_item_id;references:id" json:"productItem"`
+	Quantity      int          `gorm:"column:quantity"                          json:"quantity"`
+}
+
+func (WarehouseStock) TableName() string {
+	return "warehouse_stock"
+}